@@ -0,0 +1,52 @@
+package antispam
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Submission is everything known about anonymous form submission before it is stored
+type Submission struct {
+	CampaignID   string
+	Honeypot     string
+	FormToken    string
+	CaptchaToken string
+	RemoteIP     string
+	ContentHash  string
+	SubmittedAt  time.Time
+}
+
+// Check inspects submission, non empty reason means submission is suspected as spam
+type Check interface {
+	Check(ctx context.Context, s Submission) (string, error)
+}
+
+// Inspect runs checks in order and returns the first reason found
+func Inspect(ctx context.Context, checks []Check, s Submission) (string, error) {
+	for _, check := range checks {
+		reason, err := check.Check(ctx, s)
+		if err != nil {
+			return "", err
+		}
+		if reason != "" {
+			return reason, nil
+		}
+	}
+	return "", nil
+}
+
+// ContentHash builds fingerprint of answers, order and letter case are ignored
+func ContentHash(answers []string) string {
+	normalized := make([]string, 0, len(answers))
+	for _, v := range answers {
+		normalized = append(normalized, strings.ToLower(strings.Join(strings.Fields(v), " ")))
+	}
+	sort.Strings(normalized)
+
+	sum := sha256.Sum256([]byte(strings.Join(normalized, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
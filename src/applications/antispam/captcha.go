@@ -0,0 +1,81 @@
+package antispam
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CaptchaVerifier checks captcha token solved by the client
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token string, remoteIP string) (bool, error)
+}
+
+// NewCaptchaVerifier uses siteverify endpoint when secret is provided
+// without secret FakeCaptchaToken is accepted on development, other environments reject every token
+func NewCaptchaVerifier(verifyURL string, secret string, isDev bool) CaptchaVerifier {
+	if secret == "" {
+		if isDev {
+			return FakeCaptchaVerifier{Token: FakeCaptchaToken}
+		}
+		slog.Warn("captcha secret is empty, every captcha will be rejected")
+		return FakeCaptchaVerifier{}
+	}
+	return &HTTPCaptchaVerifier{
+		URL:    verifyURL,
+		Secret: secret,
+		Client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// HTTPCaptchaVerifier talks to siteverify api, compatible with reCAPTCHA, hCaptcha and Turnstile
+type HTTPCaptchaVerifier struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+func (v *HTTPCaptchaVerifier) Verify(ctx context.Context, token string, remoteIP string) (bool, error) {
+	form := url.Values{
+		"secret":   {v.Secret},
+		"response": {token},
+	}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, err
+	}
+	return result.Success, nil
+}
+
+const FakeCaptchaToken = "fake-captcha-token"
+
+// FakeCaptchaVerifier accepts only its token, use it for tests and local development
+type FakeCaptchaVerifier struct {
+	Token string
+}
+
+func (v FakeCaptchaVerifier) Verify(ctx context.Context, token string, remoteIP string) (bool, error) {
+	return v.Token != "" && token == v.Token, nil
+}
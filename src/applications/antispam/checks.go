@@ -0,0 +1,84 @@
+package antispam
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Honeypot flags submission that fills hidden field, only bots can see it
+type Honeypot struct{}
+
+func (Honeypot) Check(ctx context.Context, s Submission) (string, error) {
+	if s.Honeypot != "" {
+		return "honeypot field is filled", nil
+	}
+	return "", nil
+}
+
+// MinSubmitTime flags submission sent faster than human can fill the form, or sent with token
+// already spent by another submission, Spend marks nonce as used and tells whether it was used before
+type MinSubmitTime struct {
+	Secret []byte
+	Min    time.Duration
+	Spend  func(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+func (m MinSubmitTime) Check(ctx context.Context, s Submission) (string, error) {
+	token, err := ParseFormToken(m.Secret, s.FormToken, s.CampaignID, s.SubmittedAt)
+	if err != nil {
+		return "form token is " + err.Error(), nil
+	}
+	// store being down must not drop the entry, keep it for review instead
+	spent, err := m.Spend(ctx, token.Nonce, FormTokenMaxAge)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to spend form token", "error", err)
+		return "form token could not be checked", nil
+	}
+	if spent {
+		return "form token is already used", nil
+	}
+	if elapsed := s.SubmittedAt.Sub(token.IssuedAt); elapsed < m.Min {
+		return fmt.Sprintf("submitted in %d seconds, minimum is %d seconds", int(elapsed.Seconds()), int(m.Min.Seconds())), nil
+	}
+	return "", nil
+}
+
+// Captcha flags submission without solved challenge
+type Captcha struct {
+	Verifier CaptchaVerifier
+}
+
+func (c Captcha) Check(ctx context.Context, s Submission) (string, error) {
+	if s.CaptchaToken == "" {
+		return "captcha is missing", nil
+	}
+	// provider being down must not drop the entry, keep it for review instead
+	ok, err := c.Verifier.Verify(ctx, s.CaptchaToken, s.RemoteIP)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to verify captcha", "error", err)
+		return "captcha could not be verified", nil
+	}
+	if !ok {
+		return "captcha is not valid", nil
+	}
+	return "", nil
+}
+
+// Duplicate flags submission with the same answers as recent entry of the campaign
+type Duplicate struct {
+	Window time.Duration
+	Exists func(ctx context.Context, campaignID string, contentHash string, since time.Time) (bool, error)
+}
+
+func (d Duplicate) Check(ctx context.Context, s Submission) (string, error) {
+	exists, err := d.Exists(ctx, s.CampaignID, s.ContentHash, s.SubmittedAt.Add(-d.Window))
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "duplicate content of recent entry", nil
+	}
+	return "", nil
+}
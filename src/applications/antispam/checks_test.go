@@ -0,0 +1,130 @@
+package antispam
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// spentNonces is Spend of MinSubmitTime keeping nonces in memory
+func spentNonces() func(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	spent := map[string]bool{}
+	return func(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+		used := spent[nonce]
+		spent[nonce] = true
+		return used, nil
+	}
+}
+
+func TestHoneypot(t *testing.T) {
+	ctx := context.Background()
+	if reason, _ := (Honeypot{}).Check(ctx, Submission{}); reason != "" {
+		t.Fatalf("empty honeypot is flagged: %s", reason)
+	}
+	if reason, _ := (Honeypot{}).Check(ctx, Submission{Honeypot: "https://spam.example.com"}); reason != "honeypot field is filled" {
+		t.Fatalf("got reason %q, want filled honeypot", reason)
+	}
+}
+
+func TestMinSubmitTime(t *testing.T) {
+	ctx := context.Background()
+	secret := []byte("test secret key")
+	openedAt := time.Now()
+	check := MinSubmitTime{Secret: secret, Min: 5 * time.Second, Spend: spentNonces()}
+	submission := func(submittedAt time.Time) Submission {
+		token, err := SignFormToken(secret, "campaign-1", openedAt)
+		if err != nil {
+			t.Fatalf("sign form token: %v", err)
+		}
+		return Submission{CampaignID: "campaign-1", FormToken: token, SubmittedAt: submittedAt}
+	}
+
+	if reason, _ := check.Check(ctx, submission(openedAt.Add(2*time.Second))); reason != "submitted in 2 seconds, minimum is 5 seconds" {
+		t.Fatalf("got reason %q, want too fast", reason)
+	}
+	human := submission(openedAt.Add(30 * time.Second))
+	if reason, _ := check.Check(ctx, human); reason != "" {
+		t.Fatalf("human submission is flagged: %s", reason)
+	}
+
+	// token of slow submission is replayed by script
+	if reason, _ := check.Check(ctx, human); reason != "form token is already used" {
+		t.Fatalf("got reason %q, want replay", reason)
+	}
+	if reason, _ := check.Check(ctx, Submission{CampaignID: "campaign-1", SubmittedAt: openedAt}); reason != "form token is missing" {
+		t.Fatalf("got reason %q, want missing token", reason)
+	}
+
+	// store failure keeps the entry for review
+	check.Spend = func(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+		return false, errors.New("connection refused")
+	}
+	if reason, err := check.Check(ctx, submission(openedAt.Add(30*time.Second))); err != nil || reason != "form token could not be checked" {
+		t.Fatalf("got reason %q error %v, want unchecked token", reason, err)
+	}
+}
+
+func TestCaptcha(t *testing.T) {
+	ctx := context.Background()
+	check := Captcha{Verifier: FakeCaptchaVerifier{Token: FakeCaptchaToken}}
+	cases := map[string]string{
+		"":               "captcha is missing",
+		"wrong":          "captcha is not valid",
+		FakeCaptchaToken: "",
+	}
+	for token, want := range cases {
+		if reason, _ := check.Check(ctx, Submission{CaptchaToken: token}); reason != want {
+			t.Fatalf("token %q got reason %q, want %q", token, reason, want)
+		}
+	}
+
+	// verifier without secret on production rejects every token
+	rejectAll := Captcha{Verifier: NewCaptchaVerifier("", "", false)}
+	if reason, _ := rejectAll.Check(ctx, Submission{CaptchaToken: FakeCaptchaToken}); reason != "captcha is not valid" {
+		t.Fatalf("got reason %q, want rejected fake token", reason)
+	}
+}
+
+func TestDuplicate(t *testing.T) {
+	ctx := context.Background()
+	submittedAt := time.Now()
+	seen := ContentHash([]string{"name=Jane", "city=Bandung"})
+	check := Duplicate{
+		Window: time.Hour,
+		Exists: func(ctx context.Context, campaignID string, contentHash string, since time.Time) (bool, error) {
+			if !since.Equal(submittedAt.Add(-time.Hour)) {
+				t.Fatalf("got since %s, want start of window", since)
+			}
+			return contentHash == seen, nil
+		},
+	}
+
+	// order, spacing and letter case do not make content new
+	same := ContentHash([]string{"city=bandung ", "name=JANE"})
+	if reason, _ := check.Check(ctx, Submission{ContentHash: same, SubmittedAt: submittedAt}); reason != "duplicate content of recent entry" {
+		t.Fatalf("got reason %q, want duplicate", reason)
+	}
+	other := ContentHash([]string{"name=Jane", "city=Jakarta"})
+	if reason, _ := check.Check(ctx, Submission{ContentHash: other, SubmittedAt: submittedAt}); reason != "" {
+		t.Fatalf("new content is flagged: %s", reason)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	ctx := context.Background()
+	checks := []Check{Honeypot{}, Captcha{Verifier: FakeCaptchaVerifier{Token: FakeCaptchaToken}}}
+
+	if reason, _ := Inspect(ctx, checks, Submission{CaptchaToken: FakeCaptchaToken}); reason != "" {
+		t.Fatalf("clean submission is flagged: %s", reason)
+	}
+	if reason, _ := Inspect(ctx, checks, Submission{Honeypot: "x"}); reason != "honeypot field is filled" {
+		t.Fatalf("got reason %q, want first failed check", reason)
+	}
+	failing := Duplicate{Exists: func(context.Context, string, string, time.Time) (bool, error) {
+		return false, errors.New("database is down")
+	}}
+	if _, err := Inspect(ctx, []Check{failing}, Submission{}); err == nil {
+		t.Fatal("error of check is dropped")
+	}
+}
@@ -0,0 +1,68 @@
+package antispam
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormTokenMaxAge is how long form token can be used after the form is opened,
+// it is short since each token is also spent by its first submission
+const FormTokenMaxAge = 2 * time.Hour
+
+// FormToken is parsed form token, nonce makes each issued token single use
+type FormToken struct {
+	IssuedAt time.Time
+	Nonce    string
+}
+
+// SignFormToken issues token proving when the form was opened
+// format is <issued unix>.<nonce>.<signature of campaign id, issued unix and nonce>
+func SignFormToken(secret []byte, campaignID string, issuedAt time.Time) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	issued := strconv.FormatInt(issuedAt.Unix(), 10)
+	nonce := base64.RawURLEncoding.EncodeToString(b)
+	return issued + "." + nonce + "." + formTokenSignature(secret, campaignID, issued, nonce), nil
+}
+
+// ParseFormToken validates token for this campaign at now
+func ParseFormToken(secret []byte, token string, campaignID string, now time.Time) (FormToken, error) {
+	if token == "" {
+		return FormToken{}, errors.New("missing")
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return FormToken{}, errors.New("malformed")
+	}
+	issued, nonce, signature := parts[0], parts[1], parts[2]
+
+	expected := formTokenSignature(secret, campaignID, issued, nonce)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return FormToken{}, errors.New("not valid")
+	}
+
+	unix, err := strconv.ParseInt(issued, 10, 64)
+	if err != nil {
+		return FormToken{}, errors.New("malformed")
+	}
+	issuedAt := time.Unix(unix, 0)
+	if now.Sub(issuedAt) > FormTokenMaxAge {
+		return FormToken{}, errors.New("expired")
+	}
+	return FormToken{IssuedAt: issuedAt, Nonce: nonce}, nil
+}
+
+func formTokenSignature(secret []byte, campaignID string, issued string, nonce string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(campaignID + "." + issued + "." + nonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,57 @@
+package antispam
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormToken(t *testing.T) {
+	secret := []byte("test secret key")
+	issuedAt := time.Now().Truncate(time.Second)
+	token, err := SignFormToken(secret, "campaign-1", issuedAt)
+	if err != nil {
+		t.Fatalf("sign form token: %v", err)
+	}
+
+	parsed, err := ParseFormToken(secret, token, "campaign-1", issuedAt.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("parse form token: %v", err)
+	}
+	if !parsed.IssuedAt.Equal(issuedAt) || parsed.Nonce == "" {
+		t.Fatalf("got %+v, want token issued at %s with nonce", parsed, issuedAt)
+	}
+
+	other, err := SignFormToken(secret, "campaign-1", issuedAt)
+	if err != nil {
+		t.Fatalf("sign form token: %v", err)
+	}
+	if other == token {
+		t.Fatal("tokens of the same second are equal, nonce is missing")
+	}
+
+	issued, rest, _ := strings.Cut(token, ".")
+	cases := []struct {
+		name       string
+		secret     string
+		token      string
+		campaignID string
+		now        time.Time
+		want       string
+	}{
+		{"missing", "test secret key", "", "campaign-1", issuedAt, "missing"},
+		{"malformed", "test secret key", "123.abc", "campaign-1", issuedAt, "malformed"},
+		{"another campaign", "test secret key", token, "campaign-2", issuedAt, "not valid"},
+		{"another secret", "another secret", token, "campaign-1", issuedAt, "not valid"},
+		{"changed issued time", "test secret key", "1" + issued + "." + rest, "campaign-1", issuedAt, "not valid"},
+		{"expired", "test secret key", token, "campaign-1", issuedAt.Add(FormTokenMaxAge + time.Second), "expired"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseFormToken([]byte(tc.secret), tc.token, tc.campaignID, tc.now)
+			if err == nil || err.Error() != tc.want {
+				t.Fatalf("got error %v, want %s", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Code is stable machine readable error code sent to clients
+type Code string
+
+const (
+	CodeBadRequest    Code = "BAD_REQUEST"
+	CodeUnauthorized  Code = "UNAUTHORIZED"
+	CodeForbidden     Code = "FORBIDDEN"
+	CodeNotFound      Code = "NOT_FOUND"
+	CodeValidation    Code = "VALIDATION_FAILED"
+	CodeConflict      Code = "CONFLICT"
+	CodeQuotaExceeded Code = "QUOTA_EXCEEDED"
+	CodeTooMany       Code = "TOO_MANY_REQUESTS"
+	CodeInternal      Code = "INTERNAL_ERROR"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is domain error returned by usecases and rendered by http error handler
+type Error struct {
+	Code    Code
+	Status  int
+	Message string
+	Fields  []FieldError
+	Err     error
+
+	// RetryAfter is sent as Retry-After header when it is set
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap keeps original error as cause, so it can be logged and checked with errors.Is
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+func BadRequest(message string) *Error {
+	return &Error{Code: CodeBadRequest, Status: http.StatusBadRequest, Message: message}
+}
+
+func Unauthorized(message string) *Error {
+	return &Error{Code: CodeUnauthorized, Status: http.StatusUnauthorized, Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Code: CodeForbidden, Status: http.StatusForbidden, Message: message}
+}
+
+func NotFound(message string) *Error {
+	return &Error{Code: CodeNotFound, Status: http.StatusNotFound, Message: message}
+}
+
+func Validation(message string, fields ...FieldError) *Error {
+	return &Error{Code: CodeValidation, Status: http.StatusBadRequest, Message: message, Fields: fields}
+}
+
+// Field is shortcut for validation error of single field
+func Field(field string, message string) *Error {
+	return Validation(field+" "+message, FieldError{Field: field, Message: message})
+}
+
+func Conflict(message string) *Error {
+	return &Error{Code: CodeConflict, Status: http.StatusConflict, Message: message}
+}
+
+func QuotaExceeded(message string) *Error {
+	return &Error{Code: CodeQuotaExceeded, Status: http.StatusForbidden, Message: message}
+}
+
+func TooManyRequests(message string, retryAfter time.Duration) *Error {
+	return &Error{Code: CodeTooMany, Status: http.StatusTooManyRequests, Message: message, RetryAfter: retryAfter}
+}
+
+func Internal(err error) *Error {
+	return &Error{Code: CodeInternal, Status: http.StatusInternalServerError, Message: "internal server error", Err: err}
+}
+
+// As returns domain error inside err, nil when err is not a domain error
+func As(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return nil
+}
+
+// Is checks whether err is domain error with given code
+func Is(err error, code Code) bool {
+	e := As(err)
+	return e != nil && e.Code == code
+}
@@ -0,0 +1,61 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FromValidator converts validator errors into validation error with message for each field
+func FromValidator(err error) *Error {
+	var ve validator.ValidationErrors
+	if !errors.As(err, &ve) {
+		return nil
+	}
+
+	fields := []FieldError{}
+	for _, v := range ve {
+		fields = append(fields, FieldError{
+			Field:   fieldPath(v),
+			Message: fieldMessage(v),
+		})
+	}
+	return Validation("some fields are not valid", fields...)
+}
+
+// fieldPath removes root struct name, eg. CampaignPayload.forms[0].title -> forms[0].title
+func fieldPath(v validator.FieldError) string {
+	if _, path, ok := strings.Cut(v.Namespace(), "."); ok {
+		return path
+	}
+	return v.Field()
+}
+
+func fieldMessage(v validator.FieldError) string {
+	switch v.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "uuid", "uuid4":
+		return "must be a valid uuid"
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", strings.ReplaceAll(v.Param(), " ", ", "))
+	case "min":
+		return fmt.Sprintf("must be at least %s", v.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", v.Param())
+	case "len":
+		return fmt.Sprintf("must have length %s", v.Param())
+	case "eqfield":
+		return fmt.Sprintf("must be equal to %s", v.Param())
+	case "url":
+		return "must be a valid url"
+	case "numeric", "number":
+		return "must be a number"
+	default:
+		return fmt.Sprintf("is not valid (%s)", v.Tag())
+	}
+}
@@ -0,0 +1,191 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"kiraform/src/applications/models"
+	masterrepo "kiraform/src/applications/repos/masters"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"kiraform/src/utils"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+)
+
+// secretFields are never written to audit log in plain text
+var secretFields = map[string]bool{
+	"access_key": true,
+	"password":   true,
+	"secret":     true,
+	"token":      true,
+}
+
+const redacted = "[redacted]"
+
+// Entry is one mutation to be recorded, scope is WorkspaceID, CampaignID or StoreID
+// workspace of campaign is looked up when only CampaignID is known
+type Entry struct {
+	WorkspaceID string
+	CampaignID  string
+	StoreID     string
+	Action      string
+	EntityType  string
+	EntityID    string
+	Before      any
+	After       any
+}
+
+type Change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+type Recorder struct {
+	auditRepo masterrepo.AuditRepository
+}
+
+func NewRecorder(auditRepo masterrepo.AuditRepository) *Recorder {
+	return &Recorder{
+		auditRepo: auditRepo,
+	}
+}
+
+// Record stores audit log, failing here is only logged so the mutation itself is not rolled back
+// it is kept even when client disconnects, since the mutation is already done
+func (r *Recorder) Record(ctx context.Context, actor commonschema.Actor, entry Entry) {
+	ctx = context.WithoutCancel(ctx)
+	if err := r.record(ctx, actor, entry); err != nil {
+		slog.ErrorContext(ctx, "failed to record audit log", "action", entry.Action, "entity_type", entry.EntityType, "entity_id", entry.EntityID, "error", err)
+	}
+}
+
+func (r *Recorder) record(ctx context.Context, actor commonschema.Actor, entry Entry) error {
+	data := models.AuditLogs{
+		ID:         uuid.New(),
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		IP:         actor.IP,
+		UserAgent:  actor.UserAgent,
+		RequestID:  actor.RequestID,
+		CreatedAt:  time.Now(),
+	}
+
+	// resolve scope of the entity
+	workspaceID := entry.WorkspaceID
+	if workspaceID == "" && entry.CampaignID != "" {
+		ID, err := r.auditRepo.FindWorkspaceIDByCampaign(ctx, entry.CampaignID)
+		if err != nil {
+			return err
+		}
+		workspaceID = *ID
+	}
+	data.WorkspaceID = utils.NullableUUID(workspaceID)
+	data.StoreID = utils.NullableUUID(entry.StoreID)
+	data.ActorID = utils.NullableUUID(actor.UserID)
+
+	// keep snapshots and changed fields
+	before, err := Snapshot(entry.Before)
+	if err != nil {
+		return err
+	}
+	after, err := Snapshot(entry.After)
+	if err != nil {
+		return err
+	}
+	if entry.Action == ActionUpdate {
+		if data.Changes, err = marshal(redactChanges(Diff(before, after))); err != nil {
+			return err
+		}
+	}
+	if data.Before, err = marshal(redact(before)); err != nil {
+		return err
+	}
+	if data.After, err = marshal(redact(after)); err != nil {
+		return err
+	}
+
+	return r.auditRepo.CreateAuditLog(ctx, data)
+}
+
+// Snapshot converts value into flat map by its json fields,
+// nested objects are dropped because they are relations, not the entity itself
+func Snapshot(value any) (map[string]any, error) {
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Pointer && reflect.ValueOf(value).IsNil()) {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot map[string]any
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil, err
+	}
+
+	for k, v := range snapshot {
+		if _, ok := v.(map[string]any); ok {
+			delete(snapshot, k)
+		}
+	}
+	return snapshot, nil
+}
+
+// Diff returns fields which value is changed, updated_at is ignored since it always changes
+func Diff(before map[string]any, after map[string]any) map[string]Change {
+	changes := map[string]Change{}
+	for k, v := range after {
+		if k == "updated_at" {
+			continue
+		}
+		if old, ok := before[k]; !ok || !reflect.DeepEqual(old, v) {
+			changes[k] = Change{From: before[k], To: v}
+		}
+	}
+	for k, v := range before {
+		if _, ok := after[k]; !ok && k != "updated_at" {
+			changes[k] = Change{From: v, To: nil}
+		}
+	}
+	return changes
+}
+
+func redact(snapshot map[string]any) map[string]any {
+	for k := range snapshot {
+		if secretFields[k] {
+			snapshot[k] = redacted
+		}
+	}
+	return snapshot
+}
+
+func redactChanges(changes map[string]Change) map[string]Change {
+	for k := range changes {
+		if secretFields[k] {
+			changes[k] = Change{From: redacted, To: redacted}
+		}
+	}
+	return changes
+}
+
+func marshal(value any) (*string, error) {
+	if reflect.ValueOf(value).IsNil() {
+		return nil, nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	str := string(raw)
+	return &str, nil
+}
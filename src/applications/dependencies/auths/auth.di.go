@@ -1,8 +1,14 @@
 package authdi
 
 import (
+	"kiraform/src/applications/keyring"
 	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/applications/sessions"
+	"kiraform/src/applications/twofactor"
 	authusecase "kiraform/src/applications/usecases/auths"
+	"kiraform/src/infras/configs"
+	"kiraform/src/infras/mailer"
+	"kiraform/src/infras/ratelimit"
 
 	"gorm.io/gorm"
 )
@@ -12,14 +18,16 @@ type AuthDependencies struct {
 	UC authusecase.AuthUsecase
 }
 
-func NewAuthDependencies(DB *gorm.DB) *AuthDependencies {
+func NewAuthDependencies(DB *gorm.DB, limiter ratelimit.Store, config configs.AuthConfig, keys *keyring.Keyring, twoFactor *twofactor.Manager, mail mailer.Mailer, tracker *sessions.Tracker) *AuthDependencies {
 	// load necessary repositories
 	// it possible to more than one
 	userRepo := masterrepo.NewUserRepository(DB)
 	roleRepo := masterrepo.NewRoleRepository(DB)
+	identityRepo := masterrepo.NewUserIdentityRepository(DB)
+	loginCodeRepo := masterrepo.NewLoginCodeRepository(DB)
 
 	// load the usecase and inject into Dependency
-	authUC := authusecase.NewAuthUsecase(userRepo, roleRepo)
+	authUC := authusecase.NewAuthUsecase(userRepo, roleRepo, identityRepo, loginCodeRepo, limiter, config, keys, twoFactor, mail, tracker)
 	return &AuthDependencies{
 		DB: DB,
 		UC: authUC,
@@ -0,0 +1,29 @@
+package masterdi
+
+import (
+	"kiraform/src/applications/audit"
+	masterrepo "kiraform/src/applications/repos/masters"
+	masterusecase "kiraform/src/applications/usecases/masters"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyDependencies struct {
+	DB *gorm.DB
+	UC masterusecase.APIKeyUsecase
+}
+
+func NewAPIKeyDependencies(DB *gorm.DB) *APIKeyDependencies {
+	// load repositories
+	apiKeyRepo := masterrepo.NewAPIKeyRepository(DB)
+	workspaceRepo := masterrepo.NewWorkspaceRepository(DB)
+	userRepo := masterrepo.NewUserRepository(DB)
+	recorder := audit.NewRecorder(masterrepo.NewAuditRepository(DB))
+
+	// init dependencies
+	UC := masterusecase.NewAPIKeyUsecase(apiKeyRepo, workspaceRepo, userRepo, recorder)
+	return &APIKeyDependencies{
+		DB: DB,
+		UC: UC,
+	}
+}
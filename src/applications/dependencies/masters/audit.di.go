@@ -0,0 +1,22 @@
+package masterdi
+
+import (
+	masterrepo "kiraform/src/applications/repos/masters"
+	masterusecase "kiraform/src/applications/usecases/masters"
+
+	"gorm.io/gorm"
+)
+
+type AuditDependencies struct {
+	DB *gorm.DB
+	UC masterusecase.AuditUsecase
+}
+
+func NewAuditDependencies(DB *gorm.DB) *AuditDependencies {
+	auditRepo := masterrepo.NewAuditRepository(DB)
+	UC := masterusecase.NewAuditUsecase(auditRepo)
+	return &AuditDependencies{
+		DB: DB,
+		UC: UC,
+	}
+}
@@ -1,6 +1,7 @@
 package masterdi
 
 import (
+	"kiraform/src/applications/audit"
 	masterrepo "kiraform/src/applications/repos/masters"
 	storerepo "kiraform/src/applications/repos/stores"
 	masterusecase "kiraform/src/applications/usecases/masters"
@@ -17,8 +18,9 @@ func NewCampaignDependencies(DB *gorm.DB) *CampaignDependencies {
 	campaignRepo := masterrepo.NewCampaignRepository(DB)
 	workspaceRepo := masterrepo.NewWorkspaceRepository(DB)
 	storeRepo := storerepo.NewStoreRepository(DB)
+	recorder := audit.NewRecorder(masterrepo.NewAuditRepository(DB))
 
-	UC := masterusecase.NewCampaignUsecase(campaignRepo, workspaceRepo, storeRepo)
+	UC := masterusecase.NewCampaignUsecase(campaignRepo, workspaceRepo, storeRepo, recorder)
 	return &CampaignDependencies{
 		DB: DB,
 		UC: UC,
@@ -1,9 +1,13 @@
 package masterdi
 
 import (
+	"kiraform/src/applications/antispam"
+	"kiraform/src/applications/audit"
 	masterrepo "kiraform/src/applications/repos/masters"
 	storerepo "kiraform/src/applications/repos/stores"
 	masterusecase "kiraform/src/applications/usecases/masters"
+	"kiraform/src/infras/configs"
+	"kiraform/src/infras/ratelimit"
 
 	"gorm.io/gorm"
 )
@@ -14,16 +18,20 @@ type FormEntryDependencies struct {
 	UCcampaign masterusecase.CampaignUsecase
 }
 
-func NewFormEntryDependencies(DB *gorm.DB) *FormEntryDependencies {
+func NewFormEntryDependencies(DB *gorm.DB, limiter ratelimit.Store, config configs.Config) *FormEntryDependencies {
 	// load repositories
 	formEntryRepo := masterrepo.NewFormEntryRepository(DB)
 	campaignRepo := masterrepo.NewCampaignRepository(DB)
 	workspaceRepo := masterrepo.NewWorkspaceRepository(DB)
 	storeRepo := storerepo.NewStoreRepository(DB)
+	recorder := audit.NewRecorder(masterrepo.NewAuditRepository(DB))
+
+	// load captcha provider for anti-spam check
+	captcha := antispam.NewCaptchaVerifier(config.Captcha.VerifyURL, config.Captcha.Secret, config.App.IsDev())
 
 	// load usecase
-	UC := masterusecase.NewFormEntryUsecase(formEntryRepo)
-	UCcampaign := masterusecase.NewCampaignUsecase(campaignRepo, workspaceRepo, storeRepo)
+	UC := masterusecase.NewFormEntryUsecase(formEntryRepo, campaignRepo, captcha, []byte(config.Auth.SecretKey), limiter)
+	UCcampaign := masterusecase.NewCampaignUsecase(campaignRepo, workspaceRepo, storeRepo, recorder)
 	return &FormEntryDependencies{
 		DB:         DB,
 		UC:         UC,
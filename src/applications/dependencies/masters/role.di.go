@@ -0,0 +1,28 @@
+package masterdi
+
+import (
+	"kiraform/src/applications/audit"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/applications/sessions"
+	masterusecase "kiraform/src/applications/usecases/masters"
+
+	"gorm.io/gorm"
+)
+
+type RoleDependencies struct {
+	DB *gorm.DB
+	UC masterusecase.RoleUsecase
+}
+
+func NewRoleDependencies(DB *gorm.DB, tracker *sessions.Tracker) *RoleDependencies {
+	// load repositories
+	roleRepo := masterrepo.NewRoleRepository(DB)
+	recorder := audit.NewRecorder(masterrepo.NewAuditRepository(DB))
+
+	// init dependencies
+	UC := masterusecase.NewRoleUsecase(roleRepo, tracker, recorder)
+	return &RoleDependencies{
+		DB: DB,
+		UC: UC,
+	}
+}
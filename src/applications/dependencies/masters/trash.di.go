@@ -0,0 +1,29 @@
+package masterdi
+
+import (
+	"kiraform/src/applications/audit"
+	masterrepo "kiraform/src/applications/repos/masters"
+	masterusecase "kiraform/src/applications/usecases/masters"
+
+	"gorm.io/gorm"
+)
+
+type TrashDependencies struct {
+	DB *gorm.DB
+	UC masterusecase.TrashUsecase
+}
+
+func NewTrashDependencies(DB *gorm.DB) *TrashDependencies {
+	// load repositories
+	trashRepo := masterrepo.NewTrashRepository(DB)
+	workspaceRepo := masterrepo.NewWorkspaceRepository(DB)
+	campaignRepo := masterrepo.NewCampaignRepository(DB)
+	recorder := audit.NewRecorder(masterrepo.NewAuditRepository(DB))
+
+	// init dependencies
+	UC := masterusecase.NewTrashUsecase(trashRepo, workspaceRepo, campaignRepo, recorder)
+	return &TrashDependencies{
+		DB: DB,
+		UC: UC,
+	}
+}
@@ -0,0 +1,33 @@
+package masterdi
+
+import (
+	"kiraform/src/applications/audit"
+	"kiraform/src/applications/keyring"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/applications/sessions"
+	masterusecase "kiraform/src/applications/usecases/masters"
+	"kiraform/src/infras/configs"
+	"kiraform/src/infras/mailer"
+
+	"gorm.io/gorm"
+)
+
+type UserDependencies struct {
+	DB *gorm.DB
+	UC masterusecase.UserUsecase
+}
+
+func NewUserDependencies(DB *gorm.DB, config configs.AuthConfig, keys *keyring.Keyring, mail mailer.Mailer, tracker *sessions.Tracker) *UserDependencies {
+	// load repositories
+	userRepo := masterrepo.NewUserRepository(DB)
+	roleRepo := masterrepo.NewRoleRepository(DB)
+	packageRepo := masterrepo.NewPackageRepository(DB)
+	recorder := audit.NewRecorder(masterrepo.NewAuditRepository(DB))
+
+	// init dependencies
+	UC := masterusecase.NewUserUsecase(userRepo, roleRepo, packageRepo, tracker, keys, mail, config, recorder)
+	return &UserDependencies{
+		DB: DB,
+		UC: UC,
+	}
+}
@@ -1,6 +1,7 @@
 package masterdi
 
 import (
+	"kiraform/src/applications/audit"
 	masterrepo "kiraform/src/applications/repos/masters"
 	masterusecase "kiraform/src/applications/usecases/masters"
 
@@ -16,9 +17,10 @@ func NewWorkspaceDependencies(DB *gorm.DB) *WorkspaceDependencies {
 	// load repositories
 	workspaceRepo := masterrepo.NewWorkspaceRepository(DB)
 	userRepo := masterrepo.NewUserRepository(DB)
+	recorder := audit.NewRecorder(masterrepo.NewAuditRepository(DB))
 
 	// init dependencies
-	UC := masterusecase.NewWorkspaceUsecase(workspaceRepo, userRepo)
+	UC := masterusecase.NewWorkspaceUsecase(workspaceRepo, userRepo, recorder)
 	return &WorkspaceDependencies{
 		DB: DB,
 		UC: UC,
@@ -2,6 +2,7 @@ package medi
 
 import (
 	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/applications/twofactor"
 	meusecase "kiraform/src/applications/usecases/me"
 
 	"gorm.io/gorm"
@@ -12,8 +13,8 @@ type MeDependencies struct {
 	UC meusecase.MeUsecase
 }
 
-func NewMeDependencies(DB *gorm.DB) *MeDependencies {
-	UC := meusecase.NewMeUsecase(masterrepo.NewUserRepository(DB))
+func NewMeDependencies(DB *gorm.DB, twoFactor *twofactor.Manager) *MeDependencies {
+	UC := meusecase.NewMeUsecase(masterrepo.NewUserRepository(DB), masterrepo.NewTwoFactorRepository(DB), twoFactor)
 	return &MeDependencies{
 		DB: DB,
 		UC: UC,
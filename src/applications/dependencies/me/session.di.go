@@ -0,0 +1,21 @@
+package medi
+
+import (
+	masterrepo "kiraform/src/applications/repos/masters"
+	meusecase "kiraform/src/applications/usecases/me"
+
+	"gorm.io/gorm"
+)
+
+type SessionDependencies struct {
+	DB *gorm.DB
+	UC meusecase.SessionUsecase
+}
+
+func NewSessionDependencies(DB *gorm.DB) *SessionDependencies {
+	UC := meusecase.NewSessionUsecase(masterrepo.NewSessionRepository(DB), masterrepo.NewLoginHistoryRepository(DB))
+	return &SessionDependencies{
+		DB: DB,
+		UC: UC,
+	}
+}
@@ -0,0 +1,29 @@
+package operatordi
+
+import (
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/applications/sessions"
+	operatorusecase "kiraform/src/applications/usecases/operators"
+
+	"gorm.io/gorm"
+)
+
+type OperatorDependencies struct {
+	DB *gorm.DB
+	UC operatorusecase.OperatorUsecase
+}
+
+func NewOperatorDependencies(DB *gorm.DB, tracker *sessions.Tracker) *OperatorDependencies {
+	// load repositories
+	userRepo := masterrepo.NewUserRepository(DB)
+	roleRepo := masterrepo.NewRoleRepository(DB)
+	packageRepo := masterrepo.NewPackageRepository(DB)
+	campaignRepo := masterrepo.NewCampaignRepository(DB)
+
+	// init dependencies
+	UC := operatorusecase.NewOperatorUsecase(userRepo, roleRepo, packageRepo, campaignRepo, tracker)
+	return &OperatorDependencies{
+		DB: DB,
+		UC: UC,
+	}
+}
@@ -1,8 +1,11 @@
 package storedi
 
 import (
+	"kiraform/src/applications/audit"
+	masterrepo "kiraform/src/applications/repos/masters"
 	storerepo "kiraform/src/applications/repos/stores"
 	storeusecase "kiraform/src/applications/usecases/stores"
+	"kiraform/src/infras/configs"
 
 	"gorm.io/gorm"
 )
@@ -12,9 +15,10 @@ type StoreDependencies struct {
 	UC storeusecase.StoreUsecase
 }
 
-func NewStoreDependencies(DB *gorm.DB) *StoreDependencies {
+func NewStoreDependencies(DB *gorm.DB, storage configs.StorageConfig) *StoreDependencies {
 	storeRepo := storerepo.NewStoreRepository(DB)
-	UC := storeusecase.NewStoreUsecase(storeRepo)
+	recorder := audit.NewRecorder(masterrepo.NewAuditRepository(DB))
+	UC := storeusecase.NewStoreUsecase(storeRepo, recorder, storage)
 	return &StoreDependencies{
 		DB: DB,
 		UC: UC,
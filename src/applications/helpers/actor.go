@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	"fmt"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/infras/logger"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"slices"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Actor collects identity of logged user and its client for audit logs
+func Actor(c echo.Context) commonschema.Actor {
+	userID, _ := c.Get("user_id").(string)
+	return commonschema.Actor{
+		UserID:    userID,
+		IP:        c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+		RequestID: logger.RequestID(c.Request().Context()),
+	}
+}
+
+// HasPermission tells any role of logged user grants permission
+func HasPermission(c echo.Context, permission string) bool {
+	permissions, _ := c.Get("permissions").([]string)
+	return slices.Contains(permissions, permission)
+}
+
+// CheckPermission allows only logged user whose roles grant permission
+func CheckPermission(c echo.Context, permission string) error {
+	if _, err := baseValidation(c); err != nil {
+		return err
+	}
+
+	if !HasPermission(c, permission) {
+		return apperrors.Forbidden(fmt.Sprintf("%s permission is required to access this data", permission))
+	}
+	return nil
+}
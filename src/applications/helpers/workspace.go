@@ -2,47 +2,50 @@ package helpers
 
 import (
 	"errors"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/applications/models"
 	masterrepo "kiraform/src/applications/repos/masters"
-	"strings"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
 
-func baseValidation(c echo.Context) (string, string, error) {
+func baseValidation(c echo.Context) (string, error) {
 	// get user login
 	userID, ok := c.Get("user_id").(string)
 	if !ok {
-		return "", "", errors.New("your identity is not recognized, please contact our admin")
+		return "", apperrors.Unauthorized("your identity is not recognized, please contact our admin")
 	}
-	roleName, ok := c.Get("role_name").(string)
-	if !ok {
-		return "", "", errors.New("you have no role registered")
+	if _, ok := c.Get("roles").([]string); !ok {
+		return "", apperrors.Forbidden("you have no role registered")
 	}
 
-	return userID, roleName, nil
+	return userID, nil
 }
 
 func CheckAllowedWorkspace(c echo.Context, workspaceID string, DB *gorm.DB) error {
 	workspaceRepo := masterrepo.NewWorkspaceRepository(DB)
 	notAllowedMessage := "you are not allowed to access this data"
 
-	userID, roleName, err := baseValidation(c)
+	userID, err := baseValidation(c)
 	if err != nil {
 		return err
 	}
 
 	// check valid workspace
-	// if user is admin, then allow to access it
-	if strings.ToLower(roleName) != "admin" {
-		data, err := workspaceRepo.FindWorkspaceUserByUserApproved(workspaceID, userID)
+	// if user can manage every workspace, then allow to access it
+	if !HasPermission(c, models.PermissionWorkspacesManage) {
+		data, err := workspaceRepo.FindWorkspaceUserByUserApproved(c.Request().Context(), workspaceID, userID)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return errors.New(notAllowedMessage)
+				return apperrors.Forbidden(notAllowedMessage)
 			}
 			return err
 		} else if data == nil {
-			return errors.New(notAllowedMessage)
+			return apperrors.Forbidden(notAllowedMessage)
+		}
+		if err := checkTwoFactor(c, workspaceID, DB); err != nil {
+			return err
 		}
 	}
 
@@ -50,26 +53,81 @@ func CheckAllowedWorkspace(c echo.Context, workspaceID string, DB *gorm.DB) erro
 	return nil
 }
 
+// CheckWorkspaceOwner allows only owner of workspace, or user who can manage every workspace
+func CheckWorkspaceOwner(c echo.Context, workspaceID string, DB *gorm.DB) error {
+	workspaceRepo := masterrepo.NewWorkspaceRepository(DB)
+	notAllowedMessage := "only owner of this workspace is allowed to change it"
+
+	userID, err := baseValidation(c)
+	if err != nil {
+		return err
+	}
+
+	if !HasPermission(c, models.PermissionWorkspacesManage) {
+		data, err := workspaceRepo.FindWorkspaceUserByUserApproved(c.Request().Context(), workspaceID, userID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.Forbidden(notAllowedMessage)
+			}
+			return err
+		} else if data == nil || data.Status != "S5" {
+			return apperrors.Forbidden(notAllowedMessage)
+		}
+		if err := checkTwoFactor(c, workspaceID, DB); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TwoFactorSigned tells access token is issued after two-factor code, api key counts as signed
+// only when its creator was signed in with two-factor code
+func TwoFactorSigned(c echo.Context) bool {
+	mfa, _ := c.Get("mfa").(string)
+	return mfa == "true"
+}
+
+// checkTwoFactor refuses member signed in by password only when workspace requires two-factor code
+func checkTwoFactor(c echo.Context, workspaceID string, DB *gorm.DB) error {
+	if TwoFactorSigned(c) {
+		return nil
+	}
+	workspace, err := masterrepo.NewWorkspaceRepository(DB).FindWorkspaceByID(c.Request().Context(), workspaceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.Forbidden("you are not allowed to access this data")
+		}
+		return err
+	}
+	if workspace.RequireTwoFactor {
+		return apperrors.Forbidden("this workspace requires two-factor authentication, enable it and sign in again")
+	}
+	return nil
+}
+
 func CheckAllowedCampaign(c echo.Context, workspaceID string, campaignID string, DB *gorm.DB) error {
 	campaignRepo := masterrepo.NewCampaignRepository(DB)
 	notAllowedMessage := "you are not allowed to access this data"
 
-	userID, roleName, err := baseValidation(c)
+	userID, err := baseValidation(c)
 	if err != nil {
 		return err
 	}
 
 	// check allowed campaign based on workspace and campaign
-	// if user is admin, then allow to access it
-	if strings.ToLower(roleName) != "admin" {
-		data, err := campaignRepo.CheckAllowedUserForCampaign(workspaceID, campaignID, userID)
+	// if user can manage every workspace, then allow to access it
+	if !HasPermission(c, models.PermissionWorkspacesManage) {
+		data, err := campaignRepo.CheckAllowedUserForCampaign(c.Request().Context(), workspaceID, campaignID, userID)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return errors.New(notAllowedMessage)
+				return apperrors.Forbidden(notAllowedMessage)
 			}
 			return err
 		} else if data == nil {
-			return errors.New(notAllowedMessage)
+			return apperrors.Forbidden(notAllowedMessage)
+		}
+		if err := checkTwoFactor(c, workspaceID, DB); err != nil {
+			return err
 		}
 	}
 
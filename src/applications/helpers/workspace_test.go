@@ -0,0 +1,74 @@
+package helpers
+
+import (
+	"database/sql/driver"
+	"errors"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/infras/dbtest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// workspaceRequiringTwoFactor answers owner membership of workspace requiring two-factor code
+func workspaceRequiringTwoFactor(t *testing.T, workspaceID string) *gorm.DB {
+	DB, _ := dbtest.Open(t, func(query string) *dbtest.Rows {
+		switch {
+		case strings.Contains(query, `FROM "workspace_users"`):
+			return &dbtest.Rows{Columns: []string{"id", "workspace_id", "status"}, Values: [][]driver.Value{{uuid.NewString(), workspaceID, "S5"}}}
+		case strings.Contains(query, `FROM "workspaces"`):
+			return &dbtest.Rows{Columns: []string{"id", "require_two_factor"}, Values: [][]driver.Value{{workspaceID, true}}}
+		}
+		return nil
+	})
+	return DB
+}
+
+func TestWorkspaceRequiringTwoFactor(t *testing.T) {
+	workspaceID := uuid.NewString()
+	DB := workspaceRequiringTwoFactor(t, workspaceID)
+	checks := map[string]func(c echo.Context, workspaceID string, DB *gorm.DB) error{
+		"allowed workspace": CheckAllowedWorkspace,
+		"workspace owner":   CheckWorkspaceOwner,
+	}
+
+	// api key created without two-factor code has mfa false, password login has none
+	cases := []struct {
+		name    string
+		mfa     any
+		allowed bool
+	}{
+		{"password login", nil, false},
+		{"api key created without two-factor", "false", false},
+		{"two-factor login or api key created with it", "true", true},
+	}
+	for checkName, check := range checks {
+		for _, tc := range cases {
+			t.Run(checkName+"/"+tc.name, func(t *testing.T) {
+				c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+				c.Set("user_id", uuid.NewString())
+				c.Set("roles", []string{"user"})
+				if tc.mfa != nil {
+					c.Set("mfa", tc.mfa)
+				}
+
+				err := check(c, workspaceID, DB)
+				if tc.allowed {
+					if err != nil {
+						t.Fatalf("got error %v, want allowed", err)
+					}
+					return
+				}
+				var appErr *apperrors.Error
+				if !errors.As(err, &appErr) || appErr.Code != apperrors.CodeForbidden {
+					t.Fatalf("got error %v, want forbidden", err)
+				}
+			})
+		}
+	}
+}
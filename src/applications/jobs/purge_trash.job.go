@@ -0,0 +1,117 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	masterrepo "kiraform/src/applications/repos/masters"
+	storerepo "kiraform/src/applications/repos/stores"
+	"kiraform/src/infras/metrics"
+	"kiraform/src/infras/tracing"
+	"kiraform/src/utils"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// purgeTrashInterval is how often trash is checked for expired items
+const purgeTrashInterval = time.Hour
+
+const purgeTrashJobName = "purge_trash"
+
+// TrashPurger permanently deletes items in trash older than given time, then returns their files
+type TrashPurger interface {
+	PurgeTrash(ctx context.Context, before time.Time) ([]string, error)
+}
+
+type PurgeTrashJob struct {
+	purgers   []TrashPurger
+	retention time.Duration
+	wg        sync.WaitGroup
+}
+
+func NewPurgeTrashJob(DB *gorm.DB, retention time.Duration) *PurgeTrashJob {
+	return &PurgeTrashJob{
+		purgers: []TrashPurger{
+			masterrepo.NewTrashRepository(DB),
+			storerepo.NewStoreRepository(DB),
+		},
+		retention: retention,
+	}
+}
+
+// Start runs purge right away and then periodically in background until ctx is done,
+// zero or negative retention keeps trash forever
+func (j *PurgeTrashJob) Start(ctx context.Context) {
+	if j.retention <= 0 {
+		slog.Info("trash retention is disabled, deleted data is kept forever")
+		return
+	}
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		ticker := time.NewTicker(purgeTrashInterval)
+		defer ticker.Stop()
+		for {
+			j.Run(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until running purge is finished or ctx is done
+func (j *PurgeTrashJob) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run purges expired trash once, a failing purger does not stop the others
+// cancelling ctx rolls back purge in progress, last error is returned
+func (j *PurgeTrashJob) Run(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "PurgeTrashJob.Run")
+	defer span.End()
+
+	start := time.Now()
+	before := start.Add(-j.retention)
+
+	var runErr error
+	for _, purger := range j.purgers {
+		files, err := purger.PurgeTrash(ctx, before)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to purge trash", "job", purgeTrashJobName, "error", err)
+			runErr = err
+			continue
+		}
+
+		// remove files after data is deleted, missing file is already clean
+		for _, file := range files {
+			if err := utils.RemoveImage(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+				slog.ErrorContext(ctx, "failed to remove file", "job", purgeTrashJobName, "file", file, "error", err)
+				continue
+			}
+			metrics.JobItems.WithLabelValues(purgeTrashJobName, "file").Inc()
+		}
+	}
+
+	tracing.Fail(span, runErr)
+	metrics.ObserveJob(purgeTrashJobName, start, runErr)
+	slog.InfoContext(ctx, "job finished", "job", purgeTrashJobName, "duration", time.Since(start), "success", runErr == nil)
+	return runErr
+}
@@ -0,0 +1,373 @@
+package keyring
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"kiraform/src/applications/models"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/infras/configs"
+	authschema "kiraform/src/interfaces/rest/schemas/auths"
+	"kiraform/src/utils"
+	"log/slog"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// token types told apart by typ claim, token without typ is access token issued before it was added
+const (
+	TokenAccess             = "access"
+	TokenTwoFactorChallenge = "2fa_challenge"
+	TokenMagicLink          = "magic_link"
+	TokenPasswordReset      = "password_reset"
+)
+
+// refreshInterval is how often keys are reloaded, so rotation by other process is picked up
+const refreshInterval = time.Minute
+
+// missReloadInterval limits reloading on unknown kid, so forged tokens can not flood the database
+const missReloadInterval = 5 * time.Second
+
+// rsaKeyBits is size of generated RSA keys
+const rsaKeyBits = 2048
+
+var ErrUnknownKey = errors.New("token is signed by unknown key")
+
+// Key is one signing key, only current key signs while retired keys still verify
+type Key struct {
+	ID        string
+	Algorithm string
+	Private   crypto.PrivateKey
+	Public    crypto.PublicKey
+	CreatedAt time.Time
+	RetiredAt *time.Time
+}
+
+// Keyring signs access tokens with current key and verifies them with current and previous keys,
+// retired key is kept for one token lifetime so issued tokens stay valid after rotation
+type Keyring struct {
+	repo   masterrepo.SigningKeyRepository
+	config configs.AuthConfig
+	box    *utils.SecretBox
+
+	mu       sync.RWMutex
+	keys     map[string]*Key
+	current  *Key
+	lastMiss time.Time
+	wg       sync.WaitGroup
+}
+
+func New(repo masterrepo.SigningKeyRepository, config configs.AuthConfig) (*Keyring, error) {
+	// private keys are stored encrypted by secret key, so database dump alone can not sign tokens
+	box, err := utils.NewSecretBox(config.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Keyring{
+		repo:   repo,
+		config: config,
+		box:    box,
+		keys:   map[string]*Key{},
+	}, nil
+}
+
+// Init creates the first key when there is none, then loads every usable key
+func (k *Keyring) Init(ctx context.Context) error {
+	key, err := k.generate(k.config.SigningAlg)
+	if err != nil {
+		return err
+	}
+	created, err := k.repo.CreateFirstSigningKey(ctx, key)
+	if err != nil {
+		return err
+	}
+	if created {
+		slog.InfoContext(ctx, "first signing key created", "kid", key.ID, "algorithm", key.Algorithm)
+	}
+	return k.Load(ctx)
+}
+
+// Start reloads keys periodically in background until ctx is done
+func (k *Keyring) Start(ctx context.Context) {
+	k.wg.Add(1)
+	go func() {
+		defer k.wg.Done()
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := k.Load(ctx); err != nil && ctx.Err() == nil {
+					slog.ErrorContext(ctx, "failed to reload signing keys", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Wait blocks until background reload is stopped
+func (k *Keyring) Wait() {
+	k.wg.Wait()
+}
+
+// Load reads current key and keys retired within one token lifetime
+func (k *Keyring) Load(ctx context.Context) error {
+	records, err := k.repo.FindSigningKeys(ctx, time.Now().Add(-k.config.TokenTTL))
+	if err != nil {
+		return err
+	}
+
+	keys := map[string]*Key{}
+	var current *Key
+	for _, v := range records {
+		key, err := k.decode(v)
+		if err != nil {
+			// key encrypted by previous secret key can not be used anymore
+			slog.WarnContext(ctx, "signing key is skipped", "kid", v.ID, "error", err)
+			continue
+		}
+		keys[key.ID] = key
+		if key.RetiredAt == nil {
+			current = key
+		}
+	}
+	if current == nil {
+		return errors.New("no usable signing key, run rotate-keys")
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.current = current
+	k.mu.Unlock()
+	return nil
+}
+
+// Rotate retires current key and starts signing with new one, replicas pick it up on next reload
+// or right away when they see its kid
+func (k *Keyring) Rotate(ctx context.Context, algorithm string) (*Key, error) {
+	if algorithm == "" {
+		algorithm = k.config.SigningAlg
+	}
+	record, err := k.generate(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	// retired keys are deleted once every token signed by them is expired
+	if err := k.repo.RotateSigningKey(ctx, record, record.CreatedAt.Add(-k.config.TokenTTL)); err != nil {
+		return nil, err
+	}
+	if err := k.Load(ctx); err != nil {
+		return nil, err
+	}
+	return k.decode(record)
+}
+
+// Keys returns loaded keys, current key first
+func (k *Keyring) Keys() []*Key {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	keys := []*Key{}
+	if k.current != nil {
+		keys = append(keys, k.current)
+	}
+	for _, v := range k.keys {
+		if v != k.current {
+			keys = append(keys, v)
+		}
+	}
+	return keys
+}
+
+// Sign issues token signed by current key with its kid in header
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	k.mu.RLock()
+	current := k.current
+	k.mu.RUnlock()
+	if current == nil {
+		return "", errors.New("keyring is not loaded")
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(current.Algorithm), claims)
+	token.Header["kid"] = current.ID
+	return token.SignedString(current.Private)
+}
+
+// Parse verifies token by its kid, unknown kid reloads keys once in a while since it may be just rotated,
+// token without kid is accepted as legacy HS256 only until the legacy cut-off
+func (k *Keyring) Parse(ctx context.Context, tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	legacy := time.Now().Before(k.config.LegacyUntil)
+	methods := []string{AlgRS256, AlgEdDSA}
+	if legacy {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok && legacy {
+				return []byte(k.config.SecretKey), nil
+			}
+			return nil, ErrUnknownKey
+		}
+
+		key := k.find(ctx, kid)
+		if key == nil {
+			return nil, ErrUnknownKey
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Method.Alg())
+		}
+		return key.Public, nil
+	}, jwt.WithValidMethods(methods))
+}
+
+// IsLegacy tells token parsed by keyring is legacy HS256 token signed by secret key
+func IsLegacy(token *jwt.Token) bool {
+	kid, _ := token.Header["kid"].(string)
+	_, hmac := token.Method.(*jwt.SigningMethodHMAC)
+	return kid == "" && hmac
+}
+
+func (k *Keyring) find(ctx context.Context, kid string) *Key {
+	k.mu.RLock()
+	key := k.keys[kid]
+	k.mu.RUnlock()
+	if key != nil {
+		return key
+	}
+
+	k.mu.Lock()
+	if time.Since(k.lastMiss) < missReloadInterval {
+		k.mu.Unlock()
+		return nil
+	}
+	k.lastMiss = time.Now()
+	k.mu.Unlock()
+
+	if err := k.Load(ctx); err != nil {
+		slog.ErrorContext(ctx, "failed to reload signing keys", "error", err)
+		return nil
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys[kid]
+}
+
+// JWKS publishes public keys so other services can verify our tokens
+func (k *Keyring) JWKS() authschema.JWKSet {
+	set := authschema.JWKSet{Keys: []authschema.JWK{}}
+	for _, v := range k.Keys() {
+		jwk := authschema.JWK{Kid: v.ID, Use: "sig", Alg: v.Algorithm}
+		switch public := v.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// generate creates new key pair ready to be stored
+func (k *Keyring) generate(algorithm string) (models.SigningKeys, error) {
+	var private crypto.Signer
+	switch algorithm {
+	case AlgRS256:
+		key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return models.SigningKeys{}, err
+		}
+		private = key
+	case AlgEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return models.SigningKeys{}, err
+		}
+		private = key
+	default:
+		return models.SigningKeys{}, fmt.Errorf("unsupported signing algorithm %q, use %s or %s", algorithm, AlgRS256, AlgEdDSA)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return models.SigningKeys{}, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return models.SigningKeys{}, err
+	}
+
+	sealed, err := k.box.Seal(privateDER)
+	if err != nil {
+		return models.SigningKeys{}, err
+	}
+
+	return models.SigningKeys{
+		ID:         strings.ReplaceAll(uuid.New().String(), "-", ""),
+		Algorithm:  algorithm,
+		PrivateKey: sealed,
+		PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// decode opens stored key pair
+func (k *Keyring) decode(record models.SigningKeys) (*Key, error) {
+	privateDER, err := k.box.Open(record.PrivateKey)
+	if err != nil {
+		return nil, errors.New("private key can not be decrypted, secret key may be changed")
+	}
+	private, err := x509.ParsePKCS8PrivateKey(privateDER)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key can not sign")
+	}
+
+	return &Key{
+		ID:        record.ID,
+		Algorithm: record.Algorithm,
+		Private:   private,
+		Public:    signer.Public(),
+		CreatedAt: record.CreatedAt,
+		RetiredAt: record.RetiredAt,
+	}, nil
+}
+
+// PasswordStamp ties password reset token to current password hash, so the token stops working once it is used
+func PasswordStamp(hashedPassword string) string {
+	sum := sha256.Sum256([]byte(hashedPassword))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// scopes granted to api keys
+const (
+	ScopeEntriesRead    = "entries:read"
+	ScopeCampaignsWrite = "campaigns:write"
+	ScopeMembersManage  = "members:manage"
+)
+
+type APIKeys struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	WorkspaceID    uuid.UUID  `gorm:"type:uuid;not null" json:"workspace_id"`
+	Workspace      Workspaces `gorm:"foreignKey:WorkspaceID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;comment:Creator, key acts on behalf of this user" json:"user_id"`
+	User           Users      `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	Name           string     `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix         string     `gorm:"type:varchar(16);not null;comment:Shown to tell keys apart" json:"prefix"`
+	KeyHash        string     `gorm:"type:char(64);not null;unique;comment:SHA-256 of key" json:"-"`
+	Scopes         string     `gorm:"type:varchar(255);not null;comment:Comma separated scopes" json:"scopes"`
+	ExpiresAt      time.Time  `gorm:"type:timestamp;not null" json:"expires_at"`
+	CreatedWithMFA bool       `gorm:"type:bool;default:false;comment:Creator signed in with two-factor code, required by workspace requiring it" json:"created_with_mfa"`
+	LastUsedAt     *time.Time `gorm:"type:timestamp" json:"last_used_at"`
+	RevokedAt      *time.Time `gorm:"type:timestamp" json:"revoked_at"`
+	CreatedAt      time.Time  `gorm:"type:timestamp" json:"created_at"`
+}
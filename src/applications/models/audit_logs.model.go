@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditLogs struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	WorkspaceID *uuid.UUID `gorm:"type:uuid;null;index:idx_audit_logs_workspace_created,priority:1" json:"workspace_id"`
+	StoreID     *uuid.UUID `gorm:"type:uuid;null;index:idx_audit_logs_store_created,priority:1" json:"store_id"`
+	ActorID     *uuid.UUID `gorm:"type:uuid;null;index" json:"actor_id"`
+	Action      string     `gorm:"type:varchar(20);not null;comment:create,update,delete" json:"action"`
+	EntityType  string     `gorm:"type:varchar(50);not null" json:"entity_type"`
+	EntityID    string     `gorm:"type:varchar(50);not null;index" json:"entity_id"`
+	Before      *string    `gorm:"type:jsonb" json:"before"`
+	After       *string    `gorm:"type:jsonb" json:"after"`
+	Changes     *string    `gorm:"type:jsonb;comment:Changed fields with old and new value" json:"changes"`
+	IP          string     `gorm:"type:varchar(45)" json:"ip"`
+	UserAgent   string     `gorm:"type:text" json:"user_agent"`
+	RequestID   string     `gorm:"type:varchar(128);index" json:"request_id"`
+	CreatedAt   time.Time  `gorm:"type:timestamp;index:idx_audit_logs_workspace_created,priority:2;index:idx_audit_logs_store_created,priority:2" json:"created_at"`
+}
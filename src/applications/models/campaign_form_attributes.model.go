@@ -14,6 +14,7 @@ type CampaignFormAttributes struct {
 	Value          string        `gorm:"type:varchar(50);not null" json:"value"`
 	IsDefault      bool          `gorm:"type:boolean;default:false" json:"is_default"`
 	Deleted        bool          `gorm:"type:boolean;default:false" json:"deleted"`
+	DeletedAt      *time.Time    `gorm:"type:timestamp;comment:Same as deleted_at of parent when deleted together" json:"deleted_at"`
 	CreatedAt      time.Time     `gorm:"type:timestamp" json:"created_at"`
 	UpdatedAt      *time.Time    `gorm:"type:timestamp" json:"updated_at"`
 }
@@ -19,6 +19,7 @@ type CampaignForms struct {
 	IsRequired   bool       `gorm:"type:boolean;default:false" json:"is_required"`
 	IsMultiple   bool       `gorm:"type:boolean;default:false" json:"is_multiple"`
 	Deleted      bool       `gorm:"type:boolean;default:false" json:"deleted"`
+	DeletedAt    *time.Time `gorm:"type:timestamp;comment:Same as deleted_at of parent when deleted together" json:"deleted_at"`
 	CreatedAt    time.Time  `gorm:"type:timestamp" json:"created_at"`
 	UpdatedAt    *time.Time `gorm:"type:timestamp" json:"updated_at"`
 }
@@ -14,6 +14,8 @@ type CampaignSeos struct {
 	Event      string     `gorm:"type:varchar(50);not null" json:"event"`
 	AccessKey  string     `gorm:"type:text;not null" json:"access_key"`
 	Deleted    bool       `gorm:"type:boolean;default:false" json:"deleted"`
+	DeletedAt  *time.Time `gorm:"type:timestamp;index" json:"deleted_at"`
+	DeletedBy  *uuid.UUID `gorm:"type:uuid;null" json:"deleted_by"`
 	CreatedAt  time.Time  `gorm:"type:timestamp" json:"created_at"`
 	UpdatedAt  *time.Time `gorm:"type:timestamp" json:"updated_at"`
 }
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CampaignSpamSettings struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	CampaignID       uuid.UUID  `gorm:"type:uuid;not null;unique" json:"campaign_id"`
+	Campaign         Campaigns  `gorm:"foreignKey:CampaignID;references:ID;constraint:OnDelete:CASCADE" json:"campaign"`
+	Honeypot         bool       `gorm:"type:boolean;default:false" json:"honeypot"`
+	MinSubmitSeconds int        `gorm:"type:int;default:0;comment:0=DISABLED" json:"min_submit_seconds"`
+	Captcha          bool       `gorm:"type:boolean;default:false" json:"captcha"`
+	DuplicateCheck   bool       `gorm:"type:boolean;default:false" json:"duplicate_check"`
+	CreatedAt        time.Time  `gorm:"type:timestamp" json:"created_at"`
+	UpdatedAt        *time.Time `gorm:"type:timestamp" json:"updated_at"`
+}
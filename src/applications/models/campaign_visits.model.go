@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CampaignVisits struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	CampaignID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_campaign_visit_date" json:"campaign_id"`
+	Campaign   Campaigns  `gorm:"foreignKey:CampaignID;references:ID;constraint:OnDelete:CASCADE" json:"campaign"`
+	VisitDate  time.Time  `gorm:"type:date;not null;uniqueIndex:idx_campaign_visit_date;comment:Date in workspace timezone" json:"visit_date"`
+	Total      int64      `gorm:"type:bigint;default:0" json:"total"`
+	CreatedAt  time.Time  `gorm:"type:timestamp" json:"created_at"`
+	UpdatedAt  *time.Time `gorm:"type:timestamp" json:"updated_at"`
+}
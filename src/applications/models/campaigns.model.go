@@ -8,7 +8,7 @@ import (
 
 type Campaigns struct {
 	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
-	WorkspaceID uuid.UUID  `gorm:"type:uuid;not null"`
+	WorkspaceID uuid.UUID  `gorm:"type:uuid;not null;index:idx_campaigns_workspace_created,priority:1"`
 	Workspace   Workspaces `gorm:"foreignKey:WorkspaceID;references:ID;constraint:OnDelete:CASCADE" json:"workspace"`
 	Key         string     `gorm:"type:varchar(100);not null;unique;comment:Generate by system" json:"key"`
 	Title       string     `gorm:"type:varchar(255);not null" json:"title"`
@@ -17,6 +17,8 @@ type Campaigns struct {
 	Thumbnail   string     `gorm:"type:varchar(100)" json:"thumbnail"`
 	IsPublish   bool       `gorm:"type:bool;default:false" json:"is_publish"`
 	Deleted     bool       `gorm:"type:bool;default:false" json:"deleted"`
-	CreatedAt   time.Time  `gorm:"type:timestamp" json:"created_at"`
+	DeletedAt   *time.Time `gorm:"type:timestamp;index" json:"deleted_at"`
+	DeletedBy   *uuid.UUID `gorm:"type:uuid;null" json:"deleted_by"`
+	CreatedAt   time.Time  `gorm:"type:timestamp;index:idx_campaigns_workspace_created,priority:2" json:"created_at"`
 	UpdatedAt   *time.Time `gorm:"type:timestamp" json:"updated_at"`
 }
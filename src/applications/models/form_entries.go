@@ -7,16 +7,21 @@ import (
 )
 
 type FormEntries struct {
-	ID         uuid.UUID     `gorm:"type:uuid;primaryKey" json:"id"`
-	UserID     *uuid.UUID    `gorm:"type:uuid;null" json:"user_id"`
-	User       Users         `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"user"`
-	CampaignID uuid.UUID     `gorm:"type:uuid;not null" json:"campaign_id"`
-	Campaign   Campaigns     `gorm:"foreignKey:CampaignID;references:ID;constraint:OnDelete:CASCADE" json:"campaign"`
-	ProductID  *uuid.UUID    `gorm:"type:uuid;null" json:"product_id"`
-	Product    StoreProducts `gorm:"foreignKey:ProductID;references:ID;constraint:OnDelete:CASCADE" json:"product"`
-	Status     string        `gorm:"type:char(2);default:S1;comment:S1=PENDING,S2=APPROVED;S3=REJECTED" json:"status"`
-	Remark     string        `gorm:"type:text" json:"remark"`
-	Deleted    bool          `gorm:"type:boolean;default:false" json:"deleted"`
-	CreatedAt  time.Time     `gorm:"type:timestamp" json:"created_at"`
-	UpdatedAt  *time.Time    `gorm:"type:timestamp" json:"updated_at"`
+	ID          uuid.UUID     `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID      *uuid.UUID    `gorm:"type:uuid;null;index:idx_form_entries_user_created,priority:1" json:"user_id"`
+	User        Users         `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"user"`
+	CampaignID  uuid.UUID     `gorm:"type:uuid;not null;index:idx_form_entries_campaign_created,priority:1;index:idx_form_entries_campaign_hash,priority:1" json:"campaign_id"`
+	Campaign    Campaigns     `gorm:"foreignKey:CampaignID;references:ID;constraint:OnDelete:CASCADE" json:"campaign"`
+	ProductID   *uuid.UUID    `gorm:"type:uuid;null" json:"product_id"`
+	Product     StoreProducts `gorm:"foreignKey:ProductID;references:ID;constraint:OnDelete:CASCADE" json:"product"`
+	Status      string        `gorm:"type:char(2);default:S1;comment:S1=PENDING,S2=APPROVED,S3=REJECTED,S4=SPAM" json:"status"`
+	Remark      string        `gorm:"type:text" json:"remark"`
+	SpamReason  string        `gorm:"type:varchar(255)" json:"spam_reason"`
+	ContentHash string        `gorm:"type:char(64);index:idx_form_entries_campaign_hash,priority:2;comment:Fingerprint of answers" json:"content_hash"`
+	ReviewedBy  *uuid.UUID    `gorm:"type:uuid;null" json:"reviewed_by"`
+	Reviewer    Users         `gorm:"foreignKey:ReviewedBy;references:ID;constraint:OnDelete:SET NULL" json:"reviewer"`
+	ReviewedAt  *time.Time    `gorm:"type:timestamp" json:"reviewed_at"`
+	Deleted     bool          `gorm:"type:boolean;default:false" json:"deleted"`
+	CreatedAt   time.Time     `gorm:"type:timestamp;index:idx_form_entries_campaign_created,priority:2;index:idx_form_entries_user_created,priority:2" json:"created_at"`
+	UpdatedAt   *time.Time    `gorm:"type:timestamp" json:"updated_at"`
 }
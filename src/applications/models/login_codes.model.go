@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LoginCodes struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null" json:"user_id"`
+	User      Users      `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	Email     string     `gorm:"type:varchar(100);not null;index;comment:Lowercase email the code is requested for" json:"email"`
+	CodeHash  string     `gorm:"type:char(64);not null;comment:SHA-256 of code" json:"-"`
+	ExpiresAt time.Time  `gorm:"type:timestamp;not null" json:"expires_at"`
+	UsedAt    *time.Time `gorm:"type:timestamp;comment:Set when code or link is used, either works once" json:"used_at"`
+	CreatedAt time.Time  `gorm:"type:timestamp" json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LoginHistories struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     *uuid.UUID `gorm:"type:uuid;null;index:idx_login_histories_user_created,priority:1;comment:Null when email is unknown" json:"user_id"`
+	Email      string     `gorm:"type:varchar(100)" json:"email"`
+	Method     string     `gorm:"type:varchar(50);not null" json:"method"`
+	Success    bool       `gorm:"type:boolean;not null" json:"success"`
+	Reason     string     `gorm:"type:varchar(100);comment:Why login failed" json:"reason"`
+	IP         string     `gorm:"type:varchar(45)" json:"ip"`
+	UserAgent  string     `gorm:"type:text" json:"user_agent"`
+	DeviceHash string     `gorm:"type:char(64);comment:SHA-256 of user agent, tells known device apart" json:"-"`
+	NewDevice  bool       `gorm:"type:boolean;default:false" json:"new_device"`
+	SessionID  *uuid.UUID `gorm:"type:uuid;null" json:"session_id"`
+	CreatedAt  time.Time  `gorm:"type:timestamp;index:idx_login_histories_user_created,priority:2" json:"created_at"`
+}
@@ -1,16 +1,48 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// built-in roles, admin holds every permission and user is given to new accounts
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// permissions granted to roles
+const (
+	PermissionUsersManage      = "users:manage"
+	PermissionRolesManage      = "roles:manage"
+	PermissionWorkspacesManage = "workspaces:manage"
+	PermissionAuditRead        = "audit:read"
+)
+
+// Permissions lists every permission in the order shown to admin
+var Permissions = []string{
+	PermissionUsersManage,
+	PermissionRolesManage,
+	PermissionWorkspacesManage,
+	PermissionAuditRead,
+}
+
 type Roles struct {
 	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
 	Name        string     `gorm:"type:varchar(50);uniqueIndex;not null" json:"name"`
 	Description string     `gorm:"type:varchar(100)" json:"description"`
+	Permissions string     `gorm:"type:varchar(500);not null;default:'';comment:Comma separated permissions" json:"permissions"`
 	Deleted     bool       `gorm:"type:boolean;default:false" json:"deleted"`
 	CreatedAt   time.Time  `gorm:"type:timestamp" json:"created_at"`
 	UpdatedAt   *time.Time `gorm:"type:timestamp" json:"updated_at"`
 }
+
+// PermissionList splits stored permissions
+func (r Roles) PermissionList() []string {
+	if r.Permissions == "" {
+		return []string{}
+	}
+	return strings.Split(r.Permissions, ",")
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+type SigningKeys struct {
+	ID         string     `gorm:"type:varchar(64);primaryKey" json:"id"` // kid header of signed token
+	Algorithm  string     `gorm:"type:varchar(10);not null;comment:RS256,EdDSA" json:"algorithm"`
+	PrivateKey string     `gorm:"type:text;not null;comment:Encrypted by secret key" json:"-"`
+	PublicKey  string     `gorm:"type:text;not null" json:"public_key"`
+	CreatedAt  time.Time  `gorm:"type:timestamp;not null" json:"created_at"`
+	RetiredAt  *time.Time `gorm:"type:timestamp;index" json:"retired_at"`
+}
@@ -13,6 +13,8 @@ type StoreProductCategories struct {
 	Name        string     `gorm:"type:varchar" json:"name"`
 	Description string     `gorm:"type:varchar" json:"description"`
 	Deleted     bool       `gorm:"type:boolean;default:false" json:"deleted"`
+	DeletedAt   *time.Time `gorm:"type:timestamp;index" json:"deleted_at"`
+	DeletedBy   *uuid.UUID `gorm:"type:uuid;null" json:"deleted_by"`
 	CreatedAt   time.Time  `gorm:"type:timestamp" json:"created_at"`
 	UpdatedAt   *time.Time `gorm:"type:timestamp" json:"updated_at"`
 }
@@ -15,6 +15,7 @@ type StoreProductImages struct {
 	FileSize       string        `gorm:"type:varchar" json:"file_size"`
 	FilePath       string        `gorm:"type:varchar" json:"file_path"`
 	Deleted        bool          `gorm:"type:boolean;default:false" json:"deleted"`
+	DeletedAt      *time.Time    `gorm:"type:timestamp;comment:Same as deleted_at of parent when deleted together" json:"deleted_at"`
 	CreatedAt      time.Time     `gorm:"type:timestamp" json:"created_at"`
 	UpdatedAt      *time.Time    `gorm:"type:timestamp" json:"updated_at"`
 }
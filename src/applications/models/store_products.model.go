@@ -8,7 +8,7 @@ import (
 
 type StoreProducts struct {
 	ID          uuid.UUID              `gorm:"type:uuid;primaryKey" json:"id"`
-	StoreID     uuid.UUID              `gorm:"type:uuid;not null" json:"store_id"`
+	StoreID     uuid.UUID              `gorm:"type:uuid;not null;index:idx_store_products_store_created,priority:1" json:"store_id"`
 	Store       Stores                 `gorm:"foreignKey:StoreID;references:ID;constraint:OnDelete:CASCADE" json:"store"`
 	CategoryID  uuid.UUID              `gorm:"type:uuid;not null" json:"category_id"`
 	Category    StoreProductCategories `gorm:"foreignKey:CategoryID;references:ID;constraint:OnDelete:CASCADE" json:"category"`
@@ -21,6 +21,8 @@ type StoreProducts struct {
 	Price       int64                  `gorm:"type:numeric;default:0" json:"price"`
 	Status      string                 `gorm:"type:char(2);default:S1;comment:S1=DRAFT;S2=PUBLISH;S3=OUT_OF_STOCK" json:"status"`
 	Deleted     bool                   `gorm:"type:boolean;default:false" json:"deleted"`
-	CreatedAt   time.Time              `gorm:"type:timestamp" json:"created_at"`
+	DeletedAt   *time.Time             `gorm:"type:timestamp;index" json:"deleted_at"`
+	DeletedBy   *uuid.UUID             `gorm:"type:uuid;null" json:"deleted_by"`
+	CreatedAt   time.Time              `gorm:"type:timestamp;index:idx_store_products_store_created,priority:2" json:"created_at"`
 	UpdatedAt   *time.Time             `gorm:"type:timestamp" json:"updated_at"`
 }
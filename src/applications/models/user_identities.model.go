@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserIdentities struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	User        Users      `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	Provider    string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_identities_provider_subject" json:"provider"`
+	Subject     string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_provider_subject;comment:Subject of id token, stable id of user at provider" json:"-"`
+	Email       string     `gorm:"type:varchar(100);comment:Email at provider when identity was linked" json:"email"`
+	CreatedAt   time.Time  `gorm:"type:timestamp" json:"created_at"`
+	LastLoginAt *time.Time `gorm:"type:timestamp" json:"last_login_at"`
+}
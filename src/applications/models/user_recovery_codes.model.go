@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserRecoveryCodes struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	User      Users      `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	CodeHash  string     `gorm:"type:char(64);not null;comment:SHA-256 of code" json:"-"`
+	UsedAt    *time.Time `gorm:"type:timestamp" json:"used_at"`
+	CreatedAt time.Time  `gorm:"type:timestamp" json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserSessions struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	User       Users      `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	Method     string     `gorm:"type:varchar(50);not null;comment:password, email_code, magic_link or sso:<provider>" json:"method"`
+	IP         string     `gorm:"type:varchar(45)" json:"ip"`
+	UserAgent  string     `gorm:"type:text" json:"user_agent"`
+	CreatedAt  time.Time  `gorm:"type:timestamp" json:"created_at"`
+	LastSeenAt time.Time  `gorm:"type:timestamp" json:"last_seen_at"`
+	ExpiresAt  time.Time  `gorm:"type:timestamp;not null;comment:Same as expiry of its access token" json:"expires_at"`
+	RevokedAt  *time.Time `gorm:"type:timestamp" json:"revoked_at"`
+}
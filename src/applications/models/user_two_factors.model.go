@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserTwoFactors struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;unique" json:"user_id"`
+	User         Users      `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	Secret       string     `gorm:"type:text;not null;comment:TOTP secret encrypted by secret key" json:"-"`
+	ConfirmedAt  *time.Time `gorm:"type:timestamp;comment:Null until first code is confirmed" json:"confirmed_at"`
+	LastUsedStep int64      `gorm:"type:bigint;default:0;comment:Time step of last accepted code, refuses replay" json:"-"`
+	CreatedAt    time.Time  `gorm:"type:timestamp" json:"created_at"`
+	UpdatedAt    *time.Time `gorm:"type:timestamp" json:"updated_at"`
+}
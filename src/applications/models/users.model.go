@@ -13,6 +13,7 @@ type Users struct {
 	Password     string     `gorm:"type:varchar(100);not null" json:"password"`
 	Fullname     string     `gorm:"type:varchar(255);not null" json:"fullname"`
 	IsActive     bool       `gorm:"type:boolean;default:false" json:"is_active"`
+	IsPending    bool       `gorm:"type:boolean;default:false;comment:Created by passwordless login, active once email is verified" json:"is_pending"`
 	Deleted      bool       `gorm:"type:boolean;default:false" json:"deleted"`
 	CreatedAt    time.Time  `gorm:"type:timestamp;" json:"created_at"`
 	UpdatedAt    *time.Time `gorm:"type:timestamp" json:"updated_at"`
@@ -7,14 +7,18 @@ import (
 )
 
 type Workspaces struct {
-	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
-	Key         string     `gorm:"type:varchar(100);not null;unique;comment:Generate by system" json:"key"`
-	Title       string     `gorm:"type:varchar(255);not null" json:"title"`
-	Slug        string     `gorm:"type:varchar(255);not null" json:"slug"`
-	Description string     `gorm:"type:text" json:"description"`
-	Thumbnail   string     `gorm:"type:varchar(100)" json:"thumbnail"`
-	IsPublish   bool       `gorm:"type:bool;default:false" json:"is_publish"`
-	Deleted     bool       `gorm:"type:bool;default:false" json:"deleted"`
-	CreatedAt   time.Time  `gorm:"type:timestamp" json:"created_at"`
-	UpdatedAt   *time.Time `gorm:"type:timestamp" json:"updated_at"`
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Key              string     `gorm:"type:varchar(100);not null;unique;comment:Generate by system" json:"key"`
+	Title            string     `gorm:"type:varchar(255);not null" json:"title"`
+	Slug             string     `gorm:"type:varchar(255);not null" json:"slug"`
+	Description      string     `gorm:"type:text" json:"description"`
+	Thumbnail        string     `gorm:"type:varchar(100)" json:"thumbnail"`
+	IsPublish        bool       `gorm:"type:bool;default:false" json:"is_publish"`
+	Timezone         string     `gorm:"type:varchar(50);default:UTC;comment:IANA timezone used for analytics" json:"timezone"`
+	RequireTwoFactor bool       `gorm:"type:bool;default:false;comment:Members must sign in with two-factor code" json:"require_two_factor"`
+	Deleted          bool       `gorm:"type:bool;default:false" json:"deleted"`
+	DeletedAt        *time.Time `gorm:"type:timestamp;index" json:"deleted_at"`
+	DeletedBy        *uuid.UUID `gorm:"type:uuid;null" json:"deleted_by"`
+	CreatedAt        time.Time  `gorm:"type:timestamp" json:"created_at"`
+	UpdatedAt        *time.Time `gorm:"type:timestamp" json:"updated_at"`
 }
@@ -0,0 +1,84 @@
+package masterrepo
+
+import (
+	"context"
+	"kiraform/src/applications/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lastUsedInterval limits writing last used time to once per interval for busy keys
+const lastUsedInterval = time.Minute
+
+type APIKeyRepository interface {
+	FindAPIKeys(ctx context.Context, workspaceID string) ([]models.APIKeys, error)
+	FindAPIKeyByID(ctx context.Context, workspaceID string, ID string) (*models.APIKeys, error)
+	FindAPIKeyByHash(ctx context.Context, hash string) (*models.APIKeys, error)
+	CreateAPIKey(ctx context.Context, data models.APIKeys) error
+	RevokeAPIKey(ctx context.Context, workspaceID string, ID string, revokedAt time.Time) error
+	TouchAPIKey(ctx context.Context, ID string, usedAt time.Time) error
+}
+
+type APIKeyQuery struct {
+	DB *gorm.DB
+}
+
+func NewAPIKeyRepository(DB *gorm.DB) APIKeyRepository {
+	return &APIKeyQuery{DB: DB}
+}
+
+// FindAPIKeys returns keys of workspace which are not revoked, newest first
+func (q *APIKeyQuery) FindAPIKeys(ctx context.Context, workspaceID string) ([]models.APIKeys, error) {
+	var keys []models.APIKeys
+	err := q.DB.WithContext(ctx).Where("workspace_id = ? AND revoked_at IS NULL", workspaceID).
+		Order("created_at DESC").
+		Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (q *APIKeyQuery) FindAPIKeyByID(ctx context.Context, workspaceID string, ID string) (*models.APIKeys, error) {
+	var key models.APIKeys
+	err := q.DB.WithContext(ctx).Where("workspace_id = ? AND id = ? AND revoked_at IS NULL", workspaceID, ID).
+		First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (q *APIKeyQuery) FindAPIKeyByHash(ctx context.Context, hash string) (*models.APIKeys, error) {
+	var key models.APIKeys
+	err := q.DB.WithContext(ctx).Where("key_hash = ?", hash).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (q *APIKeyQuery) CreateAPIKey(ctx context.Context, data models.APIKeys) error {
+	return q.DB.WithContext(ctx).Create(&data).Error
+}
+
+func (q *APIKeyQuery) RevokeAPIKey(ctx context.Context, workspaceID string, ID string, revokedAt time.Time) error {
+	result := q.DB.WithContext(ctx).Model(&models.APIKeys{}).
+		Where("workspace_id = ? AND id = ? AND revoked_at IS NULL", workspaceID, ID).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// TouchAPIKey updates last used time, skipped when it was already updated recently
+func (q *APIKeyQuery) TouchAPIKey(ctx context.Context, ID string, usedAt time.Time) error {
+	return q.DB.WithContext(ctx).Model(&models.APIKeys{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", ID, usedAt.Add(-lastUsedInterval)).
+		Update("last_used_at", usedAt).Error
+}
@@ -0,0 +1,119 @@
+package masterrepo
+
+import (
+	"context"
+	"kiraform/src/applications/models"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"kiraform/src/utils"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type AuditRepository interface {
+	CreateAuditLog(ctx context.Context, data models.AuditLogs) error
+	FindWorkspaceIDByCampaign(ctx context.Context, campaignID string) (*string, error)
+	FindAuditLogs(ctx context.Context, workspaceID *string, params *commonschema.QueryParams) ([]masterschema.AuditLogSchema, error)
+	FindCountAuditLog(ctx context.Context, workspaceID *string, params *commonschema.QueryParams) (int64, error)
+}
+
+type AuditQuery struct {
+	DB *gorm.DB
+}
+
+func NewAuditRepository(DB *gorm.DB) *AuditQuery {
+	return &AuditQuery{DB: DB}
+}
+
+func (q *AuditQuery) CreateAuditLog(ctx context.Context, data models.AuditLogs) error {
+	if err := q.DB.WithContext(ctx).Create(&data).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (q *AuditQuery) FindWorkspaceIDByCampaign(ctx context.Context, campaignID string) (*string, error) {
+	var workspaceID string
+	if err := q.DB.WithContext(ctx).Model(&models.Campaigns{}).Where("id = ?", campaignID).Select("workspace_id::TEXT").Scan(&workspaceID).Error; err != nil {
+		return nil, err
+	}
+	if workspaceID == "" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &workspaceID, nil
+}
+
+// auditLogStatement builds base query, nil workspace means logs of every workspace and store
+func (q *AuditQuery) auditLogStatement(ctx context.Context, workspaceID *string, params *commonschema.QueryParams) *gorm.DB {
+	st := q.DB.WithContext(ctx).Model(&models.AuditLogs{}).Joins("LEFT JOIN users ON users.id = audit_logs.actor_id")
+	if workspaceID != nil {
+		st = st.Where("audit_logs.workspace_id::TEXT = ?", *workspaceID)
+	}
+
+	// add search condition
+	if params.Search != "" {
+		keyword := "%" + strings.ToLower(params.Search) + "%"
+		st = st.Where("(LOWER(audit_logs.entity_id) LIKE ? OR LOWER(users.fullname) LIKE ? OR LOWER(users.email) LIKE ?)", keyword, keyword, keyword)
+	}
+
+	// add filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
+	}
+	return st
+}
+
+func (q *AuditQuery) FindAuditLogs(ctx context.Context, workspaceID *string, params *commonschema.QueryParams) ([]masterschema.AuditLogSchema, error) {
+	var auditLogs []masterschema.AuditLogSchema
+
+	// define offset
+	offset := 0
+	if params.Limit > 0 && params.Page > 0 {
+		offset = params.Limit * (params.Page - 1)
+	}
+
+	// define statements
+	st := q.auditLogStatement(ctx, workspaceID, params).Select(`
+		audit_logs.id, 
+		audit_logs.workspace_id, 
+		audit_logs.store_id, 
+		audit_logs.actor_id, 
+		users.fullname AS actor_name, 
+		users.email AS actor_email, 
+		audit_logs.action, 
+		audit_logs.entity_type, 
+		audit_logs.entity_id, 
+		audit_logs.before, 
+		audit_logs.after, 
+		audit_logs.changes, 
+		audit_logs.ip, 
+		audit_logs.user_agent, 
+		audit_logs.request_id, 
+		audit_logs.created_at
+	`)
+
+	// add orderby and limit:offset, cursor pagination uses keyset with one extra row to detect next page
+	if condition, args, order := utils.CursorClause(params, "audit_logs.created_at", "audit_logs.id"); order != "" {
+		if condition != "" {
+			st = st.Where(condition, args...)
+		}
+		st = st.Order(order).Limit(params.Limit + 1)
+	} else {
+		st = st.Order(utils.OrderClause(params, "audit_logs.created_at DESC", "audit_logs.id")).Limit(params.Limit).Offset(offset)
+	}
+
+	// perform to get the data
+	if err := st.Scan(&auditLogs).Error; err != nil {
+		return nil, err
+	}
+	return auditLogs, nil
+}
+
+func (q *AuditQuery) FindCountAuditLog(ctx context.Context, workspaceID *string, params *commonschema.QueryParams) (int64, error) {
+	var count int64
+	if err := q.auditLogStatement(ctx, workspaceID, params).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
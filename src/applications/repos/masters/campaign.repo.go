@@ -1,39 +1,52 @@
 package masterrepo
 
 import (
-	"fmt"
+	"context"
 	"kiraform/src/applications/models"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
 	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"kiraform/src/utils"
 	"strings"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CampaignRepository interface {
-	FindCampaigns(workspaceID string, params *commonschema.QueryParams) ([]masterschema.CampaignSchema, error)
-	FindCountCampaign(workspaceID string, params *commonschema.QueryParams) (int64, error)
-	FindCampaignByID(workspaceID string, ID string) (*masterschema.CampaignSchema, error)
-	FindCampaignByKey(key string, isPublish *bool) (*masterschema.CampaignSchema, error)
-	FindFormsByCampaign(campaignID string) ([]masterschema.CampaignFormSchema, error)
-	FindFormAttributes(campaignFormID string) ([]masterschema.CampaignFormAttributeSchemas, error)
-	CreateCampaign(campaign models.Campaigns, campaignForms []models.CampaignForms, campaignFormAttributes []models.CampaignFormAttributes) error
-	UpdateCampaign(ID string, campaign models.Campaigns) error
-	UpdateEntireCampaign(ID string, campaign models.Campaigns, campaignFormActions map[string][]models.CampaignForms, campaignFormAttributesCreate []models.CampaignFormAttributes) error
-	FindCampaignSeos(campaignID string, params *commonschema.QueryParams) ([]masterschema.CampaignSeoSchema, error)
-	FindCountCampaignSeo(campaignID string, params *commonschema.QueryParams) (int64, error)
-	FindCampaignSeoByID(campaignID string, ID string) (*masterschema.CampaignSeoSchema, error)
-	CreateCampaignSeo(body models.CampaignSeos) error
-	UpdateCampaignSeo(campaignID string, ID string, body models.CampaignSeos) error
-	CreateFormAttribute(formAttribute models.CampaignFormAttributes) error
-	UpdateFormAttribute(formAttribute models.CampaignFormAttributes, ID string) error
-	FindCountFormSubmissionByCampaign(campaignID string) (int64, error)
-	FindSummaryEntriesByDate(workspaceID string, campaignID string) ([]masterschema.CampaignFormEntryChart, error)
-	FindFormEntries(workspaceID string, campaignID string, params *commonschema.QueryParams) ([]masterschema.FormEntryList, error)
-	FindCountFormEntries(workspaceID string, campaignID string, params *commonschema.QueryParams) (int64, error)
-	CheckAllowedUserForCampaign(workspaceID string, campaignID string, userID string) (*masterschema.CampaignSchema, error)
-	FindFormEntry(ID string) (*masterschema.FormEntrySchema, error)
-	FindDetailFormEntry(formEntryID string) ([]masterschema.FormDetailEntrySchema, error)
+	FindCampaigns(ctx context.Context, workspaceID string, params *commonschema.QueryParams) ([]masterschema.CampaignSchema, error)
+	FindCountCampaign(ctx context.Context, workspaceID string, params *commonschema.QueryParams) (int64, error)
+	FindCampaignByID(ctx context.Context, workspaceID string, ID string) (*masterschema.CampaignSchema, error)
+	FindCampaignByKey(ctx context.Context, key string, isPublish *bool) (*masterschema.CampaignSchema, error)
+	FindFormsByCampaign(ctx context.Context, campaignID string) ([]masterschema.CampaignFormSchema, error)
+	FindFormAttributes(ctx context.Context, campaignFormID string) ([]masterschema.CampaignFormAttributeSchemas, error)
+	CreateCampaign(ctx context.Context, campaign models.Campaigns, campaignForms []models.CampaignForms, campaignFormAttributes []models.CampaignFormAttributes) error
+	UpdateCampaign(ctx context.Context, ID string, campaign models.Campaigns) error
+	DeleteCampaign(ctx context.Context, ID string, campaign models.Campaigns) error
+	UpdateEntireCampaign(ctx context.Context, ID string, campaign models.Campaigns, campaignFormActions map[string][]models.CampaignForms, campaignFormAttributesCreate []models.CampaignFormAttributes) error
+	FindCampaignSeos(ctx context.Context, campaignID string, params *commonschema.QueryParams) ([]masterschema.CampaignSeoSchema, error)
+	FindCountCampaignSeo(ctx context.Context, campaignID string, params *commonschema.QueryParams) (int64, error)
+	FindCampaignSeoByID(ctx context.Context, campaignID string, ID string) (*masterschema.CampaignSeoSchema, error)
+	CreateCampaignSeo(ctx context.Context, body models.CampaignSeos) error
+	UpdateCampaignSeo(ctx context.Context, campaignID string, ID string, body models.CampaignSeos) error
+	CreateFormAttribute(ctx context.Context, formAttribute models.CampaignFormAttributes) error
+	UpdateFormAttribute(ctx context.Context, formAttribute models.CampaignFormAttributes, ID string) error
+	FindCountFormSubmissionByCampaigns(ctx context.Context, campaignIDs []string) (map[string]int64, error)
+	FindSummaryEntriesByDate(ctx context.Context, workspaceID string, campaignID string, params *masterschema.AnalyticsParams) ([]masterschema.CampaignFormEntryChart, error)
+	FindCountEntriesByRange(ctx context.Context, workspaceID string, campaignID string, params *masterschema.AnalyticsParams) (int64, error)
+	FindAnsweredCountByField(ctx context.Context, campaignID string, params *masterschema.AnalyticsParams) ([]masterschema.FieldAnswerCount, error)
+	FindAnswerCountByAttribute(ctx context.Context, campaignID string, params *masterschema.AnalyticsParams) ([]masterschema.AttributeAnswerCount, error)
+	FindNumericStatByField(ctx context.Context, campaignID string, params *masterschema.AnalyticsParams) ([]masterschema.FieldNumericStat, error)
+	FindFormEntries(ctx context.Context, workspaceID string, campaignID string, params *commonschema.QueryParams, filter *masterschema.FormEntryFilter) ([]masterschema.FormEntryList, error)
+	FindCountFormEntries(ctx context.Context, workspaceID string, campaignID string, params *commonschema.QueryParams, filter *masterschema.FormEntryFilter) (int64, error)
+	CheckAllowedUserForCampaign(ctx context.Context, workspaceID string, campaignID string, userID string) (*masterschema.CampaignSchema, error)
+	FindFormEntry(ctx context.Context, ID string) (*masterschema.FormEntrySchema, error)
+	FindDetailFormEntry(ctx context.Context, formEntryID string) ([]masterschema.FormDetailEntrySchema, error)
+	FindDetailFormEntries(ctx context.Context, formEntryIDs []string) (map[string][]masterschema.FormDetailEntrySchema, error)
+	UpdateFormEntry(ctx context.Context, campaignID string, ID string, data models.FormEntries) error
+	IncrementCampaignVisit(ctx context.Context, campaignID string) error
+	FindSpamSetting(ctx context.Context, campaignID string) (*models.CampaignSpamSettings, error)
+	SaveSpamSetting(ctx context.Context, setting models.CampaignSpamSettings) error
 }
 
 type CampaignQuery struct {
@@ -46,7 +59,7 @@ func NewCampaignRepository(DB *gorm.DB) *CampaignQuery {
 	}
 }
 
-func (q *CampaignQuery) FindCampaigns(workspaceID string, params *commonschema.QueryParams) ([]masterschema.CampaignSchema, error) {
+func (q *CampaignQuery) FindCampaigns(ctx context.Context, workspaceID string, params *commonschema.QueryParams) ([]masterschema.CampaignSchema, error) {
 	var campaigns []masterschema.CampaignSchema
 
 	// define offset
@@ -56,20 +69,28 @@ func (q *CampaignQuery) FindCampaigns(workspaceID string, params *commonschema.Q
 	}
 
 	// define statemetns
-	st := q.DB.Model(&models.Campaigns{}).Where("deleted = ? AND workspace_id::TEXT = ?", false, workspaceID).Select("id", "workspace_id", "title", "key", "slug", "description", "is_publish", "created_at")
+	st := q.DB.WithContext(ctx).Model(&models.Campaigns{}).Where("deleted = ? AND workspace_id::TEXT = ?", false, workspaceID).Select("id", "workspace_id", "title", "key", "slug", "description", "is_publish", "created_at")
 
 	// add search condition
 	if params.Search != "" {
 		st = st.Where("LOWER(title) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
 	}
 
-	// add orderby
-	if params.OrderBy != "" {
-		st = st.Order(params.OrderBy)
+	// add filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
 	}
 
-	// add limit:offset
-	st = st.Limit(params.Limit).Offset(offset)
+	// add orderby and limit:offset, cursor pagination uses keyset with one extra row to detect next page
+	if condition, args, order := utils.CursorClause(params, "campaigns.created_at", "campaigns.id"); order != "" {
+		if condition != "" {
+			st = st.Where(condition, args...)
+		}
+		st = st.Order(order).Limit(params.Limit + 1)
+	} else {
+		st = st.Order(utils.OrderClause(params, "", "campaigns.id"))
+		st = st.Limit(params.Limit).Offset(offset)
+	}
 
 	// perform to get the data
 	if err := st.Find(&campaigns).Error; err != nil {
@@ -78,15 +99,20 @@ func (q *CampaignQuery) FindCampaigns(workspaceID string, params *commonschema.Q
 	return campaigns, nil
 }
 
-func (q *CampaignQuery) FindCountCampaign(workspaceID string, params *commonschema.QueryParams) (int64, error) {
+func (q *CampaignQuery) FindCountCampaign(ctx context.Context, workspaceID string, params *commonschema.QueryParams) (int64, error) {
 	var count int64
 
 	// prepare condition
-	st := q.DB.Model(&models.Campaigns{}).Where("deleted = ? AND workspace_id::TEXT = ?", false, workspaceID)
+	st := q.DB.WithContext(ctx).Model(&models.Campaigns{}).Where("deleted = ? AND workspace_id::TEXT = ?", false, workspaceID)
 	if params.Search != "" {
 		st = st.Where("LOWER(title) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
 	}
 
+	// add filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
+	}
+
 	// perform to get data
 	if err := st.Count(&count).Error; err != nil {
 		return 0, err
@@ -94,22 +120,22 @@ func (q *CampaignQuery) FindCountCampaign(workspaceID string, params *commonsche
 	return count, nil
 }
 
-func (q *CampaignQuery) FindCampaignByID(workspaceID string, ID string) (*masterschema.CampaignSchema, error) {
+func (q *CampaignQuery) FindCampaignByID(ctx context.Context, workspaceID string, ID string) (*masterschema.CampaignSchema, error) {
 	var campaign masterschema.CampaignSchema
 
 	// perform to query
-	st := q.DB.Model(&models.Campaigns{}).Where("deleted = ? AND workspace_id = ? and id = ?", false, workspaceID, ID)
+	st := q.DB.WithContext(ctx).Model(&models.Campaigns{}).Where("deleted = ? AND workspace_id = ? and id = ?", false, workspaceID, ID)
 	if err := st.First(&campaign).Error; err != nil {
 		return nil, err
 	}
 	return &campaign, nil
 }
 
-func (q *CampaignQuery) FindCampaignByKey(key string, isPublish *bool) (*masterschema.CampaignSchema, error) {
+func (q *CampaignQuery) FindCampaignByKey(ctx context.Context, key string, isPublish *bool) (*masterschema.CampaignSchema, error) {
 	var campaign masterschema.CampaignSchema
 
 	// perform to query
-	st := q.DB.Model(&models.Campaigns{}).Where("deleted = ? AND key = ?", false, key)
+	st := q.DB.WithContext(ctx).Model(&models.Campaigns{}).Where("deleted = ? AND key = ?", false, key)
 	if isPublish != nil {
 		st = st.Where("is_publish = ?", isPublish)
 	}
@@ -120,11 +146,11 @@ func (q *CampaignQuery) FindCampaignByKey(key string, isPublish *bool) (*masters
 	return &campaign, nil
 }
 
-func (q *CampaignQuery) FindFormsByCampaign(campaignID string) ([]masterschema.CampaignFormSchema, error) {
+func (q *CampaignQuery) FindFormsByCampaign(ctx context.Context, campaignID string) ([]masterschema.CampaignFormSchema, error) {
 	var campaignForms []masterschema.CampaignFormSchema
 
 	// perform to query
-	st := q.DB.Model(&models.CampaignForms{}).
+	st := q.DB.WithContext(ctx).Model(&models.CampaignForms{}).
 		Joins("JOIN forms ON forms.id = campaign_forms.form_id").
 		Select("campaign_forms.*", "forms.name AS form_name", "forms.code AS form_code").
 		Where("campaign_forms.deleted = ? AND campaign_forms.campaign_id = ?", false, campaignID)
@@ -135,11 +161,11 @@ func (q *CampaignQuery) FindFormsByCampaign(campaignID string) ([]masterschema.C
 	return campaignForms, nil
 }
 
-func (q *CampaignQuery) FindFormAttributes(campaignFormID string) ([]masterschema.CampaignFormAttributeSchemas, error) {
+func (q *CampaignQuery) FindFormAttributes(ctx context.Context, campaignFormID string) ([]masterschema.CampaignFormAttributeSchemas, error) {
 	var campaignFormAttributes []masterschema.CampaignFormAttributeSchemas
 
 	// perform query
-	st := q.DB.Model(&models.CampaignFormAttributes{}).Where("deleted = ? AND campaign_form_id = ?", false, campaignFormID)
+	st := q.DB.WithContext(ctx).Model(&models.CampaignFormAttributes{}).Where("deleted = ? AND campaign_form_id = ?", false, campaignFormID)
 	st = st.Order("created_at ASC")
 	if err := st.Find(&campaignFormAttributes).Error; err != nil {
 		return nil, err
@@ -147,10 +173,10 @@ func (q *CampaignQuery) FindFormAttributes(campaignFormID string) ([]masterschem
 	return campaignFormAttributes, nil
 }
 
-func (q *CampaignQuery) CreateCampaign(campaign models.Campaigns, campaignForms []models.CampaignForms, campaignFormAttributes []models.CampaignFormAttributes) error {
+func (q *CampaignQuery) CreateCampaign(ctx context.Context, campaign models.Campaigns, campaignForms []models.CampaignForms, campaignFormAttributes []models.CampaignFormAttributes) error {
 	// insert all data using transaction [commit:rollback]
 	// to prevent error coming
-	err := q.DB.Transaction(func(tx *gorm.DB) error {
+	err := q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// insert campaign header
 		if err := tx.Create(&campaign).Error; err != nil {
 			return err
@@ -180,15 +206,39 @@ func (q *CampaignQuery) CreateCampaign(campaign models.Campaigns, campaignForms
 	return nil
 }
 
-func (q *CampaignQuery) UpdateCampaign(ID string, campaign models.Campaigns) error {
-	if err := q.DB.Where("deleted = ? AND id = ?", false, ID).Updates(&campaign).Error; err != nil {
+func (q *CampaignQuery) UpdateCampaign(ctx context.Context, ID string, campaign models.Campaigns) error {
+	if err := q.DB.WithContext(ctx).Where("deleted = ? AND id = ?", false, ID).Updates(&campaign).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *CampaignQuery) UpdateEntireCampaign(ID string, campaign models.Campaigns, campaignFormActions map[string][]models.CampaignForms, campaignFormAttributesCreate []models.CampaignFormAttributes) error {
-	err := q.DB.Transaction(func(tx *gorm.DB) error {
+// DeleteCampaign flags campaign with its active forms and attributes as deleted,
+// children share deleted_at of campaign so they can be restored together
+func (q *CampaignQuery) DeleteCampaign(ctx context.Context, ID string, campaign models.Campaigns) error {
+	err := q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("deleted = ? AND id = ?", false, ID).Updates(&campaign).Error; err != nil {
+			return err
+		}
+
+		forms := tx.Model(&models.CampaignForms{}).Select("id").Where("campaign_id = ? AND deleted = ?", ID, false)
+		if err := tx.Model(&models.CampaignFormAttributes{}).
+			Where("campaign_form_id IN (?) AND deleted = ?", forms, false).
+			Updates(models.CampaignFormAttributes{Deleted: true, DeletedAt: campaign.DeletedAt, UpdatedAt: campaign.UpdatedAt}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.CampaignForms{}).
+			Where("campaign_id = ? AND deleted = ?", ID, false).
+			Updates(models.CampaignForms{Deleted: true, DeletedAt: campaign.DeletedAt, UpdatedAt: campaign.UpdatedAt}).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+	return err
+}
+
+func (q *CampaignQuery) UpdateEntireCampaign(ctx context.Context, ID string, campaign models.Campaigns, campaignFormActions map[string][]models.CampaignForms, campaignFormAttributesCreate []models.CampaignFormAttributes) error {
+	err := q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// update campaign
 		if err := tx.Where("deleted = ? AND id = ?", false, ID).Updates(campaign).Error; err != nil {
 			return err
@@ -221,7 +271,6 @@ func (q *CampaignQuery) UpdateEntireCampaign(ID string, campaign models.Campaign
 			if d, ok := campaignFormActions["delete"]; ok {
 				for _, dv := range d {
 					if err := tx.Model(&models.CampaignForms{}).Where("id = ?", dv.ID).Updates(&dv).Error; err != nil {
-						fmt.Println(err)
 						return err
 					}
 				}
@@ -246,7 +295,7 @@ func (q *CampaignQuery) UpdateEntireCampaign(ID string, campaign models.Campaign
 	return nil
 }
 
-func (q *CampaignQuery) FindCampaignSeos(campaignID string, params *commonschema.QueryParams) ([]masterschema.CampaignSeoSchema, error) {
+func (q *CampaignQuery) FindCampaignSeos(ctx context.Context, campaignID string, params *commonschema.QueryParams) ([]masterschema.CampaignSeoSchema, error) {
 	var campaigns []masterschema.CampaignSeoSchema
 
 	// define offset
@@ -256,18 +305,22 @@ func (q *CampaignQuery) FindCampaignSeos(campaignID string, params *commonschema
 	}
 
 	// define statemetns
-	st := q.DB.Model(&models.CampaignSeos{}).Where("deleted = ? AND campaign_id::TEXT = ?", false, campaignID)
+	st := q.DB.WithContext(ctx).Model(&models.CampaignSeos{}).Where("deleted = ? AND campaign_id::TEXT = ?", false, campaignID)
 
 	// add search condition
 	if params.Search != "" {
-		st = st.Where("LOWER(title) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
+		search := "%" + strings.ToLower(params.Search) + "%"
+		st = st.Where("LOWER(platform) LIKE ? OR LOWER(event) LIKE ?", search, search)
 	}
 
-	// add orderby
-	if params.OrderBy != "" {
-		st = st.Order(params.OrderBy)
+	// add filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
 	}
 
+	// add orderby
+	st = st.Order(utils.OrderClause(params, "", "campaign_seos.id"))
+
 	// add limit:offset
 	st = st.Limit(params.Limit).Offset(offset)
 
@@ -278,13 +331,19 @@ func (q *CampaignQuery) FindCampaignSeos(campaignID string, params *commonschema
 	return campaigns, nil
 }
 
-func (q *CampaignQuery) FindCountCampaignSeo(campaignID string, params *commonschema.QueryParams) (int64, error) {
+func (q *CampaignQuery) FindCountCampaignSeo(ctx context.Context, campaignID string, params *commonschema.QueryParams) (int64, error) {
 	var count int64
 
 	// prepare condition
-	st := q.DB.Model(&models.CampaignSeos{}).Where("deleted = ? AND campaign_id::TEXT = ?", false, campaignID)
+	st := q.DB.WithContext(ctx).Model(&models.CampaignSeos{}).Where("deleted = ? AND campaign_id::TEXT = ?", false, campaignID)
 	if params.Search != "" {
-		st = st.Where("LOWER(title) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
+		search := "%" + strings.ToLower(params.Search) + "%"
+		st = st.Where("LOWER(platform) LIKE ? OR LOWER(event) LIKE ?", search, search)
+	}
+
+	// add filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
 	}
 
 	// perform to get data
@@ -294,70 +353,244 @@ func (q *CampaignQuery) FindCountCampaignSeo(campaignID string, params *commonsc
 	return count, nil
 }
 
-func (q *CampaignQuery) FindCampaignSeoByID(campaignID string, ID string) (*masterschema.CampaignSeoSchema, error) {
+func (q *CampaignQuery) FindCampaignSeoByID(ctx context.Context, campaignID string, ID string) (*masterschema.CampaignSeoSchema, error) {
 	var campaign masterschema.CampaignSeoSchema
 
 	// perform to query
-	st := q.DB.Model(&models.CampaignSeos{}).Where("deleted = ? AND campaign_id = ? and id = ?", false, campaignID, ID)
+	st := q.DB.WithContext(ctx).Model(&models.CampaignSeos{}).Where("deleted = ? AND campaign_id = ? and id = ?", false, campaignID, ID)
 	if err := st.First(&campaign).Error; err != nil {
 		return nil, err
 	}
 	return &campaign, nil
 }
 
-func (q *CampaignQuery) CreateCampaignSeo(campaignSeo models.CampaignSeos) error {
-	if err := q.DB.Create(&campaignSeo).Error; err != nil {
+func (q *CampaignQuery) CreateCampaignSeo(ctx context.Context, campaignSeo models.CampaignSeos) error {
+	if err := q.DB.WithContext(ctx).Create(&campaignSeo).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *CampaignQuery) UpdateCampaignSeo(campaignID string, ID string, campaignSeo models.CampaignSeos) error {
-	if err := q.DB.Where("deleted = ? AND campaign_id = ? AND id = ?", false, campaignID, ID).Updates(&campaignSeo).Error; err != nil {
+func (q *CampaignQuery) UpdateCampaignSeo(ctx context.Context, campaignID string, ID string, campaignSeo models.CampaignSeos) error {
+	if err := q.DB.WithContext(ctx).Where("deleted = ? AND campaign_id = ? AND id = ?", false, campaignID, ID).Updates(&campaignSeo).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *CampaignQuery) CreateFormAttribute(formAttribute models.CampaignFormAttributes) error {
-	if err := q.DB.Model(&models.CampaignFormAttributes{}).Create(&formAttribute).Error; err != nil {
+func (q *CampaignQuery) CreateFormAttribute(ctx context.Context, formAttribute models.CampaignFormAttributes) error {
+	if err := q.DB.WithContext(ctx).Model(&models.CampaignFormAttributes{}).Create(&formAttribute).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *CampaignQuery) UpdateFormAttribute(formAttribute models.CampaignFormAttributes, ID string) error {
-	if err := q.DB.Model(&models.CampaignFormAttributes{}).Where("id = ?", ID).Updates(&formAttribute).Error; err != nil {
+func (q *CampaignQuery) UpdateFormAttribute(ctx context.Context, formAttribute models.CampaignFormAttributes, ID string) error {
+	if err := q.DB.WithContext(ctx).Model(&models.CampaignFormAttributes{}).Where("id = ?", ID).Updates(&formAttribute).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *CampaignQuery) FindCampaignFormAttributes(campaignFormID string) ([]models.CampaignFormAttributes, error) {
+func (q *CampaignQuery) FindCampaignFormAttributes(ctx context.Context, campaignFormID string) ([]models.CampaignFormAttributes, error) {
 	var campaignFormAttributes []models.CampaignFormAttributes
-	if err := q.DB.Model(&models.CampaignFormAttributes{}).Where("deleted = ? AND campaign_form_id = ?", false, campaignFormID).Find(&campaignFormAttributes).Error; err != nil {
+	if err := q.DB.WithContext(ctx).Model(&models.CampaignFormAttributes{}).Where("deleted = ? AND campaign_form_id = ?", false, campaignFormID).Find(&campaignFormAttributes).Error; err != nil {
 		return nil, err
 	}
 	return campaignFormAttributes, nil
 }
 
-func (q *CampaignQuery) FindCountFormSubmissionByCampaign(campaignID string) (int64, error) {
+// FindCountFormSubmissionByCampaigns counts entries of many campaigns in one query
+func (q *CampaignQuery) FindCountFormSubmissionByCampaigns(ctx context.Context, campaignIDs []string) (map[string]int64, error) {
+	var rows []commonschema.CountByID
+	if len(campaignIDs) == 0 {
+		return map[string]int64{}, nil
+	}
+
+	err := q.DB.WithContext(ctx).Model(&models.FormEntries{}).
+		Select("campaign_id AS id", "COUNT(1) AS total").
+		Where("deleted = ? AND campaign_id IN ?", false, campaignIDs).
+		Group("campaign_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return commonschema.CountMap(rows), nil
+}
+
+// created_at is stored in UTC, shift it into workspace timezone before grouping,
+// analytics queries using it leave out entries flagged as spam (S4)
+const localEntryDate = "((form_entries.created_at AT TIME ZONE 'UTC') AT TIME ZONE ?)"
+
+func (q *CampaignQuery) FindSummaryEntriesByDate(ctx context.Context, workspaceID string, campaignID string, params *masterschema.AnalyticsParams) ([]masterschema.CampaignFormEntryChart, error) {
+	var data []masterschema.CampaignFormEntryChart
+
+	query := `
+		SELECT 
+			COUNT(1) AS total, 
+			TO_CHAR(DATE_TRUNC(?, ` + localEntryDate + `), 'YYYY-MM-DD') AS date 
+		FROM form_entries 
+		JOIN campaigns ON campaigns.id = form_entries.campaign_id 
+		WHERE 
+			campaigns.deleted = ? 
+			AND form_entries.deleted = ? 
+			AND form_entries.status <> ? 
+			AND campaigns.workspace_id = ? 
+			AND campaigns.id = ? 
+			AND ` + localEntryDate + `::DATE BETWEEN ? AND ?
+		GROUP BY 2 
+		ORDER BY 2 ASC
+	`
+	args := []any{params.GroupBy, params.Timezone, false, false, "S4", workspaceID, campaignID, params.Timezone, params.StartDate, params.EndDate}
+
+	if err := q.DB.WithContext(ctx).Raw(query, args...).Scan(&data).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (q *CampaignQuery) FindCountEntriesByRange(ctx context.Context, workspaceID string, campaignID string, params *masterschema.AnalyticsParams) (int64, error) {
 	var count int64
-	if err := q.DB.Model(&models.FormEntries{}).Where("deleted = ? AND campaign_id = ?", false, campaignID).Count(&count).Error; err != nil {
+
+	query := `
+		SELECT 
+			COUNT(1) 
+		FROM form_entries 
+		JOIN campaigns ON campaigns.id = form_entries.campaign_id 
+		WHERE 
+			campaigns.deleted = ? 
+			AND form_entries.deleted = ? 
+			AND form_entries.status <> ? 
+			AND campaigns.workspace_id = ? 
+			AND campaigns.id = ? 
+			AND ` + localEntryDate + `::DATE BETWEEN ? AND ?
+	`
+	args := []any{false, false, "S4", workspaceID, campaignID, params.Timezone, params.StartDate, params.EndDate}
+
+	if err := q.DB.WithContext(ctx).Raw(query, args...).Scan(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
-func (q *CampaignQuery) FindSummaryEntriesByDate(workspaceID string, campaignID string) ([]masterschema.CampaignFormEntryChart, error) {
-	var data []masterschema.CampaignFormEntryChart
-	if err := q.DB.Raw("SELECT COUNT(1) AS total, TO_CHAR(form_entries.created_at, 'YYYY-MM-DD') AS date FROM form_entries JOIN campaigns ON campaigns.id = form_entries.campaign_id WHERE campaigns.deleted = ? AND form_entries.deleted = ? AND campaigns.workspace_id = ? AND campaigns.id = ? AND form_entries.created_at >= CURRENT_DATE - INTERVAL '60 days' GROUP BY TO_CHAR(form_entries.created_at, 'YYYY-MM-DD') ORDER BY TO_CHAR(form_entries.created_at, 'YYYY-MM-DD') ASC", false, false, workspaceID, campaignID).Scan(&data).Error; err != nil {
+func (q *CampaignQuery) FindAnsweredCountByField(ctx context.Context, campaignID string, params *masterschema.AnalyticsParams) ([]masterschema.FieldAnswerCount, error) {
+	var data []masterschema.FieldAnswerCount
+
+	// count entries having non-empty answer for each field
+	// checkbox can store more than one row per entry, so count distinct entry
+	query := `
+		SELECT 
+			form_detail_entries.campaign_form_id, 
+			COUNT(DISTINCT form_detail_entries.form_entry_id) AS total 
+		FROM form_detail_entries 
+		JOIN form_entries ON form_entries.id = form_detail_entries.form_entry_id 
+		WHERE 
+			form_detail_entries.deleted = ? 
+			AND form_entries.deleted = ? 
+			AND form_entries.status <> ? 
+			AND form_entries.campaign_id = ? 
+			AND (form_detail_entries.campaign_form_attribute_id IS NOT NULL OR TRIM(form_detail_entries.value) <> '') 
+			AND ` + localEntryDate + `::DATE BETWEEN ? AND ?
+		GROUP BY form_detail_entries.campaign_form_id
+	`
+	args := []any{false, false, "S4", campaignID, params.Timezone, params.StartDate, params.EndDate}
+
+	if err := q.DB.WithContext(ctx).Raw(query, args...).Scan(&data).Error; err != nil {
 		return nil, err
 	}
 	return data, nil
 }
 
-func (q *CampaignQuery) FindFormEntries(workspaceID string, campaignID string, params *commonschema.QueryParams) ([]masterschema.FormEntryList, error) {
+func (q *CampaignQuery) FindAnswerCountByAttribute(ctx context.Context, campaignID string, params *masterschema.AnalyticsParams) ([]masterschema.AttributeAnswerCount, error) {
+	var data []masterschema.AttributeAnswerCount
+
+	// left join answers so options never chosen are still listed
+	// older entries may only store the value, match them by value as fallback
+	query := `
+		SELECT 
+			campaign_form_attributes.campaign_form_id, 
+			campaign_form_attributes.id AS campaign_form_attribute_id, 
+			campaign_form_attributes.label, 
+			campaign_form_attributes.value, 
+			COUNT(answers.id) AS total 
+		FROM campaign_form_attributes 
+		JOIN campaign_forms ON campaign_forms.id = campaign_form_attributes.campaign_form_id 
+		JOIN forms ON forms.id = campaign_forms.form_id 
+		LEFT JOIN (
+			SELECT 
+				form_detail_entries.id, 
+				form_detail_entries.campaign_form_id, 
+				form_detail_entries.campaign_form_attribute_id, 
+				form_detail_entries.value 
+			FROM form_detail_entries 
+			JOIN form_entries ON form_entries.id = form_detail_entries.form_entry_id 
+			WHERE 
+				form_detail_entries.deleted = ? 
+				AND form_entries.deleted = ? 
+				AND form_entries.status <> ? 
+				AND form_entries.campaign_id = ? 
+				AND ` + localEntryDate + `::DATE BETWEEN ? AND ?
+		) answers ON answers.campaign_form_id = campaign_form_attributes.campaign_form_id AND (
+			answers.campaign_form_attribute_id = campaign_form_attributes.id 
+			OR (answers.campaign_form_attribute_id IS NULL AND answers.value = campaign_form_attributes.value)
+		) 
+		WHERE 
+			campaign_form_attributes.deleted = ? 
+			AND campaign_forms.deleted = ? 
+			AND campaign_forms.campaign_id = ? 
+			AND forms.code IN (?, ?, ?) 
+		GROUP BY campaign_form_attributes.campaign_form_id, campaign_form_attributes.id, campaign_form_attributes.label, campaign_form_attributes.value, campaign_form_attributes.created_at 
+		ORDER BY campaign_form_attributes.created_at ASC
+	`
+	args := []any{false, false, "S4", campaignID, params.Timezone, params.StartDate, params.EndDate, false, false, campaignID, "SELC_OPTION", "SELC_RADIO", "CHCK_BOX"}
+
+	if err := q.DB.WithContext(ctx).Raw(query, args...).Scan(&data).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (q *CampaignQuery) FindNumericStatByField(ctx context.Context, campaignID string, params *masterschema.AnalyticsParams) ([]masterschema.FieldNumericStat, error) {
+	var data []masterschema.FieldNumericStat
+
+	// skip value that can not be casted into number
+	// regex avoids `?` quantifier because gorm reads it as placeholder
+	query := `
+		SELECT 
+			answers.campaign_form_id, 
+			COUNT(1) AS total, 
+			MIN(answers.number) AS min, 
+			MAX(answers.number) AS max, 
+			AVG(answers.number) AS avg, 
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY answers.number) AS median 
+		FROM (
+			SELECT 
+				form_detail_entries.campaign_form_id, 
+				TRIM(form_detail_entries.value)::NUMERIC AS number 
+			FROM form_detail_entries 
+			JOIN form_entries ON form_entries.id = form_detail_entries.form_entry_id 
+			JOIN campaign_forms ON campaign_forms.id = form_detail_entries.campaign_form_id 
+			JOIN forms ON forms.id = campaign_forms.form_id 
+			WHERE 
+				form_detail_entries.deleted = ? 
+				AND form_entries.deleted = ? 
+				AND form_entries.status <> ? 
+				AND form_entries.campaign_id = ? 
+				AND forms.code = ? 
+				AND TRIM(form_detail_entries.value) ~ '^-{0,1}[0-9]+(\.[0-9]+){0,1}$' 
+				AND ` + localEntryDate + `::DATE BETWEEN ? AND ?
+		) answers 
+		GROUP BY answers.campaign_form_id
+	`
+	args := []any{false, false, "S4", campaignID, "INPT_NUMBER", params.Timezone, params.StartDate, params.EndDate}
+
+	if err := q.DB.WithContext(ctx).Raw(query, args...).Scan(&data).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (q *CampaignQuery) FindFormEntries(ctx context.Context, workspaceID string, campaignID string, params *commonschema.QueryParams, filter *masterschema.FormEntryFilter) ([]masterschema.FormEntryList, error) {
 	var formEntries []masterschema.FormEntryList
 
 	// define offset
@@ -366,6 +599,13 @@ func (q *CampaignQuery) FindFormEntries(workspaceID string, campaignID string, p
 		offset = params.Limit * (params.Page - 1)
 	}
 
+	// snippet only needed when searching
+	snippet, args := "''", []any{}
+	if params.Search != "" {
+		snippet = answerSnippet
+		args = append(args, params.Search, params.Search)
+	}
+
 	// define query
 	query := `
 		SELECT 
@@ -374,13 +614,15 @@ func (q *CampaignQuery) FindFormEntries(workspaceID string, campaignID string, p
 			form_entries.campaign_id, 
 			form_entries.status, 
 			form_entries.remark, 
+			form_entries.spam_reason, 
 			form_entries.created_at::TEXT AS created_at, 
 			campaigns.title AS campaign_title, 
 			campaigns.key AS campaign_key, 
 			campaigns.slug AS campaign_slug, 
 			campaigns.workspace_id, 
 			users.fullname AS user_name, 
-			users.email as user_email 
+			users.email as user_email, 
+			` + snippet + ` AS snippet 
 		FROM form_entries
 		JOIN campaigns ON campaigns.id = form_entries.campaign_id 
 		JOIN workspaces ON workspaces.id = campaigns.workspace_id
@@ -392,26 +634,46 @@ func (q *CampaignQuery) FindFormEntries(workspaceID string, campaignID string, p
 			AND campaigns.workspace_id = ? 
 			AND campaigns.id = ?
 	`
-	args := []any{false, false, false, workspaceID, campaignID}
+	args = append(args, false, false, false, workspaceID, campaignID)
 
-	// add search condition
+	// add search condition, keywords also looked up in answers
 	if params.Search != "" {
-		query += " AND LOWER(users.fullname) LIKE ? "
-		args = append(args, "%"+strings.ToLower(params.Search)+"%")
+		query += " AND (LOWER(users.fullname) LIKE ? OR " + answerSearch + ") "
+		args = append(args, "%"+strings.ToLower(params.Search)+"%", params.Search)
 	}
 
-	// add limit:offset
-	query += " ORDER BY form_entries.created_at DESC LIMIT ? OFFSET ? "
-	args = append(args, params.Limit, offset)
+	// add filter conditions
+	if conditions, filterArgs := formEntryConditions(filter); len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+		args = append(args, filterArgs...)
+	}
+
+	if condition, filterArgs := utils.FilterClause(params); condition != "" {
+		query += " AND " + condition
+		args = append(args, filterArgs...)
+	}
+
+	// add limit:offset, cursor pagination uses keyset with one extra row to detect next page
+	if condition, cursorArgs, order := utils.CursorClause(params, "form_entries.created_at", "form_entries.id"); order != "" {
+		if condition != "" {
+			query += " AND " + condition
+			args = append(args, cursorArgs...)
+		}
+		query += " ORDER BY " + order + " LIMIT ? "
+		args = append(args, params.Limit+1)
+	} else {
+		query += " ORDER BY " + utils.OrderClause(params, "form_entries.created_at DESC", "form_entries.id") + " LIMIT ? OFFSET ? "
+		args = append(args, params.Limit, offset)
+	}
 
 	// perform to get the data
-	if err := q.DB.Raw(query, args...).Scan(&formEntries).Error; err != nil {
+	if err := q.DB.WithContext(ctx).Raw(query, args...).Scan(&formEntries).Error; err != nil {
 		return nil, err
 	}
 	return formEntries, nil
 }
 
-func (q *CampaignQuery) FindCountFormEntries(workspaceID string, campaignID string, params *commonschema.QueryParams) (int64, error) {
+func (q *CampaignQuery) FindCountFormEntries(ctx context.Context, workspaceID string, campaignID string, params *commonschema.QueryParams, filter *masterschema.FormEntryFilter) (int64, error) {
 	var count int64
 
 	// define query
@@ -431,20 +693,31 @@ func (q *CampaignQuery) FindCountFormEntries(workspaceID string, campaignID stri
 	`
 	args := []any{false, false, false, workspaceID, campaignID}
 
-	// add search condition
+	// add search condition, keywords also looked up in answers
 	if params.Search != "" {
-		query += " AND LOWER(users.fullname) LIKE ? "
-		args = append(args, "%"+strings.ToLower(params.Search)+"%")
+		query += " AND (LOWER(users.fullname) LIKE ? OR " + answerSearch + ") "
+		args = append(args, "%"+strings.ToLower(params.Search)+"%", params.Search)
+	}
+
+	// add filter conditions
+	if conditions, filterArgs := formEntryConditions(filter); len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+		args = append(args, filterArgs...)
+	}
+
+	if condition, filterArgs := utils.FilterClause(params); condition != "" {
+		query += " AND " + condition
+		args = append(args, filterArgs...)
 	}
 
 	// perform to get data
-	if err := q.DB.Raw(query, args...).Scan(&count).Error; err != nil {
+	if err := q.DB.WithContext(ctx).Raw(query, args...).Scan(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
-func (q *CampaignQuery) CheckAllowedUserForCampaign(workspaceID string, campaignID string, userID string) (*masterschema.CampaignSchema, error) {
+func (q *CampaignQuery) CheckAllowedUserForCampaign(ctx context.Context, workspaceID string, campaignID string, userID string) (*masterschema.CampaignSchema, error) {
 	var campaign *masterschema.CampaignSchema
 
 	query := `
@@ -471,16 +744,16 @@ func (q *CampaignQuery) CheckAllowedUserForCampaign(workspaceID string, campaign
 	args := []any{false, false, workspaceID, campaignID, userID, false}
 
 	// perform to query
-	if err := q.DB.Raw(query, args...).Scan(&campaign).Error; err != nil {
+	if err := q.DB.WithContext(ctx).Raw(query, args...).Scan(&campaign).Error; err != nil {
 		return nil, err
 	}
 	return campaign, nil
 }
 
-func (q *CampaignQuery) FindFormEntry(ID string) (*masterschema.FormEntrySchema, error) {
+func (q *CampaignQuery) FindFormEntry(ctx context.Context, ID string) (*masterschema.FormEntrySchema, error) {
 	var formEntry masterschema.FormEntrySchema
 
-	st := q.DB.Model(&models.FormEntries{}).
+	st := q.DB.WithContext(ctx).Model(&models.FormEntries{}).
 		Where("form_entries.deleted = ? AND form_entries.id = ?", false, ID).
 		Select("form_entries.*", "campaigns.title AS campaign_title", "campaigns.description AS campaign_description", "users.fullname AS user_name", "users.email AS user_email").
 		Joins("LEFT JOIN users ON users.id = form_entries.user_id").
@@ -493,10 +766,10 @@ func (q *CampaignQuery) FindFormEntry(ID string) (*masterschema.FormEntrySchema,
 	return &formEntry, nil
 }
 
-func (q *CampaignQuery) FindDetailFormEntry(formEntryID string) ([]masterschema.FormDetailEntrySchema, error) {
+func (q *CampaignQuery) FindDetailFormEntry(ctx context.Context, formEntryID string) ([]masterschema.FormDetailEntrySchema, error) {
 	var formDetailEntries []masterschema.FormDetailEntrySchema
 
-	st := q.DB.Model(&models.FormDetailEntries{}).
+	st := q.DB.WithContext(ctx).Model(&models.FormDetailEntries{}).
 		Where("form_detail_entries.deleted = ? AND form_detail_entries.form_entry_id = ?", false, formEntryID).
 		Select("form_detail_entries.*", "forms.name AS form_name", "forms.code AS form_code", "campaign_forms.title AS campaign_form_title", "campaign_forms.description AS campaign_form_description").
 		Joins("JOIN campaign_forms ON campaign_forms.id = form_detail_entries.campaign_form_id").
@@ -508,3 +781,79 @@ func (q *CampaignQuery) FindDetailFormEntry(formEntryID string) ([]masterschema.
 
 	return formDetailEntries, nil
 }
+
+// FindDetailFormEntries loads answers of many entries in one query, grouped by entry id
+func (q *CampaignQuery) FindDetailFormEntries(ctx context.Context, formEntryIDs []string) (map[string][]masterschema.FormDetailEntrySchema, error) {
+	var rows []struct {
+		FormEntryID string
+		masterschema.FormDetailEntrySchema
+	}
+	details := map[string][]masterschema.FormDetailEntrySchema{}
+	if len(formEntryIDs) == 0 {
+		return details, nil
+	}
+
+	st := q.DB.WithContext(ctx).Model(&models.FormDetailEntries{}).
+		Where("form_detail_entries.deleted = ? AND form_detail_entries.form_entry_id IN ?", false, formEntryIDs).
+		Select("form_detail_entries.*", "forms.name AS form_name", "forms.code AS form_code", "campaign_forms.title AS campaign_form_title", "campaign_forms.description AS campaign_form_description").
+		Joins("JOIN campaign_forms ON campaign_forms.id = form_detail_entries.campaign_form_id").
+		Joins("JOIN forms ON forms.id = campaign_forms.form_id").
+		Order("form_detail_entries.created_at ASC")
+
+	if err := st.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, v := range rows {
+		details[v.FormEntryID] = append(details[v.FormEntryID], v.FormDetailEntrySchema)
+	}
+	return details, nil
+}
+
+func (q *CampaignQuery) UpdateFormEntry(ctx context.Context, campaignID string, ID string, data models.FormEntries) error {
+	if err := q.DB.WithContext(ctx).Model(&models.FormEntries{}).Where("deleted = ? AND campaign_id = ? AND id = ?", false, campaignID, ID).Updates(&data).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (q *CampaignQuery) IncrementCampaignVisit(ctx context.Context, campaignID string) error {
+	// one row per campaign per day, date follows workspace timezone
+	query := `
+		INSERT INTO campaign_visits (id, campaign_id, visit_date, total, created_at)
+		SELECT 
+			?, 
+			campaigns.id, 
+			(NOW() AT TIME ZONE COALESCE(NULLIF(workspaces.timezone, ''), 'UTC'))::DATE, 
+			1, 
+			NOW() AT TIME ZONE 'UTC'
+		FROM campaigns 
+		JOIN workspaces ON workspaces.id = campaigns.workspace_id 
+		WHERE campaigns.id = ?
+		ON CONFLICT (campaign_id, visit_date) 
+		DO UPDATE SET total = campaign_visits.total + 1, updated_at = NOW() AT TIME ZONE 'UTC'
+	`
+	if err := q.DB.WithContext(ctx).Exec(query, uuid.New(), campaignID).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (q *CampaignQuery) FindSpamSetting(ctx context.Context, campaignID string) (*models.CampaignSpamSettings, error) {
+	var setting models.CampaignSpamSettings
+	if err := q.DB.WithContext(ctx).Model(&models.CampaignSpamSettings{}).Where("campaign_id = ?", campaignID).First(&setting).Error; err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+func (q *CampaignQuery) SaveSpamSetting(ctx context.Context, setting models.CampaignSpamSettings) error {
+	// one setting per campaign, replace the existing one
+	err := q.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "campaign_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"honeypot", "min_submit_seconds", "captcha", "duplicate_check", "updated_at"}),
+	}).Create(&setting).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
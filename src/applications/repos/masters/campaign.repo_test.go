@@ -0,0 +1,59 @@
+package masterrepo
+
+import (
+	"os"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// TestLocalEntryDate runs against real postgres, set TEST_DATABASE_URL to a database it may use,
+// table is created in temporary schema of the transaction and rolled back
+func TestLocalEntryDate(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	DB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	tx := DB.Begin()
+	t.Cleanup(func() {
+		tx.Rollback()
+	})
+	if err := tx.Exec("CREATE TEMPORARY TABLE form_entries (created_at timestamp) ON COMMIT DROP").Error; err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+
+	// created_at is stored in utc without zone
+	cases := []struct {
+		createdAt string
+		timezone  string
+		date      string
+	}{
+		{"2026-03-01 17:30:00", "UTC", "2026-03-01"},
+		{"2026-03-01 17:30:00", "Asia/Jakarta", "2026-03-02"},
+		{"2026-03-01 07:30:00", "America/Los_Angeles", "2026-02-28"},
+		{"2026-03-09 04:30:00", "America/New_York", "2026-03-09"},
+		{"2026-03-08 04:30:00", "America/New_York", "2026-03-07"},
+		{"2026-10-25 22:30:00", "Europe/Berlin", "2026-10-25"},
+		{"2026-10-24 22:30:00", "Europe/Berlin", "2026-10-25"},
+	}
+	for _, tc := range cases {
+		if err := tx.Exec("TRUNCATE form_entries").Error; err != nil {
+			t.Fatalf("truncate: %v", err)
+		}
+		if err := tx.Exec("INSERT INTO form_entries (created_at) VALUES (?)", tc.createdAt).Error; err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+		var date string
+		if err := tx.Raw("SELECT TO_CHAR("+localEntryDate+"::DATE, 'YYYY-MM-DD') FROM form_entries", tc.timezone).Scan(&date).Error; err != nil {
+			t.Fatalf("select: %v", err)
+		}
+		if date != tc.date {
+			t.Fatalf("%s in %s got %s, want %s", tc.createdAt, tc.timezone, date, tc.date)
+		}
+	}
+}
@@ -1,18 +1,20 @@
 package masterrepo
 
 import (
+	"context"
 	"kiraform/src/applications/models"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
 	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"kiraform/src/utils"
 	"strings"
 
 	"gorm.io/gorm"
 )
 
 type FormRepository interface {
-	FindForms(params *commonschema.QueryParams) ([]masterschema.FormSchema, error)
-	FindCountForm(params *commonschema.QueryParams) (int64, error)
-	FindFormByID(ID string) (*masterschema.FormSchema, error)
+	FindForms(ctx context.Context, params *commonschema.QueryParams) ([]masterschema.FormSchema, error)
+	FindCountForm(ctx context.Context, params *commonschema.QueryParams) (int64, error)
+	FindFormByID(ctx context.Context, ID string) (*masterschema.FormSchema, error)
 }
 
 type FormQuery struct {
@@ -23,7 +25,7 @@ func NewFormRepository(DB *gorm.DB) *FormQuery {
 	return &FormQuery{DB: DB}
 }
 
-func (q *FormQuery) FindForms(params *commonschema.QueryParams) ([]masterschema.FormSchema, error) {
+func (q *FormQuery) FindForms(ctx context.Context, params *commonschema.QueryParams) ([]masterschema.FormSchema, error) {
 	var forms []masterschema.FormSchema
 
 	// calculating offset
@@ -33,18 +35,21 @@ func (q *FormQuery) FindForms(params *commonschema.QueryParams) ([]masterschema.
 	}
 
 	// define statments
-	st := q.DB.Model(&models.Forms{}).Where("deleted = ?", false)
+	st := q.DB.WithContext(ctx).Model(&models.Forms{}).Where("deleted = ?", false)
 
 	// add search condition
 	if params.Search != "" {
 		st = st.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
 	}
 
-	// add orderby
-	if params.OrderBy != "" {
-		st = st.Order(params.OrderBy)
+	// add filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
 	}
 
+	// add orderby
+	st = st.Order(utils.OrderClause(params, "", "forms.id"))
+
 	// add limit:offset
 	st = st.Limit(params.Limit).Offset(offset)
 
@@ -57,15 +62,20 @@ func (q *FormQuery) FindForms(params *commonschema.QueryParams) ([]masterschema.
 	return forms, nil
 }
 
-func (q *FormQuery) FindCountForm(params *commonschema.QueryParams) (int64, error) {
+func (q *FormQuery) FindCountForm(ctx context.Context, params *commonschema.QueryParams) (int64, error) {
 	var count int64
 
 	// preparing conditions
-	st := q.DB.Model(&models.Forms{}).Where("deleted = ?", false)
+	st := q.DB.WithContext(ctx).Model(&models.Forms{}).Where("deleted = ?", false)
 	if params.Search != "" {
 		st = st.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
 	}
 
+	// add filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
+	}
+
 	// perform to get data
 	if err := st.Count(&count).Error; err != nil {
 		return 0, err
@@ -75,11 +85,11 @@ func (q *FormQuery) FindCountForm(params *commonschema.QueryParams) (int64, erro
 	return count, nil
 }
 
-func (q *FormQuery) FindFormByID(ID string) (*masterschema.FormSchema, error) {
+func (q *FormQuery) FindFormByID(ctx context.Context, ID string) (*masterschema.FormSchema, error) {
 	var form masterschema.FormSchema
 
 	// preparing query
-	err := q.DB.Model(&models.Forms{}).Where("deleted = ? AND id = ?", false, ID).First(&form).Error
+	err := q.DB.WithContext(ctx).Model(&models.Forms{}).Where("deleted = ? AND id = ?", false, ID).First(&form).Error
 	if err != nil {
 		return nil, err
 	}
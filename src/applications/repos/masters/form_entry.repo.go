@@ -1,20 +1,24 @@
 package masterrepo
 
 import (
+	"context"
 	"kiraform/src/applications/models"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
 	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"kiraform/src/utils"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type FormEntryRepository interface {
-	EntryForm(formEntry map[string]any, formDetailEntries []models.FormDetailEntries) error
-	FindFormEntries(userID string, params *commonschema.QueryParams) ([]masterschema.FormEntrySchema, error)
-	FindCountFormEntry(userID string, params *commonschema.QueryParams) (int64, error)
-	FindFormEntry(userID string, ID string) (*masterschema.FormEntrySchema, error)
-	FindDetailFormEntry(formEntryID string) ([]masterschema.FormDetailEntrySchema, error)
+	EntryForm(ctx context.Context, formEntry map[string]any, formDetailEntries []models.FormDetailEntries) error
+	FindFormEntries(ctx context.Context, userID string, params *commonschema.QueryParams, filter *masterschema.FormEntryFilter) ([]masterschema.FormEntrySchema, error)
+	FindCountFormEntry(ctx context.Context, userID string, params *commonschema.QueryParams, filter *masterschema.FormEntryFilter) (int64, error)
+	FindFormEntry(ctx context.Context, userID string, ID string) (*masterschema.FormEntrySchema, error)
+	FindDetailFormEntry(ctx context.Context, formEntryID string) ([]masterschema.FormDetailEntrySchema, error)
+	ExistsEntryContent(ctx context.Context, campaignID string, contentHash string, since time.Time) (bool, error)
 }
 
 type FormEntryQuery struct {
@@ -25,8 +29,78 @@ func NewFormEntryRepository(DB *gorm.DB) *FormEntryQuery {
 	return &FormEntryQuery{DB: DB}
 }
 
-func (q *FormEntryQuery) EntryForm(formEntry map[string]any, formDetailEntries []models.FormDetailEntries) error {
-	err := q.DB.Transaction(func(tx *gorm.DB) error {
+// answerVector must stay the same as expression of GIN index idx_form_detail_entries_value_fts
+const answerVector = "to_tsvector('simple', COALESCE(fde.value, ''))"
+
+// answerSearch matches entries that have at least one answer for the search keywords
+const answerSearch = `EXISTS (
+	SELECT 1 FROM form_detail_entries fde 
+	WHERE fde.form_entry_id = form_entries.id AND fde.deleted = false 
+		AND ` + answerVector + ` @@ websearch_to_tsquery('simple', ?)
+)`
+
+// answerSnippet returns matched answers with highlighted keywords
+const answerSnippet = `COALESCE((
+	SELECT STRING_AGG(ts_headline('simple', fde.value, websearch_to_tsquery('simple', ?), 'StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=5'), ' ... ') 
+	FROM form_detail_entries fde 
+	WHERE fde.form_entry_id = form_entries.id AND fde.deleted = false 
+		AND ` + answerVector + ` @@ websearch_to_tsquery('simple', ?)
+), '')`
+
+// formEntryConditions builds where conditions of status, date and answer filters
+func formEntryConditions(filter *masterschema.FormEntryFilter) ([]string, []any) {
+	var conditions []string
+	var args []any
+	if filter == nil {
+		return conditions, args
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "form_entries.status = ?")
+		args = append(args, filter.Status)
+	}
+
+	if filter.StartDate != "" {
+		conditions = append(conditions, localEntryDate+"::DATE >= ?")
+		args = append(args, filter.Timezone, filter.StartDate)
+	}
+
+	if filter.EndDate != "" {
+		conditions = append(conditions, localEntryDate+"::DATE <= ?")
+		args = append(args, filter.Timezone, filter.EndDate)
+	}
+
+	// each field filter must be matched by one of the entry answers
+	for _, v := range filter.Fields {
+		var match string
+		switch v.Operator {
+		case "contains":
+			match = "fde.value ILIKE ?"
+			args = append(args, v.CampaignFormID, "%"+v.Value+"%")
+		case "gt", "gte", "lt", "lte":
+			operators := map[string]string{"gt": ">", "gte": ">=", "lt": "<", "lte": "<="}
+			// regex avoids `?` quantifier because gorm reads it as placeholder
+			match = `CASE WHEN TRIM(fde.value) ~ '^-{0,1}[0-9]+(\.[0-9]+){0,1}$' THEN TRIM(fde.value)::NUMERIC END ` + operators[v.Operator] + ` ?`
+			args = append(args, v.CampaignFormID, v.Value)
+		default:
+			// option answers can be matched by its label or value
+			match = "(LOWER(TRIM(fde.value)) = LOWER(?) OR LOWER(campaign_form_attributes.value) = LOWER(?) OR LOWER(campaign_form_attributes.label) = LOWER(?))"
+			args = append(args, v.CampaignFormID, v.Value, v.Value, v.Value)
+		}
+
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM form_detail_entries fde 
+			LEFT JOIN campaign_form_attributes ON campaign_form_attributes.id = fde.campaign_form_attribute_id 
+			WHERE fde.form_entry_id = form_entries.id AND fde.deleted = false 
+				AND fde.campaign_form_id = ? AND `+match+`
+		)`)
+	}
+
+	return conditions, args
+}
+
+func (q *FormEntryQuery) EntryForm(ctx context.Context, formEntry map[string]any, formDetailEntries []models.FormDetailEntries) error {
+	err := q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// insert form entry header
 		if err := tx.Model(&models.FormEntries{}).Create(&formEntry).Error; err != nil {
 			return err
@@ -44,7 +118,7 @@ func (q *FormEntryQuery) EntryForm(formEntry map[string]any, formDetailEntries [
 	return nil
 }
 
-func (q *FormEntryQuery) FindFormEntries(userID string, params *commonschema.QueryParams) ([]masterschema.FormEntrySchema, error) {
+func (q *FormEntryQuery) FindFormEntries(ctx context.Context, userID string, params *commonschema.QueryParams, filter *masterschema.FormEntryFilter) ([]masterschema.FormEntrySchema, error) {
 	var formEntries []masterschema.FormEntrySchema
 
 	// calculating offset
@@ -54,25 +128,38 @@ func (q *FormEntryQuery) FindFormEntries(userID string, params *commonschema.Que
 	}
 
 	// define statments
-	st := q.DB.Model(&models.FormEntries{}).Where("form_entries.deleted = ? AND form_entries.user_id = ?", false, userID).
-		Select("form_entries.*", "users.fullname AS user_name", "users.email AS user_email", "campaigns.title AS campaign_title", "campaigns.description AS campaign_description").
+	columns := "form_entries.*, users.fullname AS user_name, users.email AS user_email, campaigns.title AS campaign_title, campaigns.description AS campaign_description"
+	st := q.DB.WithContext(ctx).Model(&models.FormEntries{}).Where("form_entries.deleted = ? AND form_entries.user_id = ?", false, userID).
 		Joins("JOIN users ON users.id = form_entries.user_id").
 		Joins("JOIN campaigns ON campaigns.ID = form_entries.campaign_id")
 
-	// add search condition
+	// add search condition, keywords also looked up in answers
 	if params.Search != "" {
-		st = st.Where("LOWER(campaigns.title) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
+		st = st.Select(columns+", "+answerSnippet+" AS snippet", params.Search, params.Search).
+			Where("(LOWER(campaigns.title) LIKE ? OR "+answerSearch+")", "%"+strings.ToLower(params.Search)+"%", params.Search)
+	} else {
+		st = st.Select(columns)
 	}
 
-	// add orderby
-	if params.OrderBy != "" {
-		st = st.Order(params.OrderBy)
-	} else {
-		st = st.Order("form_entries.created_at DESC")
+	// add filter conditions
+	if conditions, args := formEntryConditions(filter); len(conditions) > 0 {
+		st = st.Where(strings.Join(conditions, " AND "), args...)
 	}
 
-	// add limit:offset
-	st = st.Limit(params.Limit).Offset(offset)
+	// add filter condition from query spec
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
+	}
+
+	// add orderby and limit:offset, cursor pagination uses keyset with one extra row to detect next page
+	if condition, args, order := utils.CursorClause(params, "form_entries.created_at", "form_entries.id"); order != "" {
+		if condition != "" {
+			st = st.Where(condition, args...)
+		}
+		st = st.Order(order).Limit(params.Limit + 1)
+	} else {
+		st = st.Order(utils.OrderClause(params, "form_entries.created_at DESC", "form_entries.id")).Limit(params.Limit).Offset(offset)
+	}
 
 	// perform to get the data
 	if err := st.Find(&formEntries).Error; err != nil {
@@ -83,15 +170,23 @@ func (q *FormEntryQuery) FindFormEntries(userID string, params *commonschema.Que
 	return formEntries, nil
 }
 
-func (q *FormEntryQuery) FindCountFormEntry(userID string, params *commonschema.QueryParams) (int64, error) {
+func (q *FormEntryQuery) FindCountFormEntry(ctx context.Context, userID string, params *commonschema.QueryParams, filter *masterschema.FormEntryFilter) (int64, error) {
 	var count int64
 
 	// preparing conditions
-	st := q.DB.Model(&models.FormEntries{}).Where("form_entries.deleted = ? AND form_entries.user_id = ?", false, userID).
+	st := q.DB.WithContext(ctx).Model(&models.FormEntries{}).Where("form_entries.deleted = ? AND form_entries.user_id = ?", false, userID).
 		Joins("JOIN campaigns ON campaigns.id = form_entries.campaign_id")
 
 	if params.Search != "" {
-		st = st.Where("LOWER(campaigns.title) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
+		st = st.Where("(LOWER(campaigns.title) LIKE ? OR "+answerSearch+")", "%"+strings.ToLower(params.Search)+"%", params.Search)
+	}
+
+	if conditions, args := formEntryConditions(filter); len(conditions) > 0 {
+		st = st.Where(strings.Join(conditions, " AND "), args...)
+	}
+
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
 	}
 
 	// perform to get data
@@ -103,10 +198,10 @@ func (q *FormEntryQuery) FindCountFormEntry(userID string, params *commonschema.
 	return count, nil
 }
 
-func (q *FormEntryQuery) FindFormEntry(userID string, ID string) (*masterschema.FormEntrySchema, error) {
+func (q *FormEntryQuery) FindFormEntry(ctx context.Context, userID string, ID string) (*masterschema.FormEntrySchema, error) {
 	var formEntry masterschema.FormEntrySchema
 
-	st := q.DB.Model(&models.FormEntries{}).
+	st := q.DB.WithContext(ctx).Model(&models.FormEntries{}).
 		Where("form_entries.deleted = ? AND form_entries.user_id = ? AND form_entries.id = ?", false, userID, ID).
 		Select("form_entries.*", "campaigns.title AS campaign_title", "campaigns.description AS campaign_description", "users.fullname AS user_name", "users.email AS user_email").
 		Joins("JOIN users ON users.id = form_entries.user_id").
@@ -119,10 +214,10 @@ func (q *FormEntryQuery) FindFormEntry(userID string, ID string) (*masterschema.
 	return &formEntry, nil
 }
 
-func (q *FormEntryQuery) FindDetailFormEntry(formEntryID string) ([]masterschema.FormDetailEntrySchema, error) {
+func (q *FormEntryQuery) FindDetailFormEntry(ctx context.Context, formEntryID string) ([]masterschema.FormDetailEntrySchema, error) {
 	var formDetailEntries []masterschema.FormDetailEntrySchema
 
-	st := q.DB.Model(&models.FormDetailEntries{}).
+	st := q.DB.WithContext(ctx).Model(&models.FormDetailEntries{}).
 		Where("form_detail_entries.deleted = ? AND form_detail_entries.form_entry_id = ?", false, formEntryID).
 		Select("form_detail_entries.*", "forms.name AS form_name", "forms.code AS form_code", "campaign_forms.title AS campaign_form_title", "campaign_forms.description AS campaign_form_description").
 		Joins("JOIN campaign_forms ON campaign_forms.id = form_detail_entries.campaign_form_id").
@@ -134,3 +229,12 @@ func (q *FormEntryQuery) FindDetailFormEntry(formEntryID string) ([]masterschema
 
 	return formDetailEntries, nil
 }
+
+func (q *FormEntryQuery) ExistsEntryContent(ctx context.Context, campaignID string, contentHash string, since time.Time) (bool, error) {
+	var count int64
+	st := q.DB.WithContext(ctx).Model(&models.FormEntries{}).Where("deleted = ? AND campaign_id = ? AND content_hash = ? AND created_at >= ?", false, campaignID, contentHash, since)
+	if err := st.Limit(1).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
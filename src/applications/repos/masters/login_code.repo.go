@@ -0,0 +1,65 @@
+package masterrepo
+
+import (
+	"context"
+	"kiraform/src/applications/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LoginCodeRepository interface {
+	FindLoginCode(ctx context.Context, ID string) (*models.LoginCodes, error)
+	FindActiveLoginCode(ctx context.Context, email string, now time.Time) (*models.LoginCodes, error)
+	CreateLoginCode(ctx context.Context, data models.LoginCodes) error
+	UseLoginCode(ctx context.Context, ID string, usedAt time.Time) (bool, error)
+}
+
+type LoginCodeQuery struct {
+	DB *gorm.DB
+}
+
+func NewLoginCodeRepository(DB *gorm.DB) LoginCodeRepository {
+	return &LoginCodeQuery{DB: DB}
+}
+
+func (q *LoginCodeQuery) FindLoginCode(ctx context.Context, ID string) (*models.LoginCodes, error) {
+	var data models.LoginCodes
+	if err := q.DB.WithContext(ctx).Where("id = ?", ID).First(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// FindActiveLoginCode returns unused code of email which is not expired yet
+func (q *LoginCodeQuery) FindActiveLoginCode(ctx context.Context, email string, now time.Time) (*models.LoginCodes, error) {
+	var data models.LoginCodes
+	if err := q.DB.WithContext(ctx).
+		Where("email = ? AND used_at IS NULL AND expires_at > ?", email, now).
+		Order("created_at DESC").
+		First(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// CreateLoginCode replaces codes of email, only the latest requested one works
+func (q *LoginCodeQuery) CreateLoginCode(ctx context.Context, data models.LoginCodes) error {
+	return q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("email = ? AND used_at IS NULL", data.Email).Delete(&models.LoginCodes{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&data).Error
+	})
+}
+
+// UseLoginCode marks code as used, returns false when it was already used or expired
+func (q *LoginCodeQuery) UseLoginCode(ctx context.Context, ID string, usedAt time.Time) (bool, error) {
+	result := q.DB.WithContext(ctx).Model(&models.LoginCodes{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", ID, usedAt).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,109 @@
+package masterrepo
+
+import (
+	"context"
+	"kiraform/src/applications/models"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	meschema "kiraform/src/interfaces/rest/schemas/me"
+	"kiraform/src/utils"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type LoginHistoryRepository interface {
+	CreateLoginHistory(ctx context.Context, data models.LoginHistories) error
+	FindKnownDevice(ctx context.Context, userID string, deviceHash string) (bool, bool, error)
+	FindLoginHistories(ctx context.Context, userID string, params *commonschema.QueryParams) ([]meschema.LoginHistorySchema, error)
+	FindCountLoginHistory(ctx context.Context, userID string, params *commonschema.QueryParams) (int64, error)
+}
+
+type LoginHistoryQuery struct {
+	DB *gorm.DB
+}
+
+func NewLoginHistoryRepository(DB *gorm.DB) LoginHistoryRepository {
+	return &LoginHistoryQuery{DB: DB}
+}
+
+func (q *LoginHistoryQuery) CreateLoginHistory(ctx context.Context, data models.LoginHistories) error {
+	return q.DB.WithContext(ctx).Create(&data).Error
+}
+
+// FindKnownDevice tells user has logged in before, and has logged in before from device
+func (q *LoginHistoryQuery) FindKnownDevice(ctx context.Context, userID string, deviceHash string) (bool, bool, error) {
+	var result struct {
+		AnyLogin    bool
+		KnownDevice bool
+	}
+	if err := q.DB.WithContext(ctx).Model(&models.LoginHistories{}).
+		Where("user_id = ? AND success = ?", userID, true).
+		Select("COUNT(*) > 0 AS any_login, COALESCE(BOOL_OR(device_hash = ?), false) AS known_device", deviceHash).
+		Scan(&result).Error; err != nil {
+		return false, false, err
+	}
+	return result.AnyLogin, result.KnownDevice, nil
+}
+
+func (q *LoginHistoryQuery) loginHistoryStatement(ctx context.Context, userID string, params *commonschema.QueryParams) *gorm.DB {
+	st := q.DB.WithContext(ctx).Model(&models.LoginHistories{}).Where("login_histories.user_id = ?", userID)
+
+	// add search condition
+	if params.Search != "" {
+		keyword := "%" + strings.ToLower(params.Search) + "%"
+		st = st.Where("(login_histories.ip LIKE ? OR LOWER(login_histories.user_agent) LIKE ?)", keyword, keyword)
+	}
+
+	// add filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
+	}
+	return st
+}
+
+func (q *LoginHistoryQuery) FindLoginHistories(ctx context.Context, userID string, params *commonschema.QueryParams) ([]meschema.LoginHistorySchema, error) {
+	var data []meschema.LoginHistorySchema
+
+	// define offset
+	offset := 0
+	if params.Limit > 0 && params.Page > 0 {
+		offset = params.Limit * (params.Page - 1)
+	}
+
+	// define statements
+	st := q.loginHistoryStatement(ctx, userID, params).Select(`
+		login_histories.id,
+		login_histories.method,
+		login_histories.success,
+		login_histories.reason,
+		login_histories.ip,
+		login_histories.user_agent,
+		login_histories.new_device,
+		login_histories.session_id,
+		login_histories.created_at
+	`)
+
+	// add orderby and limit:offset, cursor pagination uses keyset with one extra row to detect next page
+	if condition, args, order := utils.CursorClause(params, "login_histories.created_at", "login_histories.id"); order != "" {
+		if condition != "" {
+			st = st.Where(condition, args...)
+		}
+		st = st.Order(order).Limit(params.Limit + 1)
+	} else {
+		st = st.Order(utils.OrderClause(params, "login_histories.created_at DESC", "login_histories.id")).Limit(params.Limit).Offset(offset)
+	}
+
+	// perform to get the data
+	if err := st.Scan(&data).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (q *LoginHistoryQuery) FindCountLoginHistory(ctx context.Context, userID string, params *commonschema.QueryParams) (int64, error) {
+	var count int64
+	if err := q.loginHistoryStatement(ctx, userID, params).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
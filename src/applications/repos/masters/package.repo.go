@@ -0,0 +1,44 @@
+package masterrepo
+
+import (
+	"context"
+	"kiraform/src/applications/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PackageRepository interface {
+	FindPackageByCode(ctx context.Context, code string) (*models.Packages, error)
+	GrantUserPackage(ctx context.Context, userPackage models.UserPackages) error
+}
+
+type PackageQuery struct {
+	DB *gorm.DB
+}
+
+func NewPackageRepository(DB *gorm.DB) PackageRepository {
+	return &PackageQuery{DB: DB}
+}
+
+func (q *PackageQuery) FindPackageByCode(ctx context.Context, code string) (*models.Packages, error) {
+	var data models.Packages
+	if err := q.DB.WithContext(ctx).Where("deleted = ? AND UPPER(code) = ?", false, strings.ToUpper(code)).First(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// GrantUserPackage deactivates current packages of the user, so only the granted one is active
+func (q *PackageQuery) GrantUserPackage(ctx context.Context, userPackage models.UserPackages) error {
+	return q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.UserPackages{}).
+			Where("deleted = ? AND is_active = ? AND user_id = ?", false, true, userPackage.UserID).
+			Updates(map[string]any{"is_active": false, "updated_at": time.Now()}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&userPackage).Error
+	})
+}
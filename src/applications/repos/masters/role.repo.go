@@ -1,14 +1,26 @@
 package masterrepo
 
 import (
+	"context"
 	"kiraform/src/applications/models"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"kiraform/src/utils"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type RoleRepository interface {
-	FindRoleByName(name string) (*models.Roles, error)
+	FindRoles(ctx context.Context, params *commonschema.QueryParams) ([]models.Roles, error)
+	FindCountRole(ctx context.Context, params *commonschema.QueryParams) (int64, error)
+	FindRoleByID(ctx context.Context, ID string) (*models.Roles, error)
+	FindRoleByName(ctx context.Context, name string) (*models.Roles, error)
+	FindRoleNameTaken(ctx context.Context, name string, exceptID string) (bool, error)
+	FindCountUserByRoles(ctx context.Context, roleIDs []string) (map[string]int64, error)
+	CreateRole(ctx context.Context, role models.Roles) error
+	UpdateRole(ctx context.Context, ID string, role models.Roles) error
+	DeleteRole(ctx context.Context, ID string) error
 }
 
 type RoleQuery struct {
@@ -19,10 +31,123 @@ func NewRoleRepository(DB *gorm.DB) RoleRepository {
 	return &RoleQuery{DB: DB}
 }
 
-func (q *RoleQuery) FindRoleByName(name string) (*models.Roles, error) {
+// roleStatement builds base query of roles which are not deleted
+func (q *RoleQuery) roleStatement(ctx context.Context, params *commonschema.QueryParams) *gorm.DB {
+	st := q.DB.WithContext(ctx).Model(&models.Roles{}).Where("roles.deleted = ?", false)
+
+	// add search condition
+	if params.Search != "" {
+		keyword := "%" + strings.ToLower(params.Search) + "%"
+		st = st.Where("(LOWER(roles.name) LIKE ? OR LOWER(roles.description) LIKE ?)", keyword, keyword)
+	}
+
+	// add filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
+	}
+	return st
+}
+
+func (q *RoleQuery) FindRoles(ctx context.Context, params *commonschema.QueryParams) ([]models.Roles, error) {
+	var roles []models.Roles
+
+	// define offset
+	offset := 0
+	if params.Limit > 0 && params.Page > 0 {
+		offset = params.Limit * (params.Page - 1)
+	}
+
+	// add orderby and limit:offset, cursor pagination uses keyset with one extra row to detect next page
+	st := q.roleStatement(ctx, params)
+	if condition, args, order := utils.CursorClause(params, "roles.created_at", "roles.id"); order != "" {
+		if condition != "" {
+			st = st.Where(condition, args...)
+		}
+		st = st.Order(order).Limit(params.Limit + 1)
+	} else {
+		st = st.Order(utils.OrderClause(params, "roles.created_at", "roles.id")).Limit(params.Limit).Offset(offset)
+	}
+
+	// perform to get the data
+	if err := st.Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (q *RoleQuery) FindCountRole(ctx context.Context, params *commonschema.QueryParams) (int64, error) {
+	var count int64
+	if err := q.roleStatement(ctx, params).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (q *RoleQuery) FindRoleByID(ctx context.Context, ID string) (*models.Roles, error) {
+	var role models.Roles
+	if err := q.DB.WithContext(ctx).Where("deleted = ? AND id = ?", false, ID).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (q *RoleQuery) FindRoleByName(ctx context.Context, name string) (*models.Roles, error) {
 	var role models.Roles
-	if err := q.DB.Where("deleted = ? AND LOWER(name) = ?", false, strings.ToLower(name)).First(&role).Error; err != nil {
+	if err := q.DB.WithContext(ctx).Where("deleted = ? AND LOWER(name) = ?", false, strings.ToLower(name)).First(&role).Error; err != nil {
 		return nil, err
 	}
 	return &role, nil
 }
+
+// FindRoleNameTaken checks name against every role, deleted one keeps its name since it is unique
+func (q *RoleQuery) FindRoleNameTaken(ctx context.Context, name string, exceptID string) (bool, error) {
+	var count int64
+	st := q.DB.WithContext(ctx).Model(&models.Roles{}).Where("LOWER(name) = ?", strings.ToLower(name))
+	if exceptID != "" {
+		st = st.Where("id <> ?", exceptID)
+	}
+	if err := st.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FindCountUserByRoles counts users holding many roles in one query
+func (q *RoleQuery) FindCountUserByRoles(ctx context.Context, roleIDs []string) (map[string]int64, error) {
+	var rows []commonschema.CountByID
+	if len(roleIDs) == 0 {
+		return map[string]int64{}, nil
+	}
+
+	err := q.DB.WithContext(ctx).Model(&models.UserRoles{}).
+		Select("role_id AS id", "COUNT(1) AS total").
+		Where("deleted = ? AND role_id IN ?", false, roleIDs).
+		Group("role_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return commonschema.CountMap(rows), nil
+}
+
+func (q *RoleQuery) CreateRole(ctx context.Context, role models.Roles) error {
+	return q.DB.WithContext(ctx).Create(&role).Error
+}
+
+// UpdateRole writes every editable field, so description and permissions can be emptied
+func (q *RoleQuery) UpdateRole(ctx context.Context, ID string, role models.Roles) error {
+	return q.DB.WithContext(ctx).Model(&models.Roles{}).
+		Where("deleted = ? AND id = ?", false, ID).
+		Updates(map[string]any{
+			"name":        role.Name,
+			"description": role.Description,
+			"permissions": role.Permissions,
+			"updated_at":  time.Now(),
+		}).Error
+}
+
+func (q *RoleQuery) DeleteRole(ctx context.Context, ID string) error {
+	return q.DB.WithContext(ctx).Model(&models.Roles{}).
+		Where("deleted = ? AND id = ?", false, ID).
+		Updates(map[string]any{"deleted": true, "updated_at": time.Now()}).Error
+}
@@ -0,0 +1,92 @@
+package masterrepo
+
+import (
+	"context"
+	"kiraform/src/applications/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, data models.UserSessions) error
+	FindActiveSession(ctx context.Context, ID string, now time.Time) (*models.UserSessions, error)
+	FindActiveSessions(ctx context.Context, userID string, now time.Time) ([]models.UserSessions, error)
+	RevokeSession(ctx context.Context, userID string, ID string, revokedAt time.Time) error
+	RevokeOtherSessions(ctx context.Context, userID string, exceptID string, revokedAt time.Time) (int64, error)
+	RevokeRoleSessions(ctx context.Context, roleID string, revokedAt time.Time) (int64, error)
+	TouchSession(ctx context.Context, ID string, seenAt time.Time) error
+}
+
+type SessionQuery struct {
+	DB *gorm.DB
+}
+
+func NewSessionRepository(DB *gorm.DB) SessionRepository {
+	return &SessionQuery{DB: DB}
+}
+
+func (q *SessionQuery) CreateSession(ctx context.Context, data models.UserSessions) error {
+	return q.DB.WithContext(ctx).Create(&data).Error
+}
+
+// FindActiveSession finds session which is not revoked or expired, its user is checked by caller
+func (q *SessionQuery) FindActiveSession(ctx context.Context, ID string, now time.Time) (*models.UserSessions, error) {
+	var data models.UserSessions
+	if err := q.DB.WithContext(ctx).
+		Where("id = ? AND revoked_at IS NULL AND expires_at > ?", ID, now).
+		First(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (q *SessionQuery) FindActiveSessions(ctx context.Context, userID string, now time.Time) ([]models.UserSessions, error) {
+	var data []models.UserSessions
+	if err := q.DB.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at DESC").
+		Find(&data).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (q *SessionQuery) RevokeSession(ctx context.Context, userID string, ID string, revokedAt time.Time) error {
+	result := q.DB.WithContext(ctx).Model(&models.UserSessions{}).
+		Where("user_id = ? AND id = ? AND revoked_at IS NULL", userID, ID).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RevokeOtherSessions revokes every session of user except exceptID, empty exceptID revokes all of them
+func (q *SessionQuery) RevokeOtherSessions(ctx context.Context, userID string, exceptID string, revokedAt time.Time) (int64, error) {
+	st := q.DB.WithContext(ctx).Model(&models.UserSessions{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if exceptID != "" {
+		st = st.Where("id <> ?", exceptID)
+	}
+	result := st.Update("revoked_at", revokedAt)
+	return result.RowsAffected, result.Error
+}
+
+// RevokeRoleSessions revokes every session of users holding the role
+func (q *SessionQuery) RevokeRoleSessions(ctx context.Context, roleID string, revokedAt time.Time) (int64, error) {
+	holders := q.DB.Model(&models.UserRoles{}).Select("user_id").Where("deleted = ? AND role_id = ?", false, roleID)
+	result := q.DB.WithContext(ctx).Model(&models.UserSessions{}).
+		Where("revoked_at IS NULL AND user_id IN (?)", holders).
+		Update("revoked_at", revokedAt)
+	return result.RowsAffected, result.Error
+}
+
+// TouchSession updates last seen time, skipped when it was already updated recently
+func (q *SessionQuery) TouchSession(ctx context.Context, ID string, seenAt time.Time) error {
+	return q.DB.WithContext(ctx).Model(&models.UserSessions{}).
+		Where("id = ? AND last_seen_at < ?", ID, seenAt.Add(-lastUsedInterval)).
+		Update("last_seen_at", seenAt).Error
+}
@@ -0,0 +1,74 @@
+package masterrepo
+
+import (
+	"context"
+	"kiraform/src/applications/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// signingKeyLock serializes key creation and rotation between replicas and cli
+const signingKeyLock int64 = 4_739_201_837
+
+type SigningKeyRepository interface {
+	FindSigningKeys(ctx context.Context, retiredAfter time.Time) ([]models.SigningKeys, error)
+	CreateFirstSigningKey(ctx context.Context, key models.SigningKeys) (bool, error)
+	RotateSigningKey(ctx context.Context, key models.SigningKeys, purgeBefore time.Time) error
+}
+
+type SigningKeyQuery struct {
+	DB *gorm.DB
+}
+
+func NewSigningKeyRepository(DB *gorm.DB) SigningKeyRepository {
+	return &SigningKeyQuery{DB: DB}
+}
+
+// FindSigningKeys returns current key and keys retired after given time, newest first
+func (q *SigningKeyQuery) FindSigningKeys(ctx context.Context, retiredAfter time.Time) ([]models.SigningKeys, error) {
+	var keys []models.SigningKeys
+	if err := q.DB.WithContext(ctx).
+		Where("retired_at IS NULL OR retired_at > ?", retiredAfter).
+		Order("created_at DESC").
+		Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// CreateFirstSigningKey inserts key only when there is no current key, returns true when inserted
+func (q *SigningKeyQuery) CreateFirstSigningKey(ctx context.Context, key models.SigningKeys) (bool, error) {
+	created := false
+	err := q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", signingKeyLock).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.SigningKeys{}).Where("retired_at IS NULL").Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		created = true
+		return tx.Create(&key).Error
+	})
+	return created, err
+}
+
+// RotateSigningKey retires current key and inserts the new one, keys retired before purgeBefore are deleted
+func (q *SigningKeyQuery) RotateSigningKey(ctx context.Context, key models.SigningKeys, purgeBefore time.Time) error {
+	return q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", signingKeyLock).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.SigningKeys{}).Where("retired_at IS NULL").Update("retired_at", key.CreatedAt).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("retired_at < ?", purgeBefore).Delete(&models.SigningKeys{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&key).Error
+	})
+}
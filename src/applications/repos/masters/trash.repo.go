@@ -0,0 +1,274 @@
+package masterrepo
+
+import (
+	"context"
+	"kiraform/src/applications/models"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"kiraform/src/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TrashRepository interface {
+	FindWorkspaceTrash(ctx context.Context, workspaceID string, params *commonschema.QueryParams) ([]commonschema.TrashSchema, error)
+	FindCountWorkspaceTrash(ctx context.Context, workspaceID string, params *commonschema.QueryParams) (int64, error)
+	FindDeletedWorkspaces(ctx context.Context, userID string, params *commonschema.QueryParams) ([]commonschema.TrashSchema, error)
+	FindCountDeletedWorkspace(ctx context.Context, userID string, params *commonschema.QueryParams) (int64, error)
+	FindDeletedWorkspace(ctx context.Context, userID string, ID string) (*models.Workspaces, error)
+	FindDeletedCampaign(ctx context.Context, workspaceID string, ID string) (*models.Campaigns, error)
+	FindDeletedCampaignSeo(ctx context.Context, workspaceID string, ID string) (*models.CampaignSeos, error)
+	RestoreWorkspace(ctx context.Context, ID string) error
+	RestoreCampaign(ctx context.Context, ID string, deletedAt *time.Time) error
+	RestoreCampaignSeo(ctx context.Context, ID string) error
+	PurgeTrash(ctx context.Context, before time.Time) ([]string, error)
+}
+
+type TrashQuery struct {
+	DB *gorm.DB
+}
+
+func NewTrashRepository(DB *gorm.DB) *TrashQuery {
+	return &TrashQuery{DB: DB}
+}
+
+// restoreValues reverts soft-delete flag, struct Updates skips false value so map is used
+func restoreValues() map[string]any {
+	return map[string]any{
+		"deleted":    false,
+		"deleted_at": nil,
+		"updated_at": time.Now(),
+	}
+}
+
+// workspaceTrashUnion lists deleted campaigns and seo of active campaigns in workspace,
+// deleted_at falls back to updated_at for data deleted before deleted_at is recorded
+func (q *TrashQuery) workspaceTrashUnion(ctx context.Context, workspaceID string) *gorm.DB {
+	campaigns := q.DB.WithContext(ctx).Model(&models.Campaigns{}).
+		Select("campaigns.id, 'campaign' AS entity_type, campaigns.title, NULL::UUID AS parent_id, COALESCE(campaigns.deleted_at, campaigns.updated_at, campaigns.created_at) AS deleted_at, campaigns.deleted_by").
+		Where("campaigns.deleted = ? AND campaigns.workspace_id = ?", true, workspaceID)
+	seos := q.DB.WithContext(ctx).Model(&models.CampaignSeos{}).
+		Select("campaign_seos.id, 'campaign_seo' AS entity_type, campaign_seos.platform || ' - ' || campaign_seos.event AS title, campaign_seos.campaign_id AS parent_id, COALESCE(campaign_seos.deleted_at, campaign_seos.updated_at, campaign_seos.created_at) AS deleted_at, campaign_seos.deleted_by").
+		Joins("JOIN campaigns ON campaigns.id = campaign_seos.campaign_id").
+		Where("campaign_seos.deleted = ? AND campaigns.deleted = ? AND campaigns.workspace_id = ?", true, false, workspaceID)
+	return q.DB.WithContext(ctx).Raw("(?) UNION ALL (?)", campaigns, seos)
+}
+
+// deletedWorkspaceUnion lists deleted workspaces owned by user
+func (q *TrashQuery) deletedWorkspaceUnion(ctx context.Context, userID string) *gorm.DB {
+	return q.DB.WithContext(ctx).Model(&models.Workspaces{}).
+		Select("workspaces.id, 'workspace' AS entity_type, workspaces.title, NULL::UUID AS parent_id, COALESCE(workspaces.deleted_at, workspaces.updated_at, workspaces.created_at) AS deleted_at, workspaces.deleted_by").
+		Joins("JOIN workspace_users ON workspace_users.workspace_id = workspaces.id AND workspace_users.deleted = ? AND workspace_users.status = ?", false, "S5").
+		Where("workspaces.deleted = ? AND workspace_users.user_id = ?", true, userID)
+}
+
+// trashStatement wraps list of deleted items, then adds actor name, search and filter
+func (q *TrashQuery) trashStatement(ctx context.Context, items *gorm.DB, params *commonschema.QueryParams) *gorm.DB {
+	st := q.DB.WithContext(ctx).Table("(?) AS trash", items).Joins("LEFT JOIN users ON users.id = trash.deleted_by")
+
+	// add search condition
+	if params.Search != "" {
+		st = st.Where("LOWER(trash.title) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
+	}
+
+	// add filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
+	}
+	return st
+}
+
+func (q *TrashQuery) findTrash(ctx context.Context, items *gorm.DB, params *commonschema.QueryParams) ([]commonschema.TrashSchema, error) {
+	var data []commonschema.TrashSchema
+
+	// define offset
+	offset := 0
+	if params.Limit > 0 && params.Page > 0 {
+		offset = params.Limit * (params.Page - 1)
+	}
+
+	// define statements
+	st := q.trashStatement(ctx, items, params).Select("trash.*, users.fullname AS deleted_by_name")
+
+	// add orderby and limit:offset, cursor pagination uses keyset with one extra row to detect next page
+	if condition, args, order := utils.CursorClause(params, "trash.deleted_at", "trash.id"); order != "" {
+		if condition != "" {
+			st = st.Where(condition, args...)
+		}
+		st = st.Order(order).Limit(params.Limit + 1)
+	} else {
+		st = st.Order(utils.OrderClause(params, "trash.deleted_at DESC", "trash.id")).Limit(params.Limit).Offset(offset)
+	}
+
+	// perform to get the data
+	if err := st.Scan(&data).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (q *TrashQuery) findCountTrash(ctx context.Context, items *gorm.DB, params *commonschema.QueryParams) (int64, error) {
+	var count int64
+	if err := q.trashStatement(ctx, items, params).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (q *TrashQuery) FindWorkspaceTrash(ctx context.Context, workspaceID string, params *commonschema.QueryParams) ([]commonschema.TrashSchema, error) {
+	return q.findTrash(ctx, q.workspaceTrashUnion(ctx, workspaceID), params)
+}
+
+func (q *TrashQuery) FindCountWorkspaceTrash(ctx context.Context, workspaceID string, params *commonschema.QueryParams) (int64, error) {
+	return q.findCountTrash(ctx, q.workspaceTrashUnion(ctx, workspaceID), params)
+}
+
+func (q *TrashQuery) FindDeletedWorkspaces(ctx context.Context, userID string, params *commonschema.QueryParams) ([]commonschema.TrashSchema, error) {
+	return q.findTrash(ctx, q.deletedWorkspaceUnion(ctx, userID), params)
+}
+
+func (q *TrashQuery) FindCountDeletedWorkspace(ctx context.Context, userID string, params *commonschema.QueryParams) (int64, error) {
+	return q.findCountTrash(ctx, q.deletedWorkspaceUnion(ctx, userID), params)
+}
+
+func (q *TrashQuery) FindDeletedWorkspace(ctx context.Context, userID string, ID string) (*models.Workspaces, error) {
+	var data models.Workspaces
+	if err := q.DB.WithContext(ctx).Model(&models.Workspaces{}).
+		Joins("JOIN workspace_users ON workspace_users.workspace_id = workspaces.id AND workspace_users.deleted = ? AND workspace_users.status = ?", false, "S5").
+		Where("workspaces.deleted = ? AND workspaces.id = ? AND workspace_users.user_id = ?", true, ID, userID).
+		First(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (q *TrashQuery) FindDeletedCampaign(ctx context.Context, workspaceID string, ID string) (*models.Campaigns, error) {
+	var data models.Campaigns
+	if err := q.DB.WithContext(ctx).Model(&models.Campaigns{}).Where("deleted = ? AND workspace_id = ? AND id = ?", true, workspaceID, ID).First(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// FindDeletedCampaignSeo only finds seo of active campaign, deleted campaign must be restored first
+func (q *TrashQuery) FindDeletedCampaignSeo(ctx context.Context, workspaceID string, ID string) (*models.CampaignSeos, error) {
+	var data models.CampaignSeos
+	if err := q.DB.WithContext(ctx).Model(&models.CampaignSeos{}).
+		Joins("JOIN campaigns ON campaigns.id = campaign_seos.campaign_id").
+		Where("campaign_seos.deleted = ? AND campaigns.deleted = ? AND campaigns.workspace_id = ? AND campaign_seos.id = ?", true, false, workspaceID, ID).
+		First(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (q *TrashQuery) RestoreWorkspace(ctx context.Context, ID string) error {
+	values := restoreValues()
+	values["deleted_by"] = nil
+	if err := q.DB.WithContext(ctx).Model(&models.Workspaces{}).Where("deleted = ? AND id = ?", true, ID).Updates(values).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// RestoreCampaign brings back campaign with forms and attributes deleted together with it,
+// those are marked by the same deleted_at
+func (q *TrashQuery) RestoreCampaign(ctx context.Context, ID string, deletedAt *time.Time) error {
+	err := q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if deletedAt != nil {
+			forms := tx.Model(&models.CampaignForms{}).Select("id").Where("campaign_id = ? AND deleted = ? AND deleted_at = ?", ID, true, deletedAt)
+			if err := tx.Model(&models.CampaignFormAttributes{}).
+				Where("campaign_form_id IN (?) AND deleted = ? AND deleted_at = ?", forms, true, deletedAt).
+				Updates(restoreValues()).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.CampaignForms{}).
+				Where("campaign_id = ? AND deleted = ? AND deleted_at = ?", ID, true, deletedAt).
+				Updates(restoreValues()).Error; err != nil {
+				return err
+			}
+		}
+
+		values := restoreValues()
+		values["deleted_by"] = nil
+		if err := tx.Model(&models.Campaigns{}).Where("deleted = ? AND id = ?", true, ID).Updates(values).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+	return err
+}
+
+func (q *TrashQuery) RestoreCampaignSeo(ctx context.Context, ID string) error {
+	values := restoreValues()
+	values["deleted_by"] = nil
+	if err := q.DB.WithContext(ctx).Model(&models.CampaignSeos{}).Where("deleted = ? AND id = ?", true, ID).Updates(values).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// PurgeTrash permanently deletes workspaces, campaigns and seo deleted before given time,
+// children are removed by database cascade and file paths to be removed are returned
+func (q *TrashQuery) PurgeTrash(ctx context.Context, before time.Time) ([]string, error) {
+	var files []string
+	err := q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// only rows with recorded deleted_at expire, legacy rows are backfilled by migration
+		expired := "deleted = ? AND deleted_at IS NOT NULL AND deleted_at < ?"
+
+		// collect expired workspaces and every campaign inside them
+		var workspaces []models.Workspaces
+		if err := tx.Model(&models.Workspaces{}).Where(expired, true, before).Find(&workspaces).Error; err != nil {
+			return err
+		}
+		workspaceIDs := []string{}
+		for _, v := range workspaces {
+			workspaceIDs = append(workspaceIDs, v.ID.String())
+			if v.Thumbnail != "" {
+				files = append(files, v.Thumbnail)
+			}
+		}
+
+		var campaigns []models.Campaigns
+		st := tx.Model(&models.Campaigns{}).Where(expired, true, before)
+		if len(workspaceIDs) > 0 {
+			st = tx.Model(&models.Campaigns{}).Where("("+expired+") OR workspace_id IN ?", true, before, workspaceIDs)
+		}
+		if err := st.Find(&campaigns).Error; err != nil {
+			return err
+		}
+		campaignIDs := []string{}
+		for _, v := range campaigns {
+			campaignIDs = append(campaignIDs, v.ID.String())
+			if v.Thumbnail != "" {
+				files = append(files, v.Thumbnail)
+			}
+		}
+
+		// products only lose the link to campaign, they belong to store
+		if len(campaignIDs) > 0 {
+			if err := tx.Model(&models.StoreProducts{}).Where("campaign_id IN ?", campaignIDs).Update("campaign_id", nil).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("id IN ?", campaignIDs).Delete(&models.Campaigns{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(workspaceIDs) > 0 {
+			if err := tx.Where("id IN ?", workspaceIDs).Delete(&models.Workspaces{}).Error; err != nil {
+				return err
+			}
+		}
+
+		// seo of active campaigns
+		if err := tx.Where(expired, true, before).Delete(&models.CampaignSeos{}).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
@@ -0,0 +1,100 @@
+package masterrepo
+
+import (
+	"context"
+	"kiraform/src/applications/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository interface {
+	FindTwoFactorByUser(ctx context.Context, userID string) (*models.UserTwoFactors, error)
+	SavePendingTwoFactor(ctx context.Context, data models.UserTwoFactors) error
+	ConfirmTwoFactor(ctx context.Context, userID string, step int64, confirmedAt time.Time, codes []models.UserRecoveryCodes) (bool, error)
+	UseTwoFactorStep(ctx context.Context, userID string, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID string, hash string, usedAt time.Time) (bool, error)
+	FindCountRecoveryCodeLeft(ctx context.Context, userID string) (int64, error)
+	DeleteTwoFactor(ctx context.Context, userID string) error
+}
+
+type TwoFactorQuery struct {
+	DB *gorm.DB
+}
+
+func NewTwoFactorRepository(DB *gorm.DB) TwoFactorRepository {
+	return &TwoFactorQuery{DB: DB}
+}
+
+func (q *TwoFactorQuery) FindTwoFactorByUser(ctx context.Context, userID string) (*models.UserTwoFactors, error) {
+	var data models.UserTwoFactors
+	if err := q.DB.WithContext(ctx).Where("user_id = ?", userID).First(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// SavePendingTwoFactor replaces unconfirmed enrolment, confirmed one is never overwritten
+func (q *TwoFactorQuery) SavePendingTwoFactor(ctx context.Context, data models.UserTwoFactors) error {
+	return q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND confirmed_at IS NULL", data.UserID).Delete(&models.UserTwoFactors{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&data).Error
+	})
+}
+
+// ConfirmTwoFactor enables pending enrolment and replaces recovery codes, returns false when nothing is pending
+func (q *TwoFactorQuery) ConfirmTwoFactor(ctx context.Context, userID string, step int64, confirmedAt time.Time, codes []models.UserRecoveryCodes) (bool, error) {
+	confirmed := false
+	err := q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.UserTwoFactors{}).
+			Where("user_id = ? AND confirmed_at IS NULL", userID).
+			Updates(map[string]any{"confirmed_at": confirmedAt, "last_used_step": step, "updated_at": confirmedAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		confirmed = true
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCodes{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&codes).Error
+	})
+	return confirmed, err
+}
+
+// UseTwoFactorStep accepts code of step only once, returns false when the step or a later one is already used
+func (q *TwoFactorQuery) UseTwoFactorStep(ctx context.Context, userID string, step int64) (bool, error) {
+	result := q.DB.WithContext(ctx).Model(&models.UserTwoFactors{}).
+		Where("user_id = ? AND confirmed_at IS NOT NULL AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UseRecoveryCode burns unused code, returns false when it does not match
+func (q *TwoFactorQuery) UseRecoveryCode(ctx context.Context, userID string, hash string, usedAt time.Time) (bool, error) {
+	result := q.DB.WithContext(ctx).Model(&models.UserRecoveryCodes{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", usedAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (q *TwoFactorQuery) FindCountRecoveryCodeLeft(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	err := q.DB.WithContext(ctx).Model(&models.UserRecoveryCodes{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (q *TwoFactorQuery) DeleteTwoFactor(ctx context.Context, userID string) error {
+	return q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCodes{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserTwoFactors{}).Error
+	})
+}
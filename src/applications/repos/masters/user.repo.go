@@ -1,24 +1,38 @@
 package masterrepo
 
 import (
+	"context"
 	"kiraform/src/applications/models"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"kiraform/src/utils"
+	"strings"
+	"time"
 
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type UserRepository interface {
-	FindUserByEmail(email string) (*models.Users, error)
-	FindUserByID(ID string) (*models.Users, error)
-	FindUserProfile(userID string) (*models.UserProfiles, error)
-	GetRoleByUser(userID uuid.UUID) (*models.UserRoles, error)
-	CreateUser(user models.Users, userProfile models.UserProfiles, userRole models.UserRoles) error
-	UpdateUser(ID string, user models.Users) error
-	CreateUserProfile(userProfile models.UserProfiles) error
-	UpdateUserProfile(userID string, userProfile models.UserProfiles) error
-	FindCountFormByUser(userID string) (int64, error)
-	FindCountFormSubmitByUser(userID string) (int64, error)
-	FindCountFormSubmittedByUser(userID string) (int64, error)
+	FindUserByEmail(ctx context.Context, email string) (*models.Users, error)
+	FindUserByID(ctx context.Context, ID string) (*models.Users, error)
+	FindUserProfile(ctx context.Context, userID string) (*models.UserProfiles, error)
+	CreateUser(ctx context.Context, user models.Users, userProfile models.UserProfiles, userRole models.UserRoles) error
+	UpdateUser(ctx context.Context, ID string, user models.Users) error
+	UpdateUserActive(ctx context.Context, ID string, isActive bool) error
+	ActivatePendingUser(ctx context.Context, ID string) error
+	ClaimPendingUser(ctx context.Context, ID string, user models.Users, userProfile models.UserProfiles) (bool, error)
+	CreateUserProfile(ctx context.Context, userProfile models.UserProfiles) error
+	UpdateUserProfile(ctx context.Context, userID string, userProfile models.UserProfiles) error
+	FindCountFormByUser(ctx context.Context, userID string) (int64, error)
+	FindCountFormSubmitByUser(ctx context.Context, userID string) (int64, error)
+	FindCountFormSubmittedByUser(ctx context.Context, userID string) (int64, error)
+	FindUsers(ctx context.Context, params *commonschema.QueryParams) ([]models.Users, error)
+	FindCountUser(ctx context.Context, params *commonschema.QueryParams) (int64, error)
+	FindRolesByUsers(ctx context.Context, userIDs []string) ([]models.UserRoles, error)
+	FindActivePackagesByUsers(ctx context.Context, userIDs []string) ([]models.UserPackages, error)
+	FindCountWorkspaceByUsers(ctx context.Context, userIDs []string) (map[string]int64, error)
+	UpdateUserDeleted(ctx context.Context, ID string, deleted bool) error
+	CreateUserRole(ctx context.Context, userRole models.UserRoles) error
+	DeleteUserRole(ctx context.Context, userID string, roleID string) error
 }
 
 type UserQuery struct {
@@ -29,48 +43,37 @@ func NewUserRepository(DB *gorm.DB) UserRepository {
 	return &UserQuery{DB: DB}
 }
 
-func (q *UserQuery) FindUserByID(id string) (*models.Users, error) {
+func (q *UserQuery) FindUserByID(ctx context.Context, id string) (*models.Users, error) {
 	var user models.Users
 
-	if err := q.DB.Where("id = ?", id).First(&user).Error; err != nil {
+	if err := q.DB.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
 		return nil, err
 	}
 
 	return &user, nil
 }
 
-func (q *UserQuery) FindUserByEmail(email string) (*models.Users, error) {
+func (q *UserQuery) FindUserByEmail(ctx context.Context, email string) (*models.Users, error) {
 	var user models.Users
 
-	if err := q.DB.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := q.DB.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
 		return nil, err
 	}
 
 	return &user, nil
 }
 
-func (q *UserQuery) FindUserProfile(userID string) (*models.UserProfiles, error) {
+func (q *UserQuery) FindUserProfile(ctx context.Context, userID string) (*models.UserProfiles, error) {
 	var userProfile *models.UserProfiles
-	if err := q.DB.Model(&models.UserProfiles{}).Where("deleted = ? AND user_id = ?", false, userID).First(&userProfile).Error; err != nil {
+	if err := q.DB.WithContext(ctx).Model(&models.UserProfiles{}).Where("deleted = ? AND user_id = ?", false, userID).First(&userProfile).Error; err != nil {
 		return nil, err
 	}
 	return userProfile, nil
 }
 
-func (q *UserQuery) GetRoleByUser(userID uuid.UUID) (*models.UserRoles, error) {
-	var userRole models.UserRoles
-	if err := q.DB.
-		Where("deleted = ? AND user_id = ?", false, userID).
-		Preload("Role"). // join table
-		First(&userRole).Error; err != nil {
-		return nil, err
-	}
-	return &userRole, nil
-}
-
-func (q *UserQuery) CreateUser(user models.Users, userProfile models.UserProfiles, userRole models.UserRoles) error {
+func (q *UserQuery) CreateUser(ctx context.Context, user models.Users, userProfile models.UserProfiles, userRole models.UserRoles) error {
 	// perform to insert using transaction:rollback
-	err := q.DB.Transaction(func(tx *gorm.DB) error {
+	err := q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(&user).Error; err != nil {
 			return err
 		}
@@ -88,28 +91,71 @@ func (q *UserQuery) CreateUser(user models.Users, userProfile models.UserProfile
 	return nil
 }
 
-func (q *UserQuery) UpdateUser(ID string, user models.Users) error {
-	if err := q.DB.Model(&models.Users{}).Where("deleted = ? AND id = ?", false, ID).Updates(&user).Error; err != nil {
+func (q *UserQuery) UpdateUser(ctx context.Context, ID string, user models.Users) error {
+	if err := q.DB.WithContext(ctx).Model(&models.Users{}).Where("deleted = ? AND id = ?", false, ID).Updates(&user).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *UserQuery) CreateUserProfile(userProfile models.UserProfiles) error {
-	if err := q.DB.Model(&models.UserProfiles{}).Create(&userProfile).Error; err != nil {
+// UpdateUserActive is separated from UpdateUser since false is skipped on struct update,
+// admin decision ends pending state so deactivated account is not activated by passwordless login
+func (q *UserQuery) UpdateUserActive(ctx context.Context, ID string, isActive bool) error {
+	if err := q.DB.WithContext(ctx).Model(&models.Users{}).Where("deleted = ? AND id = ?", false, ID).Updates(map[string]any{"is_active": isActive, "is_pending": false, "updated_at": time.Now()}).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *UserQuery) UpdateUserProfile(userID string, userProfile models.UserProfiles) error {
-	if err := q.DB.Model(&models.UserProfiles{}).Where("deleted = ? AND user_id = ?", false, userID).Updates(&userProfile).Error; err != nil {
+// ActivatePendingUser activates user created by passwordless login, deactivated user is left as is
+func (q *UserQuery) ActivatePendingUser(ctx context.Context, ID string) error {
+	return q.DB.WithContext(ctx).Model(&models.Users{}).
+		Where("deleted = ? AND id = ? AND is_pending = ?", false, ID, true).
+		Updates(map[string]any{"is_active": true, "is_pending": false, "updated_at": time.Now()}).Error
+}
+
+// ClaimPendingUser gives never verified account of passwordless login to registration, it sets
+// password, name and profile names then activates it, false means account is not pending anymore
+func (q *UserQuery) ClaimPendingUser(ctx context.Context, ID string, user models.Users, userProfile models.UserProfiles) (bool, error) {
+	claimed := false
+	err := q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.Users{}).
+			Where("deleted = ? AND id = ? AND is_pending = ?", false, ID, true).
+			Updates(map[string]any{"password": user.Password, "fullname": user.Fullname, "is_active": true, "is_pending": false, "updated_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		claimed = true
+
+		// names are written as map, so empty middle and last name replace the ones taken from email
+		return tx.Model(&models.UserProfiles{}).Where("user_id = ?", ID).
+			Updates(map[string]any{"first_name": userProfile.FirstName, "middle_name": userProfile.MiddleName, "last_name": userProfile.LastName, "updated_at": now}).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return claimed, nil
+}
+
+func (q *UserQuery) CreateUserProfile(ctx context.Context, userProfile models.UserProfiles) error {
+	if err := q.DB.WithContext(ctx).Model(&models.UserProfiles{}).Create(&userProfile).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *UserQuery) FindCountFormByUser(userID string) (int64, error) {
+func (q *UserQuery) UpdateUserProfile(ctx context.Context, userID string, userProfile models.UserProfiles) error {
+	if err := q.DB.WithContext(ctx).Model(&models.UserProfiles{}).Where("deleted = ? AND user_id = ?", false, userID).Updates(&userProfile).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (q *UserQuery) FindCountFormByUser(ctx context.Context, userID string) (int64, error) {
 	query := `
 		SELECT 
 			COUNT(1)
@@ -131,13 +177,13 @@ func (q *UserQuery) FindCountFormByUser(userID string) (int64, error) {
 	args := []any{false, false, false, userID, "S3", "S5"}
 
 	var count int64
-	if err := q.DB.Raw(query, args...).Scan(&count).Error; err != nil {
+	if err := q.DB.WithContext(ctx).Raw(query, args...).Scan(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
-func (q *UserQuery) FindCountFormSubmitByUser(userID string) (int64, error) {
+func (q *UserQuery) FindCountFormSubmitByUser(ctx context.Context, userID string) (int64, error) {
 	query := `
 		SELECT
 			COUNT(1)
@@ -161,13 +207,13 @@ func (q *UserQuery) FindCountFormSubmitByUser(userID string) (int64, error) {
 	args := []any{false, false, false, false, userID, "S3", "S5"}
 
 	var count int64
-	if err := q.DB.Raw(query, args...).Scan(&count).Error; err != nil {
+	if err := q.DB.WithContext(ctx).Raw(query, args...).Scan(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
-func (q *UserQuery) FindCountFormSubmittedByUser(userID string) (int64, error) {
+func (q *UserQuery) FindCountFormSubmittedByUser(ctx context.Context, userID string) (int64, error) {
 	query := `
 		SELECT
 			COUNT(1)
@@ -179,8 +225,140 @@ func (q *UserQuery) FindCountFormSubmittedByUser(userID string) (int64, error) {
 	args := []any{false, userID}
 
 	var count int64
-	if err := q.DB.Raw(query, args...).Scan(&count).Error; err != nil {
+	if err := q.DB.WithContext(ctx).Raw(query, args...).Scan(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// userStatement builds base query of users seen by admin, deleted users are included so they can be restored
+func (q *UserQuery) userStatement(ctx context.Context, params *commonschema.QueryParams) *gorm.DB {
+	st := q.DB.WithContext(ctx).Model(&models.Users{})
+
+	// add search condition
+	if params.Search != "" {
+		keyword := "%" + strings.ToLower(params.Search) + "%"
+		st = st.Where("(LOWER(users.email) LIKE ? OR LOWER(users.fullname) LIKE ? OR LOWER(users.user_identity) LIKE ?)", keyword, keyword, keyword)
+	}
+
+	// add filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
+	}
+	return st
+}
+
+func (q *UserQuery) FindUsers(ctx context.Context, params *commonschema.QueryParams) ([]models.Users, error) {
+	var users []models.Users
+
+	// define offset
+	offset := 0
+	if params.Limit > 0 && params.Page > 0 {
+		offset = params.Limit * (params.Page - 1)
+	}
+
+	// add orderby and limit:offset, cursor pagination uses keyset with one extra row to detect next page
+	st := q.userStatement(ctx, params)
+	if condition, args, order := utils.CursorClause(params, "users.created_at", "users.id"); order != "" {
+		if condition != "" {
+			st = st.Where(condition, args...)
+		}
+		st = st.Order(order).Limit(params.Limit + 1)
+	} else {
+		st = st.Order(utils.OrderClause(params, "users.created_at DESC", "users.id")).Limit(params.Limit).Offset(offset)
+	}
+
+	// perform to get the data
+	if err := st.Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (q *UserQuery) FindCountUser(ctx context.Context, params *commonschema.QueryParams) (int64, error) {
+	var count int64
+	if err := q.userStatement(ctx, params).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
+
+// FindRolesByUsers loads roles of many users in one query, deleted role is left out
+func (q *UserQuery) FindRolesByUsers(ctx context.Context, userIDs []string) ([]models.UserRoles, error) {
+	var data []models.UserRoles
+	if len(userIDs) == 0 {
+		return data, nil
+	}
+	if err := q.DB.WithContext(ctx).
+		Select("user_roles.*").
+		Joins("JOIN roles ON roles.id = user_roles.role_id AND roles.deleted = ?", false).
+		Where("user_roles.deleted = ? AND user_roles.user_id IN ?", false, userIDs).
+		Order("user_roles.created_at").
+		Preload("Role"). // join table
+		Find(&data).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// FindActivePackagesByUsers loads active package of many users in one query
+func (q *UserQuery) FindActivePackagesByUsers(ctx context.Context, userIDs []string) ([]models.UserPackages, error) {
+	var data []models.UserPackages
+	if len(userIDs) == 0 {
+		return data, nil
+	}
+	if err := q.DB.WithContext(ctx).
+		Where("deleted = ? AND is_active = ? AND user_id IN ?", false, true, userIDs).
+		Preload("Package"). // join table
+		Find(&data).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// FindCountWorkspaceByUsers counts workspaces owned or joined by many users in one query
+func (q *UserQuery) FindCountWorkspaceByUsers(ctx context.Context, userIDs []string) (map[string]int64, error) {
+	var rows []commonschema.CountByID
+	if len(userIDs) == 0 {
+		return map[string]int64{}, nil
+	}
+
+	err := q.DB.WithContext(ctx).Model(&models.WorkspaceUsers{}).
+		Select("workspace_users.user_id AS id", "COUNT(1) AS total").
+		Joins("JOIN workspaces ON workspaces.id = workspace_users.workspace_id AND workspaces.deleted = ?", false).
+		Where("workspace_users.deleted = ? AND workspace_users.status IN ? AND workspace_users.user_id IN ?", false, []string{"S3", "S5"}, userIDs).
+		Group("workspace_users.user_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return commonschema.CountMap(rows), nil
+}
+
+// UpdateUserDeleted soft deletes or restores user, deleted user is inactive too and
+// restored user stays inactive until admin activates it again
+func (q *UserQuery) UpdateUserDeleted(ctx context.Context, ID string, deleted bool) error {
+	values := map[string]any{"deleted": deleted, "updated_at": time.Now()}
+	if deleted {
+		values["is_active"] = false
+		values["is_pending"] = false
+	}
+	return q.DB.WithContext(ctx).Model(&models.Users{}).Where("id = ?", ID).Updates(values).Error
+}
+
+func (q *UserQuery) CreateUserRole(ctx context.Context, userRole models.UserRoles) error {
+	return q.DB.WithContext(ctx).Create(&userRole).Error
+}
+
+func (q *UserQuery) DeleteUserRole(ctx context.Context, userID string, roleID string) error {
+	result := q.DB.WithContext(ctx).Model(&models.UserRoles{}).
+		Where("deleted = ? AND user_id = ? AND role_id = ?", false, userID, roleID).
+		Updates(map[string]any{"deleted": true, "updated_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package masterrepo
+
+import (
+	"context"
+	"kiraform/src/applications/models"
+	"kiraform/src/infras/dbtest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestUpdateUserActiveEndsPending(t *testing.T) {
+	for _, isActive := range []bool{true, false} {
+		DB, recorder := dbtest.Open(t, func(string) *dbtest.Rows { return nil })
+		if err := NewUserRepository(DB).UpdateUserActive(context.Background(), uuid.NewString(), isActive); err != nil {
+			t.Fatalf("update user active: %v", err)
+		}
+		queries := recorder.Queries()
+		if len(queries) != 1 || !strings.Contains(queries[0], `"is_pending"=`) {
+			t.Fatalf("active %v sent %q, want is_pending cleared", isActive, queries)
+		}
+	}
+}
+
+func TestRestoreUserLeavesActiveAlone(t *testing.T) {
+	DB, recorder := dbtest.Open(t, func(string) *dbtest.Rows { return nil })
+	repo := NewUserRepository(DB)
+	ID := uuid.NewString()
+	if err := repo.UpdateUserDeleted(context.Background(), ID, true); err != nil {
+		t.Fatalf("delete user: %v", err)
+	}
+	if err := repo.UpdateUserDeleted(context.Background(), ID, false); err != nil {
+		t.Fatalf("restore user: %v", err)
+	}
+
+	queries := recorder.Queries()
+	if len(queries) != 2 {
+		t.Fatalf("got %d queries, want 2", len(queries))
+	}
+	if !strings.Contains(queries[0], `"is_active"=`) {
+		t.Fatalf("delete sent %q, want user deactivated", queries[0])
+	}
+	if strings.Contains(queries[1], "is_active") || strings.Contains(queries[1], "is_pending") {
+		t.Fatalf("restore sent %q, want only deleted flag changed", queries[1])
+	}
+}
+
+func TestClaimPendingUserOnlyTakesPending(t *testing.T) {
+	DB, recorder := dbtest.Open(t, func(string) *dbtest.Rows { return nil })
+	claimed, err := NewUserRepository(DB).ClaimPendingUser(context.Background(), uuid.NewString(), models.Users{Password: "hash", Fullname: "Jane Doe"}, models.UserProfiles{FirstName: "Jane"})
+	if err != nil {
+		t.Fatalf("claim pending user: %v", err)
+	}
+
+	// fake driver updates no row, like account verified meanwhile
+	if claimed {
+		t.Fatal("account which is not pending is claimed")
+	}
+	queries := recorder.Queries()
+	if len(queries) != 1 || !strings.Contains(queries[0], "is_pending = $") {
+		t.Fatalf("sent %q, want update limited to pending account and no profile update", queries)
+	}
+}
@@ -0,0 +1,58 @@
+package masterrepo
+
+import (
+	"context"
+	"kiraform/src/applications/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository interface {
+	FindUserIdentity(ctx context.Context, provider string, subject string) (*models.UserIdentities, error)
+	CreateUserIdentity(ctx context.Context, data models.UserIdentities) error
+	CreateUserWithIdentity(ctx context.Context, user models.Users, userProfile models.UserProfiles, userRole models.UserRoles, identity models.UserIdentities) error
+	TouchUserIdentity(ctx context.Context, ID string, loginAt time.Time) error
+}
+
+type UserIdentityQuery struct {
+	DB *gorm.DB
+}
+
+func NewUserIdentityRepository(DB *gorm.DB) UserIdentityRepository {
+	return &UserIdentityQuery{DB: DB}
+}
+
+func (q *UserIdentityQuery) FindUserIdentity(ctx context.Context, provider string, subject string) (*models.UserIdentities, error) {
+	var data models.UserIdentities
+	if err := q.DB.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (q *UserIdentityQuery) CreateUserIdentity(ctx context.Context, data models.UserIdentities) error {
+	return q.DB.WithContext(ctx).Create(&data).Error
+}
+
+// CreateUserWithIdentity registers user of first sso login, user is not left without its identity
+func (q *UserIdentityQuery) CreateUserWithIdentity(ctx context.Context, user models.Users, userProfile models.UserProfiles, userRole models.UserRoles, identity models.UserIdentities) error {
+	return q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&userProfile).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&userRole).Error; err != nil {
+			return err
+		}
+		return tx.Create(&identity).Error
+	})
+}
+
+func (q *UserIdentityQuery) TouchUserIdentity(ctx context.Context, ID string, loginAt time.Time) error {
+	return q.DB.WithContext(ctx).Model(&models.UserIdentities{}).
+		Where("id = ?", ID).
+		Update("last_login_at", loginAt).Error
+}
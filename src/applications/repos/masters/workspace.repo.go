@@ -1,30 +1,40 @@
 package masterrepo
 
 import (
+	"context"
 	"kiraform/src/applications/models"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
 	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"kiraform/src/utils"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type WorkspaceRepository interface {
-	FindWorkspaces(userID *string, params *commonschema.QueryParams) ([]models.Workspaces, error)
-	FindCountWorkspace(userID *string, params *commonschema.QueryParams) (int64, error)
-	FindWorkspaceByID(ID string) (*models.Workspaces, error)
-	CreateWorkspace(data models.Workspaces) error
-	UpdateWorkspace(ID string, data models.Workspaces) error
-	FindWorkspaceUsers(workspaceID string, params *commonschema.QueryParams) ([]masterschema.WorkspaceUserSchema, error)
-	FindCountWorkspaceUser(workspaceID string, params *commonschema.QueryParams) (int64, error)
-	FindWorkspaceUserByID(workspaceID string, ID string) (*masterschema.WorkspaceUserSchema, error)
-	FindWorkspaceUserByUser(workspaceID string, userID string) (*masterschema.WorkspaceUserSchema, error)
-	FindWorkspaceUserByUserApproved(workspaceID string, userID string) (*masterschema.WorkspaceUserSchema, error)
-	CreateWorkspaceUser(data models.WorkspaceUsers) error
-	UpdateWorkspaceUser(workspaceID string, ID string, data models.WorkspaceUsers) error
-	FindCountCampaignByWorkspace(workspaceID string) (int64, error)
-	FindCountFormSubmissionByWorkspace(workspaceID string) (int64, error)
-	FindAllCampaignsByUser(userID string) ([]masterschema.CampaignSelectResponse, error)
+	FindWorkspaces(ctx context.Context, userID *string, params *commonschema.QueryParams) ([]models.Workspaces, error)
+	FindCountWorkspace(ctx context.Context, userID *string, params *commonschema.QueryParams) (int64, error)
+	FindWorkspaceByID(ctx context.Context, ID string) (*models.Workspaces, error)
+	CreateWorkspace(ctx context.Context, data models.Workspaces) error
+	UpdateWorkspace(ctx context.Context, ID string, data models.Workspaces) error
+	UpdateWorkspaceTwoFactor(ctx context.Context, ID string, require bool) error
+	FindWorkspaceUsers(ctx context.Context, workspaceID string, params *commonschema.QueryParams) ([]masterschema.WorkspaceUserSchema, error)
+	FindCountWorkspaceUser(ctx context.Context, workspaceID string, params *commonschema.QueryParams) (int64, error)
+	FindWorkspaceUserByID(ctx context.Context, workspaceID string, ID string) (*masterschema.WorkspaceUserSchema, error)
+	FindWorkspaceUserByUser(ctx context.Context, workspaceID string, userID string) (*masterschema.WorkspaceUserSchema, error)
+	FindWorkspaceUserByUserApproved(ctx context.Context, workspaceID string, userID string) (*masterschema.WorkspaceUserSchema, error)
+	CreateWorkspaceUser(ctx context.Context, data models.WorkspaceUsers) error
+	UpdateWorkspaceUser(ctx context.Context, workspaceID string, ID string, data models.WorkspaceUsers) error
+	FindCountCampaignByWorkspaces(ctx context.Context, workspaceIDs []string) (map[string]int64, error)
+	FindCountFormSubmissionByWorkspace(ctx context.Context, workspaceID string) (int64, error)
+	FindCountFormSubmissionByWorkspaces(ctx context.Context, workspaceIDs []string) (map[string]int64, error)
+	FindAllCampaignsByUser(ctx context.Context, userID string) ([]masterschema.CampaignSelectResponse, error)
+	FindSummaryEntriesByWorkspace(ctx context.Context, workspaceID string, params *masterschema.AnalyticsParams) ([]masterschema.CampaignFormEntryChart, error)
+	FindEntryStatusSummary(ctx context.Context, workspaceID string, params *masterschema.AnalyticsParams) (*masterschema.WorkspaceAnalyticsSummary, error)
+	FindCountVisitByWorkspace(ctx context.Context, workspaceID string, params *masterschema.AnalyticsParams) (int64, error)
+	FindCampaignPerformances(ctx context.Context, workspaceID string, params *masterschema.AnalyticsParams) ([]masterschema.WorkspaceTopCampaign, error)
+	FindTopReviewers(ctx context.Context, workspaceID string, params *masterschema.AnalyticsParams, limit int) ([]masterschema.WorkspaceTopReviewer, error)
 }
 
 type WorkspaceQuery struct {
@@ -35,7 +45,7 @@ func NewWorkspaceRepository(DB *gorm.DB) *WorkspaceQuery {
 	return &WorkspaceQuery{DB: DB}
 }
 
-func (q *WorkspaceQuery) FindWorkspaces(userID *string, params *commonschema.QueryParams) ([]models.Workspaces, error) {
+func (q *WorkspaceQuery) FindWorkspaces(ctx context.Context, userID *string, params *commonschema.QueryParams) ([]models.Workspaces, error) {
 	var workspaces []models.Workspaces
 
 	// calculating offset
@@ -45,7 +55,7 @@ func (q *WorkspaceQuery) FindWorkspaces(userID *string, params *commonschema.Que
 	}
 
 	// define statments
-	st := q.DB.Model(&models.Workspaces{}).Where("deleted = ?", false)
+	st := q.DB.WithContext(ctx).Model(&models.Workspaces{}).Where("deleted = ?", false)
 	if userID != nil {
 		st = st.Where("workspaces.id IN (SELECT workspace_id FROM workspace_users WHERE workspace_users.workspace_id = workspaces.id AND deleted = ? AND user_id = ?)", false, userID)
 	}
@@ -55,11 +65,14 @@ func (q *WorkspaceQuery) FindWorkspaces(userID *string, params *commonschema.Que
 		st = st.Where("LOWER(title) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
 	}
 
-	// add orderby
-	if params.OrderBy != "" {
-		st = st.Order(params.OrderBy)
+	// add filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
 	}
 
+	// add orderby
+	st = st.Order(utils.OrderClause(params, "", "workspaces.id"))
+
 	// add limit:offset
 	st = st.Limit(params.Limit).Offset(offset)
 
@@ -72,15 +85,20 @@ func (q *WorkspaceQuery) FindWorkspaces(userID *string, params *commonschema.Que
 	return workspaces, nil
 }
 
-func (q *WorkspaceQuery) FindCountWorkspace(userID *string, params *commonschema.QueryParams) (int64, error) {
+func (q *WorkspaceQuery) FindCountWorkspace(ctx context.Context, userID *string, params *commonschema.QueryParams) (int64, error) {
 	var count int64
 
 	// preparing conditions
-	st := q.DB.Model(&models.Workspaces{}).Where("deleted = ?", false)
+	st := q.DB.WithContext(ctx).Model(&models.Workspaces{}).Where("deleted = ?", false)
 	if params.Search != "" {
 		st = st.Where("LOWER(title) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
 	}
 
+	// add filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
+	}
+
 	// perform to get data
 	if err := st.Count(&count).Error; err != nil {
 		return 0, err
@@ -90,32 +108,38 @@ func (q *WorkspaceQuery) FindCountWorkspace(userID *string, params *commonschema
 	return count, nil
 }
 
-func (q *WorkspaceQuery) FindWorkspaceByID(ID string) (*models.Workspaces, error) {
+func (q *WorkspaceQuery) FindWorkspaceByID(ctx context.Context, ID string) (*models.Workspaces, error) {
 	var workspace models.Workspaces
 
 	// preparing query
-	err := q.DB.Where("deleted = ? AND id = ?", false, ID).First(&workspace).Error
+	err := q.DB.WithContext(ctx).Where("deleted = ? AND id = ?", false, ID).First(&workspace).Error
 	if err != nil {
 		return nil, err
 	}
 	return &workspace, nil
 }
 
-func (q *WorkspaceQuery) CreateWorkspace(data models.Workspaces) error {
-	if err := q.DB.Create(&data).Error; err != nil {
+func (q *WorkspaceQuery) CreateWorkspace(ctx context.Context, data models.Workspaces) error {
+	if err := q.DB.WithContext(ctx).Create(&data).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *WorkspaceQuery) UpdateWorkspace(ID string, data models.Workspaces) error {
-	if err := q.DB.Model(&models.Workspaces{}).Where("id = ?", ID).Updates(&data).Error; err != nil {
+func (q *WorkspaceQuery) UpdateWorkspace(ctx context.Context, ID string, data models.Workspaces) error {
+	if err := q.DB.WithContext(ctx).Model(&models.Workspaces{}).Where("id = ?", ID).Updates(&data).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *WorkspaceQuery) FindWorkspaceUsers(workspaceID string, params *commonschema.QueryParams) ([]masterschema.WorkspaceUserSchema, error) {
+// UpdateWorkspaceTwoFactor uses map so turning requirement off is saved too
+func (q *WorkspaceQuery) UpdateWorkspaceTwoFactor(ctx context.Context, ID string, require bool) error {
+	return q.DB.WithContext(ctx).Model(&models.Workspaces{}).Where("id = ?", ID).
+		Updates(map[string]any{"require_two_factor": require, "updated_at": time.Now()}).Error
+}
+
+func (q *WorkspaceQuery) FindWorkspaceUsers(ctx context.Context, workspaceID string, params *commonschema.QueryParams) ([]masterschema.WorkspaceUserSchema, error) {
 	var workspaces []masterschema.WorkspaceUserSchema
 
 	// calculating offset
@@ -125,7 +149,7 @@ func (q *WorkspaceQuery) FindWorkspaceUsers(workspaceID string, params *commonsc
 	}
 
 	// define statments
-	st := q.DB.Model(&models.WorkspaceUsers{}).Where("workspace_users.deleted = ? AND workspace_users.workspace_id = ?", false, workspaceID).
+	st := q.DB.WithContext(ctx).Model(&models.WorkspaceUsers{}).Where("workspace_users.deleted = ? AND workspace_users.workspace_id = ?", false, workspaceID).
 		Select("workspace_users.*", "users.email AS user_email", "users.fullname AS user_name", "workspaces.title AS workspace_title").
 		Joins("JOIN users ON users.id = workspace_users.user_id").
 		Joins("JOIN workspaces ON workspaces.id = workspace_users.workspace_id")
@@ -135,11 +159,14 @@ func (q *WorkspaceQuery) FindWorkspaceUsers(workspaceID string, params *commonsc
 		st = st.Where("(LOWER(users.email) LIKE ? OR LOWER(users.fullname) LIKE ?)", "%"+strings.ToLower(params.Search)+"%", "%"+strings.ToLower(params.Search)+"%")
 	}
 
-	// add orderby
-	if params.OrderBy != "" {
-		st = st.Order(params.OrderBy)
+	// add filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
 	}
 
+	// add orderby
+	st = st.Order(utils.OrderClause(params, "", "workspace_users.id"))
+
 	// add limit:offset
 	st = st.Limit(params.Limit).Offset(offset)
 
@@ -152,17 +179,22 @@ func (q *WorkspaceQuery) FindWorkspaceUsers(workspaceID string, params *commonsc
 	return workspaces, nil
 }
 
-func (q *WorkspaceQuery) FindCountWorkspaceUser(workspaceID string, params *commonschema.QueryParams) (int64, error) {
+func (q *WorkspaceQuery) FindCountWorkspaceUser(ctx context.Context, workspaceID string, params *commonschema.QueryParams) (int64, error) {
 	var count int64
 
 	// preparing conditions
-	st := q.DB.Model(&models.WorkspaceUsers{}).Where("workspace_users.deleted = ? AND workspace_users.workspace_id = ?", false, workspaceID).
+	st := q.DB.WithContext(ctx).Model(&models.WorkspaceUsers{}).Where("workspace_users.deleted = ? AND workspace_users.workspace_id = ?", false, workspaceID).
 		Joins("JOIN users ON users.id = workspace_users.user_id")
 
 	if params.Search != "" {
 		st = st.Where("(LOWER(users.email) LIKE ? OR LOWER(users.fullname) LIKE ?)", "%"+strings.ToLower(params.Search)+"%", "%"+strings.ToLower(params.Search)+"%")
 	}
 
+	// add filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
+	}
+
 	// perform to get data
 	if err := st.Count(&count).Error; err != nil {
 		return 0, err
@@ -172,11 +204,11 @@ func (q *WorkspaceQuery) FindCountWorkspaceUser(workspaceID string, params *comm
 	return count, nil
 }
 
-func (q *WorkspaceQuery) FindWorkspaceUserByID(workspaceID string, ID string) (*masterschema.WorkspaceUserSchema, error) {
+func (q *WorkspaceQuery) FindWorkspaceUserByID(ctx context.Context, workspaceID string, ID string) (*masterschema.WorkspaceUserSchema, error) {
 	var workspaceUser masterschema.WorkspaceUserSchema
 
 	// preparing query
-	err := q.DB.Model(&models.WorkspaceUsers{}).Where("workspace_users.deleted = ? AND workspace_users.workspace_id = ? AND workspace_users.id = ?", false, workspaceID, ID).
+	err := q.DB.WithContext(ctx).Model(&models.WorkspaceUsers{}).Where("workspace_users.deleted = ? AND workspace_users.workspace_id = ? AND workspace_users.id = ?", false, workspaceID, ID).
 		Select("workspace_users.*", "users.email AS user_email", "users.fullname AS user_name", "workspaces.title AS workspace_title").
 		Joins("JOIN users ON users.id = workspace_users.user_id").
 		Joins("JOIN workspaces ON workspaces.id = workspace_users.workspace_id").
@@ -187,11 +219,11 @@ func (q *WorkspaceQuery) FindWorkspaceUserByID(workspaceID string, ID string) (*
 	return &workspaceUser, nil
 }
 
-func (q *WorkspaceQuery) FindWorkspaceUserByUser(workspaceID string, userID string) (*masterschema.WorkspaceUserSchema, error) {
+func (q *WorkspaceQuery) FindWorkspaceUserByUser(ctx context.Context, workspaceID string, userID string) (*masterschema.WorkspaceUserSchema, error) {
 	var workspaceUser masterschema.WorkspaceUserSchema
 
 	// preparing query
-	err := q.DB.Model(&models.WorkspaceUsers{}).Where("workspace_users.deleted = ? AND workspace_users.workspace_id = ? AND workspace_users.user_id = ?", false, workspaceID, userID).
+	err := q.DB.WithContext(ctx).Model(&models.WorkspaceUsers{}).Where("workspace_users.deleted = ? AND workspace_users.workspace_id = ? AND workspace_users.user_id = ?", false, workspaceID, userID).
 		Select("workspace_users.*", "users.email AS user_email", "users.fullname AS user_name", "workspaces.title AS workspace_title").
 		Joins("JOIN users ON users.id = workspace_users.user_id").
 		Joins("JOIN workspaces ON workspaces.id = workspace_users.workspace_id").
@@ -202,11 +234,11 @@ func (q *WorkspaceQuery) FindWorkspaceUserByUser(workspaceID string, userID stri
 	return &workspaceUser, nil
 }
 
-func (q *WorkspaceQuery) FindWorkspaceUserByUserApproved(workspaceID string, userID string) (*masterschema.WorkspaceUserSchema, error) {
+func (q *WorkspaceQuery) FindWorkspaceUserByUserApproved(ctx context.Context, workspaceID string, userID string) (*masterschema.WorkspaceUserSchema, error) {
 	var workspaceUser masterschema.WorkspaceUserSchema
 
 	// preparing query
-	err := q.DB.Model(&models.WorkspaceUsers{}).Where("workspace_users.deleted = ? AND workspace_users.workspace_id = ? AND workspace_users.user_id = ? AND workspace_users.status IN (?, ?)", false, workspaceID, userID, "S3", "S5").
+	err := q.DB.WithContext(ctx).Model(&models.WorkspaceUsers{}).Where("workspace_users.deleted = ? AND workspace_users.workspace_id = ? AND workspace_users.user_id = ? AND workspace_users.status IN (?, ?)", false, workspaceID, userID, "S3", "S5").
 		Select("workspace_users.*", "users.email AS user_email", "users.fullname AS user_name", "workspaces.title AS workspace_title").
 		Joins("JOIN users ON users.id = workspace_users.user_id").
 		Joins("JOIN workspaces ON workspaces.id = workspace_users.workspace_id").
@@ -217,39 +249,69 @@ func (q *WorkspaceQuery) FindWorkspaceUserByUserApproved(workspaceID string, use
 	return &workspaceUser, nil
 }
 
-func (q *WorkspaceQuery) CreateWorkspaceUser(data models.WorkspaceUsers) error {
-	if err := q.DB.Model(&models.WorkspaceUsers{}).Create(&data).Error; err != nil {
+func (q *WorkspaceQuery) CreateWorkspaceUser(ctx context.Context, data models.WorkspaceUsers) error {
+	if err := q.DB.WithContext(ctx).Model(&models.WorkspaceUsers{}).Create(&data).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *WorkspaceQuery) UpdateWorkspaceUser(workspaceID string, ID string, data models.WorkspaceUsers) error {
-	if err := q.DB.Model(&models.WorkspaceUsers{}).Where("workspace_id = ? AND id = ?", workspaceID, ID).Updates(&data).Error; err != nil {
+func (q *WorkspaceQuery) UpdateWorkspaceUser(ctx context.Context, workspaceID string, ID string, data models.WorkspaceUsers) error {
+	if err := q.DB.WithContext(ctx).Model(&models.WorkspaceUsers{}).Where("workspace_id = ? AND id = ?", workspaceID, ID).Updates(&data).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *WorkspaceQuery) FindCountCampaignByWorkspace(workspaceID string) (int64, error) {
-	var count int64
-	if err := q.DB.Model(&models.Campaigns{}).Where("deleted = ? AND workspace_id = ?", false, workspaceID).Count(&count).Error; err != nil {
-		return 0, err
+// FindCountCampaignByWorkspaces counts campaigns of many workspaces in one query
+func (q *WorkspaceQuery) FindCountCampaignByWorkspaces(ctx context.Context, workspaceIDs []string) (map[string]int64, error) {
+	var rows []commonschema.CountByID
+	if len(workspaceIDs) == 0 {
+		return map[string]int64{}, nil
 	}
-	return count, nil
+
+	err := q.DB.WithContext(ctx).Model(&models.Campaigns{}).
+		Select("workspace_id AS id", "COUNT(1) AS total").
+		Where("deleted = ? AND workspace_id IN ?", false, workspaceIDs).
+		Group("workspace_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return commonschema.CountMap(rows), nil
 }
 
-func (q *WorkspaceQuery) FindCountFormSubmissionByWorkspace(workspaceID string) (int64, error) {
+func (q *WorkspaceQuery) FindCountFormSubmissionByWorkspace(ctx context.Context, workspaceID string) (int64, error) {
 	var count int64
-	if err := q.DB.Raw("SELECT COUNT(1) FROM form_entries JOIN campaigns ON campaigns.id = form_entries.campaign_id WHERE campaigns.deleted = ? AND form_entries.deleted = ? AND campaigns.workspace_id = ?", false, false, workspaceID).Scan(&count).Error; err != nil {
+	if err := q.DB.WithContext(ctx).Raw("SELECT COUNT(1) FROM form_entries JOIN campaigns ON campaigns.id = form_entries.campaign_id WHERE campaigns.deleted = ? AND form_entries.deleted = ? AND campaigns.workspace_id = ?", false, false, workspaceID).Scan(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
-func (q *WorkspaceQuery) FindAllCampaignsByUser(userID string) ([]masterschema.CampaignSelectResponse, error) {
+// FindCountFormSubmissionByWorkspaces counts entries of many workspaces in one query
+func (q *WorkspaceQuery) FindCountFormSubmissionByWorkspaces(ctx context.Context, workspaceIDs []string) (map[string]int64, error) {
+	var rows []commonschema.CountByID
+	if len(workspaceIDs) == 0 {
+		return map[string]int64{}, nil
+	}
+
+	query := `
+		SELECT campaigns.workspace_id AS id, COUNT(1) AS total
+		FROM form_entries
+		JOIN campaigns ON campaigns.id = form_entries.campaign_id
+		WHERE campaigns.deleted = ? AND form_entries.deleted = ? AND campaigns.workspace_id IN ?
+		GROUP BY campaigns.workspace_id
+	`
+	if err := q.DB.WithContext(ctx).Raw(query, false, false, workspaceIDs).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return commonschema.CountMap(rows), nil
+}
+
+func (q *WorkspaceQuery) FindAllCampaignsByUser(ctx context.Context, userID string) ([]masterschema.CampaignSelectResponse, error) {
 	var data []masterschema.CampaignSelectResponse
-	err := q.DB.Raw(`
+	err := q.DB.WithContext(ctx).Raw(`
 		SELECT 
 			campaigns.id, campaigns.workspace_id, campaigns.title, campaigns.description, 
 			workspaces.title AS workspace_title, workspaces.description AS workspace_description 
@@ -274,3 +336,151 @@ func (q *WorkspaceQuery) FindAllCampaignsByUser(userID string) ([]masterschema.C
 	}
 	return data, nil
 }
+
+func (q *WorkspaceQuery) FindSummaryEntriesByWorkspace(ctx context.Context, workspaceID string, params *masterschema.AnalyticsParams) ([]masterschema.CampaignFormEntryChart, error) {
+	var data []masterschema.CampaignFormEntryChart
+
+	query := `
+		SELECT 
+			COUNT(1) AS total, 
+			TO_CHAR(DATE_TRUNC(?, ` + localEntryDate + `), 'YYYY-MM-DD') AS date 
+		FROM form_entries 
+		JOIN campaigns ON campaigns.id = form_entries.campaign_id 
+		WHERE 
+			campaigns.deleted = ? 
+			AND form_entries.deleted = ? 
+			AND form_entries.status <> ? 
+			AND campaigns.workspace_id = ? 
+			AND ` + localEntryDate + `::DATE BETWEEN ? AND ?
+		GROUP BY 2 
+		ORDER BY 2 ASC
+	`
+	args := []any{params.GroupBy, params.Timezone, false, false, "S4", workspaceID, params.Timezone, params.StartDate, params.EndDate}
+
+	if err := q.DB.WithContext(ctx).Raw(query, args...).Scan(&data).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (q *WorkspaceQuery) FindEntryStatusSummary(ctx context.Context, workspaceID string, params *masterschema.AnalyticsParams) (*masterschema.WorkspaceAnalyticsSummary, error) {
+	var data masterschema.WorkspaceAnalyticsSummary
+
+	query := `
+		SELECT 
+			COUNT(1) AS total_submit, 
+			COUNT(1) FILTER (WHERE form_entries.status = ?) AS total_pending, 
+			COUNT(1) FILTER (WHERE form_entries.status = ?) AS total_approved, 
+			COUNT(1) FILTER (WHERE form_entries.status = ?) AS total_rejected 
+		FROM form_entries 
+		JOIN campaigns ON campaigns.id = form_entries.campaign_id 
+		WHERE 
+			campaigns.deleted = ? 
+			AND form_entries.deleted = ? 
+			AND form_entries.status <> ? 
+			AND campaigns.workspace_id = ? 
+			AND ` + localEntryDate + `::DATE BETWEEN ? AND ?
+	`
+	args := []any{"S1", "S2", "S3", false, false, "S4", workspaceID, params.Timezone, params.StartDate, params.EndDate}
+
+	if err := q.DB.WithContext(ctx).Raw(query, args...).Scan(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (q *WorkspaceQuery) FindCountVisitByWorkspace(ctx context.Context, workspaceID string, params *masterschema.AnalyticsParams) (int64, error) {
+	var count int64
+
+	// visit_date is already stored in workspace timezone
+	query := `
+		SELECT 
+			COALESCE(SUM(campaign_visits.total), 0) 
+		FROM campaign_visits 
+		JOIN campaigns ON campaigns.id = campaign_visits.campaign_id 
+		WHERE 
+			campaigns.deleted = ? 
+			AND campaigns.workspace_id = ? 
+			AND campaign_visits.visit_date BETWEEN ? AND ?
+	`
+	args := []any{false, workspaceID, params.StartDate, params.EndDate}
+
+	if err := q.DB.WithContext(ctx).Raw(query, args...).Scan(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (q *WorkspaceQuery) FindCampaignPerformances(ctx context.Context, workspaceID string, params *masterschema.AnalyticsParams) ([]masterschema.WorkspaceTopCampaign, error) {
+	var data []masterschema.WorkspaceTopCampaign
+
+	query := `
+		SELECT 
+			campaigns.id AS campaign_id, 
+			campaigns.title, 
+			campaigns.key, 
+			COALESCE(entries.total, 0) AS total_submit, 
+			COALESCE(visits.total, 0) AS total_visit 
+		FROM campaigns 
+		LEFT JOIN (
+			SELECT 
+				form_entries.campaign_id, 
+				COUNT(1) AS total 
+			FROM form_entries 
+			WHERE 
+				form_entries.deleted = ? 
+				AND form_entries.status <> ? 
+				AND ` + localEntryDate + `::DATE BETWEEN ? AND ?
+			GROUP BY form_entries.campaign_id
+		) entries ON entries.campaign_id = campaigns.id 
+		LEFT JOIN (
+			SELECT 
+				campaign_visits.campaign_id, 
+				SUM(campaign_visits.total) AS total 
+			FROM campaign_visits 
+			WHERE campaign_visits.visit_date BETWEEN ? AND ?
+			GROUP BY campaign_visits.campaign_id
+		) visits ON visits.campaign_id = campaigns.id 
+		WHERE 
+			campaigns.deleted = ? 
+			AND campaigns.workspace_id = ? 
+		ORDER BY total_submit DESC, campaigns.title ASC
+	`
+	args := []any{false, "S4", params.Timezone, params.StartDate, params.EndDate, params.StartDate, params.EndDate, false, workspaceID}
+
+	if err := q.DB.WithContext(ctx).Raw(query, args...).Scan(&data).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// FindTopReviewers counts every review from audit log, form entry keeps its last reviewer only
+func (q *WorkspaceQuery) FindTopReviewers(ctx context.Context, workspaceID string, params *masterschema.AnalyticsParams, limit int) ([]masterschema.WorkspaceTopReviewer, error) {
+	var data []masterschema.WorkspaceTopReviewer
+
+	query := `
+		SELECT 
+			users.id AS user_id, 
+			users.fullname AS user_name, 
+			users.email AS user_email, 
+			COUNT(1) AS total_reviewed, 
+			COUNT(1) FILTER (WHERE audit_logs.after->>'status' = ?) AS total_approved, 
+			COUNT(1) FILTER (WHERE audit_logs.after->>'status' = ?) AS total_rejected 
+		FROM audit_logs 
+		JOIN users ON users.id = audit_logs.actor_id 
+		WHERE 
+			audit_logs.workspace_id = ? 
+			AND audit_logs.entity_type = ? 
+			AND audit_logs.action = ? 
+			AND ((audit_logs.created_at AT TIME ZONE 'UTC') AT TIME ZONE ?)::DATE BETWEEN ? AND ?
+		GROUP BY users.id, users.fullname, users.email 
+		ORDER BY total_reviewed DESC, users.id 
+		LIMIT ?
+	`
+	args := []any{"S2", "S3", workspaceID, "form_entry", "update", params.Timezone, params.StartDate, params.EndDate, limit}
+
+	if err := q.DB.WithContext(ctx).Raw(query, args...).Scan(&data).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -1,37 +1,49 @@
 package storerepo
 
 import (
+	"context"
 	"kiraform/src/applications/models"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
 	storeschema "kiraform/src/interfaces/rest/schemas/stores"
+	"kiraform/src/utils"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type StoreRepository interface {
-	FindStoreByUser(userID string) (*models.Stores, error)
-	FindStoreByKey(key string) (*models.Stores, error)
-	CreateStore(data models.Stores) error
-	CreateStoreUser(data models.StoreUsers) error
-	UpdateStore(ID string, data models.Stores) error
-	FindStoreProductCategories(storeID string, paramns *commonschema.QueryParams) ([]models.StoreProductCategories, error)
-	FindCountStoreProductCategories(storeID string, params *commonschema.QueryParams) (int64, error)
-	FindStoreProductCategory(storeID string, ID string) (*models.StoreProductCategories, error)
-	CreateStoreProductCategory(data models.StoreProductCategories) error
-	UpdateStoreProductCategory(userID string, data models.StoreProductCategories) error
-	FindStoreProducts(storeID string, params *commonschema.QueryParams, category_id *string) ([]models.StoreProducts, error)
-	FindCountStoreProducts(storeID string, params *commonschema.QueryParams, category_id *string) (int64, error)
-	FindCountStoreProductsByCategory(storeID string, storeProductCategoryID string) (int64, error)
-	FindStoreProduct(storeID string, ID string) (*models.StoreProducts, error)
-	CreateProduct(data models.StoreProducts) error
-	CreateProductImages(data []models.StoreProductImages) error
-	FindImagesByProduct(storeProductID string) ([]models.StoreProductImages, error)
-	UpdateStoreProduct(data models.StoreProducts, storeID string, ID string) error
-	DeleteProductImage(storeProductImageID string) error
-	FindStoreProductById(ID string) (*models.StoreProducts, error)
-	FindStoreProductFormEntries(productID string, params *commonschema.QueryParams) ([]storeschema.FormEntrySchema, error)
-	FindCountStoreProductFormEntries(productID string, params *commonschema.QueryParams) (int64, error)
+	FindStoreByUser(ctx context.Context, userID string) (*models.Stores, error)
+	FindStoreByKey(ctx context.Context, key string) (*models.Stores, error)
+	CreateStore(ctx context.Context, data models.Stores) error
+	CreateStoreUser(ctx context.Context, data models.StoreUsers) error
+	UpdateStore(ctx context.Context, ID string, data models.Stores) error
+	FindStoreProductCategories(ctx context.Context, storeID string, paramns *commonschema.QueryParams) ([]models.StoreProductCategories, error)
+	FindCountStoreProductCategories(ctx context.Context, storeID string, params *commonschema.QueryParams) (int64, error)
+	FindStoreProductCategory(ctx context.Context, storeID string, ID string) (*models.StoreProductCategories, error)
+	CreateStoreProductCategory(ctx context.Context, data models.StoreProductCategories) error
+	UpdateStoreProductCategory(ctx context.Context, userID string, data models.StoreProductCategories) error
+	FindStoreProducts(ctx context.Context, storeID string, params *commonschema.QueryParams, category_id *string) ([]models.StoreProducts, error)
+	FindCountStoreProducts(ctx context.Context, storeID string, params *commonschema.QueryParams, category_id *string) (int64, error)
+	FindCountStoreProductsByCategories(ctx context.Context, storeID string, storeProductCategoryIDs []string) (map[string]int64, error)
+	FindStoreProduct(ctx context.Context, storeID string, ID string) (*models.StoreProducts, error)
+	CreateProduct(ctx context.Context, data models.StoreProducts) error
+	CreateProductImages(ctx context.Context, data []models.StoreProductImages) error
+	FindImagesByProduct(ctx context.Context, storeProductID string) ([]models.StoreProductImages, error)
+	FindImagesByProducts(ctx context.Context, storeProductIDs []string) (map[string][]models.StoreProductImages, error)
+	UpdateStoreProduct(ctx context.Context, data models.StoreProducts, storeID string, ID string) error
+	DeleteStoreProduct(ctx context.Context, data models.StoreProducts, storeID string, ID string) error
+	DeleteProductImage(ctx context.Context, storeProductImageID string) error
+	FindStoreProductById(ctx context.Context, ID string) (*models.StoreProducts, error)
+	FindStoreProductFormEntries(ctx context.Context, productID string, params *commonschema.QueryParams) ([]storeschema.FormEntrySchema, error)
+	FindCountStoreProductFormEntries(ctx context.Context, productID string, params *commonschema.QueryParams) (int64, error)
+	FindTrash(ctx context.Context, storeID string, params *commonschema.QueryParams) ([]commonschema.TrashSchema, error)
+	FindCountTrash(ctx context.Context, storeID string, params *commonschema.QueryParams) (int64, error)
+	FindDeletedStoreProductCategory(ctx context.Context, storeID string, ID string) (*models.StoreProductCategories, error)
+	FindDeletedStoreProduct(ctx context.Context, storeID string, ID string) (*models.StoreProducts, error)
+	RestoreStoreProductCategory(ctx context.Context, ID string) error
+	RestoreStoreProduct(ctx context.Context, ID string, deletedAt *time.Time) error
+	PurgeTrash(ctx context.Context, before time.Time) ([]string, error)
 }
 
 type StoreQuery struct {
@@ -42,9 +54,9 @@ func NewStoreRepository(DB *gorm.DB) *StoreQuery {
 	return &StoreQuery{DB: DB}
 }
 
-func (q *StoreQuery) FindStoreByUser(userID string) (*models.Stores, error) {
+func (q *StoreQuery) FindStoreByUser(ctx context.Context, userID string) (*models.Stores, error) {
 	var store models.Stores
-	if err := q.DB.Model(&models.Stores{}).
+	if err := q.DB.WithContext(ctx).Model(&models.Stores{}).
 		Where("stores.deleted = ? AND store_users.deleted = ? AND store_users.user_id = ?", false, false, userID).
 		Joins("LEFT JOIN store_users ON stores.id = store_users.store_id AND store_users.deleted = ?", false).
 		First(&store).Error; err != nil {
@@ -53,9 +65,9 @@ func (q *StoreQuery) FindStoreByUser(userID string) (*models.Stores, error) {
 	return &store, nil
 }
 
-func (q *StoreQuery) FindStoreByKey(key string) (*models.Stores, error) {
+func (q *StoreQuery) FindStoreByKey(ctx context.Context, key string) (*models.Stores, error) {
 	var store models.Stores
-	if err := q.DB.Model(&models.Stores{}).
+	if err := q.DB.WithContext(ctx).Model(&models.Stores{}).
 		Where("stores.deleted = ? AND stores.key = ?", false, key).
 		First(&store).Error; err != nil {
 		return nil, err
@@ -63,40 +75,43 @@ func (q *StoreQuery) FindStoreByKey(key string) (*models.Stores, error) {
 	return &store, nil
 }
 
-func (q *StoreQuery) CreateStore(data models.Stores) error {
-	if err := q.DB.Model(&models.Stores{}).Create(&data).Error; err != nil {
+func (q *StoreQuery) CreateStore(ctx context.Context, data models.Stores) error {
+	if err := q.DB.WithContext(ctx).Model(&models.Stores{}).Create(&data).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *StoreQuery) CreateStoreUser(data models.StoreUsers) error {
-	if err := q.DB.Model(&models.StoreUsers{}).Create(&data).Error; err != nil {
+func (q *StoreQuery) CreateStoreUser(ctx context.Context, data models.StoreUsers) error {
+	if err := q.DB.WithContext(ctx).Model(&models.StoreUsers{}).Create(&data).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *StoreQuery) UpdateStore(ID string, data models.Stores) error {
-	if err := q.DB.Model(&models.Stores{}).Where("id = ? AND deleted = ?", ID, false).Updates(&data).Error; err != nil {
+func (q *StoreQuery) UpdateStore(ctx context.Context, ID string, data models.Stores) error {
+	if err := q.DB.WithContext(ctx).Model(&models.Stores{}).Where("id = ? AND deleted = ?", ID, false).Updates(&data).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *StoreQuery) FindStoreProductCategories(storeID string, params *commonschema.QueryParams) ([]models.StoreProductCategories, error) {
+func (q *StoreQuery) FindStoreProductCategories(ctx context.Context, storeID string, params *commonschema.QueryParams) ([]models.StoreProductCategories, error) {
 	var data []models.StoreProductCategories
 
 	// init statement
-	st := q.DB.Model(&models.StoreProductCategories{}).Where("deleted = ? AND store_id = ?", false, storeID)
+	st := q.DB.WithContext(ctx).Model(&models.StoreProductCategories{}).Where("deleted = ? AND store_id = ?", false, storeID)
 
 	// handle search condition
 	if params != nil && params.Search != "" {
 		st = st.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
 	}
 
-	// handle order
-	st = st.Order("created_at DESC")
+	// handle filter and order
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
+	}
+	st = st.Order(utils.OrderClause(params, "store_product_categories.created_at DESC", "store_product_categories.id"))
 
 	// handle pagination
 	if params != nil {
@@ -114,17 +129,22 @@ func (q *StoreQuery) FindStoreProductCategories(storeID string, params *commonsc
 	return data, nil
 }
 
-func (q *StoreQuery) FindCountStoreProductCategories(storeID string, params *commonschema.QueryParams) (int64, error) {
+func (q *StoreQuery) FindCountStoreProductCategories(ctx context.Context, storeID string, params *commonschema.QueryParams) (int64, error) {
 	var count int64
 
 	// init statement
-	st := q.DB.Model(&models.StoreProductCategories{}).Where("deleted = ? AND store_id = ?", false, storeID)
+	st := q.DB.WithContext(ctx).Model(&models.StoreProductCategories{}).Where("deleted = ? AND store_id = ?", false, storeID)
 
 	// handle search condition
 	if params != nil && params.Search != "" {
 		st = st.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
 	}
 
+	// handle filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
+	}
+
 	// perform to get data
 	if err := st.Count(&count).Error; err != nil {
 		return 0, err
@@ -132,33 +152,33 @@ func (q *StoreQuery) FindCountStoreProductCategories(storeID string, params *com
 	return count, nil
 }
 
-func (q *StoreQuery) FindStoreProductCategory(storeID string, ID string) (*models.StoreProductCategories, error) {
+func (q *StoreQuery) FindStoreProductCategory(ctx context.Context, storeID string, ID string) (*models.StoreProductCategories, error) {
 	var data models.StoreProductCategories
-	if err := q.DB.Model(&models.StoreProductCategories{}).Where("store_id = ? AND id = ? AND deleted = ?", storeID, ID, false).First(&data).Error; err != nil {
+	if err := q.DB.WithContext(ctx).Model(&models.StoreProductCategories{}).Where("store_id = ? AND id = ? AND deleted = ?", storeID, ID, false).First(&data).Error; err != nil {
 		return nil, err
 	}
 	return &data, nil
 }
 
-func (q *StoreQuery) CreateStoreProductCategory(data models.StoreProductCategories) error {
-	if err := q.DB.Model(&models.StoreProductCategories{}).Create(&data).Error; err != nil {
+func (q *StoreQuery) CreateStoreProductCategory(ctx context.Context, data models.StoreProductCategories) error {
+	if err := q.DB.WithContext(ctx).Model(&models.StoreProductCategories{}).Create(&data).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *StoreQuery) UpdateStoreProductCategory(ID string, data models.StoreProductCategories) error {
-	if err := q.DB.Model(&models.StoreProductCategories{}).Where("id = ?", ID).Updates(&data).Error; err != nil {
+func (q *StoreQuery) UpdateStoreProductCategory(ctx context.Context, ID string, data models.StoreProductCategories) error {
+	if err := q.DB.WithContext(ctx).Model(&models.StoreProductCategories{}).Where("id = ?", ID).Updates(&data).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *StoreQuery) FindStoreProducts(storeID string, params *commonschema.QueryParams, category_id *string) ([]models.StoreProducts, error) {
+func (q *StoreQuery) FindStoreProducts(ctx context.Context, storeID string, params *commonschema.QueryParams, category_id *string) ([]models.StoreProducts, error) {
 	var data []models.StoreProducts
 
 	// init statement
-	st := q.DB.Model(&models.StoreProducts{}).Where("store_products.deleted = ? AND store_products.store_id = ?", false, storeID).
+	st := q.DB.WithContext(ctx).Model(&models.StoreProducts{}).Where("store_products.deleted = ? AND store_products.store_id = ?", false, storeID).
 		Preload("Store").
 		Preload("Category").
 		Preload("Campaign")
@@ -173,16 +193,26 @@ func (q *StoreQuery) FindStoreProducts(storeID string, params *commonschema.Quer
 		st = st.Where("LOWER(store_products.name) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
 	}
 
-	// handle order
-	st = st.Order("store_products.created_at DESC")
+	// handle filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
+	}
 
-	// handle pagination
-	if params != nil {
-		offset := 0
-		if params.Limit > 0 && params.Page > 0 {
-			offset = (params.Limit * params.Page) - params.Limit
+	// handle pagination, cursor pagination uses keyset with one extra row to detect next page
+	if condition, args, order := utils.CursorClause(params, "store_products.created_at", "store_products.id"); order != "" {
+		if condition != "" {
+			st = st.Where(condition, args...)
+		}
+		st = st.Order(order).Limit(params.Limit + 1)
+	} else {
+		st = st.Order(utils.OrderClause(params, "store_products.created_at DESC", "store_products.id"))
+		if params != nil {
+			offset := 0
+			if params.Limit > 0 && params.Page > 0 {
+				offset = (params.Limit * params.Page) - params.Limit
+			}
+			st = st.Limit(params.Limit).Offset(offset)
 		}
-		st = st.Limit(params.Limit).Offset(offset)
 	}
 
 	// perform to get data
@@ -192,11 +222,11 @@ func (q *StoreQuery) FindStoreProducts(storeID string, params *commonschema.Quer
 	return data, nil
 }
 
-func (q *StoreQuery) FindCountStoreProducts(storeID string, params *commonschema.QueryParams, category_id *string) (int64, error) {
+func (q *StoreQuery) FindCountStoreProducts(ctx context.Context, storeID string, params *commonschema.QueryParams, category_id *string) (int64, error) {
 	var count int64
 
 	// init statement
-	st := q.DB.Model(&models.StoreProducts{}).Where("deleted = ? AND store_id = ?", false, storeID)
+	st := q.DB.WithContext(ctx).Model(&models.StoreProducts{}).Where("deleted = ? AND store_id = ?", false, storeID)
 
 	// handle category filter
 	if category_id != nil && *category_id != "" {
@@ -208,6 +238,11 @@ func (q *StoreQuery) FindCountStoreProducts(storeID string, params *commonschema
 		st = st.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
 	}
 
+	// handle filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
+	}
+
 	// perform to get data
 	if err := st.Count(&count).Error; err != nil {
 		return 0, err
@@ -215,17 +250,27 @@ func (q *StoreQuery) FindCountStoreProducts(storeID string, params *commonschema
 	return count, nil
 }
 
-func (q *StoreQuery) FindCountStoreProductsByCategory(storeID string, storeProductCategoryID string) (int64, error) {
-	var count int64
-	if err := q.DB.Model(&models.StoreProducts{}).Where("deleted = ? AND store_id = ? AND category_id = ?", false, storeID, storeProductCategoryID).Count(&count).Error; err != nil {
-		return 0, err
+// FindCountStoreProductsByCategories counts products of many categories in one query
+func (q *StoreQuery) FindCountStoreProductsByCategories(ctx context.Context, storeID string, storeProductCategoryIDs []string) (map[string]int64, error) {
+	var rows []commonschema.CountByID
+	if len(storeProductCategoryIDs) == 0 {
+		return map[string]int64{}, nil
 	}
-	return count, nil
+
+	err := q.DB.WithContext(ctx).Model(&models.StoreProducts{}).
+		Select("category_id AS id", "COUNT(1) AS total").
+		Where("deleted = ? AND store_id = ? AND category_id IN ?", false, storeID, storeProductCategoryIDs).
+		Group("category_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return commonschema.CountMap(rows), nil
 }
 
-func (q *StoreQuery) FindStoreProduct(storeID string, ID string) (*models.StoreProducts, error) {
+func (q *StoreQuery) FindStoreProduct(ctx context.Context, storeID string, ID string) (*models.StoreProducts, error) {
 	var data models.StoreProducts
-	if err := q.DB.Model(&models.StoreProducts{}).
+	if err := q.DB.WithContext(ctx).Model(&models.StoreProducts{}).
 		Preload("Store").
 		Preload("Category").
 		Preload("Campaign").
@@ -236,23 +281,23 @@ func (q *StoreQuery) FindStoreProduct(storeID string, ID string) (*models.StoreP
 	return &data, nil
 }
 
-func (q *StoreQuery) CreateProduct(data models.StoreProducts) error {
-	if err := q.DB.Model(&models.StoreProducts{}).Create(&data).Error; err != nil {
+func (q *StoreQuery) CreateProduct(ctx context.Context, data models.StoreProducts) error {
+	if err := q.DB.WithContext(ctx).Model(&models.StoreProducts{}).Create(&data).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *StoreQuery) CreateProductImages(data []models.StoreProductImages) error {
-	if err := q.DB.Model(&models.StoreProductImages{}).Create(&data).Error; err != nil {
+func (q *StoreQuery) CreateProductImages(ctx context.Context, data []models.StoreProductImages) error {
+	if err := q.DB.WithContext(ctx).Model(&models.StoreProductImages{}).Create(&data).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *StoreQuery) FindImagesByProduct(storeProductID string) ([]models.StoreProductImages, error) {
+func (q *StoreQuery) FindImagesByProduct(ctx context.Context, storeProductID string) ([]models.StoreProductImages, error) {
 	var images []models.StoreProductImages
-	if err := q.DB.Model(&models.StoreProductImages{}).
+	if err := q.DB.WithContext(ctx).Model(&models.StoreProductImages{}).
 		Where("deleted = ? AND store_product_id = ?", false, storeProductID).
 		Order("created_at ASC").Find(&images).Error; err != nil {
 		return nil, err
@@ -260,15 +305,52 @@ func (q *StoreQuery) FindImagesByProduct(storeProductID string) ([]models.StoreP
 	return images, nil
 }
 
-func (q *StoreQuery) UpdateStoreProduct(data models.StoreProducts, storeID string, ID string) error {
-	if err := q.DB.Model(&models.StoreProducts{}).Where("deleted = ? AND store_id = ? AND id = ?", false, storeID, ID).Updates(&data).Error; err != nil {
+// FindImagesByProducts loads images of many products in one query, grouped by product id
+func (q *StoreQuery) FindImagesByProducts(ctx context.Context, storeProductIDs []string) (map[string][]models.StoreProductImages, error) {
+	var images []models.StoreProductImages
+	data := map[string][]models.StoreProductImages{}
+	if len(storeProductIDs) == 0 {
+		return data, nil
+	}
+
+	if err := q.DB.WithContext(ctx).Model(&models.StoreProductImages{}).
+		Where("deleted = ? AND store_product_id IN ?", false, storeProductIDs).
+		Order("created_at ASC").Find(&images).Error; err != nil {
+		return nil, err
+	}
+	for _, v := range images {
+		key := v.StoreProductID.String()
+		data[key] = append(data[key], v)
+	}
+	return data, nil
+}
+
+func (q *StoreQuery) UpdateStoreProduct(ctx context.Context, data models.StoreProducts, storeID string, ID string) error {
+	if err := q.DB.WithContext(ctx).Model(&models.StoreProducts{}).Where("deleted = ? AND store_id = ? AND id = ?", false, storeID, ID).Updates(&data).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (q *StoreQuery) DeleteProductImage(storeProductImageID string) error {
-	if err := q.DB.Model(&models.StoreProductImages{}).
+// DeleteStoreProduct flags product with its images as deleted,
+// images share deleted_at of product so they can be restored together
+func (q *StoreQuery) DeleteStoreProduct(ctx context.Context, data models.StoreProducts, storeID string, ID string) error {
+	err := q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.StoreProducts{}).Where("deleted = ? AND store_id = ? AND id = ?", false, storeID, ID).Updates(&data).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.StoreProductImages{}).
+			Where("deleted = ? AND store_product_id = ?", false, ID).
+			Updates(models.StoreProductImages{Deleted: true, DeletedAt: data.DeletedAt, UpdatedAt: data.UpdatedAt}).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+	return err
+}
+
+func (q *StoreQuery) DeleteProductImage(ctx context.Context, storeProductImageID string) error {
+	if err := q.DB.WithContext(ctx).Model(&models.StoreProductImages{}).
 		Where("deleted = ? AND id = ?", false, storeProductImageID).
 		Delete(&models.StoreProductImages{}).Error; err != nil {
 		return err
@@ -276,9 +358,9 @@ func (q *StoreQuery) DeleteProductImage(storeProductImageID string) error {
 	return nil
 }
 
-func (q *StoreQuery) FindStoreProductById(ID string) (*models.StoreProducts, error) {
+func (q *StoreQuery) FindStoreProductById(ctx context.Context, ID string) (*models.StoreProducts, error) {
 	var data models.StoreProducts
-	if err := q.DB.Model(&models.StoreProducts{}).
+	if err := q.DB.WithContext(ctx).Model(&models.StoreProducts{}).
 		Where("store_products.deleted = ? AND store_products.id = ?", false, ID).
 		Preload("Store").
 		Preload("Category").
@@ -289,11 +371,11 @@ func (q *StoreQuery) FindStoreProductById(ID string) (*models.StoreProducts, err
 	return &data, nil
 }
 
-func (q *StoreQuery) FindStoreProductFormEntries(productID string, params *commonschema.QueryParams) ([]storeschema.FormEntrySchema, error) {
+func (q *StoreQuery) FindStoreProductFormEntries(ctx context.Context, productID string, params *commonschema.QueryParams) ([]storeschema.FormEntrySchema, error) {
 	var data []storeschema.FormEntrySchema
 
 	// init statement
-	st := q.DB.Model(&models.FormEntries{}).
+	st := q.DB.WithContext(ctx).Model(&models.FormEntries{}).
 		Where("form_entries.deleted = ? AND form_entries.product_id = ?", false, productID).
 		Select("form_entries.*, campaigns.workspace_id, campaigns.title AS campaign_title, campaigns.description AS campaign_description, workspaces.title AS workspace_title, workspaces.description AS workspace_description").
 		Joins("LEFT JOIN campaigns ON campaigns.id = form_entries.campaign_id").
@@ -304,12 +386,17 @@ func (q *StoreQuery) FindStoreProductFormEntries(productID string, params *commo
 		st = st.Where("LOWER(campaigns.title) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
 	}
 
+	// handle filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
+	}
+
 	// handle pagination
 	offset := 0
 	if params.Limit > 0 && params.Page > 0 {
 		offset = (params.Limit * params.Page) - params.Limit
 	}
-	st = st.Order("created_at DESC")
+	st = st.Order(utils.OrderClause(params, "form_entries.created_at DESC", "form_entries.id"))
 	st = st.Limit(params.Limit).Offset(offset)
 
 	// perform to get data
@@ -319,11 +406,11 @@ func (q *StoreQuery) FindStoreProductFormEntries(productID string, params *commo
 	return data, nil
 }
 
-func (q *StoreQuery) FindCountStoreProductFormEntries(productID string, params *commonschema.QueryParams) (int64, error) {
+func (q *StoreQuery) FindCountStoreProductFormEntries(ctx context.Context, productID string, params *commonschema.QueryParams) (int64, error) {
 	var count int64
 
 	// init statement
-	st := q.DB.Model(&models.FormEntries{}).
+	st := q.DB.WithContext(ctx).Model(&models.FormEntries{}).
 		Where("form_entries.deleted = ? AND form_entries.product_id = ?", false, productID).
 		Joins("LEFT JOIN campaigns ON campaigns.id = form_entries.campaign_id")
 
@@ -332,9 +419,166 @@ func (q *StoreQuery) FindCountStoreProductFormEntries(productID string, params *
 		st = st.Where("LOWER(campaigns.title) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
 	}
 
+	// handle filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
+	}
+
 	// perform to get data
 	if err := st.Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
+
+// trashUnion lists deleted categories and products of active categories in store,
+// deleted_at falls back to updated_at for data deleted before deleted_at is recorded
+func (q *StoreQuery) trashUnion(ctx context.Context, storeID string) *gorm.DB {
+	categories := q.DB.WithContext(ctx).Model(&models.StoreProductCategories{}).
+		Select("store_product_categories.id, 'product_category' AS entity_type, store_product_categories.name AS title, NULL::UUID AS parent_id, COALESCE(store_product_categories.deleted_at, store_product_categories.updated_at, store_product_categories.created_at) AS deleted_at, store_product_categories.deleted_by").
+		Where("store_product_categories.deleted = ? AND store_product_categories.store_id = ?", true, storeID)
+	products := q.DB.WithContext(ctx).Model(&models.StoreProducts{}).
+		Select("store_products.id, 'product' AS entity_type, store_products.name AS title, store_products.category_id AS parent_id, COALESCE(store_products.deleted_at, store_products.updated_at, store_products.created_at) AS deleted_at, store_products.deleted_by").
+		Where("store_products.deleted = ? AND store_products.store_id = ?", true, storeID)
+	return q.DB.WithContext(ctx).Raw("(?) UNION ALL (?)", categories, products)
+}
+
+// trashStatement wraps list of deleted items, then adds actor name, search and filter
+func (q *StoreQuery) trashStatement(ctx context.Context, storeID string, params *commonschema.QueryParams) *gorm.DB {
+	st := q.DB.WithContext(ctx).Table("(?) AS trash", q.trashUnion(ctx, storeID)).Joins("LEFT JOIN users ON users.id = trash.deleted_by")
+
+	// handle search condition
+	if params.Search != "" {
+		st = st.Where("LOWER(trash.title) LIKE ?", "%"+strings.ToLower(params.Search)+"%")
+	}
+
+	// handle filter condition
+	if condition, args := utils.FilterClause(params); condition != "" {
+		st = st.Where(condition, args...)
+	}
+	return st
+}
+
+func (q *StoreQuery) FindTrash(ctx context.Context, storeID string, params *commonschema.QueryParams) ([]commonschema.TrashSchema, error) {
+	var data []commonschema.TrashSchema
+
+	// init statement
+	st := q.trashStatement(ctx, storeID, params).Select("trash.*, users.fullname AS deleted_by_name")
+
+	// handle pagination, cursor pagination uses keyset with one extra row to detect next page
+	if condition, args, order := utils.CursorClause(params, "trash.deleted_at", "trash.id"); order != "" {
+		if condition != "" {
+			st = st.Where(condition, args...)
+		}
+		st = st.Order(order).Limit(params.Limit + 1)
+	} else {
+		offset := 0
+		if params.Limit > 0 && params.Page > 0 {
+			offset = (params.Limit * params.Page) - params.Limit
+		}
+		st = st.Order(utils.OrderClause(params, "trash.deleted_at DESC", "trash.id")).Limit(params.Limit).Offset(offset)
+	}
+
+	// perform to get data
+	if err := st.Scan(&data).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (q *StoreQuery) FindCountTrash(ctx context.Context, storeID string, params *commonschema.QueryParams) (int64, error) {
+	var count int64
+	if err := q.trashStatement(ctx, storeID, params).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (q *StoreQuery) FindDeletedStoreProductCategory(ctx context.Context, storeID string, ID string) (*models.StoreProductCategories, error) {
+	var data models.StoreProductCategories
+	if err := q.DB.WithContext(ctx).Model(&models.StoreProductCategories{}).Where("deleted = ? AND store_id = ? AND id = ?", true, storeID, ID).First(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (q *StoreQuery) FindDeletedStoreProduct(ctx context.Context, storeID string, ID string) (*models.StoreProducts, error) {
+	var data models.StoreProducts
+	if err := q.DB.WithContext(ctx).Model(&models.StoreProducts{}).Where("deleted = ? AND store_id = ? AND id = ?", true, storeID, ID).First(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (q *StoreQuery) RestoreStoreProductCategory(ctx context.Context, ID string) error {
+	values := map[string]any{"deleted": false, "deleted_at": nil, "deleted_by": nil, "updated_at": time.Now()}
+	if err := q.DB.WithContext(ctx).Model(&models.StoreProductCategories{}).Where("deleted = ? AND id = ?", true, ID).Updates(values).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// RestoreStoreProduct brings back product with images deleted together with it,
+// struct Updates skips false value so map is used
+func (q *StoreQuery) RestoreStoreProduct(ctx context.Context, ID string, deletedAt *time.Time) error {
+	err := q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if deletedAt != nil {
+			if err := tx.Model(&models.StoreProductImages{}).
+				Where("deleted = ? AND store_product_id = ? AND deleted_at = ?", true, ID, deletedAt).
+				Updates(map[string]any{"deleted": false, "deleted_at": nil, "updated_at": now}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.StoreProducts{}).
+			Where("deleted = ? AND id = ?", true, ID).
+			Updates(map[string]any{"deleted": false, "deleted_at": nil, "deleted_by": nil, "updated_at": now}).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+	return err
+}
+
+// PurgeTrash permanently deletes products and categories deleted before given time,
+// then returns image paths to be removed from disk
+func (q *StoreQuery) PurgeTrash(ctx context.Context, before time.Time) ([]string, error) {
+	var files []string
+	err := q.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// only rows with recorded deleted_at expire, legacy rows are backfilled by migration
+		expired := "deleted = ? AND deleted_at IS NOT NULL AND deleted_at < ?"
+
+		// collect expired products and their images
+		var productIDs []string
+		if err := tx.Model(&models.StoreProducts{}).Where(expired, true, before).Pluck("id", &productIDs).Error; err != nil {
+			return err
+		}
+		if len(productIDs) > 0 {
+			if err := tx.Model(&models.StoreProductImages{}).Where("store_product_id IN ?", productIDs).Pluck("file_name", &files).Error; err != nil {
+				return err
+			}
+
+			// entries stay in their campaign, only the link to product is removed
+			if err := tx.Model(&models.FormEntries{}).Where("product_id IN ?", productIDs).Update("product_id", nil).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("store_product_id IN ?", productIDs).Delete(&models.StoreProductImages{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("id IN ?", productIDs).Delete(&models.StoreProducts{}).Error; err != nil {
+				return err
+			}
+		}
+
+		// category is kept while any product still refers to it
+		if err := tx.Where(expired+" AND NOT EXISTS (SELECT 1 FROM store_products WHERE store_products.category_id = store_product_categories.id)", true, before).
+			Delete(&models.StoreProductCategories{}).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
@@ -0,0 +1,230 @@
+package sessions
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/applications/models"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/infras/mailer"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// login methods recorded in sessions and login history, sso is recorded as "sso:<provider>"
+const (
+	MethodPassword  = "password"
+	MethodEmailCode = "email_code"
+	MethodMagicLink = "magic_link"
+	MethodSSO       = "sso:"
+)
+
+// Attempt is failed login, UserID is nil when email is unknown
+type Attempt struct {
+	Email  string
+	UserID *uuid.UUID
+	Method string
+	Reason string
+}
+
+// Tracker records login history and sessions of access tokens, a revoked session stops its token
+type Tracker struct {
+	sessionRepo masterrepo.SessionRepository
+	historyRepo masterrepo.LoginHistoryRepository
+	userRepo    masterrepo.UserRepository
+	mailer      mailer.Mailer
+	issuer      string
+}
+
+func NewTracker(sessionRepo masterrepo.SessionRepository, historyRepo masterrepo.LoginHistoryRepository, userRepo masterrepo.UserRepository, mail mailer.Mailer, issuer string) *Tracker {
+	return &Tracker{
+		sessionRepo: sessionRepo,
+		historyRepo: historyRepo,
+		userRepo:    userRepo,
+		mailer:      mail,
+		issuer:      issuer,
+	}
+}
+
+// Failed records failed login, failing here is only logged so the client still gets the login error
+func (t *Tracker) Failed(ctx context.Context, actor commonschema.Actor, attempt Attempt) {
+	ctx = context.WithoutCancel(ctx)
+	data := models.LoginHistories{
+		ID:         uuid.New(),
+		UserID:     attempt.UserID,
+		Email:      strings.ToLower(attempt.Email),
+		Method:     attempt.Method,
+		Success:    false,
+		Reason:     attempt.Reason,
+		IP:         actor.IP,
+		UserAgent:  actor.UserAgent,
+		DeviceHash: deviceHash(actor.UserAgent),
+		CreatedAt:  time.Now(),
+	}
+	if err := t.historyRepo.CreateLoginHistory(ctx, data); err != nil {
+		slog.ErrorContext(ctx, "failed to record login history", "error", err)
+	}
+}
+
+// Start opens session of access token which expires at expiresAt, records the login and
+// notifies user when it comes from a device not seen before
+func (t *Tracker) Start(ctx context.Context, actor commonschema.Actor, user *models.Users, method string, expiresAt time.Time) (string, error) {
+	now := time.Now()
+	session := models.UserSessions{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		Method:     method,
+		IP:         actor.IP,
+		UserAgent:  actor.UserAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt,
+	}
+	if err := t.sessionRepo.CreateSession(ctx, session); err != nil {
+		return "", err
+	}
+
+	// first login of user is not a new device, there is nothing to compare with
+	device := deviceHash(actor.UserAgent)
+	anyLogin, known, err := t.historyRepo.FindKnownDevice(ctx, user.ID.String(), device)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to check known device", "error", err)
+		known = true
+	}
+	newDevice := anyLogin && !known
+
+	data := models.LoginHistories{
+		ID:         uuid.New(),
+		UserID:     &user.ID,
+		Email:      strings.ToLower(user.Email),
+		Method:     method,
+		Success:    true,
+		IP:         actor.IP,
+		UserAgent:  actor.UserAgent,
+		DeviceHash: device,
+		NewDevice:  newDevice,
+		SessionID:  &session.ID,
+		CreatedAt:  now,
+	}
+	if err := t.historyRepo.CreateLoginHistory(ctx, data); err != nil {
+		slog.ErrorContext(ctx, "failed to record login history", "error", err)
+	}
+
+	if newDevice {
+		t.notifyNewDevice(ctx, actor, user, method, now)
+	}
+	return session.ID.String(), nil
+}
+
+// Verify checks session of access token is not revoked or expired and its user is still active,
+// token without session can not be revoked, so it is refused
+func (t *Tracker) Verify(ctx context.Context, sessionID string, userID string) error {
+	// user deactivated or deleted outside of the api has no revoked session, so it is checked on every token
+	if err := t.verifyUser(ctx, userID); err != nil {
+		return err
+	}
+	if sessionID == "" {
+		return apperrors.Unauthorized("your session is outdated, please login again")
+	}
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return apperrors.Unauthorized("your session is invalid, please login again")
+	}
+	now := time.Now()
+	session, err := t.sessionRepo.FindActiveSession(ctx, sessionID, now)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.Unauthorized("your session is revoked or expired, please login again")
+		}
+		return err
+	}
+	if session.UserID.String() != userID {
+		return apperrors.Unauthorized("your session is invalid, please login again")
+	}
+	if err := t.sessionRepo.TouchSession(ctx, sessionID, now); err != nil {
+		slog.ErrorContext(ctx, "failed to update last seen of session", "error", err)
+	}
+	return nil
+}
+
+// RevokeAll signs user out of every device, used when account is deactivated or its access is changed
+func (t *Tracker) RevokeAll(ctx context.Context, userID string) error {
+	count, err := t.sessionRepo.RevokeOtherSessions(ctx, userID, "", time.Now())
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "sessions revoked", "user_id", userID, "count", count)
+	return nil
+}
+
+// RevokeRole signs out every holder of role whose permissions are changed
+func (t *Tracker) RevokeRole(ctx context.Context, roleID string) error {
+	count, err := t.sessionRepo.RevokeRoleSessions(ctx, roleID, time.Now())
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "sessions revoked", "role_id", roleID, "count", count)
+	return nil
+}
+
+// VerifyLegacy checks only user of legacy token issued before sessions were recorded,
+// keyring accepts such token until the legacy cut-off
+func (t *Tracker) VerifyLegacy(ctx context.Context, userID string) error {
+	return t.verifyUser(ctx, userID)
+}
+
+func (t *Tracker) verifyUser(ctx context.Context, userID string) error {
+	if _, err := uuid.Parse(userID); err != nil {
+		return apperrors.Unauthorized("your identity is not recognized, please login again")
+	}
+	user, err := t.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.Unauthorized("your identity is not recognized, please login again")
+		}
+		return err
+	}
+	if !user.IsActive || user.Deleted {
+		return apperrors.Unauthorized("your account is deactivated, please contact admin")
+	}
+	return nil
+}
+
+// notifyNewDevice emails user about login from new device, failing here does not fail the login
+func (t *Tracker) notifyNewDevice(ctx context.Context, actor commonschema.Actor, user *models.Users, method string, at time.Time) {
+	device := actor.UserAgent
+	if device == "" {
+		device = "unknown device"
+	}
+	body := strings.Join([]string{
+		fmt.Sprintf("Your %s account was just signed in from a new device.", t.issuer),
+		"",
+		"Time: " + at.UTC().Format(time.RFC1123),
+		"IP address: " + actor.IP,
+		"Device: " + device,
+		"Method: " + method,
+		"",
+		"If this was you, you can ignore this email.",
+		"If not, revoke the session under your account sessions and change your password.",
+	}, "\n")
+	if err := t.mailer.Send(context.WithoutCancel(ctx), mailer.Message{
+		To:      user.Email,
+		Subject: "New sign-in to your " + t.issuer + " account",
+		Body:    body,
+	}); err != nil {
+		slog.ErrorContext(ctx, "failed to notify new device", "user_id", user.ID, "error", err)
+	}
+}
+
+// deviceHash tells device apart by its user agent, raw user agent is kept only for display
+func deviceHash(userAgent string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(userAgent)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,264 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kiraform/src/infras/configs"
+	"kiraform/src/utils"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// StateCookie keeps state, nonce and PKCE verifier of the login between redirect and callback
+	StateCookie = "kiraform_oidc"
+
+	// StateTTL is how long user may stay on provider page before login has to be started again
+	StateTTL = 10 * time.Minute
+
+	// discoveryTimeout limits every request to provider, discovery, token exchange and keys
+	discoveryTimeout = 10 * time.Second
+)
+
+var (
+	ErrUnknownProvider = errors.New("sso provider is not configured")
+	ErrInvalidState    = errors.New("sso login session is expired or invalid")
+)
+
+// Identity is user of external provider, email is trusted only when EmailVerified
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// state is sealed into cookie, so no server storage is needed between redirect and callback
+type state struct {
+	Provider  string `json:"provider"`
+	State     string `json:"state"`
+	Nonce     string `json:"nonce"`
+	Verifier  string `json:"verifier"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// client is discovered provider, discovery is done on first use so unreachable provider
+// does not stop the server from starting
+type client struct {
+	config   configs.OIDCProvider
+	oauth    *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// Registry runs authorization code flow with PKCE against configured OIDC providers
+type Registry struct {
+	config configs.OIDCConfig
+	box    *utils.SecretBox
+
+	// HTTPClient reaches providers and Now is the clock of state and id token expiry,
+	// both can be replaced before the first login, e.g. by tests
+	HTTPClient *http.Client
+	Now        func() time.Time
+
+	mu      sync.Mutex
+	clients map[string]*client
+}
+
+// New creates registry, state cookie is encrypted by secretKey
+func New(config configs.OIDCConfig, secretKey string) (*Registry, error) {
+	box, err := utils.NewSecretBox(secretKey)
+	if err != nil {
+		return nil, err
+	}
+	// providers are copied, SetIssuer must not change config of the caller
+	config.Providers = slices.Clone(config.Providers)
+	return &Registry{
+		config:     config,
+		box:        box,
+		HTTPClient: &http.Client{Timeout: discoveryTimeout},
+		Now:        time.Now,
+		clients:    map[string]*client{},
+	}, nil
+}
+
+// SetIssuer points provider at another issuer, e.g. preset provider at a test server,
+// provider is discovered again on next login
+func (r *Registry) SetIssuer(name string, issuer string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.config.Providers {
+		if r.config.Providers[i].Name == name {
+			r.config.Providers[i].Issuer = issuer
+			delete(r.clients, name)
+			return nil
+		}
+	}
+	return ErrUnknownProvider
+}
+
+// Providers returns name of configured providers in configured order
+func (r *Registry) Providers() []string {
+	names := []string{}
+	for _, v := range r.config.Providers {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+// FrontendURL is where browser is sent after callback
+func (r *Registry) FrontendURL() string {
+	return r.config.FrontendURL
+}
+
+// SecureCookie tells state cookie must be sent only over https
+func (r *Registry) SecureCookie() bool {
+	return strings.HasPrefix(r.config.BaseURL, "https://")
+}
+
+// Start returns url of provider login page and sealed state to be kept in StateCookie
+func (r *Registry) Start(ctx context.Context, name string) (string, string, error) {
+	c, err := r.client(ctx, name)
+	if err != nil {
+		return "", "", err
+	}
+
+	data := state{
+		Provider:  name,
+		State:     randomString(),
+		Nonce:     randomString(),
+		Verifier:  oauth2.GenerateVerifier(),
+		ExpiresAt: r.Now().Add(StateTTL).Unix(),
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", "", err
+	}
+	sealed, err := r.box.Seal(raw)
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL := c.oauth.AuthCodeURL(data.State, oauth2.S256ChallengeOption(data.Verifier), oidc.Nonce(data.Nonce))
+	return authURL, sealed, nil
+}
+
+// Finish exchanges code of callback and verifies id token, sealed is value of StateCookie
+func (r *Registry) Finish(ctx context.Context, name string, code string, callbackState string, sealed string) (*Identity, error) {
+	c, err := r.client(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// state must belong to this browser, this provider and be fresh
+	raw, err := r.box.Open(sealed)
+	if err != nil {
+		return nil, ErrInvalidState
+	}
+	var data state
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, ErrInvalidState
+	}
+	if data.Provider != name || data.State == "" || data.State != callbackState || r.Now().Unix() > data.ExpiresAt {
+		return nil, ErrInvalidState
+	}
+
+	ctx = oidc.ClientContext(ctx, r.HTTPClient)
+	token, err := c.oauth.Exchange(ctx, code, oauth2.VerifierOption(data.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("provider did not return id token")
+	}
+	idToken, err := c.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify id token: %w", err)
+	}
+	if idToken.Nonce != data.Nonce {
+		return nil, errors.New("nonce of id token does not match")
+	}
+
+	// some providers send email_verified as string
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified any    `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+	verified := c.config.TrustEmail
+	switch v := claims.EmailVerified.(type) {
+	case bool:
+		verified = verified || v
+	case string:
+		verified = verified || v == "true"
+	}
+
+	return &Identity{
+		Provider:      name,
+		Subject:       idToken.Subject,
+		Email:         strings.ToLower(strings.TrimSpace(claims.Email)),
+		EmailVerified: verified && claims.Email != "",
+		Name:          strings.TrimSpace(claims.Name),
+	}, nil
+}
+
+// client returns discovered provider, failed discovery is retried on next login
+func (r *Registry) client(ctx context.Context, name string) (*client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if c, ok := r.clients[name]; ok {
+		return c, nil
+	}
+
+	var config *configs.OIDCProvider
+	for i := range r.config.Providers {
+		if r.config.Providers[i].Name == name {
+			config = &r.config.Providers[i]
+			break
+		}
+	}
+	if config == nil {
+		return nil, ErrUnknownProvider
+	}
+
+	// discovered provider keeps this client for fetching its signing keys later
+	provider, err := oidc.NewProvider(oidc.ClientContext(ctx, r.HTTPClient), config.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover provider %s: %w", name, err)
+	}
+
+	c := &client{
+		config: *config,
+		oauth: &oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			Endpoint:     provider.Endpoint(),
+			RedirectURL:  r.config.BaseURL + "/api/oidc/" + name + "/callback",
+			Scopes:       config.Scopes,
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: config.ClientID, Now: func() time.Time { return r.Now() }}),
+	}
+	r.clients[name] = c
+	return c, nil
+}
+
+func randomString() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
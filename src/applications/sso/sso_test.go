@@ -0,0 +1,163 @@
+package sso
+
+import (
+	"context"
+	"errors"
+	"kiraform/src/applications/sso/ssotest"
+	"kiraform/src/infras/configs"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestRegistry returns registry of provider "corp" served by fake provider
+func newTestRegistry(t *testing.T) (*Registry, *ssotest.Provider) {
+	t.Helper()
+	provider := ssotest.NewProvider(t, "kiraform", "client secret")
+	registry, err := New(configs.OIDCConfig{
+		Providers: []configs.OIDCProvider{
+			{Name: "corp", Issuer: provider.URL, ClientID: provider.ClientID, ClientSecret: provider.ClientSecret, Scopes: []string{"openid", "email", "profile"}},
+			{Name: "other", Issuer: provider.URL, ClientID: provider.ClientID, ClientSecret: provider.ClientSecret, Scopes: []string{"openid"}},
+		},
+		BaseURL:     "https://api.example.com",
+		FrontendURL: "https://app.example.com/sso",
+	}, "test secret key")
+	if err != nil {
+		t.Fatalf("new registry: %v", err)
+	}
+	registry.HTTPClient = provider.Client()
+	return registry, provider
+}
+
+func TestLoginWithPKCE(t *testing.T) {
+	ctx := context.Background()
+	registry, provider := newTestRegistry(t)
+
+	authURL, sealed, err := registry.Start(ctx, "corp")
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	u, _ := url.Parse(authURL)
+	if got := u.Query().Get("redirect_uri"); got != "https://api.example.com/api/oidc/corp/callback" {
+		t.Fatalf("got redirect uri %s", got)
+	}
+	if strings.Contains(authURL, "code_verifier") {
+		t.Fatal("verifier must stay in sealed state, not in auth url")
+	}
+
+	code, state := provider.Login(t, authURL, ssotest.Claims{Subject: "sub-1", Email: " Jane@Example.com ", EmailVerified: "true", Name: "Jane Doe"})
+	identity, err := registry.Finish(ctx, "corp", code, state, sealed)
+	if err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+	want := Identity{Provider: "corp", Subject: "sub-1", Email: "jane@example.com", EmailVerified: true, Name: "Jane Doe"}
+	if *identity != want {
+		t.Fatalf("got identity %+v, want %+v", *identity, want)
+	}
+
+	// code works once
+	if _, err := registry.Finish(ctx, "corp", code, state, sealed); err == nil {
+		t.Fatal("code was exchanged twice")
+	}
+}
+
+func TestFinishRejectsCodeOfAnotherLogin(t *testing.T) {
+	ctx := context.Background()
+	registry, provider := newTestRegistry(t)
+
+	// code issued for attacker login is injected into callback of victim browser,
+	// verifier of victim does not match challenge of that code
+	victimURL, victimSealed, err := registry.Start(ctx, "corp")
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	attackerURL, _, err := registry.Start(ctx, "corp")
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	_, victimState := provider.Login(t, victimURL, ssotest.Claims{Subject: "victim", Email: "victim@example.com", EmailVerified: true})
+	code, _ := provider.Login(t, attackerURL, ssotest.Claims{Subject: "attacker", Email: "attacker@example.com", EmailVerified: true})
+
+	if _, err := registry.Finish(ctx, "corp", code, victimState, victimSealed); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("got error %v, want invalid_grant of provider", err)
+	}
+}
+
+func TestFinishValidatesState(t *testing.T) {
+	ctx := context.Background()
+	registry, provider := newTestRegistry(t)
+	authURL, sealed, err := registry.Start(ctx, "corp")
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	code, state := provider.Login(t, authURL, ssotest.Claims{Subject: "sub-1", Email: "jane@example.com", EmailVerified: true})
+
+	cases := []struct {
+		name     string
+		provider string
+		state    string
+		sealed   string
+		now      time.Time
+	}{
+		{"state of another login", "corp", "forged", sealed, time.Now()},
+		{"tampered cookie", "corp", state, sealed[:len(sealed)-4] + "AAAA", time.Now()},
+		{"missing cookie", "corp", state, "", time.Now()},
+		{"cookie of another provider", "other", state, sealed, time.Now()},
+		{"expired state", "corp", state, sealed, time.Now().Add(StateTTL + time.Minute)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			registry.Now = func() time.Time { return tc.now }
+			if _, err := registry.Finish(ctx, tc.provider, code, tc.state, tc.sealed); !errors.Is(err, ErrInvalidState) {
+				t.Fatalf("got error %v, want invalid state", err)
+			}
+		})
+	}
+
+	// code is not spent by rejected callbacks
+	registry.Now = time.Now
+	if _, err := registry.Finish(ctx, "corp", code, state, sealed); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+}
+
+func TestUnverifiedEmail(t *testing.T) {
+	ctx := context.Background()
+	registry, provider := newTestRegistry(t)
+
+	for _, verified := range []any{nil, false, "false"} {
+		authURL, sealed, err := registry.Start(ctx, "corp")
+		if err != nil {
+			t.Fatalf("start: %v", err)
+		}
+		code, state := provider.Login(t, authURL, ssotest.Claims{Subject: "sub-1", Email: "jane@example.com", EmailVerified: verified})
+		identity, err := registry.Finish(ctx, "corp", code, state, sealed)
+		if err != nil {
+			t.Fatalf("finish: %v", err)
+		}
+		if identity.EmailVerified {
+			t.Fatalf("email_verified %v is taken as verified", verified)
+		}
+	}
+}
+
+func TestSetIssuer(t *testing.T) {
+	ctx := context.Background()
+	registry, provider := newTestRegistry(t)
+	if err := registry.SetIssuer("corp", "http://127.0.0.1:1"); err != nil {
+		t.Fatalf("set issuer: %v", err)
+	}
+	if _, _, err := registry.Start(ctx, "corp"); err == nil {
+		t.Fatal("provider is not discovered again from new issuer")
+	}
+	if err := registry.SetIssuer("corp", provider.URL); err != nil {
+		t.Fatalf("set issuer: %v", err)
+	}
+	if _, _, err := registry.Start(ctx, "corp"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := registry.SetIssuer("unknown", provider.URL); !errors.Is(err, ErrUnknownProvider) {
+		t.Fatalf("got error %v, want unknown provider", err)
+	}
+}
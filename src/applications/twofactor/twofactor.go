@@ -0,0 +1,208 @@
+package twofactor
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/applications/models"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// recoveryCodeCount is how many codes are given on confirmation, each works once
+	recoveryCodeCount = 10
+
+	// recoveryCodeBytes gives 16 base32 characters, shown in groups of 4
+	recoveryCodeBytes = 10
+)
+
+var (
+	ErrNotEnabled  = errors.New("two-factor authentication is not enabled")
+	ErrInvalidCode = errors.New("two-factor code is invalid")
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Manager enrols TOTP authenticator of user and verifies its codes, recovery codes are the fallback
+// when authenticator is lost
+type Manager struct {
+	repo   masterrepo.TwoFactorRepository
+	box    *utils.SecretBox
+	issuer string
+}
+
+// New creates manager, secret of authenticator is stored encrypted by secretKey and
+// issuer is the name shown in authenticator app
+func New(repo masterrepo.TwoFactorRepository, secretKey string, issuer string) (*Manager, error) {
+	box, err := utils.NewSecretBox(secretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{
+		repo:   repo,
+		box:    box,
+		issuer: issuer,
+	}, nil
+}
+
+// Enabled tells user has confirmed authenticator
+func (m *Manager) Enabled(ctx context.Context, userID string) (bool, error) {
+	data, err := m.repo.FindTwoFactorByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return data.ConfirmedAt != nil, nil
+}
+
+// Enroll starts new pending enrolment, returns secret and otpauth uri for authenticator app
+func (m *Manager) Enroll(ctx context.Context, userID string, account string) (string, string, error) {
+	enabled, err := m.Enabled(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+	if enabled {
+		return "", "", apperrors.Conflict("two-factor authentication is already enabled, disable it first")
+	}
+	UUIDuserID, err := uuid.Parse(userID)
+	if err != nil {
+		return "", "", apperrors.Unauthorized("your identity is not recognized, please contact our admin")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+	sealed, err := m.box.Seal([]byte(secret))
+	if err != nil {
+		return "", "", err
+	}
+	data := models.UserTwoFactors{
+		ID:        uuid.New(),
+		UserID:    UUIDuserID,
+		Secret:    sealed,
+		CreatedAt: time.Now(),
+	}
+	if err := m.repo.SavePendingTwoFactor(ctx, data); err != nil {
+		return "", "", err
+	}
+	return secret, utils.TOTPURI(m.issuer, account, secret), nil
+}
+
+// Confirm enables pending enrolment with its first code, returns recovery codes shown only once
+func (m *Manager) Confirm(ctx context.Context, userID string, code string) ([]string, error) {
+	data, err := m.repo.FindTwoFactorByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("two-factor enrolment is not started")
+		}
+		return nil, err
+	}
+	if data.ConfirmedAt != nil {
+		return nil, apperrors.Conflict("two-factor authentication is already enabled")
+	}
+
+	secret, err := m.box.Open(data.Secret)
+	if err != nil {
+		return nil, err
+	}
+	t := time.Now()
+	step, ok := utils.VerifyTOTP(string(secret), code, t)
+	if !ok {
+		return nil, apperrors.Field("code", "is invalid, check time of your device")
+	}
+
+	// plain codes go to user, only their hashes are stored
+	plain := make([]string, 0, recoveryCodeCount)
+	codes := make([]models.UserRecoveryCodes, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		random := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		value := strings.ToLower(recoveryEncoding.EncodeToString(random))
+		plain = append(plain, value[0:4]+"-"+value[4:8]+"-"+value[8:12]+"-"+value[12:16])
+		codes = append(codes, models.UserRecoveryCodes{
+			ID:        uuid.New(),
+			UserID:    data.UserID,
+			CodeHash:  hashRecoveryCode(value),
+			CreatedAt: t,
+		})
+	}
+
+	confirmed, err := m.repo.ConfirmTwoFactor(ctx, userID, step, t, codes)
+	if err != nil {
+		return nil, err
+	}
+	if !confirmed {
+		return nil, apperrors.Conflict("two-factor authentication is already enabled")
+	}
+	return plain, nil
+}
+
+// Verify accepts current authenticator code or unused recovery code, each of them works only once
+func (m *Manager) Verify(ctx context.Context, userID string, code string) error {
+	data, err := m.repo.FindTwoFactorByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotEnabled
+		}
+		return err
+	}
+	if data.ConfirmedAt == nil {
+		return ErrNotEnabled
+	}
+
+	// authenticator code is all digits, anything else is tried as recovery code
+	code = strings.TrimSpace(code)
+	if strings.Trim(code, "0123456789") == "" {
+		secret, err := m.box.Open(data.Secret)
+		if err != nil {
+			return err
+		}
+		step, ok := utils.VerifyTOTP(string(secret), code, time.Now())
+		if !ok {
+			return ErrInvalidCode
+		}
+		used, err := m.repo.UseTwoFactorStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidCode
+		}
+		return nil
+	}
+
+	value := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	used, err := m.repo.UseRecoveryCode(ctx, userID, hashRecoveryCode(value), time.Now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+// Disable removes authenticator and recovery codes of user
+func (m *Manager) Disable(ctx context.Context, userID string) error {
+	return m.repo.DeleteTwoFactor(ctx, userID)
+}
+
+func hashRecoveryCode(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
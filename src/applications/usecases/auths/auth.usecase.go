@@ -1,11 +1,24 @@
 package authusecase
 
 import (
+	"context"
 	"errors"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/applications/keyring"
 	"kiraform/src/applications/models"
 	repomasters "kiraform/src/applications/repos/masters"
+	"kiraform/src/applications/sessions"
+	"kiraform/src/applications/sso"
+	"kiraform/src/applications/twofactor"
 	"kiraform/src/infras/configs"
+	"kiraform/src/infras/mailer"
+	"kiraform/src/infras/ratelimit"
+	"kiraform/src/infras/tracing"
 	authschema "kiraform/src/interfaces/rest/schemas/auths"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"kiraform/src/utils"
+	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,92 +29,414 @@ import (
 )
 
 type AuthUsecase interface {
-	Login(body authschema.LoginPayload) (*string, error)
-	Register(body authschema.RegisterPayload) (*string, error)
+	Login(ctx context.Context, actor commonschema.Actor, body authschema.LoginPayload) (*authschema.LoginResult, error)
+	LoginTwoFactor(ctx context.Context, actor commonschema.Actor, body authschema.LoginTwoFactorPayload) (*string, error)
+	Register(ctx context.Context, body authschema.RegisterPayload) (*string, error)
+	SSOLogin(ctx context.Context, actor commonschema.Actor, identity sso.Identity) (*authschema.LoginResult, error)
+	RequestPasswordless(ctx context.Context, body authschema.PasswordlessPayload) (*string, error)
+	LoginPasswordless(ctx context.Context, actor commonschema.Actor, body authschema.PasswordlessCodePayload) (*authschema.LoginResult, error)
+	LoginMagicLink(ctx context.Context, actor commonschema.Actor, body authschema.MagicLinkPayload) (*authschema.LoginResult, error)
+	ResetPassword(ctx context.Context, actor commonschema.Actor, body authschema.ResetPasswordPayload) (*string, error)
 }
 
+// account is locked for loginLockWindow after maxLoginAttempts failed login
+const (
+	maxLoginAttempts = 5
+	loginLockWindow  = 15 * time.Minute
+)
+
+// loginLockKey counts failed password logins per email and client address,
+// so guessing from another address does not lock the owner out
+func loginLockKey(email string, ip string) string {
+	return "login_failed:" + strings.ToLower(email) + ":" + ip
+}
+
+// challengeTTL is how long password step stays valid waiting for two-factor code
+const challengeTTL = 5 * time.Minute
+
+// errEmailNotVerified refuses linking sso identity, unverified email could take over someone else account
+var errEmailNotVerified = errors.New("email is not verified by provider")
+
 type AuthService struct {
-	UserRepo repomasters.UserRepository
-	RoleRepo repomasters.RoleRepository
+	UserRepo      repomasters.UserRepository
+	RoleRepo      repomasters.RoleRepository
+	IdentityRepo  repomasters.UserIdentityRepository
+	LoginCodeRepo repomasters.LoginCodeRepository
+	Limiter       ratelimit.Store
+	Config        configs.AuthConfig
+	Keyring       *keyring.Keyring
+	TwoFactor     *twofactor.Manager
+	Mailer        mailer.Mailer
+	Sessions      *sessions.Tracker
 }
 
-func NewAuthUsecase(userRepo repomasters.UserRepository, roleRepo repomasters.RoleRepository) *AuthService {
+func NewAuthUsecase(userRepo repomasters.UserRepository, roleRepo repomasters.RoleRepository, identityRepo repomasters.UserIdentityRepository, loginCodeRepo repomasters.LoginCodeRepository, limiter ratelimit.Store, config configs.AuthConfig, keys *keyring.Keyring, twoFactor *twofactor.Manager, mail mailer.Mailer, tracker *sessions.Tracker) *AuthService {
 	return &AuthService{
-		UserRepo: userRepo,
-		RoleRepo: roleRepo,
+		UserRepo:      userRepo,
+		RoleRepo:      roleRepo,
+		IdentityRepo:  identityRepo,
+		LoginCodeRepo: loginCodeRepo,
+		Limiter:       limiter,
+		Config:        config,
+		Keyring:       keys,
+		TwoFactor:     twoFactor,
+		Mailer:        mail,
+		Sessions:      tracker,
 	}
 }
 
-func (s *AuthService) Login(body authschema.LoginPayload) (*string, error) {
+func (s *AuthService) Login(ctx context.Context, actor commonschema.Actor, body authschema.LoginPayload) (*authschema.LoginResult, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Login")
+	defer span.End()
+
+	// reject locked account before checking the password
+	lockKey := loginLockKey(body.Email, actor.IP)
+	attempts, ttl, err := s.Limiter.Get(lockKey)
+	if err == nil && attempts >= maxLoginAttempts {
+		s.Sessions.Failed(ctx, actor, sessions.Attempt{Email: body.Email, Method: sessions.MethodPassword, Reason: "account locked"})
+		return nil, apperrors.TooManyRequests("too many failed login attempts, your account is locked for a while", ttl)
+	}
+
 	// get data
-	data, err := s.UserRepo.FindUserByEmail(body.Email)
+	data, err := s.UserRepo.FindUserByEmail(ctx, body.Email)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.Sessions.Failed(ctx, actor, sessions.Attempt{Email: body.Email, Method: sessions.MethodPassword, Reason: "unknown email"})
+			return nil, s.loginFailed(ctx, lockKey)
+		}
 		return nil, err
 	}
 
 	// validate matching password
 	if err := bcrypt.CompareHashAndPassword([]byte(data.Password), []byte(body.Password)); err != nil {
-		return nil, errors.New("password does not match")
+		s.Sessions.Failed(ctx, actor, sessions.Attempt{Email: body.Email, UserID: &data.ID, Method: sessions.MethodPassword, Reason: "wrong password"})
+		return nil, s.loginFailed(ctx, lockKey)
 	}
 
-	// get user role
-	roleName := "user" // default
-	role, err := s.UserRepo.GetRoleByUser(data.ID)
-	if err == nil && role.Role.Name != "" {
-		roleName = role.Role.Name
+	// deactivated account is rejected after password check, so its status is not leaked
+	if !data.IsActive {
+		s.Sessions.Failed(ctx, actor, sessions.Attempt{Email: body.Email, UserID: &data.ID, Method: sessions.MethodPassword, Reason: "account deactivated"})
+		return nil, apperrors.Forbidden("your account is deactivated, please contact admin")
 	}
 
-	// prepare data as response
-	response := map[string]any{
-		"id":        data.ID,
-		"role_name": roleName,
+	// successful login clears failed attempts
+	if err := s.Limiter.Reset(lockKey); err != nil {
+		slog.ErrorContext(ctx, "failed to reset login attempts", "error", err)
 	}
 
-	// convert into jwt token
-	claims := jwt.MapClaims{
-		"exp": time.Now().Add(time.Hour * 24).Unix(),
+	return s.completeLogin(ctx, actor, data, sessions.MethodPassword)
+}
+
+// completeLogin issues access token of user whose first factor is checked by method,
+// user with authenticator gets challenge token instead, access token is issued by LoginTwoFactor
+func (s *AuthService) completeLogin(ctx context.Context, actor commonschema.Actor, data *models.Users, method string) (*authschema.LoginResult, error) {
+	enabled, err := s.TwoFactor.Enabled(ctx, data.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		challenge, err := s.Keyring.Sign(jwt.MapClaims{
+			"sub":    data.ID.String(),
+			"typ":    keyring.TokenTwoFactorChallenge,
+			"method": method,
+			"exp":    time.Now().Add(challengeTTL).Unix(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &authschema.LoginResult{ChallengeToken: challenge}, nil
+	}
+
+	signedToken, err := s.issueToken(ctx, actor, data, method, false)
+	if err != nil {
+		return nil, err
+	}
+	return &authschema.LoginResult{AccessToken: signedToken}, nil
+}
+
+// LoginTwoFactor finishes login of user with authenticator by challenge token and its code
+func (s *AuthService) LoginTwoFactor(ctx context.Context, actor commonschema.Actor, body authschema.LoginTwoFactorPayload) (*string, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.LoginTwoFactor")
+	defer span.End()
+
+	invalidChallenge := apperrors.Unauthorized("login session is expired, please login again")
+	decode, err := s.Keyring.Parse(ctx, body.ChallengeToken, jwt.MapClaims{})
+	if err != nil {
+		return nil, invalidChallenge
+	}
+	claims, ok := decode.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != keyring.TokenTwoFactorChallenge {
+		return nil, invalidChallenge
 	}
-	for k, v := range response {
-		claims[k] = v
+	userID, _ := claims["sub"].(string)
+	method, _ := claims["method"].(string)
+	if method == "" {
+		method = sessions.MethodPassword
+	}
+
+	// guessing code is limited per account like password
+	lockKey := "2fa_failed:" + userID
+	attempts, ttl, err := s.Limiter.Get(lockKey)
+	if err == nil && attempts >= maxLoginAttempts {
+		return nil, apperrors.TooManyRequests("too many failed two-factor attempts, your account is locked for a while", ttl)
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	key := []byte(configs.Environment().SECRET_KEY)
-	signedToken, err := token.SignedString(key)
+
+	data, err := s.UserRepo.FindUserByID(ctx, userID)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalidChallenge
+		}
 		return nil, err
 	}
+	if !data.IsActive {
+		return nil, apperrors.Forbidden("your account is deactivated, please contact admin")
+	}
 
+	if err := s.TwoFactor.Verify(ctx, userID, body.Code); err != nil {
+		switch {
+		case errors.Is(err, twofactor.ErrInvalidCode):
+			s.Sessions.Failed(ctx, actor, sessions.Attempt{Email: data.Email, UserID: &data.ID, Method: method, Reason: "wrong two-factor code"})
+			attempts, ttl, err := s.Limiter.Hit(lockKey, loginLockWindow)
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to count two-factor attempts", "error", err)
+			}
+			if attempts >= maxLoginAttempts {
+				return nil, apperrors.TooManyRequests("too many failed two-factor attempts, your account is locked for a while", ttl)
+			}
+			return nil, apperrors.Unauthorized("two-factor code is invalid")
+		case errors.Is(err, twofactor.ErrNotEnabled):
+			return nil, invalidChallenge
+		}
+		return nil, err
+	}
+	if err := s.Limiter.Reset(lockKey); err != nil {
+		slog.ErrorContext(ctx, "failed to reset two-factor attempts", "error", err)
+	}
+
+	signedToken, err := s.issueToken(ctx, actor, data, method, true)
+	if err != nil {
+		return nil, err
+	}
 	return &signedToken, nil
 }
 
-func (s *AuthService) Register(body authschema.RegisterPayload) (*string, error) {
+// SSOLogin signs in user of external provider, identity is linked to user of same verified email
+// or new user is registered on first login
+func (s *AuthService) SSOLogin(ctx context.Context, actor commonschema.Actor, identity sso.Identity) (*authschema.LoginResult, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.SSOLogin")
+	defer span.End()
+
+	method := sessions.MethodSSO + identity.Provider
+	var data *models.Users
+	linked, err := s.IdentityRepo.FindUserIdentity(ctx, identity.Provider, identity.Subject)
+	switch {
+	case err == nil:
+		data, err = s.UserRepo.FindUserByID(ctx, linked.UserID.String())
+		if err != nil {
+			return nil, err
+		}
+		if err := s.IdentityRepo.TouchUserIdentity(ctx, linked.ID.String(), time.Now()); err != nil {
+			slog.ErrorContext(ctx, "failed to update last login of identity", "error", err)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		data, err = s.linkIdentity(ctx, identity)
+		if err != nil {
+			if errors.Is(err, errEmailNotVerified) {
+				s.Sessions.Failed(ctx, actor, sessions.Attempt{Email: identity.Email, Method: method, Reason: "email not verified"})
+				return nil, apperrors.Forbidden("email of your " + identity.Provider + " account is not verified")
+			}
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	// provider has verified the email, so account waiting for passwordless verification is active now
+	if err := s.activatePending(ctx, data); err != nil {
+		return nil, err
+	}
+	if !data.IsActive {
+		s.Sessions.Failed(ctx, actor, sessions.Attempt{Email: data.Email, UserID: &data.ID, Method: method, Reason: "account deactivated"})
+		return nil, apperrors.Forbidden("your account is deactivated, please contact admin")
+	}
+	return s.completeLogin(ctx, actor, data, method)
+}
+
+// linkIdentity links new identity by verified email
+func (s *AuthService) linkIdentity(ctx context.Context, identity sso.Identity) (*models.Users, error) {
+	if !identity.EmailVerified {
+		return nil, errEmailNotVerified
+	}
+
+	now := time.Now()
+	dataIdentity := models.UserIdentities{
+		ID:          uuid.New(),
+		Provider:    identity.Provider,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		CreatedAt:   now,
+		LastLoginAt: &now,
+	}
+
+	// existing user gets new way to sign in
+	user, err := s.UserRepo.FindUserByEmail(ctx, identity.Email)
+	if err == nil {
+		dataIdentity.UserID = user.ID
+		if err := s.IdentityRepo.CreateUserIdentity(ctx, dataIdentity); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// first login registers user
+	hashedPassword, err := utils.UnusablePassword()
+	if err != nil {
+		return nil, err
+	}
+	fullname := identity.Name
+	if fullname == "" {
+		fullname = strings.Split(identity.Email, "@")[0]
+	}
+	dataUser, dataUserProfile, dataUserRole, err := s.newAccount(ctx, identity.Email, fullname, hashedPassword)
+	if err != nil {
+		return nil, err
+	}
+	dataIdentity.UserID = dataUser.ID
+	if err := s.IdentityRepo.CreateUserWithIdentity(ctx, dataUser, dataUserProfile, dataUserRole, dataIdentity); err != nil {
+		return nil, err
+	}
+	return &dataUser, nil
+}
+
+// issueToken opens session and signs its access token, mfa tells it is issued after two-factor code
+func (s *AuthService) issueToken(ctx context.Context, actor commonschema.Actor, data *models.Users, method string, mfa bool) (string, error) {
+	// get user roles and what they allow
+	roles, permissions, err := s.userAccess(ctx, data.ID.String())
+	if err != nil {
+		return "", err
+	}
+
+	// session lives as long as its token, revoking it stops the token
+	expiresAt := time.Now().Add(s.Config.TokenTTL)
+	sessionID, err := s.Sessions.Start(ctx, actor, data, method, expiresAt)
+	if err != nil {
+		return "", err
+	}
+
+	// convert into jwt token
+	claims := jwt.MapClaims{
+		"exp":         expiresAt.Unix(),
+		"sid":         sessionID,
+		"typ":         keyring.TokenAccess,
+		"id":          data.ID,
+		"roles":       roles,
+		"permissions": permissions,
+		"mfa":         mfa,
+	}
+	return s.Keyring.Sign(claims)
+}
+
+// userAccess returns role names of user and every permission granted by them,
+// user without role is treated as user role like before roles could be managed
+func (s *AuthService) userAccess(ctx context.Context, userID string) ([]string, []string, error) {
+	rows, err := s.UserRepo.FindRolesByUsers(ctx, []string{userID})
+	if err != nil {
+		return nil, nil, err
+	}
+	roles := []string{}
+	permissions := []string{}
+	for _, v := range rows {
+		roles = append(roles, v.Role.Name)
+		for _, permission := range v.Role.PermissionList() {
+			if !slices.Contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	if len(roles) == 0 {
+		roles = append(roles, models.RoleUser)
+	}
+	return roles, permissions, nil
+}
+
+// loginFailed counts failed attempt and returns error for the client
+// unknown email is counted too, so existing account can not be guessed
+func (s *AuthService) loginFailed(ctx context.Context, lockKey string) error {
+	attempts, ttl, err := s.Limiter.Hit(lockKey, loginLockWindow)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to count login attempts", "error", err)
+	}
+	if attempts >= maxLoginAttempts {
+		return apperrors.TooManyRequests("too many failed login attempts, your account is locked for a while", ttl)
+	}
+	return apperrors.Unauthorized("email or password does not match")
+}
+
+func (s *AuthService) Register(ctx context.Context, body authschema.RegisterPayload) (*string, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Register")
+	defer span.End()
+
 	// check existing email
 	// validate to return error query, not error not found
-	user, err := s.UserRepo.FindUserByEmail(body.Email)
+	user, err := s.UserRepo.FindUserByEmail(ctx, body.Email)
 	if err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, err
 		}
 	}
-	if user != nil {
-		return nil, errors.New("email is already taken, try another one")
+	if user != nil && !user.IsPending {
+		return nil, apperrors.Conflict("email is already taken, try another one")
 	}
 
-	// load data role[user]
-	role, err := s.RoleRepo.FindRoleByName("user")
+	// hasing password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("role for this registartion is not found, please contact admin")
-		}
 		return nil, err
 	}
 
-	// hasing password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
+	// preparing data user, user profile and role[user]
+	dataUser, dataUserProfile, dataUserRole, err := s.newAccount(ctx, body.Email, body.Fullname, string(hashedPassword))
+	if err != nil {
+		return nil, err
+	}
+	responseMsg := "Your account is successfully registered"
+
+	// passwordless request left never verified account of this email, registration takes it over
+	if user != nil {
+		claimed, err := s.UserRepo.ClaimPendingUser(ctx, user.ID.String(), dataUser, dataUserProfile)
+		if err != nil {
+			return nil, err
+		}
+		if !claimed {
+			return nil, apperrors.Conflict("email is already taken, try another one")
+		}
+		return &responseMsg, nil
+	}
+
+	// perform to insert data
+	err = s.UserRepo.CreateUser(ctx, dataUser, dataUserProfile, dataUserRole)
 	if err != nil {
 		return nil, err
 	}
 
+	// response
+	return &responseMsg, nil
+}
+
+// newAccount prepares active user with its profile and default role[user]
+func (s *AuthService) newAccount(ctx context.Context, email string, fullname string, hashedPassword string) (models.Users, models.UserProfiles, models.UserRoles, error) {
+	// load data role[user]
+	role, err := s.RoleRepo.FindRoleByName(ctx, models.RoleUser)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Users{}, models.UserProfiles{}, models.UserRoles{}, apperrors.NotFound("role for this registartion is not found, please contact admin")
+		}
+		return models.Users{}, models.UserProfiles{}, models.UserRoles{}, err
+	}
+
 	// generating user identity
 	userID := uuid.New()
 	uuidStr := strings.Split(userID.String(), "-")
@@ -110,19 +445,18 @@ func (s *AuthService) Register(body authschema.RegisterPayload) (*string, error)
 		userIdentity = strings.ToUpper(uuidStr[0] + uuidStr[1])
 	}
 
-	// preparing data user and user profile
 	dataUser := models.Users{
 		ID:           userID,
 		UserIdentity: userIdentity,
-		Email:        body.Email,
-		Password:     string(hashedPassword),
-		Fullname:     body.Fullname,
+		Email:        email,
+		Password:     hashedPassword,
+		Fullname:     fullname,
 		IsActive:     true, // default true for now, next version needs to active it manually using OTP
 		CreatedAt:    time.Now(),
 	}
 
 	firstName, middleName, lastName := "", "", ""
-	nameParts := strings.Fields(body.Fullname)
+	nameParts := strings.Fields(fullname)
 
 	if len(nameParts) > 0 {
 		firstName = nameParts[0]
@@ -132,7 +466,7 @@ func (s *AuthService) Register(body authschema.RegisterPayload) (*string, error)
 		middleName = nameParts[1]
 	}
 
-	if len(lastName) > 2 {
+	if len(nameParts) > 2 {
 		lastName = nameParts[2]
 	}
 
@@ -151,14 +485,18 @@ func (s *AuthService) Register(body authschema.RegisterPayload) (*string, error)
 		RoleID:    role.ID,
 		CreatedAt: time.Now(),
 	}
+	return dataUser, dataUserProfile, dataUserRole, nil
+}
 
-	// perform to insert data
-	err = s.UserRepo.CreateUser(dataUser, dataUserProfile, dataUserRole)
-	if err != nil {
-		return nil, err
+// activatePending activates account created by passwordless request once its email is verified
+func (s *AuthService) activatePending(ctx context.Context, data *models.Users) error {
+	if !data.IsPending {
+		return nil
 	}
-
-	// response
-	responseMsg := "Your account is successfully registered"
-	return &responseMsg, nil
+	if err := s.UserRepo.ActivatePendingUser(ctx, data.ID.String()); err != nil {
+		return err
+	}
+	data.IsActive = true
+	data.IsPending = false
+	return nil
 }
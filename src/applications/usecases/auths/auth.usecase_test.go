@@ -0,0 +1,365 @@
+package authusecase
+
+import (
+	"context"
+	"errors"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/applications/keyring"
+	"kiraform/src/applications/models"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/applications/sessions"
+	"kiraform/src/applications/twofactor"
+	"kiraform/src/infras/configs"
+	"kiraform/src/infras/dbtest"
+	"kiraform/src/infras/mailer"
+	"kiraform/src/infras/ratelimit"
+	authschema "kiraform/src/interfaces/rest/schemas/auths"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// memoryRepos keeps users, login codes, identities and signing keys in memory, so a test can
+// follow one account across several calls, tables which are only written go to dbtest
+type memoryRepos struct {
+	mu         sync.Mutex
+	users      map[uuid.UUID]*models.Users
+	userRoles  []models.UserRoles
+	profiles   []models.UserProfiles
+	codes      map[uuid.UUID]*models.LoginCodes
+	identities []models.UserIdentities
+	keys       []models.SigningKeys
+	roleUser   models.Roles
+}
+
+// testAuth is auth usecase wired to memory repos, mail keeps every sent email
+type testAuth struct {
+	*AuthService
+	repos *memoryRepos
+	mail  *mailer.MemoryMailer
+	DB    *gorm.DB
+}
+
+func newTestAuth(t *testing.T) *testAuth {
+	t.Helper()
+	DB, _ := dbtest.Open(t, func(string) *dbtest.Rows { return nil })
+	repos := &memoryRepos{
+		users:    map[uuid.UUID]*models.Users{},
+		codes:    map[uuid.UUID]*models.LoginCodes{},
+		roleUser: models.Roles{ID: uuid.New(), Name: models.RoleUser},
+	}
+	config := configs.AuthConfig{
+		SecretKey:    "test secret key",
+		TokenTTL:     time.Hour,
+		Issuer:       "Kiraform",
+		SigningAlg:   "EdDSA",
+		MagicLinkURL: "https://app.test/magic",
+	}
+
+	keys, err := keyring.New(memorySigningKeys{repos}, config)
+	if err != nil {
+		t.Fatalf("keyring: %v", err)
+	}
+	if err := keys.Init(context.Background()); err != nil {
+		t.Fatalf("keyring init: %v", err)
+	}
+	twoFactor, err := twofactor.New(masterrepo.NewTwoFactorRepository(DB), config.SecretKey, config.Issuer)
+	if err != nil {
+		t.Fatalf("two factor: %v", err)
+	}
+	limiter := ratelimit.NewMemoryStore()
+	t.Cleanup(func() {
+		limiter.Close()
+	})
+
+	mail := mailer.NewMemoryMailer()
+	users := memoryUsers{memoryRepos: repos}
+	tracker := sessions.NewTracker(masterrepo.NewSessionRepository(DB), masterrepo.NewLoginHistoryRepository(DB), users, mail, config.Issuer)
+	uc := NewAuthUsecase(users, memoryRoles{memoryRepos: repos}, memoryIdentities{repos}, memoryLoginCodes{repos}, limiter, config, keys, twoFactor, mail, tracker)
+	return &testAuth{AuthService: uc, repos: repos, mail: mail, DB: DB}
+}
+
+// user returns copy of stored user by email
+func (a *testAuth) user(t *testing.T, email string) models.Users {
+	t.Helper()
+	data, err := a.UserRepo.FindUserByEmail(context.Background(), email)
+	if err != nil {
+		t.Fatalf("find user %s: %v", email, err)
+	}
+	return *data
+}
+
+// addUser stores active user of email, its password is "secret password"
+func (a *testAuth) addUser(t *testing.T, email string) models.Users {
+	t.Helper()
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("secret password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	data := models.Users{ID: uuid.New(), Email: email, Password: string(hashedPassword), IsActive: true, CreatedAt: time.Now()}
+	a.repos.mu.Lock()
+	defer a.repos.mu.Unlock()
+	a.repos.users[data.ID] = &data
+	return data
+}
+
+var loginCodePattern = regexp.MustCompile(`login code is (\d{6})`)
+
+// sentCode returns code of latest email sent to address
+func (a *testAuth) sentCode(t *testing.T, email string) string {
+	t.Helper()
+	msg, ok := a.mail.Last(email)
+	if !ok {
+		t.Fatalf("no email sent to %s", email)
+	}
+	match := loginCodePattern.FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("no login code in email:\n%s", msg.Body)
+	}
+	return match[1]
+}
+
+// wantCode fails unless err is domain error of code
+func wantCode(t *testing.T, err error, code apperrors.Code) {
+	t.Helper()
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) || appErr.Code != code {
+		t.Fatalf("got error %v, want %s", err, code)
+	}
+}
+
+type memoryUsers struct {
+	masterrepo.UserRepository
+	*memoryRepos
+}
+
+func (r memoryUsers) FindUserByEmail(ctx context.Context, email string) (*models.Users, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range r.users {
+		if v.Email == email {
+			data := *v
+			return &data, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r memoryUsers) FindUserByID(ctx context.Context, ID string) (*models.Users, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range r.users {
+		if v.ID.String() == ID {
+			data := *v
+			return &data, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r memoryUsers) CreateUser(ctx context.Context, user models.Users, userProfile models.UserProfiles, userRole models.UserRoles) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[user.ID] = &user
+	r.profiles = append(r.profiles, userProfile)
+	r.userRoles = append(r.userRoles, userRole)
+	return nil
+}
+
+func (r memoryUsers) UpdateUserActive(ctx context.Context, ID string, isActive bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.users[uuid.MustParse(ID)]; ok && !v.Deleted {
+		v.IsActive = isActive
+		v.IsPending = false
+	}
+	return nil
+}
+
+func (r memoryUsers) ActivatePendingUser(ctx context.Context, ID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.users[uuid.MustParse(ID)]; ok && !v.Deleted && v.IsPending {
+		v.IsActive = true
+		v.IsPending = false
+	}
+	return nil
+}
+
+func (r memoryUsers) ClaimPendingUser(ctx context.Context, ID string, user models.Users, userProfile models.UserProfiles) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.users[uuid.MustParse(ID)]
+	if !ok || v.Deleted || !v.IsPending {
+		return false, nil
+	}
+	v.Password, v.Fullname, v.IsActive, v.IsPending = user.Password, user.Fullname, true, false
+	for i := range r.profiles {
+		if r.profiles[i].UserID == v.ID {
+			r.profiles[i].FirstName, r.profiles[i].MiddleName, r.profiles[i].LastName = userProfile.FirstName, userProfile.MiddleName, userProfile.LastName
+		}
+	}
+	return true, nil
+}
+
+func (r memoryUsers) FindRolesByUsers(ctx context.Context, userIDs []string) ([]models.UserRoles, error) {
+	return nil, nil
+}
+
+type memoryRoles struct {
+	masterrepo.RoleRepository
+	*memoryRepos
+}
+
+func (r memoryRoles) FindRoleByName(ctx context.Context, name string) (*models.Roles, error) {
+	if name != r.roleUser.Name {
+		return nil, gorm.ErrRecordNotFound
+	}
+	role := r.roleUser
+	return &role, nil
+}
+
+type memoryIdentities struct {
+	*memoryRepos
+}
+
+func (r memoryIdentities) FindUserIdentity(ctx context.Context, provider string, subject string) (*models.UserIdentities, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range r.identities {
+		if v.Provider == provider && v.Subject == subject {
+			return &v, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r memoryIdentities) CreateUserIdentity(ctx context.Context, data models.UserIdentities) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.identities = append(r.identities, data)
+	return nil
+}
+
+func (r memoryIdentities) CreateUserWithIdentity(ctx context.Context, user models.Users, userProfile models.UserProfiles, userRole models.UserRoles, identity models.UserIdentities) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[user.ID] = &user
+	r.profiles = append(r.profiles, userProfile)
+	r.userRoles = append(r.userRoles, userRole)
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r memoryIdentities) TouchUserIdentity(ctx context.Context, ID string, loginAt time.Time) error {
+	return nil
+}
+
+type memoryLoginCodes struct {
+	*memoryRepos
+}
+
+func (r memoryLoginCodes) FindLoginCode(ctx context.Context, ID string) (*models.LoginCodes, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.codes[uuid.MustParse(ID)]; ok {
+		data := *v
+		return &data, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r memoryLoginCodes) FindActiveLoginCode(ctx context.Context, email string, now time.Time) (*models.LoginCodes, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found *models.LoginCodes
+	for _, v := range r.codes {
+		if v.Email == email && v.UsedAt == nil && v.ExpiresAt.After(now) && (found == nil || v.CreatedAt.After(found.CreatedAt)) {
+			found = v
+		}
+	}
+	if found == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	data := *found
+	return &data, nil
+}
+
+func (r memoryLoginCodes) CreateLoginCode(ctx context.Context, data models.LoginCodes) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for ID, v := range r.codes {
+		if v.Email == data.Email && v.UsedAt == nil {
+			delete(r.codes, ID)
+		}
+	}
+	r.codes[data.ID] = &data
+	return nil
+}
+
+func (r memoryLoginCodes) UseLoginCode(ctx context.Context, ID string, usedAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.codes[uuid.MustParse(ID)]
+	if !ok || v.UsedAt != nil || !v.ExpiresAt.After(usedAt) {
+		return false, nil
+	}
+	v.UsedAt = &usedAt
+	return true, nil
+}
+
+type memorySigningKeys struct {
+	*memoryRepos
+}
+
+func (r memorySigningKeys) FindSigningKeys(ctx context.Context, retiredAfter time.Time) ([]models.SigningKeys, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.SigningKeys(nil), r.keys...), nil
+}
+
+func (r memorySigningKeys) CreateFirstSigningKey(ctx context.Context, key models.SigningKeys) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.keys) > 0 {
+		return false, nil
+	}
+	r.keys = append(r.keys, key)
+	return true, nil
+}
+
+func (r memorySigningKeys) RotateSigningKey(ctx context.Context, key models.SigningKeys, purgeBefore time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = append([]models.SigningKeys{key}, r.keys...)
+	return nil
+}
+
+func TestLoginLockedPerAddress(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuth(t)
+	email := "jane@example.com"
+	a.addUser(t, email)
+	attacker := commonschema.Actor{IP: "203.0.113.7"}
+	owner := commonschema.Actor{IP: "198.51.100.20"}
+
+	for range maxLoginAttempts - 1 {
+		_, err := a.Login(ctx, attacker, authschema.LoginPayload{Email: email, Password: "guess"})
+		wantCode(t, err, apperrors.CodeUnauthorized)
+	}
+	_, err := a.Login(ctx, attacker, authschema.LoginPayload{Email: email, Password: "guess"})
+	wantCode(t, err, apperrors.CodeTooMany)
+
+	// guessing address stays locked even with the right password, the owner is not
+	_, err = a.Login(ctx, attacker, authschema.LoginPayload{Email: "Jane@Example.com", Password: "secret password"})
+	wantCode(t, err, apperrors.CodeTooMany)
+	if _, err := a.Login(ctx, owner, authschema.LoginPayload{Email: email, Password: "secret password"}); err != nil {
+		t.Fatalf("login of owner: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package authusecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/applications/keyring"
+	"kiraform/src/applications/models"
+	"kiraform/src/infras/tracing"
+	authschema "kiraform/src/interfaces/rest/schemas/auths"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"log/slog"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ResetPassword sets new password with token of reset link sent when admin forces password reset,
+// token carries stamp of password it replaces, so it stops working once password is changed
+func (s *AuthService) ResetPassword(ctx context.Context, actor commonschema.Actor, body authschema.ResetPasswordPayload) (*string, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.ResetPassword")
+	defer span.End()
+
+	// check confirm password
+	if body.NewPassword != body.ConfirmPassword {
+		return nil, apperrors.Field("confirm_password", "does not match")
+	}
+
+	invalidLink := apperrors.Unauthorized("reset link is expired or already used, please ask admin for a new one")
+	decode, err := s.Keyring.Parse(ctx, body.Token, jwt.MapClaims{})
+	if err != nil {
+		return nil, invalidLink
+	}
+	claims, ok := decode.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != keyring.TokenPasswordReset {
+		return nil, invalidLink
+	}
+	userID, _ := claims["sub"].(string)
+	stamp, _ := claims["pwd"].(string)
+
+	data, err := s.UserRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalidLink
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(keyring.PasswordStamp(data.Password)), []byte(stamp)) != 1 {
+		return nil, invalidLink
+	}
+	if !data.IsActive || data.Deleted {
+		return nil, apperrors.Forbidden("your account is deactivated, please contact admin")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(body.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	// perform to update password
+	now := time.Now()
+	if err := s.UserRepo.UpdateUser(ctx, userID, models.Users{Password: string(hashedPassword), UpdatedAt: &now}); err != nil {
+		return nil, err
+	}
+
+	// anyone who signed in before the reset is signed out, lock of failed logins from this address is lifted
+	if err := s.Sessions.RevokeAll(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := s.Limiter.Reset(loginLockKey(data.Email, actor.IP)); err != nil {
+		slog.ErrorContext(ctx, "failed to reset login attempts", "error", err)
+	}
+
+	responseMsg := "Password is changed, please login with your new password"
+	return &responseMsg, nil
+}
@@ -0,0 +1,271 @@
+package authusecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/applications/keyring"
+	"kiraform/src/applications/models"
+	"kiraform/src/applications/sessions"
+	"kiraform/src/infras/mailer"
+	"kiraform/src/infras/tracing"
+	authschema "kiraform/src/interfaces/rest/schemas/auths"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"kiraform/src/utils"
+	"log/slog"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// loginCodeTTL is how long code and link of passwordless login stay valid
+	loginCodeTTL = 10 * time.Minute
+
+	// maxLoginCodeRequests limits emails sent to one address in loginLockWindow
+	maxLoginCodeRequests = 3
+)
+
+// RequestPasswordless sends one-time code, and magic link when its page is configured, to email.
+// New email gets pending account which is activated by its first login
+func (s *AuthService) RequestPasswordless(ctx context.Context, body authschema.PasswordlessPayload) (*string, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.RequestPasswordless")
+	defer span.End()
+
+	// same response for every email, so existing account can not be guessed
+	responseMsg := "If the email can sign in, a login code has been sent to it"
+	email := strings.ToLower(body.Email)
+
+	attempts, ttl, err := s.Limiter.Hit("passwordless_request:"+email, loginLockWindow)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to count passwordless requests", "error", err)
+	}
+	if attempts > maxLoginCodeRequests {
+		return nil, apperrors.TooManyRequests("too many login codes requested, please wait a while", ttl)
+	}
+
+	// get data, or create pending account for new email
+	data, err := s.UserRepo.FindUserByEmail(ctx, body.Email)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		data, err = s.createPendingUser(ctx, body.Email)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// deactivated account gets no email
+	if !data.IsActive && !data.IsPending {
+		return &responseMsg, nil
+	}
+
+	code, err := loginCode()
+	if err != nil {
+		return nil, err
+	}
+	dataCode := models.LoginCodes{
+		ID:        uuid.New(),
+		UserID:    data.ID,
+		Email:     email,
+		CodeHash:  hashLoginCode(code),
+		ExpiresAt: time.Now().Add(loginCodeTTL),
+		CreatedAt: time.Now(),
+	}
+	if err := s.LoginCodeRepo.CreateLoginCode(ctx, dataCode); err != nil {
+		return nil, err
+	}
+
+	lines := []string{
+		fmt.Sprintf("Your %s login code is %s", s.Config.Issuer, code),
+		"",
+		fmt.Sprintf("It expires in %d minutes and works once.", int(loginCodeTTL.Minutes())),
+	}
+
+	// magic link carries id of the same code, using either one uses both
+	if s.Config.MagicLinkURL != "" {
+		token, err := s.Keyring.Sign(jwt.MapClaims{
+			"sub": data.ID.String(),
+			"jti": dataCode.ID.String(),
+			"typ": keyring.TokenMagicLink,
+			"exp": dataCode.ExpiresAt.Unix(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, "", "Or sign in with this link:", s.Config.MagicLinkURL+"?token="+url.QueryEscape(token))
+	}
+	lines = append(lines, "", "If you did not request it, you can ignore this email.")
+
+	if err := s.Mailer.Send(ctx, mailer.Message{
+		To:      data.Email,
+		Subject: s.Config.Issuer + " login code",
+		Body:    strings.Join(lines, "\n"),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &responseMsg, nil
+}
+
+// LoginPasswordless exchanges code sent by RequestPasswordless for access token
+func (s *AuthService) LoginPasswordless(ctx context.Context, actor commonschema.Actor, body authschema.PasswordlessCodePayload) (*authschema.LoginResult, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.LoginPasswordless")
+	defer span.End()
+
+	// guessing code is limited per email like password
+	email := strings.ToLower(body.Email)
+	lockKey := "passwordless_failed:" + email
+	attempts, ttl, err := s.Limiter.Get(lockKey)
+	if err == nil && attempts >= maxLoginAttempts {
+		s.Sessions.Failed(ctx, actor, sessions.Attempt{Email: email, Method: sessions.MethodEmailCode, Reason: "account locked"})
+		return nil, apperrors.TooManyRequests("too many failed login attempts, your account is locked for a while", ttl)
+	}
+
+	data, err := s.LoginCodeRepo.FindActiveLoginCode(ctx, email, time.Now())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.Sessions.Failed(ctx, actor, sessions.Attempt{Email: email, Method: sessions.MethodEmailCode, Reason: "no active code"})
+			return nil, s.loginCodeFailed(ctx, lockKey)
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashLoginCode(body.Code)), []byte(data.CodeHash)) != 1 {
+		s.Sessions.Failed(ctx, actor, sessions.Attempt{Email: email, UserID: &data.UserID, Method: sessions.MethodEmailCode, Reason: "wrong code"})
+		return nil, s.loginCodeFailed(ctx, lockKey)
+	}
+
+	// code works once, concurrent request with same code loses here
+	used, err := s.LoginCodeRepo.UseLoginCode(ctx, data.ID.String(), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, s.loginCodeFailed(ctx, lockKey)
+	}
+	if err := s.Limiter.Reset(lockKey); err != nil {
+		slog.ErrorContext(ctx, "failed to reset passwordless attempts", "error", err)
+	}
+
+	return s.completePasswordless(ctx, actor, data.UserID.String(), sessions.MethodEmailCode)
+}
+
+// LoginMagicLink exchanges token of magic link for access token
+func (s *AuthService) LoginMagicLink(ctx context.Context, actor commonschema.Actor, body authschema.MagicLinkPayload) (*authschema.LoginResult, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.LoginMagicLink")
+	defer span.End()
+
+	invalidLink := apperrors.Unauthorized("login link is expired or already used, please request a new one")
+	decode, err := s.Keyring.Parse(ctx, body.Token, jwt.MapClaims{})
+	if err != nil {
+		return nil, invalidLink
+	}
+	claims, ok := decode.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != keyring.TokenMagicLink {
+		return nil, invalidLink
+	}
+	codeID, _ := claims["jti"].(string)
+	userID, _ := claims["sub"].(string)
+	if _, err := uuid.Parse(codeID); err != nil {
+		return nil, invalidLink
+	}
+
+	data, err := s.LoginCodeRepo.FindLoginCode(ctx, codeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalidLink
+		}
+		return nil, err
+	}
+	if data.UserID.String() != userID {
+		return nil, invalidLink
+	}
+
+	// link works once, it is replaced too when new code is requested
+	used, err := s.LoginCodeRepo.UseLoginCode(ctx, codeID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		s.Sessions.Failed(ctx, actor, sessions.Attempt{Email: data.Email, UserID: &data.UserID, Method: sessions.MethodMagicLink, Reason: "link expired or used"})
+		return nil, invalidLink
+	}
+
+	return s.completePasswordless(ctx, actor, userID, sessions.MethodMagicLink)
+}
+
+// completePasswordless signs in owner of used code, its email is verified by receiving it
+func (s *AuthService) completePasswordless(ctx context.Context, actor commonschema.Actor, userID string, method string) (*authschema.LoginResult, error) {
+	data, err := s.UserRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.Unauthorized("account is not found, please request a new code")
+		}
+		return nil, err
+	}
+	if err := s.activatePending(ctx, data); err != nil {
+		return nil, err
+	}
+	if !data.IsActive {
+		s.Sessions.Failed(ctx, actor, sessions.Attempt{Email: data.Email, UserID: &data.ID, Method: method, Reason: "account deactivated"})
+		return nil, apperrors.Forbidden("your account is deactivated, please contact admin")
+	}
+	return s.completeLogin(ctx, actor, data, method)
+}
+
+// createPendingUser registers lightweight account of new email, it stays inactive until its first code is used
+func (s *AuthService) createPendingUser(ctx context.Context, email string) (*models.Users, error) {
+	hashedPassword, err := utils.UnusablePassword()
+	if err != nil {
+		return nil, err
+	}
+	dataUser, dataUserProfile, dataUserRole, err := s.newAccount(ctx, email, strings.Split(email, "@")[0], hashedPassword)
+	if err != nil {
+		return nil, err
+	}
+	dataUser.IsActive = false
+	dataUser.IsPending = true
+
+	// perform to insert data
+	if err := s.UserRepo.CreateUser(ctx, dataUser, dataUserProfile, dataUserRole); err != nil {
+		return nil, err
+	}
+	return &dataUser, nil
+}
+
+// loginCodeFailed counts failed attempt of passwordless code
+func (s *AuthService) loginCodeFailed(ctx context.Context, lockKey string) error {
+	attempts, ttl, err := s.Limiter.Hit(lockKey, loginLockWindow)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to count passwordless attempts", "error", err)
+	}
+	if attempts >= maxLoginAttempts {
+		return apperrors.TooManyRequests("too many failed login attempts, your account is locked for a while", ttl)
+	}
+	return apperrors.Unauthorized("login code is invalid or expired")
+}
+
+// loginCode returns random 6 digits code
+func loginCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func hashLoginCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
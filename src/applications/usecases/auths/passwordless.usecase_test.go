@@ -0,0 +1,236 @@
+package authusecase
+
+import (
+	"context"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/applications/audit"
+	masterrepo "kiraform/src/applications/repos/masters"
+	masterusecase "kiraform/src/applications/usecases/masters"
+	authschema "kiraform/src/interfaces/rest/schemas/auths"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestDeactivatedPendingUserStaysInactive(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuth(t)
+	email := "pending@example.com"
+
+	// passwordless request of new email creates pending account and sends its code
+	if _, err := a.RequestPasswordless(ctx, authschema.PasswordlessPayload{Email: email}); err != nil {
+		t.Fatalf("request passwordless: %v", err)
+	}
+	code := a.sentCode(t, email)
+	pending := a.user(t, email)
+	if pending.IsActive || !pending.IsPending {
+		t.Fatalf("got active %v pending %v, want pending account", pending.IsActive, pending.IsPending)
+	}
+
+	// admin deactivates it before the code is used
+	users := masterusecase.NewUserUsecase(a.UserRepo, a.RoleRepo, nil, a.Sessions, a.Keyring, a.Mailer, a.Config, audit.NewRecorder(masterrepo.NewAuditRepository(a.DB)))
+	inactive := false
+	admin := commonschema.Actor{UserID: "00000000-0000-0000-0000-000000000001"}
+	if err := users.UpdateUserActive(ctx, admin, pending.ID.String(), masterschema.UserActivePayload{IsActive: &inactive}); err != nil {
+		t.Fatalf("deactivate user: %v", err)
+	}
+
+	// deactivated account gets no new code
+	sent := a.mail.Count(email)
+	if _, err := a.RequestPasswordless(ctx, authschema.PasswordlessPayload{Email: email}); err != nil {
+		t.Fatalf("request passwordless: %v", err)
+	}
+	if a.mail.Count(email) != sent {
+		t.Fatal("deactivated account got a login code")
+	}
+
+	// code sent before deactivation does not activate it either
+	_, err := a.LoginPasswordless(ctx, commonschema.Actor{}, authschema.PasswordlessCodePayload{Email: email, Code: code})
+	wantCode(t, err, apperrors.CodeForbidden)
+	after := a.user(t, email)
+	if after.IsActive || after.IsPending {
+		t.Fatalf("got active %v pending %v, want inactive account", after.IsActive, after.IsPending)
+	}
+}
+
+func TestLoginCodeWorksOnce(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuth(t)
+	email := "jane@example.com"
+	a.addUser(t, email)
+
+	if _, err := a.RequestPasswordless(ctx, authschema.PasswordlessPayload{Email: email}); err != nil {
+		t.Fatalf("request passwordless: %v", err)
+	}
+	code := a.sentCode(t, email)
+	result, err := a.LoginPasswordless(ctx, commonschema.Actor{}, authschema.PasswordlessCodePayload{Email: email, Code: code})
+	if err != nil {
+		t.Fatalf("login passwordless: %v", err)
+	}
+	if result.AccessToken == "" {
+		t.Fatal("no access token issued")
+	}
+
+	_, err = a.LoginPasswordless(ctx, commonschema.Actor{}, authschema.PasswordlessCodePayload{Email: email, Code: code})
+	wantCode(t, err, apperrors.CodeUnauthorized)
+}
+
+func TestExpiredLoginCodeRefused(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuth(t)
+	email := "pending@example.com"
+
+	if _, err := a.RequestPasswordless(ctx, authschema.PasswordlessPayload{Email: email}); err != nil {
+		t.Fatalf("request passwordless: %v", err)
+	}
+	code := a.sentCode(t, email)
+	a.repos.mu.Lock()
+	for _, v := range a.repos.codes {
+		v.ExpiresAt = time.Now().Add(-time.Second)
+	}
+	a.repos.mu.Unlock()
+
+	_, err := a.LoginPasswordless(ctx, commonschema.Actor{}, authschema.PasswordlessCodePayload{Email: email, Code: code})
+	wantCode(t, err, apperrors.CodeUnauthorized)
+	if data := a.user(t, email); data.IsActive || !data.IsPending {
+		t.Fatalf("got active %v pending %v, want account still pending", data.IsActive, data.IsPending)
+	}
+}
+
+func TestPasswordlessRateLimit(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuth(t)
+	email := "jane@example.com"
+	a.addUser(t, email)
+
+	// emails to one address are limited, letter case does not reset the counter
+	for i := range maxLoginCodeRequests {
+		if _, err := a.RequestPasswordless(ctx, authschema.PasswordlessPayload{Email: email}); err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+	}
+	_, err := a.RequestPasswordless(ctx, authschema.PasswordlessPayload{Email: "Jane@Example.com"})
+	wantCode(t, err, apperrors.CodeTooMany)
+	if got := a.mail.Count(email); got != maxLoginCodeRequests {
+		t.Fatalf("got %d emails, want %d", got, maxLoginCodeRequests)
+	}
+
+	// guessing locks the email, even the right code is refused afterwards
+	code := a.sentCode(t, email)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	for range maxLoginAttempts - 1 {
+		_, err := a.LoginPasswordless(ctx, commonschema.Actor{}, authschema.PasswordlessCodePayload{Email: email, Code: wrong})
+		wantCode(t, err, apperrors.CodeUnauthorized)
+	}
+	_, err = a.LoginPasswordless(ctx, commonschema.Actor{}, authschema.PasswordlessCodePayload{Email: email, Code: wrong})
+	wantCode(t, err, apperrors.CodeTooMany)
+	_, err = a.LoginPasswordless(ctx, commonschema.Actor{}, authschema.PasswordlessCodePayload{Email: email, Code: code})
+	wantCode(t, err, apperrors.CodeTooMany)
+}
+
+var magicLinkPattern = regexp.MustCompile(`\?token=(\S+)`)
+
+func TestMagicLink(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuth(t)
+	email := "pending@example.com"
+
+	if _, err := a.RequestPasswordless(ctx, authschema.PasswordlessPayload{Email: email}); err != nil {
+		t.Fatalf("request passwordless: %v", err)
+	}
+	msg, _ := a.mail.Last(email)
+	match := magicLinkPattern.FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("no magic link in email:\n%s", msg.Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatalf("unescape token: %v", err)
+	}
+
+	if _, err := a.LoginMagicLink(ctx, commonschema.Actor{}, authschema.MagicLinkPayload{Token: token}); err != nil {
+		t.Fatalf("login magic link: %v", err)
+	}
+	if data := a.user(t, email); !data.IsActive || data.IsPending {
+		t.Fatalf("got active %v pending %v, want verified account", data.IsActive, data.IsPending)
+	}
+
+	// link works once and spends the code of the same email
+	_, err = a.LoginMagicLink(ctx, commonschema.Actor{}, authschema.MagicLinkPayload{Token: token})
+	wantCode(t, err, apperrors.CodeUnauthorized)
+	_, err = a.LoginPasswordless(ctx, commonschema.Actor{}, authschema.PasswordlessCodePayload{Email: email, Code: a.sentCode(t, email)})
+	wantCode(t, err, apperrors.CodeUnauthorized)
+
+	// tampered link is refused
+	_, err = a.LoginMagicLink(ctx, commonschema.Actor{}, authschema.MagicLinkPayload{Token: token + "x"})
+	wantCode(t, err, apperrors.CodeUnauthorized)
+}
+
+func TestPendingUserActiveOnlyAfterVerification(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuth(t)
+	email := "pending@example.com"
+
+	if _, err := a.RequestPasswordless(ctx, authschema.PasswordlessPayload{Email: email}); err != nil {
+		t.Fatalf("request passwordless: %v", err)
+	}
+	code := a.sentCode(t, email)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	// password login and wrong code do not activate it
+	_, err := a.Login(ctx, commonschema.Actor{}, authschema.LoginPayload{Email: email, Password: "anything"})
+	if err == nil {
+		t.Fatal("pending account signed in with password")
+	}
+	_, err = a.LoginPasswordless(ctx, commonschema.Actor{}, authschema.PasswordlessCodePayload{Email: email, Code: wrong})
+	wantCode(t, err, apperrors.CodeUnauthorized)
+	if data := a.user(t, email); data.IsActive || !data.IsPending {
+		t.Fatalf("got active %v pending %v, want account still pending", data.IsActive, data.IsPending)
+	}
+
+	if _, err := a.LoginPasswordless(ctx, commonschema.Actor{}, authschema.PasswordlessCodePayload{Email: email, Code: code}); err != nil {
+		t.Fatalf("login passwordless: %v", err)
+	}
+	if data := a.user(t, email); !data.IsActive || data.IsPending {
+		t.Fatalf("got active %v pending %v, want verified account", data.IsActive, data.IsPending)
+	}
+}
+
+func TestRegisterTakesOverPendingUser(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuth(t)
+	email := "pending@example.com"
+
+	// someone requested a code for the email but never used it
+	if _, err := a.RequestPasswordless(ctx, authschema.PasswordlessPayload{Email: email}); err != nil {
+		t.Fatalf("request passwordless: %v", err)
+	}
+	pending := a.user(t, email)
+
+	if _, err := a.Register(ctx, authschema.RegisterPayload{Email: email, Password: "secret password", Fullname: "Jane Doe"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	data := a.user(t, email)
+	if data.ID != pending.ID || !data.IsActive || data.IsPending || data.Fullname != "Jane Doe" {
+		t.Fatalf("got user %+v, want pending account registered", data)
+	}
+	if len(a.repos.profiles) != 1 || a.repos.profiles[0].FirstName != "Jane" || a.repos.profiles[0].MiddleName != "Doe" {
+		t.Fatalf("got profiles %+v, want names of registration", a.repos.profiles)
+	}
+	if _, err := a.Login(ctx, commonschema.Actor{}, authschema.LoginPayload{Email: email, Password: "secret password"}); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	// verified account is not taken over
+	_, err := a.Register(ctx, authschema.RegisterPayload{Email: email, Password: "another password", Fullname: "Mallory"})
+	wantCode(t, err, apperrors.CodeConflict)
+}
@@ -0,0 +1,109 @@
+package authusecase
+
+import (
+	"context"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/applications/sso"
+	"kiraform/src/applications/sso/ssotest"
+	"kiraform/src/infras/configs"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"testing"
+)
+
+// ssoIdentity signs user in on fake provider and returns identity of the callback
+func ssoIdentity(t *testing.T, claims ssotest.Claims) sso.Identity {
+	t.Helper()
+	ctx := context.Background()
+	provider := ssotest.NewProvider(t, "kiraform", "client secret")
+	registry, err := sso.New(configs.OIDCConfig{
+		Providers:   []configs.OIDCProvider{{Name: "corp", Issuer: provider.URL, ClientID: provider.ClientID, ClientSecret: provider.ClientSecret}},
+		BaseURL:     "https://api.example.com",
+		FrontendURL: "https://app.example.com/sso",
+	}, "test secret key")
+	if err != nil {
+		t.Fatalf("new registry: %v", err)
+	}
+	registry.HTTPClient = provider.Client()
+
+	authURL, sealed, err := registry.Start(ctx, "corp")
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	code, state := provider.Login(t, authURL, claims)
+	identity, err := registry.Finish(ctx, "corp", code, state, sealed)
+	if err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+	return *identity
+}
+
+func TestSSOLinksUserOfVerifiedEmail(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuth(t)
+	existing := a.addUser(t, "jane@example.com")
+
+	identity := ssoIdentity(t, ssotest.Claims{Subject: "sub-1", Email: "Jane@example.com", EmailVerified: true, Name: "Jane Doe"})
+	if _, err := a.SSOLogin(ctx, commonschema.Actor{}, identity); err != nil {
+		t.Fatalf("sso login: %v", err)
+	}
+	if len(a.repos.users) != 1 {
+		t.Fatalf("got %d users, want existing user only", len(a.repos.users))
+	}
+	if len(a.repos.identities) != 1 || a.repos.identities[0].UserID != existing.ID || a.repos.identities[0].Subject != "sub-1" {
+		t.Fatalf("got identities %+v, want identity of existing user", a.repos.identities)
+	}
+
+	// next login goes through linked identity
+	if _, err := a.SSOLogin(ctx, commonschema.Actor{}, identity); err != nil {
+		t.Fatalf("sso login: %v", err)
+	}
+	if len(a.repos.identities) != 1 {
+		t.Fatalf("got %d identities, want 1", len(a.repos.identities))
+	}
+}
+
+func TestSSORefusesUnverifiedEmail(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuth(t)
+	a.addUser(t, "jane@example.com")
+
+	// anyone can put victim email on provider account, unverified email must not take over the user
+	identity := ssoIdentity(t, ssotest.Claims{Subject: "attacker", Email: "jane@example.com", EmailVerified: false})
+	_, err := a.SSOLogin(ctx, commonschema.Actor{}, identity)
+	wantCode(t, err, apperrors.CodeForbidden)
+	if len(a.repos.identities) != 0 {
+		t.Fatalf("got identities %+v, want none", a.repos.identities)
+	}
+
+	// nor register new one
+	identity = ssoIdentity(t, ssotest.Claims{Subject: "attacker", Email: "new@example.com"})
+	_, err = a.SSOLogin(ctx, commonschema.Actor{}, identity)
+	wantCode(t, err, apperrors.CodeForbidden)
+	if len(a.repos.users) != 1 {
+		t.Fatalf("got %d users, want 1", len(a.repos.users))
+	}
+}
+
+func TestSSOFirstLoginRegistersUser(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuth(t)
+
+	identity := ssoIdentity(t, ssotest.Claims{Subject: "sub-1", Email: "jane@example.com", EmailVerified: "true", Name: "Jane Doe"})
+	if _, err := a.SSOLogin(ctx, commonschema.Actor{}, identity); err != nil {
+		t.Fatalf("sso login: %v", err)
+	}
+
+	data := a.user(t, "jane@example.com")
+	if !data.IsActive || data.IsPending || data.Fullname != "Jane Doe" {
+		t.Fatalf("got user %+v, want active user named by provider", data)
+	}
+	if len(a.repos.profiles) != 1 || a.repos.profiles[0].UserID != data.ID {
+		t.Fatalf("got profiles %+v, want profile of new user", a.repos.profiles)
+	}
+	if len(a.repos.userRoles) != 1 || a.repos.userRoles[0].UserID != data.ID || a.repos.userRoles[0].RoleID != a.repos.roleUser.ID {
+		t.Fatalf("got roles %+v, want user role", a.repos.userRoles)
+	}
+	if len(a.repos.identities) != 1 || a.repos.identities[0].UserID != data.ID {
+		t.Fatalf("got identities %+v, want identity of new user", a.repos.identities)
+	}
+}
@@ -0,0 +1,235 @@
+package masterusecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/applications/audit"
+	"kiraform/src/applications/models"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/infras/tracing"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// APIKeyPrefix tells api key apart from access token in bearer header
+	APIKeyPrefix = "kf_"
+
+	// apiKeyBytes is random part of key, 32 bytes makes guessing or brute forcing its hash pointless
+	apiKeyBytes = 32
+
+	// apiKeyShownLength is how much of key is kept in plain text to tell keys apart
+	apiKeyShownLength = 10
+)
+
+type APIKeyUsecase interface {
+	FindAPIKeys(ctx context.Context, workspaceID string) ([]masterschema.APIKeyResponse, error)
+	CreateAPIKey(ctx context.Context, actor commonschema.Actor, workspaceID string, body masterschema.APIKeyPayload, mfa bool) (*masterschema.APIKeyCreatedResponse, error)
+	RevokeAPIKey(ctx context.Context, actor commonschema.Actor, workspaceID string, ID string) error
+	Authenticate(ctx context.Context, key string) (*models.APIKeys, error)
+}
+
+type APIKeyService struct {
+	apiKeyRepo    masterrepo.APIKeyRepository
+	workspaceRepo masterrepo.WorkspaceRepository
+	userRepo      masterrepo.UserRepository
+	audit         *audit.Recorder
+}
+
+func NewAPIKeyUsecase(apiKeyRepo masterrepo.APIKeyRepository, workspaceRepo masterrepo.WorkspaceRepository, userRepo masterrepo.UserRepository, recorder *audit.Recorder) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo:    apiKeyRepo,
+		workspaceRepo: workspaceRepo,
+		userRepo:      userRepo,
+		audit:         recorder,
+	}
+}
+
+func (s *APIKeyService) FindAPIKeys(ctx context.Context, workspaceID string) ([]masterschema.APIKeyResponse, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyService.FindAPIKeys")
+	defer span.End()
+
+	keys, err := s.apiKeyRepo.FindAPIKeys(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	response := make([]masterschema.APIKeyResponse, 0, len(keys))
+	for _, v := range keys {
+		response = append(response, apiKeyResponse(v))
+	}
+	return response, nil
+}
+
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, actor commonschema.Actor, workspaceID string, body masterschema.APIKeyPayload, mfa bool) (*masterschema.APIKeyCreatedResponse, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyService.CreateAPIKey")
+	defer span.End()
+
+	UUIDworkspaceID, err := uuid.Parse(workspaceID)
+	if err != nil {
+		return nil, apperrors.NotFound("workspace is not found")
+	}
+	UUIDuserID, err := uuid.Parse(actor.UserID)
+	if err != nil {
+		return nil, apperrors.Unauthorized("your identity is not recognized, please contact our admin")
+	}
+
+	// key acts on behalf of its creator, so creator must be a member even when being admin
+	if _, err := s.workspaceRepo.FindWorkspaceUserByUserApproved(ctx, workspaceID, actor.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.Forbidden("only approved member of this workspace can create api key")
+		}
+		return nil, err
+	}
+
+	// generate key, only its hash is stored
+	random := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	t := time.Now()
+	data := models.APIKeys{
+		ID:          uuid.New(),
+		WorkspaceID: UUIDworkspaceID,
+		UserID:      UUIDuserID,
+		Name:        body.Name,
+		Prefix:      key[:apiKeyShownLength],
+		KeyHash:     hashAPIKey(key),
+		Scopes:      strings.Join(uniqueScopes(body.Scopes), ","),
+		ExpiresAt:   t.AddDate(0, 0, body.ExpiresInDays),
+		CreatedAt:   t,
+
+		// key keeps two-factor state of its creator, workspace requiring it refuses key without it
+		CreatedWithMFA: mfa,
+	}
+	if err := s.apiKeyRepo.CreateAPIKey(ctx, data); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		WorkspaceID: workspaceID,
+		Action:      audit.ActionCreate,
+		EntityType:  "api_key",
+		EntityID:    data.ID.String(),
+		After:       data,
+	})
+
+	return &masterschema.APIKeyCreatedResponse{
+		APIKeyResponse: apiKeyResponse(data),
+		Key:            key,
+	}, nil
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, actor commonschema.Actor, workspaceID string, ID string) error {
+	ctx, span := tracing.Start(ctx, "APIKeyService.RevokeAPIKey")
+	defer span.End()
+
+	if _, err := uuid.Parse(ID); err != nil {
+		return apperrors.NotFound("api key is not found")
+	}
+	before, err := s.apiKeyRepo.FindAPIKeyByID(ctx, workspaceID, ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound("api key is not found")
+		}
+		return err
+	}
+	if err := s.apiKeyRepo.RevokeAPIKey(ctx, workspaceID, ID, time.Now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound("api key is not found")
+		}
+		return err
+	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		WorkspaceID: workspaceID,
+		Action:      audit.ActionDelete,
+		EntityType:  "api_key",
+		EntityID:    ID,
+		Before:      before,
+	})
+	return nil
+}
+
+// Authenticate finds usable key, every failure gives the same message so keys can not be probed
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*models.APIKeys, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyService.Authenticate")
+	defer span.End()
+
+	invalid := apperrors.Unauthorized("invalid or expired api key")
+	data, err := s.apiKeyRepo.FindAPIKeyByHash(ctx, hashAPIKey(key))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	t := time.Now()
+	if data.RevokedAt != nil || !t.Before(data.ExpiresAt) {
+		return nil, invalid
+	}
+
+	// deactivated or deleted creator takes its keys down too
+	user, err := s.userRepo.FindUserByID(ctx, data.UserID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	if !user.IsActive || user.Deleted {
+		return nil, invalid
+	}
+
+	// last used time is informative, failing to write it must not block the request
+	if err := s.apiKeyRepo.TouchAPIKey(ctx, data.ID.String(), t); err != nil {
+		slog.WarnContext(ctx, "failed to update api key last used time", "api_key_id", data.ID, "error", err)
+	}
+	return data, nil
+}
+
+// APIKeyScopes splits stored scopes of key
+func APIKeyScopes(key *models.APIKeys) []string {
+	return strings.Split(key.Scopes, ",")
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func uniqueScopes(scopes []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, v := range scopes {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func apiKeyResponse(data models.APIKeys) masterschema.APIKeyResponse {
+	return masterschema.APIKeyResponse{
+		ID:         data.ID,
+		Name:       data.Name,
+		Prefix:     data.Prefix,
+		Scopes:     APIKeyScopes(&data),
+		CreatedBy:  data.UserID,
+		MFA:        data.CreatedWithMFA,
+		ExpiresAt:  data.ExpiresAt,
+		LastUsedAt: data.LastUsedAt,
+		CreatedAt:  data.CreatedAt,
+	}
+}
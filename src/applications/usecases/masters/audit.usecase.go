@@ -0,0 +1,64 @@
+package masterusecase
+
+import (
+	"context"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/infras/tracing"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"kiraform/src/utils"
+	"math"
+)
+
+type AuditUsecase interface {
+	FindAuditLogs(ctx context.Context, workspaceID *string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
+}
+
+type AuditService struct {
+	auditRepo masterrepo.AuditRepository
+}
+
+func NewAuditUsecase(auditRepo masterrepo.AuditRepository) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// FindAuditLogs returns audit logs of workspace, or all of them when workspaceID is nil
+func (s *AuditService) FindAuditLogs(ctx context.Context, workspaceID *string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "AuditService.FindAuditLogs")
+	defer span.End()
+
+	response := commonschema.ResponseList{
+		Parameters: *params,
+		TotalPage:  1,
+		Rows:       nil,
+	}
+
+	// get list data
+	rows, err := s.auditRepo.FindAuditLogs(ctx, workspaceID, params)
+	if err != nil {
+		return nil, err
+	}
+	rows, response.NextCursor, response.PrevCursor = utils.CursorPage(rows, params, func(v masterschema.AuditLogSchema) (string, string) {
+		return utils.CursorTime(v.CreatedAt), v.ID
+	})
+
+	// get count data, client can skip it on large list
+	totalPage := 0
+	if !params.SkipCount {
+		count, err := s.auditRepo.FindCountAuditLog(ctx, workspaceID, params)
+		if err != nil {
+			return nil, err
+		}
+		totalPage = 1
+		if count > 0 {
+			totalPage = int(math.Ceil(float64(int(count)) / float64(params.Limit)))
+		}
+	}
+
+	// send response
+	response.TotalPage = totalPage
+	response.Rows = rows
+	return &response, nil
+}
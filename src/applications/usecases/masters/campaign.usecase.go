@@ -1,10 +1,14 @@
 package masterusecase
 
 import (
+	"context"
 	"errors"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/applications/audit"
 	"kiraform/src/applications/models"
 	masterrepo "kiraform/src/applications/repos/masters"
 	storerepo "kiraform/src/applications/repos/stores"
+	"kiraform/src/infras/tracing"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
 	masterschema "kiraform/src/interfaces/rest/schemas/masters"
 	"kiraform/src/utils"
@@ -19,40 +23,64 @@ import (
 )
 
 type CampaignUsecase interface {
-	FindCampaigns(workspaceID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
-	FindCampaign(workspaceID string, ID string) (*masterschema.DetailCampaignSchema, error)
-	CampaignDashboard(workspaceID string) (*masterschema.CampaignDashboard, error)
-	FindCampaignByKey(key string, isPublish *bool) (*masterschema.DetailCampaignSchema, error)
-	FindFormsByCampaign(campaignID string) ([]masterschema.DetailCampaignFormSchema, error)
-	FindFormAttributes(campaignFormID string) ([]masterschema.CampaignFormAttributeSchemas, error)
-	CreateCampaign(workspaceID string, body masterschema.CampaignPayload) error
-	UpdateCampaign(workspaceID string, ID string, body masterschema.CampaignPayload) error
-	DeleteCampaign(workspaceID string, ID string) error
-	FindCampaignSeos(campaignID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
-	FindCampaignSeoByID(campaignID string, ID string) (*masterschema.CampaignSeoSchema, error)
-	CreateCampaignSeo(campaignID string, body masterschema.CampaignSeoPayload) error
-	UpdateCampaignSeo(campaignID string, ID string, body masterschema.CampaignSeoPayload) error
-	DeleteCampaignSeo(campaignID string, ID string) error
-	FindSummaryEntriesByDate(workspaceID string, campaignID string) ([]masterschema.CampaignFormEntryChart, error)
-	FindFormEntries(workspaceID string, campaignID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
-	FindFormEntry(c echo.Context, ID string) (*masterschema.FormEntryResponse, error)
+	FindCampaigns(ctx context.Context, workspaceID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
+	FindCampaign(ctx context.Context, workspaceID string, ID string) (*masterschema.DetailCampaignSchema, error)
+	CampaignDashboard(ctx context.Context, workspaceID string) (*masterschema.CampaignDashboard, error)
+	FindCampaignByKey(ctx context.Context, key string, isPublish *bool) (*masterschema.DetailCampaignSchema, error)
+	FindFormsByCampaign(ctx context.Context, campaignID string) ([]masterschema.DetailCampaignFormSchema, error)
+	FindFormAttributes(ctx context.Context, campaignFormID string) ([]masterschema.CampaignFormAttributeSchemas, error)
+	CreateCampaign(ctx context.Context, actor commonschema.Actor, workspaceID string, body masterschema.CampaignPayload) error
+	UpdateCampaign(ctx context.Context, actor commonschema.Actor, workspaceID string, ID string, body masterschema.CampaignPayload) error
+	DeleteCampaign(ctx context.Context, actor commonschema.Actor, workspaceID string, ID string) error
+	FindCampaignSeos(ctx context.Context, campaignID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
+	FindCampaignSeoByID(ctx context.Context, campaignID string, ID string) (*masterschema.CampaignSeoSchema, error)
+	CreateCampaignSeo(ctx context.Context, actor commonschema.Actor, campaignID string, body masterschema.CampaignSeoPayload) error
+	UpdateCampaignSeo(ctx context.Context, actor commonschema.Actor, campaignID string, ID string, body masterschema.CampaignSeoPayload) error
+	DeleteCampaignSeo(ctx context.Context, actor commonschema.Actor, campaignID string, ID string) error
+	FindSummaryEntriesByDate(ctx context.Context, workspaceID string, campaignID string, params *masterschema.AnalyticsParams) (*masterschema.CampaignEntryAnalytics, error)
+	FindFieldAnalytics(ctx context.Context, workspaceID string, campaignID string, params *masterschema.AnalyticsParams) (*masterschema.CampaignFieldAnalyticsResponse, error)
+	FindFormEntries(ctx context.Context, workspaceID string, campaignID string, params *commonschema.QueryParams, filter *masterschema.FormEntryFilter) (*commonschema.ResponseList, error)
+	FindFormEntry(ctx context.Context, c echo.Context, ID string) (*masterschema.FormEntryResponse, error)
+	ReviewFormEntry(ctx context.Context, actor commonschema.Actor, campaignID string, ID string, body masterschema.FormEntryReviewPayload) error
+	RecordVisit(ctx context.Context, campaignID string) error
+	FindSpamSetting(ctx context.Context, campaignID string) (*masterschema.CampaignSpamSettingSchema, error)
+	UpdateSpamSetting(ctx context.Context, actor commonschema.Actor, campaignID string, body masterschema.CampaignSpamSettingPayload) error
 }
 
 type CampaignService struct {
 	campaignRepo  masterrepo.CampaignRepository
 	workspaceRepo masterrepo.WorkspaceRepository
 	storeRepo     storerepo.StoreRepository
+	audit         *audit.Recorder
 }
 
-func NewCampaignUsecase(campaignRepo masterrepo.CampaignRepository, workspaceRepo masterrepo.WorkspaceRepository, storeRepo storerepo.StoreRepository) *CampaignService {
+func NewCampaignUsecase(campaignRepo masterrepo.CampaignRepository, workspaceRepo masterrepo.WorkspaceRepository, storeRepo storerepo.StoreRepository, recorder *audit.Recorder) *CampaignService {
 	return &CampaignService{
 		campaignRepo:  campaignRepo,
 		workspaceRepo: workspaceRepo,
 		storeRepo:     storeRepo,
+		audit:         recorder,
 	}
 }
 
-func (s *CampaignService) FindCampaigns(workspaceID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+// campaignSnapshot returns campaign with its forms for audit log
+func (s *CampaignService) campaignSnapshot(ctx context.Context, workspaceID string, ID string) (*masterschema.DetailCampaignSchema, error) {
+	data, err := s.FindCampaign(ctx, workspaceID, ID)
+	if err != nil {
+		return nil, err
+	}
+	forms, err := s.FindFormsByCampaign(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	data.Forms = forms
+	return data, nil
+}
+
+func (s *CampaignService) FindCampaigns(ctx context.Context, workspaceID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "CampaignService.FindCampaigns")
+	defer span.End()
+
 	response := commonschema.ResponseList{
 		Parameters: *params,
 		TotalPage:  1,
@@ -60,7 +88,20 @@ func (s *CampaignService) FindCampaigns(workspaceID string, params *commonschema
 	}
 
 	// get list data
-	rows, err := s.campaignRepo.FindCampaigns(workspaceID, params)
+	rows, err := s.campaignRepo.FindCampaigns(ctx, workspaceID, params)
+	if err != nil {
+		return nil, err
+	}
+	rows, response.NextCursor, response.PrevCursor = utils.CursorPage(rows, params, func(v masterschema.CampaignSchema) (string, string) {
+		return utils.CursorTime(*v.CreatedAt), v.ID.String()
+	})
+
+	// get total submit of every campaign in the page at once
+	campaignIDs := make([]string, 0, len(rows))
+	for _, v := range rows {
+		campaignIDs = append(campaignIDs, v.ID.String())
+	}
+	totalSubmits, err := s.campaignRepo.FindCountFormSubmissionByCampaigns(ctx, campaignIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -68,12 +109,7 @@ func (s *CampaignService) FindCampaigns(workspaceID string, params *commonschema
 	// converting format data from []masterschema.CampaignSchema -> []masterschema.CampaignSchemaWithSummary
 	var list []masterschema.CampaignSchemaWithSummary
 	for _, v := range rows {
-		// get total submit for this campaign
-		countSubmit, err := s.campaignRepo.FindCountFormSubmissionByCampaign(v.ID.String())
-		if err != nil {
-			return nil, err
-		}
-
+		countSubmit := totalSubmits[v.ID.String()]
 		totalVisitor := float64(countSubmit) * 1.3 // static prediction visitor for now [mvp-purpose]
 		list = append(list, masterschema.CampaignSchemaWithSummary{
 			ID:           v.ID,
@@ -89,14 +125,17 @@ func (s *CampaignService) FindCampaigns(workspaceID string, params *commonschema
 		})
 	}
 
-	// get count data
-	count, err := s.campaignRepo.FindCountCampaign(workspaceID, params)
-	if err != nil {
-		return nil, err
-	}
-	totalPage := 1
-	if count > 0 {
-		totalPage = int(math.Ceil(float64(int(count)) / float64(params.Limit)))
+	// get count data, client can skip it on large list
+	totalPage := 0
+	if !params.SkipCount {
+		count, err := s.campaignRepo.FindCountCampaign(ctx, workspaceID, params)
+		if err != nil {
+			return nil, err
+		}
+		totalPage = 1
+		if count > 0 {
+			totalPage = int(math.Ceil(float64(int(count)) / float64(params.Limit)))
+		}
 	}
 
 	// send response
@@ -105,11 +144,14 @@ func (s *CampaignService) FindCampaigns(workspaceID string, params *commonschema
 	return &response, nil
 }
 
-func (s *CampaignService) FindCampaign(workspaceID string, ID string) (*masterschema.DetailCampaignSchema, error) {
-	data, err := s.campaignRepo.FindCampaignByID(workspaceID, ID)
+func (s *CampaignService) FindCampaign(ctx context.Context, workspaceID string, ID string) (*masterschema.DetailCampaignSchema, error) {
+	ctx, span := tracing.Start(ctx, "CampaignService.FindCampaign")
+	defer span.End()
+
+	data, err := s.campaignRepo.FindCampaignByID(ctx, workspaceID, ID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("records not found")
+			return nil, apperrors.NotFound("records not found")
 		} else {
 			return nil, err
 		}
@@ -126,9 +168,12 @@ func (s *CampaignService) FindCampaign(workspaceID string, ID string) (*mastersc
 	}, nil
 }
 
-func (s *CampaignService) CampaignDashboard(workspaceID string) (*masterschema.CampaignDashboard, error) {
+func (s *CampaignService) CampaignDashboard(ctx context.Context, workspaceID string) (*masterschema.CampaignDashboard, error) {
+	ctx, span := tracing.Start(ctx, "CampaignService.CampaignDashboard")
+	defer span.End()
+
 	// get total submit for this campaign
-	countSubmit, err := s.workspaceRepo.FindCountFormSubmissionByWorkspace(workspaceID)
+	countSubmit, err := s.workspaceRepo.FindCountFormSubmissionByWorkspace(ctx, workspaceID)
 	if err != nil {
 		return nil, err
 	}
@@ -142,11 +187,14 @@ func (s *CampaignService) CampaignDashboard(workspaceID string) (*masterschema.C
 	return &dashboard, nil
 }
 
-func (s *CampaignService) FindCampaignByKey(key string, isPublish *bool) (*masterschema.DetailCampaignSchema, error) {
-	data, err := s.campaignRepo.FindCampaignByKey(key, isPublish)
+func (s *CampaignService) FindCampaignByKey(ctx context.Context, key string, isPublish *bool) (*masterschema.DetailCampaignSchema, error) {
+	ctx, span := tracing.Start(ctx, "CampaignService.FindCampaignByKey")
+	defer span.End()
+
+	data, err := s.campaignRepo.FindCampaignByKey(ctx, key, isPublish)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("records not found")
+			return nil, apperrors.NotFound("records not found")
 		} else {
 			return nil, err
 		}
@@ -163,8 +211,11 @@ func (s *CampaignService) FindCampaignByKey(key string, isPublish *bool) (*maste
 	}, nil
 }
 
-func (s *CampaignService) FindFormsByCampaign(campaignID string) ([]masterschema.DetailCampaignFormSchema, error) {
-	data, err := s.campaignRepo.FindFormsByCampaign(campaignID)
+func (s *CampaignService) FindFormsByCampaign(ctx context.Context, campaignID string) ([]masterschema.DetailCampaignFormSchema, error) {
+	ctx, span := tracing.Start(ctx, "CampaignService.FindFormsByCampaign")
+	defer span.End()
+
+	data, err := s.campaignRepo.FindFormsByCampaign(ctx, campaignID)
 	if err != nil {
 		return nil, err
 	}
@@ -191,15 +242,21 @@ func (s *CampaignService) FindFormsByCampaign(campaignID string) ([]masterschema
 	return response, nil
 }
 
-func (s *CampaignService) FindFormAttributes(campaignFormID string) ([]masterschema.CampaignFormAttributeSchemas, error) {
-	data, err := s.campaignRepo.FindFormAttributes(campaignFormID)
+func (s *CampaignService) FindFormAttributes(ctx context.Context, campaignFormID string) ([]masterschema.CampaignFormAttributeSchemas, error) {
+	ctx, span := tracing.Start(ctx, "CampaignService.FindFormAttributes")
+	defer span.End()
+
+	data, err := s.campaignRepo.FindFormAttributes(ctx, campaignFormID)
 	if err != nil {
 		return nil, err
 	}
 	return data, err
 }
 
-func (s *CampaignService) CreateCampaign(workspaceID string, body masterschema.CampaignPayload) error {
+func (s *CampaignService) CreateCampaign(ctx context.Context, actor commonschema.Actor, workspaceID string, body masterschema.CampaignPayload) error {
+	ctx, span := tracing.Start(ctx, "CampaignService.CreateCampaign")
+	defer span.End()
+
 	// prepare usable data
 	campaignID := uuid.New()
 	campaignIDarr := strings.Split(campaignID.String(), "-")
@@ -236,7 +293,7 @@ func (s *CampaignService) CreateCampaign(workspaceID string, body masterschema.C
 	var campaignFormAttributes []models.CampaignFormAttributes
 
 	for _, v := range body.Forms {
-		formID, err := uuid.Parse(v.FormID)
+		formID, err := utils.ParseUUID(v.FormID, "form_id")
 		if err != nil {
 			return err
 		}
@@ -268,14 +325,31 @@ func (s *CampaignService) CreateCampaign(workspaceID string, body masterschema.C
 	}
 
 	// perform to insert entire data
-	err = s.campaignRepo.CreateCampaign(campaign, campaignForms, campaignFormAttributes)
+	err = s.campaignRepo.CreateCampaign(ctx, campaign, campaignForms, campaignFormAttributes)
 	if err != nil {
-		return nil
+		return err
 	}
 
+	after, _ := s.campaignSnapshot(ctx, workspaceID, campaignID.String())
+	s.audit.Record(ctx, actor, audit.Entry{
+		WorkspaceID: workspaceID,
+		Action:      audit.ActionCreate,
+		EntityType:  "campaign",
+		EntityID:    campaignID.String(),
+		After:       after,
+	})
 	return nil
 }
-func (s *CampaignService) UpdateCampaign(workspaceID string, ID string, body masterschema.CampaignPayload) error {
+func (s *CampaignService) UpdateCampaign(ctx context.Context, actor commonschema.Actor, workspaceID string, ID string, body masterschema.CampaignPayload) error {
+	ctx, span := tracing.Start(ctx, "CampaignService.UpdateCampaign")
+	defer span.End()
+
+	// keep existing data for audit log
+	before, err := s.campaignSnapshot(ctx, workspaceID, ID)
+	if err != nil {
+		return err
+	}
+
 	// prepare usable data
 	t := time.Now()
 	thumbnail := ""
@@ -306,7 +380,7 @@ func (s *CampaignService) UpdateCampaign(workspaceID string, ID string, body mas
 	}
 
 	// check missing data from existing to flag as delete
-	existingForms, err := s.campaignRepo.FindFormsByCampaign(ID)
+	existingForms, err := s.campaignRepo.FindFormsByCampaign(ctx, ID)
 	if err != nil {
 		return err
 	}
@@ -315,7 +389,7 @@ func (s *CampaignService) UpdateCampaign(workspaceID string, ID string, body mas
 		isExists := false
 		for _, j := range body.Forms {
 			if j.ID != nil {
-				campaignFormID, err := uuid.Parse(*j.ID)
+				campaignFormID, err := utils.ParseUUID(*j.ID, "id")
 				if err != nil {
 					return err
 				}
@@ -333,7 +407,7 @@ func (s *CampaignService) UpdateCampaign(workspaceID string, ID string, body mas
 	// perform to create and update data campaign form
 	var campaignFormAttributesCreate []models.CampaignFormAttributes
 	for _, v := range body.Forms {
-		formID, err := uuid.Parse(v.FormID)
+		formID, err := utils.ParseUUID(v.FormID, "form_id")
 		if err != nil {
 			return err
 		}
@@ -349,7 +423,7 @@ func (s *CampaignService) UpdateCampaign(workspaceID string, ID string, body mas
 		}
 		if v.ID != nil {
 			cf.UpdatedAt = &t
-			campaignFormID, err := uuid.Parse(*v.ID)
+			campaignFormID, err := utils.ParseUUID(*v.ID, "id")
 			if err != nil {
 				return err
 			}
@@ -365,13 +439,13 @@ func (s *CampaignService) UpdateCampaign(workspaceID string, ID string, body mas
 					IsDefault:      j.IsDefault,
 				}
 				if j.ID != nil {
-					err := s.campaignRepo.UpdateFormAttribute(fa, *j.ID)
+					err := s.campaignRepo.UpdateFormAttribute(ctx, fa, *j.ID)
 					if err != nil {
 						return nil
 					}
 				} else {
 					fa.ID = uuid.New()
-					err := s.campaignRepo.CreateFormAttribute(fa)
+					err := s.campaignRepo.CreateFormAttribute(ctx, fa)
 					if err != nil {
 						return nil
 					}
@@ -379,7 +453,7 @@ func (s *CampaignService) UpdateCampaign(workspaceID string, ID string, body mas
 			}
 
 			// check for delete attribute possibility
-			existingFormAttributes, err := s.campaignRepo.FindFormAttributes(*v.ID)
+			existingFormAttributes, err := s.campaignRepo.FindFormAttributes(ctx, *v.ID)
 			if err != nil {
 				return err
 			}
@@ -392,7 +466,7 @@ func (s *CampaignService) UpdateCampaign(workspaceID string, ID string, body mas
 					}
 				}
 				if isDelete {
-					err := s.campaignRepo.UpdateFormAttribute(models.CampaignFormAttributes{
+					err := s.campaignRepo.UpdateFormAttribute(ctx, models.CampaignFormAttributes{
 						Deleted: true,
 					}, j.ID.String())
 					if err != nil {
@@ -431,36 +505,61 @@ func (s *CampaignService) UpdateCampaign(workspaceID string, ID string, body mas
 	}
 
 	// perform to query for entire data
-	if err := s.campaignRepo.UpdateEntireCampaign(ID, campaign, campaignFormActions, campaignFormAttributesCreate); err != nil {
+	if err := s.campaignRepo.UpdateEntireCampaign(ctx, ID, campaign, campaignFormActions, campaignFormAttributesCreate); err != nil {
 		return err
 	}
+
+	after, _ := s.campaignSnapshot(ctx, workspaceID, ID)
+	s.audit.Record(ctx, actor, audit.Entry{
+		WorkspaceID: workspaceID,
+		Action:      audit.ActionUpdate,
+		EntityType:  "campaign",
+		EntityID:    ID,
+		Before:      before,
+		After:       after,
+	})
 	return nil
 }
 
-func (s *CampaignService) DeleteCampaign(workspaceID string, ID string) error {
+func (s *CampaignService) DeleteCampaign(ctx context.Context, actor commonschema.Actor, workspaceID string, ID string) error {
+	ctx, span := tracing.Start(ctx, "CampaignService.DeleteCampaign")
+	defer span.End()
+
 	// check existing data
-	_, err := s.campaignRepo.FindCampaignByID(workspaceID, ID)
+	before, err := s.campaignRepo.FindCampaignByID(ctx, workspaceID, ID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("record not found")
+			return apperrors.NotFound("record not found")
 		}
 		return err
 	}
 
-	// start update data
+	// start update data, forms are moved to trash together with campaign
 	t := time.Now()
 	campaign := models.Campaigns{
 		Deleted:   true,
+		DeletedAt: &t,
+		DeletedBy: utils.NullableUUID(actor.UserID),
 		UpdatedAt: &t,
 	}
-	err = s.campaignRepo.UpdateCampaign(ID, campaign)
+	err = s.campaignRepo.DeleteCampaign(ctx, ID, campaign)
 	if err != nil {
 		return err
 	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		WorkspaceID: workspaceID,
+		Action:      audit.ActionDelete,
+		EntityType:  "campaign",
+		EntityID:    ID,
+		Before:      before,
+	})
 	return nil
 }
 
-func (s *CampaignService) FindCampaignSeos(campaignID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+func (s *CampaignService) FindCampaignSeos(ctx context.Context, campaignID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "CampaignService.FindCampaignSeos")
+	defer span.End()
+
 	response := commonschema.ResponseList{
 		Parameters: *params,
 		TotalPage:  1,
@@ -468,13 +567,13 @@ func (s *CampaignService) FindCampaignSeos(campaignID string, params *commonsche
 	}
 
 	// get list data
-	rows, err := s.campaignRepo.FindCampaignSeos(campaignID, params)
+	rows, err := s.campaignRepo.FindCampaignSeos(ctx, campaignID, params)
 	if err != nil {
 		return nil, err
 	}
 
 	// get count data
-	count, err := s.campaignRepo.FindCountCampaignSeo(campaignID, params)
+	count, err := s.campaignRepo.FindCountCampaignSeo(ctx, campaignID, params)
 	if err != nil {
 		return nil, err
 	}
@@ -489,15 +588,21 @@ func (s *CampaignService) FindCampaignSeos(campaignID string, params *commonsche
 	return &response, nil
 }
 
-func (s *CampaignService) FindCampaignSeoByID(campaignID string, ID string) (*masterschema.CampaignSeoSchema, error) {
-	data, err := s.campaignRepo.FindCampaignSeoByID(campaignID, ID)
+func (s *CampaignService) FindCampaignSeoByID(ctx context.Context, campaignID string, ID string) (*masterschema.CampaignSeoSchema, error) {
+	ctx, span := tracing.Start(ctx, "CampaignService.FindCampaignSeoByID")
+	defer span.End()
+
+	data, err := s.campaignRepo.FindCampaignSeoByID(ctx, campaignID, ID)
 	if err != nil {
 		return nil, err
 	}
 	return data, nil
 }
 
-func (s *CampaignService) CreateCampaignSeo(campaignID string, body masterschema.CampaignSeoPayload) error {
+func (s *CampaignService) CreateCampaignSeo(ctx context.Context, actor commonschema.Actor, campaignID string, body masterschema.CampaignSeoPayload) error {
+	ctx, span := tracing.Start(ctx, "CampaignService.CreateCampaignSeo")
+	defer span.End()
+
 	UUIDcampaignID, err := uuid.Parse(campaignID)
 	if err != nil {
 		return err
@@ -510,13 +615,29 @@ func (s *CampaignService) CreateCampaignSeo(campaignID string, body masterschema
 		Event:      body.Event,
 		AccessKey:  body.AccessKey,
 	}
-	if err := s.campaignRepo.CreateCampaignSeo(campaignSeo); err != nil {
+	if err := s.campaignRepo.CreateCampaignSeo(ctx, campaignSeo); err != nil {
 		return err
 	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		CampaignID: campaignID,
+		Action:     audit.ActionCreate,
+		EntityType: "campaign_seo",
+		EntityID:   campaignSeo.ID.String(),
+		After:      campaignSeo,
+	})
 	return nil
 }
 
-func (s *CampaignService) UpdateCampaignSeo(campaignID string, ID string, body masterschema.CampaignSeoPayload) error {
+func (s *CampaignService) UpdateCampaignSeo(ctx context.Context, actor commonschema.Actor, campaignID string, ID string, body masterschema.CampaignSeoPayload) error {
+	ctx, span := tracing.Start(ctx, "CampaignService.UpdateCampaignSeo")
+	defer span.End()
+
+	// keep existing data for audit log
+	before, err := s.campaignRepo.FindCampaignSeoByID(ctx, campaignID, ID)
+	if err != nil {
+		return err
+	}
+
 	t := time.Now()
 	campaignSeo := models.CampaignSeos{
 		Platform:  body.Platform,
@@ -524,53 +645,226 @@ func (s *CampaignService) UpdateCampaignSeo(campaignID string, ID string, body m
 		AccessKey: body.AccessKey,
 		UpdatedAt: &t,
 	}
-	if err := s.campaignRepo.UpdateCampaignSeo(campaignID, ID, campaignSeo); err != nil {
+	if err := s.campaignRepo.UpdateCampaignSeo(ctx, campaignID, ID, campaignSeo); err != nil {
 		return err
 	}
+
+	after, _ := s.campaignRepo.FindCampaignSeoByID(ctx, campaignID, ID)
+	s.audit.Record(ctx, actor, audit.Entry{
+		CampaignID: campaignID,
+		Action:     audit.ActionUpdate,
+		EntityType: "campaign_seo",
+		EntityID:   ID,
+		Before:     before,
+		After:      after,
+	})
 	return nil
 }
 
-func (s *CampaignService) DeleteCampaignSeo(campaignID string, ID string) error {
+func (s *CampaignService) DeleteCampaignSeo(ctx context.Context, actor commonschema.Actor, campaignID string, ID string) error {
+	ctx, span := tracing.Start(ctx, "CampaignService.DeleteCampaignSeo")
+	defer span.End()
+
+	// keep existing data for audit log
+	before, err := s.campaignRepo.FindCampaignSeoByID(ctx, campaignID, ID)
+	if err != nil {
+		return err
+	}
+
 	t := time.Now()
 	campaignSeo := models.CampaignSeos{
 		Deleted:   true,
+		DeletedAt: &t,
+		DeletedBy: utils.NullableUUID(actor.UserID),
 		UpdatedAt: &t,
 	}
-	if err := s.campaignRepo.UpdateCampaignSeo(campaignID, ID, campaignSeo); err != nil {
+	if err := s.campaignRepo.UpdateCampaignSeo(ctx, campaignID, ID, campaignSeo); err != nil {
 		return err
 	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		CampaignID: campaignID,
+		Action:     audit.ActionDelete,
+		EntityType: "campaign_seo",
+		EntityID:   ID,
+		Before:     before,
+	})
 	return nil
 }
 
-func (s *CampaignService) FindSummaryEntriesByDate(workspaceID string, campaignID string) ([]masterschema.CampaignFormEntryChart, error) {
-	data, err := s.campaignRepo.FindSummaryEntriesByDate(workspaceID, campaignID)
+func (s *CampaignService) workspaceTimezone(ctx context.Context, workspaceID string) (string, error) {
+	workspace, err := s.workspaceRepo.FindWorkspaceByID(ctx, workspaceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", apperrors.NotFound("workspace not found")
+		}
+		return "", err
+	}
+
+	// fallback for workspace created before timezone exists
+	if workspace.Timezone == "" {
+		return "UTC", nil
+	}
+	return workspace.Timezone, nil
+}
+
+func (s *CampaignService) FindSummaryEntriesByDate(ctx context.Context, workspaceID string, campaignID string, params *masterschema.AnalyticsParams) (*masterschema.CampaignEntryAnalytics, error) {
+	ctx, span := tracing.Start(ctx, "CampaignService.FindSummaryEntriesByDate")
+	defer span.End()
+
+	// entries are grouped based on workspace timezone
+	timezone, err := s.workspaceTimezone(ctx, workspaceID)
 	if err != nil {
 		return nil, err
 	}
-	return data, nil
+	params.Timezone = timezone
+	if err := utils.AnalyticsRange(params); err != nil {
+		return nil, err
+	}
+
+	data, err := s.campaignRepo.FindSummaryEntriesByDate(ctx, workspaceID, campaignID, params)
+	if err != nil {
+		return nil, err
+	}
+	return &masterschema.CampaignEntryAnalytics{
+		Parameters: *params,
+		Rows:       data,
+	}, nil
+}
+
+func (s *CampaignService) FindFieldAnalytics(ctx context.Context, workspaceID string, campaignID string, params *masterschema.AnalyticsParams) (*masterschema.CampaignFieldAnalyticsResponse, error) {
+	ctx, span := tracing.Start(ctx, "CampaignService.FindFieldAnalytics")
+	defer span.End()
+
+	timezone, err := s.workspaceTimezone(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	params.Timezone = timezone
+	if err := utils.AnalyticsRange(params); err != nil {
+		return nil, err
+	}
+
+	// get total entries as base of completion rate
+	totalEntries, err := s.campaignRepo.FindCountEntriesByRange(ctx, workspaceID, campaignID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// get all fields of this campaign
+	forms, err := s.campaignRepo.FindFormsByCampaign(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	// get aggregate data
+	answered, err := s.campaignRepo.FindAnsweredCountByField(ctx, campaignID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := s.campaignRepo.FindAnswerCountByAttribute(ctx, campaignID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	numerics, err := s.campaignRepo.FindNumericStatByField(ctx, campaignID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// merge aggregate data into each field
+	fields := []masterschema.CampaignFieldAnalytics{}
+	for _, v := range forms {
+		field := masterschema.CampaignFieldAnalytics{
+			CampaignFormID: v.ID,
+			Title:          v.Title,
+			FormCode:       v.FormCode,
+			FormName:       v.FormName,
+			IsRequired:     v.IsRequired,
+			Options:        []masterschema.AnalyticsOption{},
+		}
+
+		if a := utils.Find(answered, func(x masterschema.FieldAnswerCount) bool { return x.CampaignFormID == v.ID }); a != nil {
+			field.TotalAnswered = a.Total
+		}
+		if totalEntries > 0 {
+			field.CompletionRate = math.Round(float64(field.TotalAnswered)/float64(totalEntries)*10000) / 100
+		}
+
+		for _, o := range options {
+			if o.CampaignFormID != v.ID {
+				continue
+			}
+			option := masterschema.AnalyticsOption{
+				CampaignFormAttributeID: o.CampaignFormAttributeID,
+				Label:                   o.Label,
+				Value:                   o.Value,
+				Total:                   o.Total,
+			}
+			if field.TotalAnswered > 0 {
+				option.Percentage = math.Round(float64(o.Total)/float64(field.TotalAnswered)*10000) / 100
+			}
+			field.Options = append(field.Options, option)
+		}
+
+		if n := utils.Find(numerics, func(x masterschema.FieldNumericStat) bool { return x.CampaignFormID == v.ID }); n != nil {
+			field.Numeric = &masterschema.AnalyticsNumeric{
+				Total:  n.Total,
+				Min:    n.Min,
+				Max:    n.Max,
+				Avg:    n.Avg,
+				Median: n.Median,
+			}
+		}
+
+		fields = append(fields, field)
+	}
+
+	// send response
+	return &masterschema.CampaignFieldAnalyticsResponse{
+		Parameters:   *params,
+		TotalEntries: totalEntries,
+		Fields:       fields,
+	}, nil
 }
 
-func (s *CampaignService) FindFormEntries(workspaceID string, campaignID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+func (s *CampaignService) FindFormEntries(ctx context.Context, workspaceID string, campaignID string, params *commonschema.QueryParams, filter *masterschema.FormEntryFilter) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "CampaignService.FindFormEntries")
+	defer span.End()
+
 	response := commonschema.ResponseList{
 		Parameters: *params,
 		TotalPage:  1,
 		Rows:       nil,
 	}
 
-	// get list data
-	rows, err := s.campaignRepo.FindFormEntries(workspaceID, campaignID, params)
+	// date filter follows workspace timezone
+	timezone, err := s.workspaceTimezone(ctx, workspaceID)
 	if err != nil {
 		return nil, err
 	}
+	filter.Timezone = timezone
 
-	// get count data
-	count, err := s.campaignRepo.FindCountFormEntries(workspaceID, campaignID, params)
+	// get list data
+	rows, err := s.campaignRepo.FindFormEntries(ctx, workspaceID, campaignID, params, filter)
 	if err != nil {
 		return nil, err
 	}
-	totalPage := 1
-	if count > 0 {
-		totalPage = int(math.Ceil(float64(int(count)) / float64(params.Limit)))
+	rows, response.NextCursor, response.PrevCursor = utils.CursorPage(rows, params, func(v masterschema.FormEntryList) (string, string) {
+		return v.CreatedAt, v.ID
+	})
+
+	// get count data, client can skip it on large list
+	totalPage := 0
+	if !params.SkipCount {
+		count, err := s.campaignRepo.FindCountFormEntries(ctx, workspaceID, campaignID, params, filter)
+		if err != nil {
+			return nil, err
+		}
+		totalPage = 1
+		if count > 0 {
+			totalPage = int(math.Ceil(float64(int(count)) / float64(params.Limit)))
+		}
 	}
 
 	// send response
@@ -579,15 +873,18 @@ func (s *CampaignService) FindFormEntries(workspaceID string, campaignID string,
 	return &response, nil
 }
 
-func (s *CampaignService) FindFormEntry(c echo.Context, ID string) (*masterschema.FormEntryResponse, error) {
+func (s *CampaignService) FindFormEntry(ctx context.Context, c echo.Context, ID string) (*masterschema.FormEntryResponse, error) {
+	ctx, span := tracing.Start(ctx, "CampaignService.FindFormEntry")
+	defer span.End()
+
 	// get form entry header
-	formEntry, err := s.campaignRepo.FindFormEntry(ID)
+	formEntry, err := s.campaignRepo.FindFormEntry(ctx, ID)
 	if err != nil {
 		return nil, err
 	}
 
 	// get detail form entries
-	formDetailEntry, err := s.campaignRepo.FindDetailFormEntry(formEntry.ID)
+	formDetailEntry, err := s.campaignRepo.FindDetailFormEntry(ctx, formEntry.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -601,14 +898,14 @@ func (s *CampaignService) FindFormEntry(c echo.Context, ID string) (*masterschem
 	// get product if exists
 	if formEntry.ProductID != nil && *formEntry.ProductID != "" {
 		productID := formEntry.ProductID
-		product, err := s.storeRepo.FindStoreProductById(*productID)
+		product, err := s.storeRepo.FindStoreProductById(ctx, *productID)
 		if err != nil {
 			return nil, err
 		}
 
 		// generate url for thumbnail and entire images
 		thumbnail := ""
-		images, err := s.storeRepo.FindImagesByProduct(product.ID.String())
+		images, err := s.storeRepo.FindImagesByProduct(ctx, product.ID.String())
 		if err != nil {
 			return nil, err
 		} else if len(images) > 0 {
@@ -648,3 +945,122 @@ func (s *CampaignService) FindFormEntry(c echo.Context, ID string) (*masterschem
 	// send response
 	return response, nil
 }
+
+func (s *CampaignService) ReviewFormEntry(ctx context.Context, actor commonschema.Actor, campaignID string, ID string, body masterschema.FormEntryReviewPayload) error {
+	ctx, span := tracing.Start(ctx, "CampaignService.ReviewFormEntry")
+	defer span.End()
+
+	UUIDuserID, err := uuid.Parse(actor.UserID)
+	if err != nil {
+		return err
+	}
+
+	// keep existing data for audit log
+	before, err := s.campaignRepo.FindFormEntry(ctx, ID)
+	if err != nil {
+		return err
+	}
+
+	// keep last reviewer on the entry, every review is counted from audit log by workspace analytics
+	t := time.Now()
+	data := models.FormEntries{
+		Status:     body.Status,
+		Remark:     body.Remark,
+		ReviewedBy: &UUIDuserID,
+		ReviewedAt: &t,
+		UpdatedAt:  &t,
+	}
+	if err := s.campaignRepo.UpdateFormEntry(ctx, campaignID, ID, data); err != nil {
+		return err
+	}
+
+	after, _ := s.campaignRepo.FindFormEntry(ctx, ID)
+	s.audit.Record(ctx, actor, audit.Entry{
+		CampaignID: campaignID,
+		Action:     audit.ActionUpdate,
+		EntityType: "form_entry",
+		EntityID:   ID,
+		Before:     before,
+		After:      after,
+	})
+	return nil
+}
+
+func (s *CampaignService) RecordVisit(ctx context.Context, campaignID string) error {
+	ctx, span := tracing.Start(ctx, "CampaignService.RecordVisit")
+	defer span.End()
+
+	if err := s.campaignRepo.IncrementCampaignVisit(ctx, campaignID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *CampaignService) FindSpamSetting(ctx context.Context, campaignID string) (*masterschema.CampaignSpamSettingSchema, error) {
+	ctx, span := tracing.Start(ctx, "CampaignService.FindSpamSetting")
+	defer span.End()
+
+	response := masterschema.CampaignSpamSettingSchema{CampaignID: campaignID}
+
+	// campaign without setting has every check disabled
+	data, err := s.campaignRepo.FindSpamSetting(ctx, campaignID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &response, nil
+		}
+		return nil, err
+	}
+
+	response.Honeypot = data.Honeypot
+	response.MinSubmitSeconds = data.MinSubmitSeconds
+	response.Captcha = data.Captcha
+	response.DuplicateCheck = data.DuplicateCheck
+	response.UpdatedAt = data.UpdatedAt
+	return &response, nil
+}
+
+func (s *CampaignService) UpdateSpamSetting(ctx context.Context, actor commonschema.Actor, campaignID string, body masterschema.CampaignSpamSettingPayload) error {
+	ctx, span := tracing.Start(ctx, "CampaignService.UpdateSpamSetting")
+	defer span.End()
+
+	UUIDcampaignID, err := uuid.Parse(campaignID)
+	if err != nil {
+		return err
+	}
+
+	// keep existing data for audit log, campaign may not have setting yet
+	before, err := s.campaignRepo.FindSpamSetting(ctx, campaignID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	t := time.Now()
+	setting := models.CampaignSpamSettings{
+		ID:               uuid.New(),
+		CampaignID:       UUIDcampaignID,
+		Honeypot:         body.Honeypot,
+		MinSubmitSeconds: body.MinSubmitSeconds,
+		Captcha:          body.Captcha,
+		DuplicateCheck:   body.DuplicateCheck,
+		CreatedAt:        t,
+		UpdatedAt:        &t,
+	}
+	if err := s.campaignRepo.SaveSpamSetting(ctx, setting); err != nil {
+		return err
+	}
+
+	after, _ := s.campaignRepo.FindSpamSetting(ctx, campaignID)
+	entry := audit.Entry{
+		CampaignID: campaignID,
+		Action:     audit.ActionCreate,
+		EntityType: "campaign_spam_setting",
+		EntityID:   after.ID.String(),
+		After:      after,
+	}
+	if before != nil {
+		entry.Action = audit.ActionUpdate
+		entry.Before = before
+	}
+	s.audit.Record(ctx, actor, entry)
+	return nil
+}
@@ -0,0 +1,56 @@
+package masterusecase
+
+import (
+	"context"
+	"database/sql/driver"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/infras/dbtest"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestFindCampaignsQueryCount(t *testing.T) {
+	workspaceID := uuid.NewString()
+	dbtest.ConstantQueries(t, []int{1, 25}, func(t *testing.T, total int) []string {
+		campaigns := &dbtest.Rows{Columns: []string{"id", "workspace_id", "title", "created_at"}}
+		submits := &dbtest.Rows{Columns: []string{"id", "total"}}
+		for range total {
+			id := uuid.NewString()
+			campaigns.Values = append(campaigns.Values, []driver.Value{id, workspaceID, "Campaign", time.Now()})
+			submits.Values = append(submits.Values, []driver.Value{id, int64(10)})
+		}
+
+		DB, recorder := dbtest.Open(t, func(query string) *dbtest.Rows {
+			switch {
+			case strings.Contains(query, "count(*)"):
+				return &dbtest.Rows{Columns: []string{"count"}, Values: [][]driver.Value{{int64(total)}}}
+			case strings.Contains(query, `FROM "campaigns"`):
+				return campaigns
+			case strings.Contains(query, `FROM "form_entries"`):
+				return submits
+			}
+			return nil
+		})
+
+		uc := NewCampaignUsecase(masterrepo.NewCampaignRepository(DB), nil, nil, nil)
+		response, err := uc.FindCampaigns(context.Background(), workspaceID, &commonschema.QueryParams{Page: 1, Limit: 50})
+		if err != nil {
+			t.Fatalf("find campaigns: %v", err)
+		}
+		list := response.Rows.([]masterschema.CampaignSchemaWithSummary)
+		if len(list) != total {
+			t.Fatalf("got %d campaigns, want %d", len(list), total)
+		}
+		for _, v := range list {
+			if v.TotalSubmit != 10 {
+				t.Fatalf("got total submit %d, want 10", v.TotalSubmit)
+			}
+		}
+		return recorder.Queries()
+	})
+}
@@ -1,15 +1,17 @@
 package masterusecase
 
 import (
+	"context"
 	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/infras/tracing"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
 	masterschema "kiraform/src/interfaces/rest/schemas/masters"
 	"math"
 )
 
 type FormUsecase interface {
-	FindForms(params *commonschema.QueryParams) (*commonschema.ResponseList, error)
-	FindFormByID(ID string) (*masterschema.FormSchema, error)
+	FindForms(ctx context.Context, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
+	FindFormByID(ctx context.Context, ID string) (*masterschema.FormSchema, error)
 }
 
 type FormService struct {
@@ -22,7 +24,10 @@ func NewFormUsecase(formRepo masterrepo.FormRepository) *FormService {
 	}
 }
 
-func (s *FormService) FindForms(params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+func (s *FormService) FindForms(ctx context.Context, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "FormService.FindForms")
+	defer span.End()
+
 	response := commonschema.ResponseList{
 		Parameters: *params,
 		TotalPage:  1,
@@ -30,13 +35,13 @@ func (s *FormService) FindForms(params *commonschema.QueryParams) (*commonschema
 	}
 
 	// get list data
-	rows, err := s.formRepo.FindForms(params)
+	rows, err := s.formRepo.FindForms(ctx, params)
 	if err != nil {
 		return nil, err
 	}
 
 	// get count data
-	count, err := s.formRepo.FindCountForm(params)
+	count, err := s.formRepo.FindCountForm(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -51,8 +56,11 @@ func (s *FormService) FindForms(params *commonschema.QueryParams) (*commonschema
 	return &response, nil
 }
 
-func (s *FormService) FindFormByID(ID string) (*masterschema.FormSchema, error) {
-	data, err := s.formRepo.FindFormByID(ID)
+func (s *FormService) FindFormByID(ctx context.Context, ID string) (*masterschema.FormSchema, error) {
+	ctx, span := tracing.Start(ctx, "FormService.FindFormByID")
+	defer span.End()
+
+	data, err := s.formRepo.FindFormByID(ctx, ID)
 	if err != nil {
 		return nil, err
 	}
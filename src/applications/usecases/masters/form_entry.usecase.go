@@ -1,33 +1,124 @@
 package masterusecase
 
 import (
+	"context"
+	"errors"
+	"kiraform/src/applications/antispam"
 	"kiraform/src/applications/models"
 	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/infras/metrics"
+	"kiraform/src/infras/ratelimit"
+	"kiraform/src/infras/tracing"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
 	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"kiraform/src/utils"
 	"math"
+	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// same answers within this window are treated as duplicate
+const duplicateEntryWindow = 24 * time.Hour
+
 type FormEntryUsecase interface {
-	EntryForm(campaignID string, userID *string, body []masterschema.FormEntryPayload, productID *string) error
-	GetHistory(userID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
-	GetDetailHistory(userID string, ID string) (*masterschema.FormEntryResponse, error)
+	FormEntryGuard(ctx context.Context, campaignID string) (*masterschema.FormEntryGuardSchema, error)
+	EntryForm(ctx context.Context, campaignID string, userID *string, body []masterschema.FormEntryPayload, productID *string, guard masterschema.FormEntryGuardPayload) error
+	GetHistory(ctx context.Context, userID string, params *commonschema.QueryParams, filter *masterschema.FormEntryFilter) (*commonschema.ResponseList, error)
+	GetDetailHistory(ctx context.Context, userID string, ID string) (*masterschema.FormEntryResponse, error)
 }
 
 type FormEntryService struct {
 	formEntryRepo masterrepo.FormEntryRepository
+	campaignRepo  masterrepo.CampaignRepository
+	captcha       antispam.CaptchaVerifier
+	secret        []byte          // signs form token
+	nonces        ratelimit.Store // remembers spent form tokens
 }
 
-func NewFormEntryUsecase(formEntryRepo masterrepo.FormEntryRepository) *FormEntryService {
+func NewFormEntryUsecase(formEntryRepo masterrepo.FormEntryRepository, campaignRepo masterrepo.CampaignRepository, captcha antispam.CaptchaVerifier, secret []byte, nonces ratelimit.Store) *FormEntryService {
 	return &FormEntryService{
 		formEntryRepo: formEntryRepo,
+		campaignRepo:  campaignRepo,
+		captcha:       captcha,
+		secret:        secret,
+		nonces:        nonces,
+	}
+}
+
+// spamSetting returns anti-spam setting of campaign, every check is disabled when it is not set yet
+func (s *FormEntryService) spamSetting(ctx context.Context, campaignID string) (*models.CampaignSpamSettings, error) {
+	setting, err := s.campaignRepo.FindSpamSetting(ctx, campaignID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.CampaignSpamSettings{}, nil
+		}
+		return nil, err
+	}
+	return setting, nil
+}
+
+func (s *FormEntryService) FormEntryGuard(ctx context.Context, campaignID string) (*masterschema.FormEntryGuardSchema, error) {
+	ctx, span := tracing.Start(ctx, "FormEntryService.FormEntryGuard")
+	defer span.End()
+
+	setting, err := s.spamSetting(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	// token is always issued, so minimum time can be enabled for opened forms
+	formToken, err := antispam.SignFormToken(s.secret, campaignID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &masterschema.FormEntryGuardSchema{
+		FormToken: formToken,
+		Honeypot:  setting.Honeypot,
+		Captcha:   setting.Captcha,
+	}, nil
+}
+
+// spamChecks builds enabled checks of campaign
+func (s *FormEntryService) spamChecks(setting *models.CampaignSpamSettings) []antispam.Check {
+	var checks []antispam.Check
+	if setting.Honeypot {
+		checks = append(checks, antispam.Honeypot{})
+	}
+	if setting.MinSubmitSeconds > 0 {
+		checks = append(checks, antispam.MinSubmitTime{
+			Secret: s.secret,
+			Min:    time.Duration(setting.MinSubmitSeconds) * time.Second,
+			Spend:  s.spendFormToken,
+		})
+	}
+	if setting.Captcha {
+		checks = append(checks, antispam.Captcha{Verifier: s.captcha})
+	}
+	if setting.DuplicateCheck {
+		checks = append(checks, antispam.Duplicate{
+			Window: duplicateEntryWindow,
+			Exists: s.formEntryRepo.ExistsEntryContent,
+		})
 	}
+	return checks
 }
 
-func (s *FormEntryService) EntryForm(campaignID string, userID *string, body []masterschema.FormEntryPayload, productID *string) error {
-	UUIDcampaignID, err := uuid.Parse(campaignID)
+// spendFormToken counts submissions of token nonce, any count after the first is replay
+func (s *FormEntryService) spendFormToken(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	count, _, err := s.nonces.Hit("form_token:"+nonce, ttl)
+	if err != nil {
+		return false, err
+	}
+	return count > 1, nil
+}
+
+func (s *FormEntryService) EntryForm(ctx context.Context, campaignID string, userID *string, body []masterschema.FormEntryPayload, productID *string, guard masterschema.FormEntryGuardPayload) error {
+	ctx, span := tracing.Start(ctx, "FormEntryService.EntryForm")
+	defer span.End()
+
+	UUIDcampaignID, err := utils.ParseUUID(campaignID, "campaign_id")
 	if err != nil {
 		return err
 	}
@@ -42,16 +133,18 @@ func (s *FormEntryService) EntryForm(campaignID string, userID *string, body []m
 	}
 
 	// preparing data
+	t := time.Now()
 	formEntryID := uuid.New()
 	formEntry := map[string]any{
 		"id":          formEntryID,
 		"user_id":     UUIDuserID,
 		"campaign_id": UUIDcampaignID,
 		"status":      "S1", // static as pending
+		"created_at":  t,
 	}
 
 	if productID != nil && *productID != "" {
-		UUIDproductID, err := uuid.Parse(*productID)
+		UUIDproductID, err := utils.ParseUUID(*productID, "product_id")
 		if err != nil {
 			return err
 		}
@@ -59,15 +152,16 @@ func (s *FormEntryService) EntryForm(campaignID string, userID *string, body []m
 	}
 
 	var formDetailEntries []models.FormDetailEntries
+	var answers []string
 	for _, v := range body {
-		UUIDcampaignFormID, err := uuid.Parse(v.CampaignFormID)
+		UUIDcampaignFormID, err := utils.ParseUUID(v.CampaignFormID, "campaign_form_id")
 		if err != nil {
 			return err
 		}
 
 		var UUIDcampaignFormAttributeID *uuid.UUID
 		if v.CampaignFormAttributeID != nil && *v.CampaignFormAttributeID != "" {
-			_attributeID, err := uuid.Parse(*v.CampaignFormAttributeID)
+			_attributeID, err := utils.ParseUUID(*v.CampaignFormAttributeID, "campaign_form_attribute_id")
 			if err != nil {
 				return err
 			}
@@ -85,16 +179,52 @@ func (s *FormEntryService) EntryForm(campaignID string, userID *string, body []m
 		}
 
 		formDetailEntries = append(formDetailEntries, fde)
+		answers = append(answers, v.CampaignFormID+"="+v.Value)
+	}
+
+	// run anti-spam checks of this campaign
+	// suspected entry is kept with spam status, so owner can still review it
+	setting, err := s.spamSetting(ctx, campaignID)
+	if err != nil {
+		return err
+	}
+	contentHash := antispam.ContentHash(answers)
+	reason, err := antispam.Inspect(ctx, s.spamChecks(setting), antispam.Submission{
+		CampaignID:   campaignID,
+		Honeypot:     guard.Honeypot,
+		FormToken:    guard.FormToken,
+		CaptchaToken: guard.CaptchaToken,
+		RemoteIP:     guard.RemoteIP,
+		ContentHash:  contentHash,
+		SubmittedAt:  t,
+	})
+	if err != nil {
+		return err
+	}
+	formEntry["content_hash"] = contentHash
+	if reason != "" {
+		formEntry["status"] = "S4" // suspected spam
+		formEntry["spam_reason"] = reason
 	}
 
 	// perform to insert data
-	if err := s.formEntryRepo.EntryForm(formEntry, formDetailEntries); err != nil {
+	if err := s.formEntryRepo.EntryForm(ctx, formEntry, formDetailEntries); err != nil {
 		return err
 	}
+
+	// count submission per campaign
+	status := "pending"
+	if reason != "" {
+		status = "spam"
+	}
+	metrics.FormSubmissions.WithLabelValues(campaignID, status).Inc()
 	return nil
 }
 
-func (s *FormEntryService) GetHistory(userID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+func (s *FormEntryService) GetHistory(ctx context.Context, userID string, params *commonschema.QueryParams, filter *masterschema.FormEntryFilter) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "FormEntryService.GetHistory")
+	defer span.End()
+
 	response := commonschema.ResponseList{
 		Parameters: *params,
 		TotalPage:  1,
@@ -102,19 +232,25 @@ func (s *FormEntryService) GetHistory(userID string, params *commonschema.QueryP
 	}
 
 	// get list data
-	rows, err := s.formEntryRepo.FindFormEntries(userID, params)
+	rows, err := s.formEntryRepo.FindFormEntries(ctx, userID, params, filter)
 	if err != nil {
 		return nil, err
 	}
+	rows, response.NextCursor, response.PrevCursor = utils.CursorPage(rows, params, func(v masterschema.FormEntrySchema) (string, string) {
+		return utils.CursorTime(v.CreatedAt), v.ID
+	})
 
-	// get count data
-	count, err := s.formEntryRepo.FindCountFormEntry(userID, params)
-	if err != nil {
-		return nil, err
-	}
-	totalPage := 1
-	if count > 0 {
-		totalPage = int(math.Ceil(float64(int(count)) / float64(params.Limit)))
+	// get count data, client can skip it on large list
+	totalPage := 0
+	if !params.SkipCount {
+		count, err := s.formEntryRepo.FindCountFormEntry(ctx, userID, params, filter)
+		if err != nil {
+			return nil, err
+		}
+		totalPage = 1
+		if count > 0 {
+			totalPage = int(math.Ceil(float64(int(count)) / float64(params.Limit)))
+		}
 	}
 
 	// send response
@@ -123,15 +259,18 @@ func (s *FormEntryService) GetHistory(userID string, params *commonschema.QueryP
 	return &response, nil
 }
 
-func (s *FormEntryService) GetDetailHistory(userID string, ID string) (*masterschema.FormEntryResponse, error) {
+func (s *FormEntryService) GetDetailHistory(ctx context.Context, userID string, ID string) (*masterschema.FormEntryResponse, error) {
+	ctx, span := tracing.Start(ctx, "FormEntryService.GetDetailHistory")
+	defer span.End()
+
 	// get form entry header
-	formEntry, err := s.formEntryRepo.FindFormEntry(userID, ID)
+	formEntry, err := s.formEntryRepo.FindFormEntry(ctx, userID, ID)
 	if err != nil {
 		return nil, err
 	}
 
 	// get detail form entries
-	formDetailEntry, err := s.formEntryRepo.FindDetailFormEntry(formEntry.ID)
+	formDetailEntry, err := s.formEntryRepo.FindDetailFormEntry(ctx, formEntry.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,96 @@
+package masterusecase
+
+import (
+	"context"
+	"kiraform/src/applications/antispam"
+	"kiraform/src/applications/models"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/infras/ratelimit"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memoryFormEntries keeps inserted form entries
+type memoryFormEntries struct {
+	masterrepo.FormEntryRepository
+	entries []map[string]any
+}
+
+func (r *memoryFormEntries) EntryForm(ctx context.Context, formEntry map[string]any, formDetailEntries []models.FormDetailEntries) error {
+	r.entries = append(r.entries, formEntry)
+	return nil
+}
+
+func (r *memoryFormEntries) ExistsEntryContent(ctx context.Context, campaignID string, contentHash string, since time.Time) (bool, error) {
+	for _, v := range r.entries {
+		if v["content_hash"] == contentHash && v["created_at"].(time.Time).After(since) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type staticSpamSetting struct {
+	masterrepo.CampaignRepository
+	setting models.CampaignSpamSettings
+}
+
+func (r staticSpamSetting) FindSpamSetting(ctx context.Context, campaignID string) (*models.CampaignSpamSettings, error) {
+	setting := r.setting
+	return &setting, nil
+}
+
+func TestEntryFormStoresSpamStatus(t *testing.T) {
+	ctx := context.Background()
+	secret := []byte("test secret key")
+	campaignID := uuid.NewString()
+	entries := &memoryFormEntries{}
+	nonces := ratelimit.NewMemoryStore()
+	t.Cleanup(func() {
+		nonces.Close()
+	})
+	setting := models.CampaignSpamSettings{Honeypot: true, MinSubmitSeconds: 5, DuplicateCheck: true}
+	uc := NewFormEntryUsecase(entries, staticSpamSetting{setting: setting}, antispam.FakeCaptchaVerifier{}, secret, nonces)
+
+	// form opened a minute ago
+	token, err := antispam.SignFormToken(secret, campaignID, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("sign form token: %v", err)
+	}
+	guard, err := uc.FormEntryGuard(ctx, campaignID)
+	if err != nil {
+		t.Fatalf("form entry guard: %v", err)
+	}
+
+	answer := func(value string) []masterschema.FormEntryPayload {
+		return []masterschema.FormEntryPayload{{CampaignFormID: uuid.NewString(), Value: value}}
+	}
+	submissions := []struct {
+		name   string
+		body   []masterschema.FormEntryPayload
+		guard  masterschema.FormEntryGuardPayload
+		status string
+		reason string
+	}{
+		{"human", answer("first"), masterschema.FormEntryGuardPayload{FormToken: token}, "S1", ""},
+		{"replayed token", answer("second"), masterschema.FormEntryGuardPayload{FormToken: token}, "S4", "form token is already used"},
+		{"filled honeypot", answer("third"), masterschema.FormEntryGuardPayload{FormToken: guard.FormToken, Honeypot: "x"}, "S4", "honeypot field is filled"},
+		{"too fast", answer("fourth"), masterschema.FormEntryGuardPayload{FormToken: guard.FormToken}, "S4", "submitted in 0 seconds, minimum is 5 seconds"},
+	}
+	for i, v := range submissions {
+		if err := uc.EntryForm(ctx, campaignID, nil, v.body, nil, v.guard); err != nil {
+			t.Fatalf("%s: entry form: %v", v.name, err)
+		}
+		if len(entries.entries) != i+1 {
+			t.Fatalf("%s: entry is not stored", v.name)
+		}
+		stored := entries.entries[i]
+		reason, _ := stored["spam_reason"].(string)
+		if stored["status"] != v.status || reason != v.reason {
+			t.Fatalf("%s: got status %v reason %q, want %s %q", v.name, stored["status"], reason, v.status, v.reason)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package masterusecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/applications/audit"
+	"kiraform/src/applications/models"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/applications/sessions"
+	"kiraform/src/infras/tracing"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"kiraform/src/utils"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RoleUsecase manages global roles and their permissions, its routes need roles:manage permission
+type RoleUsecase interface {
+	FindRoles(ctx context.Context, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
+	FindRole(ctx context.Context, ID string) (*masterschema.RoleList, error)
+	CreateRole(ctx context.Context, actor commonschema.Actor, body masterschema.RolePayload) (*masterschema.RoleList, error)
+	UpdateRole(ctx context.Context, actor commonschema.Actor, ID string, body masterschema.RolePayload) error
+	DeleteRole(ctx context.Context, actor commonschema.Actor, ID string) error
+}
+
+type RoleService struct {
+	roleRepo masterrepo.RoleRepository
+	tracker  *sessions.Tracker
+	audit    *audit.Recorder
+}
+
+func NewRoleUsecase(roleRepo masterrepo.RoleRepository, tracker *sessions.Tracker, recorder *audit.Recorder) *RoleService {
+	return &RoleService{
+		roleRepo: roleRepo,
+		tracker:  tracker,
+		audit:    recorder,
+	}
+}
+
+func (s *RoleService) FindRoles(ctx context.Context, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "RoleService.FindRoles")
+	defer span.End()
+
+	response := commonschema.ResponseList{
+		Parameters: *params,
+		TotalPage:  1,
+		Rows:       nil,
+	}
+
+	// get list data
+	rows, err := s.roleRepo.FindRoles(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	rows, response.NextCursor, response.PrevCursor = utils.CursorPage(rows, params, func(v models.Roles) (string, string) {
+		return utils.CursorTime(v.CreatedAt), v.ID.String()
+	})
+	list, err := s.roleList(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// get count data, client can skip it on large list
+	totalPage := 0
+	if !params.SkipCount {
+		count, err := s.roleRepo.FindCountRole(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		totalPage = 1
+		if count > 0 {
+			totalPage = int(math.Ceil(float64(int(count)) / float64(params.Limit)))
+		}
+	}
+
+	// send response
+	response.TotalPage = totalPage
+	response.Rows = list
+	return &response, nil
+}
+
+func (s *RoleService) FindRole(ctx context.Context, ID string) (*masterschema.RoleList, error) {
+	ctx, span := tracing.Start(ctx, "RoleService.FindRole")
+	defer span.End()
+
+	data, err := s.findRole(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.roleList(ctx, []models.Roles{*data})
+	if err != nil {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+func (s *RoleService) CreateRole(ctx context.Context, actor commonschema.Actor, body masterschema.RolePayload) (*masterschema.RoleList, error) {
+	ctx, span := tracing.Start(ctx, "RoleService.CreateRole")
+	defer span.End()
+
+	name := strings.TrimSpace(body.Name)
+	if err := s.checkRoleName(ctx, name, ""); err != nil {
+		return nil, err
+	}
+
+	// perform to insert data
+	data := models.Roles{
+		ID:          uuid.New(),
+		Name:        name,
+		Description: body.Description,
+		Permissions: joinPermissions(body.Permissions),
+		CreatedAt:   time.Now(),
+	}
+	if err := s.roleRepo.CreateRole(ctx, data); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		Action:     audit.ActionCreate,
+		EntityType: "role",
+		EntityID:   data.ID.String(),
+		After:      data,
+	})
+
+	list, err := s.roleList(ctx, []models.Roles{data})
+	if err != nil {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// UpdateRole changes role, holders are signed out when what their token carries is changed
+func (s *RoleService) UpdateRole(ctx context.Context, actor commonschema.Actor, ID string, body masterschema.RolePayload) error {
+	ctx, span := tracing.Start(ctx, "RoleService.UpdateRole")
+	defer span.End()
+
+	before, err := s.findRole(ctx, ID)
+	if err != nil {
+		return err
+	}
+	after := *before
+	after.Name = strings.TrimSpace(body.Name)
+	after.Description = body.Description
+	after.Permissions = joinPermissions(body.Permissions)
+
+	// built-in roles are looked up by name, and admin keeps every permission so it can not be locked out
+	if builtInRole(before.Name) && !strings.EqualFold(after.Name, before.Name) {
+		return apperrors.Field("name", "built-in role can not be renamed")
+	}
+	if strings.EqualFold(before.Name, models.RoleAdmin) && after.Permissions != joinPermissions(models.Permissions) {
+		return apperrors.Field("permissions", "admin role always has every permission")
+	}
+	if err := s.checkRoleName(ctx, after.Name, ID); err != nil {
+		return err
+	}
+
+	// perform to update data
+	if err := s.roleRepo.UpdateRole(ctx, ID, after); err != nil {
+		return err
+	}
+	if after.Name != before.Name || after.Permissions != before.Permissions {
+		if err := s.tracker.RevokeRole(ctx, ID); err != nil {
+			return err
+		}
+	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		Action:     audit.ActionUpdate,
+		EntityType: "role",
+		EntityID:   ID,
+		Before:     before,
+		After:      after,
+	})
+	return nil
+}
+
+// DeleteRole soft deletes role which nobody holds, built-in roles are kept
+func (s *RoleService) DeleteRole(ctx context.Context, actor commonschema.Actor, ID string) error {
+	ctx, span := tracing.Start(ctx, "RoleService.DeleteRole")
+	defer span.End()
+
+	before, err := s.findRole(ctx, ID)
+	if err != nil {
+		return err
+	}
+	if builtInRole(before.Name) {
+		return apperrors.Conflict("built-in role can not be deleted")
+	}
+	totalUsers, err := s.roleRepo.FindCountUserByRoles(ctx, []string{ID})
+	if err != nil {
+		return err
+	}
+	if total := totalUsers[ID]; total > 0 {
+		return apperrors.Conflict(fmt.Sprintf("role is held by %d users, revoke it from them first", total))
+	}
+
+	// perform to delete data
+	if err := s.roleRepo.DeleteRole(ctx, ID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		Action:     audit.ActionDelete,
+		EntityType: "role",
+		EntityID:   ID,
+		Before:     before,
+	})
+	return nil
+}
+
+// roleList loads total holder of every role in the page at once
+func (s *RoleService) roleList(ctx context.Context, rows []models.Roles) ([]masterschema.RoleList, error) {
+	roleIDs := make([]string, 0, len(rows))
+	for _, v := range rows {
+		roleIDs = append(roleIDs, v.ID.String())
+	}
+	totalUsers, err := s.roleRepo.FindCountUserByRoles(ctx, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// converting format data from []models.Roles -> []masterschema.RoleList
+	list := make([]masterschema.RoleList, 0, len(rows))
+	for _, v := range rows {
+		list = append(list, masterschema.RoleList{
+			ID:          v.ID.String(),
+			Name:        v.Name,
+			Description: v.Description,
+			Permissions: v.PermissionList(),
+			BuiltIn:     builtInRole(v.Name),
+			TotalUser:   totalUsers[v.ID.String()],
+			CreatedAt:   v.CreatedAt,
+			UpdatedAt:   v.UpdatedAt,
+		})
+	}
+	return list, nil
+}
+
+func (s *RoleService) findRole(ctx context.Context, ID string) (*models.Roles, error) {
+	if _, err := uuid.Parse(ID); err != nil {
+		return nil, apperrors.NotFound("role is not found")
+	}
+	data, err := s.roleRepo.FindRoleByID(ctx, ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("role is not found")
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// checkRoleName refuses name of another role, deleted roles included since name stays unique
+func (s *RoleService) checkRoleName(ctx context.Context, name string, exceptID string) error {
+	if name == "" {
+		return apperrors.Field("name", "is required")
+	}
+	taken, err := s.roleRepo.FindRoleNameTaken(ctx, name, exceptID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return apperrors.Field("name", "is already taken")
+	}
+	return nil
+}
+
+func builtInRole(name string) bool {
+	return strings.EqualFold(name, models.RoleAdmin) || strings.EqualFold(name, models.RoleUser)
+}
+
+// joinPermissions stores permissions once each in the order of models.Permissions
+func joinPermissions(permissions []string) string {
+	var list []string
+	for _, v := range models.Permissions {
+		if slices.Contains(permissions, v) {
+			list = append(list, v)
+		}
+	}
+	return strings.Join(list, ",")
+}
@@ -0,0 +1,183 @@
+package masterusecase
+
+import (
+	"context"
+	"errors"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/applications/audit"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/infras/tracing"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"kiraform/src/utils"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+type TrashUsecase interface {
+	FindWorkspaceTrash(ctx context.Context, workspaceID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
+	RestoreWorkspaceItem(ctx context.Context, actor commonschema.Actor, workspaceID string, entityType string, ID string) error
+	FindDeletedWorkspaces(ctx context.Context, userID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
+	RestoreWorkspace(ctx context.Context, actor commonschema.Actor, ID string) error
+}
+
+type TrashService struct {
+	trashRepo     masterrepo.TrashRepository
+	workspaceRepo masterrepo.WorkspaceRepository
+	campaignRepo  masterrepo.CampaignRepository
+	audit         *audit.Recorder
+}
+
+func NewTrashUsecase(trashRepo masterrepo.TrashRepository, workspaceRepo masterrepo.WorkspaceRepository, campaignRepo masterrepo.CampaignRepository, recorder *audit.Recorder) *TrashService {
+	return &TrashService{
+		trashRepo:     trashRepo,
+		workspaceRepo: workspaceRepo,
+		campaignRepo:  campaignRepo,
+		audit:         recorder,
+	}
+}
+
+// trashList builds response list of trash items, count is skipped when client asks for it
+func trashList(params *commonschema.QueryParams, rows []commonschema.TrashSchema, count func() (int64, error)) (*commonschema.ResponseList, error) {
+	response := commonschema.ResponseList{
+		Parameters: *params,
+		TotalPage:  1,
+		Rows:       nil,
+	}
+	rows, response.NextCursor, response.PrevCursor = utils.CursorPage(rows, params, func(v commonschema.TrashSchema) (string, string) {
+		return utils.CursorTime(v.DeletedAt), v.ID
+	})
+
+	// get count data, client can skip it on large list
+	totalPage := 0
+	if !params.SkipCount {
+		total, err := count()
+		if err != nil {
+			return nil, err
+		}
+		totalPage = 1
+		if total > 0 {
+			totalPage = int(math.Ceil(float64(int(total)) / float64(params.Limit)))
+		}
+	}
+
+	// send response
+	response.TotalPage = totalPage
+	response.Rows = rows
+	return &response, nil
+}
+
+func (s *TrashService) FindWorkspaceTrash(ctx context.Context, workspaceID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "TrashService.FindWorkspaceTrash")
+	defer span.End()
+
+	rows, err := s.trashRepo.FindWorkspaceTrash(ctx, workspaceID, params)
+	if err != nil {
+		return nil, err
+	}
+	return trashList(params, rows, func() (int64, error) {
+		return s.trashRepo.FindCountWorkspaceTrash(ctx, workspaceID, params)
+	})
+}
+
+func (s *TrashService) RestoreWorkspaceItem(ctx context.Context, actor commonschema.Actor, workspaceID string, entityType string, ID string) error {
+	ctx, span := tracing.Start(ctx, "TrashService.RestoreWorkspaceItem")
+	defer span.End()
+
+	switch entityType {
+	case "campaign":
+		// check deleted data
+		before, err := s.trashRepo.FindDeletedCampaign(ctx, workspaceID, ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("campaign not found in trash")
+			}
+			return err
+		}
+
+		// perform to restore campaign with its forms
+		if err := s.trashRepo.RestoreCampaign(ctx, ID, before.DeletedAt); err != nil {
+			return err
+		}
+
+		after, _ := s.campaignRepo.FindCampaignByID(ctx, workspaceID, ID)
+		s.audit.Record(ctx, actor, audit.Entry{
+			WorkspaceID: workspaceID,
+			Action:      audit.ActionRestore,
+			EntityType:  entityType,
+			EntityID:    ID,
+			Before:      before,
+			After:       after,
+		})
+		return nil
+	case "campaign_seo":
+		// check deleted data, its campaign must be active
+		before, err := s.trashRepo.FindDeletedCampaignSeo(ctx, workspaceID, ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("campaign seo not found in trash, restore its campaign first")
+			}
+			return err
+		}
+
+		// perform to restore data
+		if err := s.trashRepo.RestoreCampaignSeo(ctx, ID); err != nil {
+			return err
+		}
+
+		after, _ := s.campaignRepo.FindCampaignSeoByID(ctx, before.CampaignID.String(), ID)
+		s.audit.Record(ctx, actor, audit.Entry{
+			WorkspaceID: workspaceID,
+			Action:      audit.ActionRestore,
+			EntityType:  entityType,
+			EntityID:    ID,
+			Before:      before,
+			After:       after,
+		})
+		return nil
+	}
+	return apperrors.Field("entity_type", "must be one of campaign, campaign_seo")
+}
+
+func (s *TrashService) FindDeletedWorkspaces(ctx context.Context, userID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "TrashService.FindDeletedWorkspaces")
+	defer span.End()
+
+	rows, err := s.trashRepo.FindDeletedWorkspaces(ctx, userID, params)
+	if err != nil {
+		return nil, err
+	}
+	return trashList(params, rows, func() (int64, error) {
+		return s.trashRepo.FindCountDeletedWorkspace(ctx, userID, params)
+	})
+}
+
+func (s *TrashService) RestoreWorkspace(ctx context.Context, actor commonschema.Actor, ID string) error {
+	ctx, span := tracing.Start(ctx, "TrashService.RestoreWorkspace")
+	defer span.End()
+
+	// only owner can restore deleted workspace
+	before, err := s.trashRepo.FindDeletedWorkspace(ctx, actor.UserID, ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound("workspace not found in trash")
+		}
+		return err
+	}
+
+	// perform to restore data
+	if err := s.trashRepo.RestoreWorkspace(ctx, ID); err != nil {
+		return err
+	}
+
+	after, _ := s.workspaceRepo.FindWorkspaceByID(ctx, ID)
+	s.audit.Record(ctx, actor, audit.Entry{
+		WorkspaceID: ID,
+		Action:      audit.ActionRestore,
+		EntityType:  "workspace",
+		EntityID:    ID,
+		Before:      before,
+		After:       after,
+	})
+	return nil
+}
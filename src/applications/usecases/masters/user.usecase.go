@@ -0,0 +1,520 @@
+package masterusecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/applications/audit"
+	"kiraform/src/applications/keyring"
+	"kiraform/src/applications/models"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/applications/sessions"
+	"kiraform/src/infras/configs"
+	"kiraform/src/infras/mailer"
+	"kiraform/src/infras/tracing"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"kiraform/src/utils"
+	"math"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// passwordResetTTL is how long reset link sent by admin stays valid
+const passwordResetTTL = 24 * time.Hour
+
+// UserUsecase manages accounts of every user, its routes need users:manage permission
+type UserUsecase interface {
+	FindUsers(ctx context.Context, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
+	FindUser(ctx context.Context, ID string) (*masterschema.UserList, error)
+	UpdateUserActive(ctx context.Context, actor commonschema.Actor, ID string, body masterschema.UserActivePayload) error
+	DeleteUser(ctx context.Context, actor commonschema.Actor, ID string) error
+	RestoreUser(ctx context.Context, actor commonschema.Actor, ID string) error
+	ResetUserPassword(ctx context.Context, actor commonschema.Actor, ID string) (*masterschema.UserPasswordResetResponse, error)
+	AssignUserRole(ctx context.Context, actor commonschema.Actor, ID string, body masterschema.UserRolePayload) error
+	RevokeUserRole(ctx context.Context, actor commonschema.Actor, ID string, roleName string) error
+	GrantUserPackage(ctx context.Context, actor commonschema.Actor, ID string, body masterschema.UserPackagePayload) error
+}
+
+type UserService struct {
+	userRepo    masterrepo.UserRepository
+	roleRepo    masterrepo.RoleRepository
+	packageRepo masterrepo.PackageRepository
+	tracker     *sessions.Tracker
+	keys        *keyring.Keyring
+	mailer      mailer.Mailer
+	config      configs.AuthConfig
+	audit       *audit.Recorder
+}
+
+func NewUserUsecase(userRepo masterrepo.UserRepository, roleRepo masterrepo.RoleRepository, packageRepo masterrepo.PackageRepository, tracker *sessions.Tracker, keys *keyring.Keyring, mail mailer.Mailer, config configs.AuthConfig, recorder *audit.Recorder) *UserService {
+	return &UserService{
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		packageRepo: packageRepo,
+		tracker:     tracker,
+		keys:        keys,
+		mailer:      mail,
+		config:      config,
+		audit:       recorder,
+	}
+}
+
+func (s *UserService) FindUsers(ctx context.Context, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "UserService.FindUsers")
+	defer span.End()
+
+	response := commonschema.ResponseList{
+		Parameters: *params,
+		TotalPage:  1,
+		Rows:       nil,
+	}
+
+	// get list data
+	rows, err := s.userRepo.FindUsers(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	rows, response.NextCursor, response.PrevCursor = utils.CursorPage(rows, params, func(v models.Users) (string, string) {
+		return utils.CursorTime(v.CreatedAt), v.ID.String()
+	})
+	list, err := s.userList(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// get count data, client can skip it on large list
+	totalPage := 0
+	if !params.SkipCount {
+		count, err := s.userRepo.FindCountUser(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		totalPage = 1
+		if count > 0 {
+			totalPage = int(math.Ceil(float64(int(count)) / float64(params.Limit)))
+		}
+	}
+
+	// send response
+	response.TotalPage = totalPage
+	response.Rows = list
+	return &response, nil
+}
+
+func (s *UserService) FindUser(ctx context.Context, ID string) (*masterschema.UserList, error) {
+	ctx, span := tracing.Start(ctx, "UserService.FindUser")
+	defer span.End()
+
+	data, err := s.findUser(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.userList(ctx, []models.Users{*data})
+	if err != nil {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// UpdateUserActive activates or deactivates user, deactivated user is signed out of every device at once
+func (s *UserService) UpdateUserActive(ctx context.Context, actor commonschema.Actor, ID string, body masterschema.UserActivePayload) error {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateUserActive")
+	defer span.End()
+
+	before, err := s.findUser(ctx, ID)
+	if err != nil {
+		return err
+	}
+	if before.Deleted {
+		return apperrors.Conflict("user is deleted, restore it first")
+	}
+	if !*body.IsActive && actor.UserID == ID {
+		return apperrors.Forbidden("you can not deactivate your own account")
+	}
+
+	// perform to update data
+	if err := s.userRepo.UpdateUserActive(ctx, ID, *body.IsActive); err != nil {
+		return err
+	}
+	if !*body.IsActive {
+		if err := s.tracker.RevokeAll(ctx, ID); err != nil {
+			return err
+		}
+	}
+
+	after := *before
+	after.IsActive = *body.IsActive
+	after.IsPending = false
+	s.audit.Record(ctx, actor, audit.Entry{
+		Action:     audit.ActionUpdate,
+		EntityType: "user",
+		EntityID:   ID,
+		Before:     before,
+		After:      after,
+	})
+	return nil
+}
+
+// DeleteUser soft deletes user, so it can be restored with its workspaces and history
+func (s *UserService) DeleteUser(ctx context.Context, actor commonschema.Actor, ID string) error {
+	ctx, span := tracing.Start(ctx, "UserService.DeleteUser")
+	defer span.End()
+
+	before, err := s.findUser(ctx, ID)
+	if err != nil {
+		return err
+	}
+	if before.Deleted {
+		return apperrors.Conflict("user is already deleted")
+	}
+	if actor.UserID == ID {
+		return apperrors.Forbidden("you can not delete your own account")
+	}
+
+	// perform to delete data
+	if err := s.userRepo.UpdateUserDeleted(ctx, ID, true); err != nil {
+		return err
+	}
+	if err := s.tracker.RevokeAll(ctx, ID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		Action:     audit.ActionDelete,
+		EntityType: "user",
+		EntityID:   ID,
+		Before:     before,
+	})
+	return nil
+}
+
+func (s *UserService) RestoreUser(ctx context.Context, actor commonschema.Actor, ID string) error {
+	ctx, span := tracing.Start(ctx, "UserService.RestoreUser")
+	defer span.End()
+
+	before, err := s.findUser(ctx, ID)
+	if err != nil {
+		return err
+	}
+	if !before.Deleted {
+		return apperrors.Conflict("user is not deleted")
+	}
+
+	// perform to restore data
+	if err := s.userRepo.UpdateUserDeleted(ctx, ID, false); err != nil {
+		return err
+	}
+	after := *before
+	after.Deleted = false
+	s.audit.Record(ctx, actor, audit.Entry{
+		Action:     audit.ActionRestore,
+		EntityType: "user",
+		EntityID:   ID,
+		Before:     before,
+		After:      after,
+	})
+	return nil
+}
+
+// ResetUserPassword replaces password with unusable one, signs user out and emails reset link,
+// admin never sees the link so admin can not sign in as the user
+func (s *UserService) ResetUserPassword(ctx context.Context, actor commonschema.Actor, ID string) (*masterschema.UserPasswordResetResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.ResetUserPassword")
+	defer span.End()
+
+	before, err := s.findUser(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	if !before.IsActive || before.Deleted {
+		return nil, apperrors.Conflict("user is inactive, activate it first")
+	}
+
+	hashedPassword, err := utils.UnusablePassword()
+	if err != nil {
+		return nil, err
+	}
+
+	// perform to update password
+	now := time.Now()
+	if err := s.userRepo.UpdateUser(ctx, ID, models.Users{Password: hashedPassword, UpdatedAt: &now}); err != nil {
+		return nil, err
+	}
+	if err := s.tracker.RevokeAll(ctx, ID); err != nil {
+		return nil, err
+	}
+
+	// link works until password is changed, using it changes password
+	expiresAt := now.Add(passwordResetTTL)
+	token, err := s.keys.Sign(jwt.MapClaims{
+		"sub": ID,
+		"pwd": keyring.PasswordStamp(hashedPassword),
+		"typ": keyring.TokenPasswordReset,
+		"exp": expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	link := "Reset token: " + token
+	if s.config.ResetURL != "" {
+		link = s.config.ResetURL + "?token=" + url.QueryEscape(token)
+	}
+	if err := s.mailer.Send(ctx, mailer.Message{
+		To:      before.Email,
+		Subject: "Reset your " + s.config.Issuer + " password",
+		Body: strings.Join([]string{
+			fmt.Sprintf("An admin has reset the password of your %s account and signed you out of every device.", s.config.Issuer),
+			"",
+			"Set a new password with this link:",
+			link,
+			"",
+			fmt.Sprintf("It expires in %d hours and works once.", int(passwordResetTTL.Hours())),
+		}, "\n"),
+	}); err != nil {
+		return nil, err
+	}
+
+	after := *before
+	after.Password = hashedPassword
+	s.audit.Record(ctx, actor, audit.Entry{
+		Action:     audit.ActionUpdate,
+		EntityType: "user",
+		EntityID:   ID,
+		Before:     before,
+		After:      after,
+	})
+	return &masterschema.UserPasswordResetResponse{
+		Email:     before.Email,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// AssignUserRole adds role to user, user is signed out so the next token carries it
+func (s *UserService) AssignUserRole(ctx context.Context, actor commonschema.Actor, ID string, body masterschema.UserRolePayload) error {
+	ctx, span := tracing.Start(ctx, "UserService.AssignUserRole")
+	defer span.End()
+
+	user, err := s.findUser(ctx, ID)
+	if err != nil {
+		return err
+	}
+	role, err := s.findRole(ctx, body.Role)
+	if err != nil {
+		return err
+	}
+	roles, err := s.userRepo.FindRolesByUsers(ctx, []string{ID})
+	if err != nil {
+		return err
+	}
+	for _, v := range roles {
+		if v.RoleID == role.ID {
+			return apperrors.Conflict("user already has this role")
+		}
+	}
+
+	// perform to insert data
+	data := models.UserRoles{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		RoleID:    role.ID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.userRepo.CreateUserRole(ctx, data); err != nil {
+		return err
+	}
+	if err := s.tracker.RevokeAll(ctx, ID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		Action:     audit.ActionCreate,
+		EntityType: "user_role",
+		EntityID:   data.ID.String(),
+		After:      data,
+	})
+	return nil
+}
+
+// RevokeUserRole removes role of user, user keeps at least one role so it always has a role in its token
+func (s *UserService) RevokeUserRole(ctx context.Context, actor commonschema.Actor, ID string, roleName string) error {
+	ctx, span := tracing.Start(ctx, "UserService.RevokeUserRole")
+	defer span.End()
+
+	if _, err := s.findUser(ctx, ID); err != nil {
+		return err
+	}
+	role, err := s.findRole(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	if actor.UserID == ID && slices.Contains(role.PermissionList(), models.PermissionUsersManage) {
+		return apperrors.Forbidden("you can not revoke your own role which lets you manage users")
+	}
+
+	roles, err := s.userRepo.FindRolesByUsers(ctx, []string{ID})
+	if err != nil {
+		return err
+	}
+	var before *models.UserRoles
+	for i := range roles {
+		if roles[i].RoleID == role.ID {
+			before = &roles[i]
+		}
+	}
+	if before == nil {
+		return apperrors.NotFound("user does not have this role")
+	}
+	if len(roles) == 1 {
+		return apperrors.Conflict("user must keep at least one role")
+	}
+
+	// perform to delete data
+	if err := s.userRepo.DeleteUserRole(ctx, ID, role.ID.String()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound("user does not have this role")
+		}
+		return err
+	}
+	if err := s.tracker.RevokeAll(ctx, ID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		Action:     audit.ActionDelete,
+		EntityType: "user_role",
+		EntityID:   before.ID.String(),
+		Before:     before,
+	})
+	return nil
+}
+
+// GrantUserPackage replaces active package of user with package of code for days
+func (s *UserService) GrantUserPackage(ctx context.Context, actor commonschema.Actor, ID string, body masterschema.UserPackagePayload) error {
+	ctx, span := tracing.Start(ctx, "UserService.GrantUserPackage")
+	defer span.End()
+
+	user, err := s.findUser(ctx, ID)
+	if err != nil {
+		return err
+	}
+	pkg, err := s.packageRepo.FindPackageByCode(ctx, body.Code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.Field("code", "package is not found")
+		}
+		return err
+	}
+
+	// perform to insert data
+	now := time.Now()
+	data := models.UserPackages{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		PackageID:  pkg.ID,
+		ActiveDate: now,
+		ExpireDate: now.AddDate(0, 0, body.Days),
+		Remark:     "granted by admin",
+		IsActive:   true,
+		CreatedAt:  now,
+	}
+	if err := s.packageRepo.GrantUserPackage(ctx, data); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		Action:     audit.ActionCreate,
+		EntityType: "user_package",
+		EntityID:   data.ID.String(),
+		After:      data,
+	})
+	return nil
+}
+
+// userList loads roles, active package and total workspace of every user in the page at once
+func (s *UserService) userList(ctx context.Context, rows []models.Users) ([]masterschema.UserList, error) {
+	userIDs := make([]string, 0, len(rows))
+	for _, v := range rows {
+		userIDs = append(userIDs, v.ID.String())
+	}
+	roles, err := s.userRepo.FindRolesByUsers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	packages, err := s.userRepo.FindActivePackagesByUsers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	totalWorkspaces, err := s.userRepo.FindCountWorkspaceByUsers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	userRoles := map[string][]masterschema.UserRoleSchema{}
+	for _, v := range roles {
+		userRoles[v.UserID.String()] = append(userRoles[v.UserID.String()], masterschema.UserRoleSchema{
+			ID:        v.RoleID.String(),
+			Name:      v.Role.Name,
+			CreatedAt: v.CreatedAt,
+		})
+	}
+	userPackages := map[string]*masterschema.UserPackageSchema{}
+	for _, v := range packages {
+		userPackages[v.UserID.String()] = &masterschema.UserPackageSchema{
+			ID:         v.PackageID.String(),
+			Code:       v.Package.Code,
+			Name:       v.Package.Name,
+			ActiveDate: v.ActiveDate,
+			ExpireDate: v.ExpireDate,
+		}
+	}
+
+	// converting format data from []models.Users -> []masterschema.UserList
+	list := make([]masterschema.UserList, 0, len(rows))
+	for _, v := range rows {
+		ID := v.ID.String()
+		list = append(list, masterschema.UserList{
+			ID:             ID,
+			UserIdentity:   v.UserIdentity,
+			Email:          v.Email,
+			Fullname:       v.Fullname,
+			IsActive:       v.IsActive,
+			IsPending:      v.IsPending,
+			Deleted:        v.Deleted,
+			Roles:          userRoles[ID],
+			Package:        userPackages[ID],
+			TotalWorkspace: totalWorkspaces[ID],
+			CreatedAt:      v.CreatedAt,
+			UpdatedAt:      v.UpdatedAt,
+		})
+	}
+	return list, nil
+}
+
+func (s *UserService) findUser(ctx context.Context, ID string) (*models.Users, error) {
+	if _, err := uuid.Parse(ID); err != nil {
+		return nil, apperrors.NotFound("user is not found")
+	}
+	data, err := s.userRepo.FindUserByID(ctx, ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("user is not found")
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+func (s *UserService) findRole(ctx context.Context, name string) (*models.Roles, error) {
+	role, err := s.roleRepo.FindRoleByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("role is not found")
+		}
+		return nil, err
+	}
+	return role, nil
+}
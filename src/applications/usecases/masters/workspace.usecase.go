@@ -1,13 +1,18 @@
 package masterusecase
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/applications/audit"
 	"kiraform/src/applications/models"
 	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/infras/tracing"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
 	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"kiraform/src/utils"
 	"math"
+	"sort"
 	"strings"
 	"time"
 
@@ -17,32 +22,45 @@ import (
 )
 
 type WorkspaceUsecase interface {
-	FindWorkspaces(userID *string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
-	FindWorkspaceByID(ID string) (*models.Workspaces, error)
-	CreateWorkspace(userID string, body masterschema.WorkspacePayload) error
-	UpdateWorkspace(ID string, body masterschema.WorkspacePayload) error
-	DeleteWorkspace(ID string) error
-	FindWorkspaceUsers(workspaceID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
-	FindWorkspaceUserByID(workspaceID string, ID string) (*masterschema.WorkspaceUserSchema, error)
-	CreateWorkspaceUser(workspaceID string, body masterschema.WorkspaceUserPayload) error
-	UpdateWorkspaceUser(workspaceID string, ID string, body masterschema.WorkspaceUserUpdatePayload) error
-	DeleteWorkspaceUser(workspaceID string, ID string) error
-	FindAllCampaignsByUser(userID string) ([]masterschema.CampaignSelectResponse, error)
+	FindWorkspaces(ctx context.Context, userID *string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
+	FindWorkspaceByID(ctx context.Context, ID string) (*models.Workspaces, error)
+	CreateWorkspace(ctx context.Context, actor commonschema.Actor, body masterschema.WorkspacePayload) error
+	UpdateWorkspace(ctx context.Context, actor commonschema.Actor, ID string, body masterschema.WorkspacePayload) error
+	UpdateWorkspaceTwoFactor(ctx context.Context, actor commonschema.Actor, ID string, body masterschema.WorkspaceTwoFactorPayload) error
+	DeleteWorkspace(ctx context.Context, actor commonschema.Actor, ID string) error
+	FindWorkspaceUsers(ctx context.Context, workspaceID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
+	FindWorkspaceUserByID(ctx context.Context, workspaceID string, ID string) (*masterschema.WorkspaceUserSchema, error)
+	CreateWorkspaceUser(ctx context.Context, actor commonschema.Actor, workspaceID string, body masterschema.WorkspaceUserPayload) error
+	UpdateWorkspaceUser(ctx context.Context, actor commonschema.Actor, workspaceID string, ID string, body masterschema.WorkspaceUserUpdatePayload) error
+	DeleteWorkspaceUser(ctx context.Context, actor commonschema.Actor, workspaceID string, ID string) error
+	FindAllCampaignsByUser(ctx context.Context, userID string) ([]masterschema.CampaignSelectResponse, error)
+	WorkspaceAnalytics(ctx context.Context, workspaceID string, params *masterschema.AnalyticsParams) (*masterschema.WorkspaceAnalyticsResponse, error)
 }
 
+// analyticsCacheTTL is how long workspace analytics may lag behind entries and reviews,
+// cache is not dropped on writes since it is per replica and entries change all the time
+const analyticsCacheTTL = 5 * time.Minute
+
 type WorkspaceService struct {
-	workspaceRepo masterrepo.WorkspaceRepository
-	userRepo      masterrepo.UserRepository
+	workspaceRepo  masterrepo.WorkspaceRepository
+	userRepo       masterrepo.UserRepository
+	audit          *audit.Recorder
+	analyticsCache *utils.Cache[*masterschema.WorkspaceAnalyticsResponse]
 }
 
-func NewWorkspaceUsecase(workspaceRepo masterrepo.WorkspaceRepository, userRepo masterrepo.UserRepository) *WorkspaceService {
+func NewWorkspaceUsecase(workspaceRepo masterrepo.WorkspaceRepository, userRepo masterrepo.UserRepository, recorder *audit.Recorder) *WorkspaceService {
 	return &WorkspaceService{
-		workspaceRepo: workspaceRepo,
-		userRepo:      userRepo,
+		workspaceRepo:  workspaceRepo,
+		userRepo:       userRepo,
+		audit:          recorder,
+		analyticsCache: utils.NewCache[*masterschema.WorkspaceAnalyticsResponse](analyticsCacheTTL),
 	}
 }
 
-func (s *WorkspaceService) FindWorkspaces(userID *string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+func (s *WorkspaceService) FindWorkspaces(ctx context.Context, userID *string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "WorkspaceService.FindWorkspaces")
+	defer span.End()
+
 	response := commonschema.ResponseList{
 		Parameters: *params,
 		TotalPage:  1,
@@ -50,7 +68,21 @@ func (s *WorkspaceService) FindWorkspaces(userID *string, params *commonschema.Q
 	}
 
 	// get list data
-	rows, err := s.workspaceRepo.FindWorkspaces(userID, params)
+	rows, err := s.workspaceRepo.FindWorkspaces(ctx, userID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// get total form and total submission of every workspace in the page at once
+	workspaceIDs := make([]string, 0, len(rows))
+	for _, v := range rows {
+		workspaceIDs = append(workspaceIDs, v.ID.String())
+	}
+	totalForms, err := s.workspaceRepo.FindCountCampaignByWorkspaces(ctx, workspaceIDs)
+	if err != nil {
+		return nil, err
+	}
+	totalSubmits, err := s.workspaceRepo.FindCountFormSubmissionByWorkspaces(ctx, workspaceIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -58,22 +90,6 @@ func (s *WorkspaceService) FindWorkspaces(userID *string, params *commonschema.Q
 	// converting format data from []models.Workspaces -> []masterschema.WorkspaceList
 	var list []masterschema.WorkspaceList
 	for _, v := range rows {
-		// get total form from this workspace
-		var totalForm int64
-		countForm, err := s.workspaceRepo.FindCountCampaignByWorkspace(v.ID.String())
-		if err != nil {
-			return nil, err
-		}
-		totalForm = countForm
-
-		// get total form created from entire campaign in this workspace
-		var totalSubmit int64
-		countSubmit, err := s.workspaceRepo.FindCountFormSubmissionByWorkspace(v.ID.String())
-		if err != nil {
-			return nil, err
-		}
-		totalSubmit = countSubmit
-
 		// appending data
 		list = append(list, masterschema.WorkspaceList{
 			ID:          v.ID.String(),
@@ -82,14 +98,15 @@ func (s *WorkspaceService) FindWorkspaces(userID *string, params *commonschema.Q
 			Slug:        v.Slug,
 			Description: v.Description,
 			Thumbnail:   v.Thumbnail,
-			TotalForm:   totalForm,
-			TotalSubmit: totalSubmit,
+			Timezone:    v.Timezone,
+			TotalForm:   totalForms[v.ID.String()],
+			TotalSubmit: totalSubmits[v.ID.String()],
 			CreatedAt:   v.CreatedAt,
 		})
 	}
 
 	// get count data
-	count, err := s.workspaceRepo.FindCountWorkspace(userID, params)
+	count, err := s.workspaceRepo.FindCountWorkspace(ctx, userID, params)
 	if err != nil {
 		return nil, err
 	}
@@ -104,15 +121,30 @@ func (s *WorkspaceService) FindWorkspaces(userID *string, params *commonschema.Q
 	return &response, nil
 }
 
-func (s *WorkspaceService) FindWorkspaceByID(ID string) (*models.Workspaces, error) {
-	data, err := s.workspaceRepo.FindWorkspaceByID(ID)
+func (s *WorkspaceService) FindWorkspaceByID(ctx context.Context, ID string) (*models.Workspaces, error) {
+	ctx, span := tracing.Start(ctx, "WorkspaceService.FindWorkspaceByID")
+	defer span.End()
+
+	data, err := s.workspaceRepo.FindWorkspaceByID(ctx, ID)
 	if err != nil {
 		return nil, err
 	}
 	return data, nil
 }
 
-func (s *WorkspaceService) CreateWorkspace(userID string, body masterschema.WorkspacePayload) error {
+func (s *WorkspaceService) CreateWorkspace(ctx context.Context, actor commonschema.Actor, body masterschema.WorkspacePayload) error {
+	ctx, span := tracing.Start(ctx, "WorkspaceService.CreateWorkspace")
+	defer span.End()
+
+	// validate timezone, default to UTC
+	timezone := "UTC"
+	if body.Timezone != "" {
+		if _, err := time.LoadLocation(body.Timezone); err != nil {
+			return apperrors.Field("timezone", "is not valid, use IANA format e.g. Asia/Jakarta")
+		}
+		timezone = body.Timezone
+	}
+
 	// prepare data to insert
 	ID := uuid.New()
 	arrOfID := strings.Split(ID.String(), "-")
@@ -129,16 +161,24 @@ func (s *WorkspaceService) CreateWorkspace(userID string, body masterschema.Work
 		Description: body.Description,
 		IsPublish:   body.IsPublish,
 		Thumbnail:   body.Thumbnail,
+		Timezone:    timezone,
 	}
 
 	// perform to insert data
-	err := s.workspaceRepo.CreateWorkspace(data)
+	err := s.workspaceRepo.CreateWorkspace(ctx, data)
 	if err != nil {
 		return err
 	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		WorkspaceID: ID.String(),
+		Action:      audit.ActionCreate,
+		EntityType:  "workspace",
+		EntityID:    ID.String(),
+		After:       data,
+	})
 
 	// prepare and insert workspace user
-	UUIDuserID, err := uuid.Parse(userID)
+	UUIDuserID, err := uuid.Parse(actor.UserID)
 	if err == nil {
 		wu := models.WorkspaceUsers{
 			ID:          uuid.New(),
@@ -146,14 +186,30 @@ func (s *WorkspaceService) CreateWorkspace(userID string, body masterschema.Work
 			WorkspaceID: ID,
 			Status:      "S5", // as an owner
 		}
-		_ = s.workspaceRepo.CreateWorkspaceUser(wu)
+		_ = s.workspaceRepo.CreateWorkspaceUser(ctx, wu)
 	}
 
 	// set as success
 	return nil
 }
 
-func (s *WorkspaceService) UpdateWorkspace(ID string, body masterschema.WorkspacePayload) error {
+func (s *WorkspaceService) UpdateWorkspace(ctx context.Context, actor commonschema.Actor, ID string, body masterschema.WorkspacePayload) error {
+	ctx, span := tracing.Start(ctx, "WorkspaceService.UpdateWorkspace")
+	defer span.End()
+
+	// validate timezone, empty value keeps the existing one
+	if body.Timezone != "" {
+		if _, err := time.LoadLocation(body.Timezone); err != nil {
+			return apperrors.Field("timezone", "is not valid, use IANA format e.g. Asia/Jakarta")
+		}
+	}
+
+	// keep existing data for audit log
+	before, err := s.workspaceRepo.FindWorkspaceByID(ctx, ID)
+	if err != nil {
+		return err
+	}
+
 	// preparing data
 	t := time.Now()
 	data := models.Workspaces{
@@ -162,20 +218,61 @@ func (s *WorkspaceService) UpdateWorkspace(ID string, body masterschema.Workspac
 		Description: body.Description,
 		IsPublish:   body.IsPublish,
 		Thumbnail:   body.Thumbnail,
+		Timezone:    body.Timezone,
 		UpdatedAt:   &t,
 	}
 
 	// perform to update data
-	err := s.workspaceRepo.UpdateWorkspace(ID, data)
+	err = s.workspaceRepo.UpdateWorkspace(ctx, ID, data)
+	if err != nil {
+		return err
+	}
+
+	after, _ := s.workspaceRepo.FindWorkspaceByID(ctx, ID)
+	s.audit.Record(ctx, actor, audit.Entry{
+		WorkspaceID: ID,
+		Action:      audit.ActionUpdate,
+		EntityType:  "workspace",
+		EntityID:    ID,
+		Before:      before,
+		After:       after,
+	})
+	return nil
+}
+
+func (s *WorkspaceService) UpdateWorkspaceTwoFactor(ctx context.Context, actor commonschema.Actor, ID string, body masterschema.WorkspaceTwoFactorPayload) error {
+	ctx, span := tracing.Start(ctx, "WorkspaceService.UpdateWorkspaceTwoFactor")
+	defer span.End()
+
+	// keep existing data for audit log
+	before, err := s.workspaceRepo.FindWorkspaceByID(ctx, ID)
 	if err != nil {
 		return err
 	}
+
+	// perform to update data
+	if err := s.workspaceRepo.UpdateWorkspaceTwoFactor(ctx, ID, body.RequireTwoFactor); err != nil {
+		return err
+	}
+
+	after, _ := s.workspaceRepo.FindWorkspaceByID(ctx, ID)
+	s.audit.Record(ctx, actor, audit.Entry{
+		WorkspaceID: ID,
+		Action:      audit.ActionUpdate,
+		EntityType:  "workspace",
+		EntityID:    ID,
+		Before:      before,
+		After:       after,
+	})
 	return nil
 }
 
-func (s *WorkspaceService) DeleteWorkspace(ID string) error {
+func (s *WorkspaceService) DeleteWorkspace(ctx context.Context, actor commonschema.Actor, ID string) error {
+	ctx, span := tracing.Start(ctx, "WorkspaceService.DeleteWorkspace")
+	defer span.End()
+
 	// check existing data
-	_, err := s.FindWorkspaceByID(ID)
+	before, err := s.FindWorkspaceByID(ctx, ID)
 	if err != nil {
 		return err
 	}
@@ -184,16 +281,28 @@ func (s *WorkspaceService) DeleteWorkspace(ID string) error {
 	t := time.Now()
 	data := models.Workspaces{
 		Deleted:   true,
+		DeletedAt: &t,
+		DeletedBy: utils.NullableUUID(actor.UserID),
 		UpdatedAt: &t,
 	}
-	err = s.workspaceRepo.UpdateWorkspace(ID, data)
+	err = s.workspaceRepo.UpdateWorkspace(ctx, ID, data)
 	if err != nil {
 		return err
 	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		WorkspaceID: ID,
+		Action:      audit.ActionDelete,
+		EntityType:  "workspace",
+		EntityID:    ID,
+		Before:      before,
+	})
 	return nil
 }
 
-func (s *WorkspaceService) FindWorkspaceUsers(workspaceID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+func (s *WorkspaceService) FindWorkspaceUsers(ctx context.Context, workspaceID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "WorkspaceService.FindWorkspaceUsers")
+	defer span.End()
+
 	response := commonschema.ResponseList{
 		Parameters: *params,
 		TotalPage:  1,
@@ -201,13 +310,13 @@ func (s *WorkspaceService) FindWorkspaceUsers(workspaceID string, params *common
 	}
 
 	// get list data
-	rows, err := s.workspaceRepo.FindWorkspaceUsers(workspaceID, params)
+	rows, err := s.workspaceRepo.FindWorkspaceUsers(ctx, workspaceID, params)
 	if err != nil {
 		return nil, err
 	}
 
 	// get count data
-	count, err := s.workspaceRepo.FindCountWorkspaceUser(workspaceID, params)
+	count, err := s.workspaceRepo.FindCountWorkspaceUser(ctx, workspaceID, params)
 	if err != nil {
 		return nil, err
 	}
@@ -236,8 +345,11 @@ func (s *WorkspaceService) FindWorkspaceUsers(workspaceID string, params *common
 	return &response, nil
 }
 
-func (s *WorkspaceService) FindWorkspaceUserByID(workspaceID string, ID string) (*masterschema.WorkspaceUserSchema, error) {
-	data, err := s.workspaceRepo.FindWorkspaceUserByID(workspaceID, ID)
+func (s *WorkspaceService) FindWorkspaceUserByID(ctx context.Context, workspaceID string, ID string) (*masterschema.WorkspaceUserSchema, error) {
+	ctx, span := tracing.Start(ctx, "WorkspaceService.FindWorkspaceUserByID")
+	defer span.End()
+
+	data, err := s.workspaceRepo.FindWorkspaceUserByID(ctx, workspaceID, ID)
 	if err != nil {
 		return nil, err
 	}
@@ -258,7 +370,10 @@ func (s *WorkspaceService) FindWorkspaceUserByID(workspaceID string, ID string)
 	return data, nil
 }
 
-func (s *WorkspaceService) CreateWorkspaceUser(workspaceID string, body masterschema.WorkspaceUserPayload) error {
+func (s *WorkspaceService) CreateWorkspaceUser(ctx context.Context, actor commonschema.Actor, workspaceID string, body masterschema.WorkspaceUserPayload) error {
+	ctx, span := tracing.Start(ctx, "WorkspaceService.CreateWorkspaceUser")
+	defer span.End()
+
 	// parse workspace id into uuid format
 	UUIDworkspaceID, err := uuid.Parse(workspaceID)
 	if err != nil {
@@ -270,44 +385,45 @@ func (s *WorkspaceService) CreateWorkspaceUser(workspaceID string, body mastersc
 	isExists := false
 	var UUIDuserID uuid.UUID
 	if body.UserID != nil {
-		_, err := s.userRepo.FindUserByID(*body.UserID)
+		uid, err := uuid.Parse(*body.UserID)
 		if err != nil {
-			if !errors.Is(err, gorm.ErrRecordNotFound) {
-				return err
-			}
+			return apperrors.Field("user_id", "must be a valid uuid")
 		}
-		isExists = true
 
-		uid, err := uuid.Parse(*body.UserID)
+		_, err = s.userRepo.FindUserByID(ctx, *body.UserID)
 		if err != nil {
-			return err
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		} else {
+			isExists = true
+			UUIDuserID = uid
 		}
-		UUIDuserID = uid
 	} else if body.UserEmail != nil {
-		u, err := s.userRepo.FindUserByEmail(*body.UserEmail)
+		u, err := s.userRepo.FindUserByEmail(ctx, *body.UserEmail)
 		if err != nil {
 			if !errors.Is(err, gorm.ErrRecordNotFound) {
 				return err
 			}
+		} else {
+			isExists = true
+			UUIDuserID = u.ID
 		}
-		isExists = true
-		UUIDuserID = u.ID
 	}
 
 	if !isExists {
-		return errors.New("user id or email is not found please try another user")
+		return apperrors.NotFound("user id or email is not found please try another user")
 	}
 
 	// check if user already registered in this workspace or not
-	wu, err := s.workspaceRepo.FindWorkspaceUserByUser(workspaceID, UUIDuserID.String())
+	wu, err := s.workspaceRepo.FindWorkspaceUserByUser(ctx, workspaceID, UUIDuserID.String())
 	if err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			return err
 		}
 	}
-	fmt.Println(wu)
 	if wu != nil {
-		return errors.New("this user already exists in this workspace")
+		return apperrors.Conflict("this user already exists in this workspace")
 	}
 
 	// preparing data to insert
@@ -319,14 +435,30 @@ func (s *WorkspaceService) CreateWorkspaceUser(workspaceID string, body mastersc
 	}
 
 	// perform to insert data
-	err = s.workspaceRepo.CreateWorkspaceUser(data)
+	err = s.workspaceRepo.CreateWorkspaceUser(ctx, data)
 	if err != nil {
 		return err
 	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		WorkspaceID: workspaceID,
+		Action:      audit.ActionCreate,
+		EntityType:  "workspace_user",
+		EntityID:    data.ID.String(),
+		After:       data,
+	})
 	return nil
 }
 
-func (s *WorkspaceService) UpdateWorkspaceUser(workspaceID string, ID string, body masterschema.WorkspaceUserUpdatePayload) error {
+func (s *WorkspaceService) UpdateWorkspaceUser(ctx context.Context, actor commonschema.Actor, workspaceID string, ID string, body masterschema.WorkspaceUserUpdatePayload) error {
+	ctx, span := tracing.Start(ctx, "WorkspaceService.UpdateWorkspaceUser")
+	defer span.End()
+
+	// keep existing data for audit log
+	before, err := s.workspaceRepo.FindWorkspaceUserByID(ctx, workspaceID, ID)
+	if err != nil {
+		return err
+	}
+
 	// Only status that will updated in this section
 	// User cannot update user_id
 	t := time.Now()
@@ -336,16 +468,29 @@ func (s *WorkspaceService) UpdateWorkspaceUser(workspaceID string, ID string, bo
 	}
 
 	// perform to update data
-	err := s.workspaceRepo.UpdateWorkspaceUser(workspaceID, ID, data)
+	err = s.workspaceRepo.UpdateWorkspaceUser(ctx, workspaceID, ID, data)
 	if err != nil {
 		return err
 	}
+
+	after, _ := s.workspaceRepo.FindWorkspaceUserByID(ctx, workspaceID, ID)
+	s.audit.Record(ctx, actor, audit.Entry{
+		WorkspaceID: workspaceID,
+		Action:      audit.ActionUpdate,
+		EntityType:  "workspace_user",
+		EntityID:    ID,
+		Before:      before,
+		After:       after,
+	})
 	return nil
 }
 
-func (s *WorkspaceService) DeleteWorkspaceUser(workspaceID string, ID string) error {
+func (s *WorkspaceService) DeleteWorkspaceUser(ctx context.Context, actor commonschema.Actor, workspaceID string, ID string) error {
+	ctx, span := tracing.Start(ctx, "WorkspaceService.DeleteWorkspaceUser")
+	defer span.End()
+
 	// check existing data
-	_, err := s.FindWorkspaceUserByID(workspaceID, ID)
+	before, err := s.workspaceRepo.FindWorkspaceUserByID(ctx, workspaceID, ID)
 	if err != nil {
 		return err
 	}
@@ -356,17 +501,171 @@ func (s *WorkspaceService) DeleteWorkspaceUser(workspaceID string, ID string) er
 		Deleted:   true,
 		UpdatedAt: t,
 	}
-	err = s.workspaceRepo.UpdateWorkspaceUser(workspaceID, ID, data)
+	err = s.workspaceRepo.UpdateWorkspaceUser(ctx, workspaceID, ID, data)
 	if err != nil {
 		return err
 	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		WorkspaceID: workspaceID,
+		Action:      audit.ActionDelete,
+		EntityType:  "workspace_user",
+		EntityID:    ID,
+		Before:      before,
+	})
 	return nil
 }
 
-func (s *WorkspaceService) FindAllCampaignsByUser(userID string) ([]masterschema.CampaignSelectResponse, error) {
-	data, err := s.workspaceRepo.FindAllCampaignsByUser(userID)
+func (s *WorkspaceService) FindAllCampaignsByUser(ctx context.Context, userID string) ([]masterschema.CampaignSelectResponse, error) {
+	ctx, span := tracing.Start(ctx, "WorkspaceService.FindAllCampaignsByUser")
+	defer span.End()
+
+	data, err := s.workspaceRepo.FindAllCampaignsByUser(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 	return data, nil
 }
+
+func (s *WorkspaceService) WorkspaceAnalytics(ctx context.Context, workspaceID string, params *masterschema.AnalyticsParams) (*masterschema.WorkspaceAnalyticsResponse, error) {
+	ctx, span := tracing.Start(ctx, "WorkspaceService.WorkspaceAnalytics")
+	defer span.End()
+
+	// analytics follow workspace timezone
+	workspace, err := s.workspaceRepo.FindWorkspaceByID(ctx, workspaceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("workspace not found")
+		}
+		return nil, err
+	}
+	params.Timezone = workspace.Timezone
+	if params.Timezone == "" {
+		params.Timezone = "UTC"
+	}
+
+	// default date range ends today in workspace timezone
+	if err := utils.AnalyticsRange(params); err != nil {
+		return nil, err
+	}
+
+	// aggregate queries are heavy, reuse result for a few minutes
+	// timezone is part of the key so changing it groups entries again
+	cacheKey := strings.Join([]string{workspaceID, params.Timezone, params.StartDate, params.EndDate, params.GroupBy}, "|")
+	if cached, ok := s.analyticsCache.Get(cacheKey); ok {
+		return cached, nil
+	}
+
+	// previous period has the same length and ends the day before start_date
+	start, err := time.Parse(time.DateOnly, params.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.Parse(time.DateOnly, params.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	days := int(end.Sub(start).Hours()/24) + 1
+	previous := *params
+	previous.StartDate = start.AddDate(0, 0, -days).Format(time.DateOnly)
+	previous.EndDate = start.AddDate(0, 0, -1).Format(time.DateOnly)
+
+	// get summary for both periods
+	summary, err := s.analyticsSummary(ctx, workspaceID, params)
+	if err != nil {
+		return nil, err
+	}
+	previousSummary, err := s.analyticsSummary(ctx, workspaceID, &previous)
+	if err != nil {
+		return nil, err
+	}
+
+	// get submission trend
+	submissions, err := s.workspaceRepo.FindSummaryEntriesByWorkspace(ctx, workspaceID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// get ranking of campaigns
+	campaigns, err := s.workspaceRepo.FindCampaignPerformances(ctx, workspaceID, params)
+	if err != nil {
+		return nil, err
+	}
+	topSubmissions := []masterschema.WorkspaceTopCampaign{}
+	topConversions := []masterschema.WorkspaceTopCampaign{}
+	for _, v := range campaigns {
+		v.ConversionRate = rate(v.TotalSubmit, v.TotalVisit)
+		if v.TotalSubmit > 0 {
+			topSubmissions = append(topSubmissions, v)
+		}
+		if v.TotalVisit > 0 {
+			topConversions = append(topConversions, v)
+		}
+	}
+	sort.SliceStable(topConversions, func(i, j int) bool {
+		return topConversions[i].ConversionRate > topConversions[j].ConversionRate
+	})
+	topSubmissions = topSubmissions[:min(len(topSubmissions), 5)]
+	topConversions = topConversions[:min(len(topConversions), 5)]
+
+	// get most active reviewers
+	topReviewers, err := s.workspaceRepo.FindTopReviewers(ctx, workspaceID, params, 5)
+	if err != nil {
+		return nil, err
+	}
+
+	data := masterschema.WorkspaceAnalyticsResponse{
+		Parameters:      *params,
+		PreviousPeriod:  previous,
+		Summary:         *summary,
+		PreviousSummary: *previousSummary,
+		Growth: masterschema.WorkspaceAnalyticsGrowth{
+			TotalSubmit:    growth(float64(summary.TotalSubmit), float64(previousSummary.TotalSubmit)),
+			TotalVisit:     growth(float64(summary.TotalVisit), float64(previousSummary.TotalVisit)),
+			ApprovalRate:   growth(summary.ApprovalRate, previousSummary.ApprovalRate),
+			ConversionRate: growth(summary.ConversionRate, previousSummary.ConversionRate),
+		},
+		Submissions:    submissions,
+		TopSubmissions: topSubmissions,
+		TopConversions: topConversions,
+		TopReviewers:   topReviewers,
+	}
+	s.analyticsCache.Set(cacheKey, &data)
+	return &data, nil
+}
+
+func (s *WorkspaceService) analyticsSummary(ctx context.Context, workspaceID string, params *masterschema.AnalyticsParams) (*masterschema.WorkspaceAnalyticsSummary, error) {
+	summary, err := s.workspaceRepo.FindEntryStatusSummary(ctx, workspaceID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	totalVisit, err := s.workspaceRepo.FindCountVisitByWorkspace(ctx, workspaceID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.TotalVisit = totalVisit
+	summary.ApprovalRate = rate(summary.TotalApproved, summary.TotalSubmit)
+	summary.RejectionRate = rate(summary.TotalRejected, summary.TotalSubmit)
+	summary.ConversionRate = rate(summary.TotalSubmit, summary.TotalVisit)
+	return summary, nil
+}
+
+// rate returns percentage of value from total, rounded to 2 decimals
+func rate(value int64, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(value)/float64(total)*10000) / 100
+}
+
+// growth returns percentage change from previous to current, rounded to 2 decimals
+func growth(current float64, previous float64) float64 {
+	if previous == 0 {
+		if current == 0 {
+			return 0
+		}
+		return 100
+	}
+	return math.Round((current-previous)/previous*10000) / 100
+}
@@ -0,0 +1,60 @@
+package masterusecase
+
+import (
+	"context"
+	"database/sql/driver"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/infras/dbtest"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestFindWorkspacesQueryCount(t *testing.T) {
+	dbtest.ConstantQueries(t, []int{1, 25}, func(t *testing.T, total int) []string {
+		workspaces := &dbtest.Rows{Columns: []string{"id", "title", "timezone", "created_at"}}
+		forms := &dbtest.Rows{Columns: []string{"id", "total"}}
+		submits := &dbtest.Rows{Columns: []string{"id", "total"}}
+		for range total {
+			id := uuid.NewString()
+			workspaces.Values = append(workspaces.Values, []driver.Value{id, "Workspace", "UTC", time.Now()})
+			forms.Values = append(forms.Values, []driver.Value{id, int64(2)})
+			submits.Values = append(submits.Values, []driver.Value{id, int64(5)})
+		}
+
+		DB, recorder := dbtest.Open(t, func(query string) *dbtest.Rows {
+			switch {
+			case strings.Contains(query, "count(*)"):
+				return &dbtest.Rows{Columns: []string{"count"}, Values: [][]driver.Value{{int64(total)}}}
+			case strings.Contains(query, `FROM "workspaces"`):
+				return workspaces
+			case strings.Contains(query, `FROM "campaigns"`):
+				return forms
+			case strings.Contains(query, "FROM form_entries"):
+				return submits
+			}
+			return nil
+		})
+
+		uc := NewWorkspaceUsecase(masterrepo.NewWorkspaceRepository(DB), nil, nil)
+		userID := uuid.NewString()
+		response, err := uc.FindWorkspaces(context.Background(), &userID, &commonschema.QueryParams{Page: 1, Limit: 50})
+		if err != nil {
+			t.Fatalf("find workspaces: %v", err)
+		}
+		list := response.Rows.([]masterschema.WorkspaceList)
+		if len(list) != total {
+			t.Fatalf("got %d workspaces, want %d", len(list), total)
+		}
+		for _, v := range list {
+			if v.TotalForm != 2 || v.TotalSubmit != 5 {
+				t.Fatalf("got total form %d and total submit %d, want 2 and 5", v.TotalForm, v.TotalSubmit)
+			}
+		}
+		return recorder.Queries()
+	})
+}
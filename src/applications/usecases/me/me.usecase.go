@@ -1,9 +1,13 @@
 package meusecase
 
 import (
+	"context"
 	"errors"
+	"kiraform/src/applications/apperrors"
 	"kiraform/src/applications/models"
 	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/applications/twofactor"
+	"kiraform/src/infras/tracing"
 	meschema "kiraform/src/interfaces/rest/schemas/me"
 	"time"
 
@@ -13,29 +17,42 @@ import (
 )
 
 type MeUsecase interface {
-	GetProfile(userID string) (*meschema.MeResponse, error)
-	UpdateProfile(userID string, body meschema.UserProfilePayload) error
-	ChangePassword(userID string, body meschema.ChangePasswordPayload) error
+	GetProfile(ctx context.Context, userID string) (*meschema.MeResponse, error)
+	UpdateProfile(ctx context.Context, userID string, body meschema.UserProfilePayload) error
+	ChangePassword(ctx context.Context, userID string, body meschema.ChangePasswordPayload) error
+	GetTwoFactor(ctx context.Context, userID string) (*meschema.TwoFactorStatus, error)
+	EnrollTwoFactor(ctx context.Context, userID string) (*meschema.TwoFactorEnrollResponse, error)
+	ConfirmTwoFactor(ctx context.Context, userID string, body meschema.TwoFactorCodePayload) (*meschema.TwoFactorRecoveryCodes, error)
+	DisableTwoFactor(ctx context.Context, userID string, body meschema.TwoFactorDisablePayload) error
 }
 
 type MeService struct {
-	userrepo masterrepo.UserRepository
+	userrepo      masterrepo.UserRepository
+	twoFactorRepo masterrepo.TwoFactorRepository
+	twoFactor     *twofactor.Manager
 }
 
-func NewMeUsecase(userrepo masterrepo.UserRepository) *MeService {
-	return &MeService{userrepo: userrepo}
+func NewMeUsecase(userrepo masterrepo.UserRepository, twoFactorRepo masterrepo.TwoFactorRepository, twoFactor *twofactor.Manager) *MeService {
+	return &MeService{
+		userrepo:      userrepo,
+		twoFactorRepo: twoFactorRepo,
+		twoFactor:     twoFactor,
+	}
 }
 
-func (s *MeService) GetProfile(userID string) (*meschema.MeResponse, error) {
+func (s *MeService) GetProfile(ctx context.Context, userID string) (*meschema.MeResponse, error) {
+	ctx, span := tracing.Start(ctx, "MeService.GetProfile")
+	defer span.End()
+
 	// get user account
-	user, err := s.userrepo.FindUserByID(userID)
+	user, err := s.userrepo.FindUserByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
 	// get user profile
 	var userProfile *meschema.UserProfile
-	up, err := s.userrepo.FindUserProfile(userID)
+	up, err := s.userrepo.FindUserProfile(ctx, userID)
 	if err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, err
@@ -59,32 +76,31 @@ func (s *MeService) GetProfile(userID string) (*meschema.MeResponse, error) {
 
 	// get user roles
 	var userRoles []meschema.UserRole
-	ur, err := s.userrepo.GetRoleByUser(user.ID)
+	roles, err := s.userrepo.FindRolesByUsers(ctx, []string{user.ID.String()})
 	if err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, err
-		}
+		return nil, err
 	}
-	if ur != nil {
+	for _, v := range roles {
 		userRoles = append(userRoles, meschema.UserRole{
-			RoleID:    ur.RoleID.String(),
-			RoleName:  ur.Role.Name,
-			CreatedAt: ur.CreatedAt,
+			RoleID:      v.RoleID.String(),
+			RoleName:    v.Role.Name,
+			Permissions: v.Role.PermissionList(),
+			CreatedAt:   v.CreatedAt,
 		})
 	}
 
 	// prepare data summary
-	totalForm, err := s.userrepo.FindCountFormByUser(user.ID.String())
+	totalForm, err := s.userrepo.FindCountFormByUser(ctx, user.ID.String())
 	if err != nil {
 		return nil, err
 	}
 
-	totalSubmit, err := s.userrepo.FindCountFormSubmitByUser(user.ID.String())
+	totalSubmit, err := s.userrepo.FindCountFormSubmitByUser(ctx, user.ID.String())
 	if err != nil {
 		return nil, err
 	}
 
-	TotalSendSubmit, err := s.userrepo.FindCountFormSubmittedByUser(user.ID.String())
+	TotalSendSubmit, err := s.userrepo.FindCountFormSubmittedByUser(ctx, user.ID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -112,7 +128,10 @@ func (s *MeService) GetProfile(userID string) (*meschema.MeResponse, error) {
 	return &data, nil
 }
 
-func (s *MeService) UpdateProfile(userID string, body meschema.UserProfilePayload) error {
+func (s *MeService) UpdateProfile(ctx context.Context, userID string, body meschema.UserProfilePayload) error {
+	ctx, span := tracing.Start(ctx, "MeService.UpdateProfile")
+	defer span.End()
+
 	var avatar string
 	t := time.Now()
 	UUIDuserID, err := uuid.Parse(userID)
@@ -150,7 +169,7 @@ func (s *MeService) UpdateProfile(userID string, body meschema.UserProfilePayloa
 	}
 
 	// check existing profile to decided action insert or update
-	exists, err := s.userrepo.FindUserProfile(userID)
+	exists, err := s.userrepo.FindUserProfile(ctx, userID)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return err
 	}
@@ -161,33 +180,36 @@ func (s *MeService) UpdateProfile(userID string, body meschema.UserProfilePayloa
 		data.UserID = UUIDuserID
 
 		// perform to create profile
-		if err := s.userrepo.CreateUserProfile(data); err != nil {
+		if err := s.userrepo.CreateUserProfile(ctx, data); err != nil {
 			return err
 		}
 	} else {
 		// perform to update profile
-		if err := s.userrepo.UpdateUserProfile(userID, data); err != nil {
+		if err := s.userrepo.UpdateUserProfile(ctx, userID, data); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (s *MeService) ChangePassword(userID string, body meschema.ChangePasswordPayload) error {
+func (s *MeService) ChangePassword(ctx context.Context, userID string, body meschema.ChangePasswordPayload) error {
+	ctx, span := tracing.Start(ctx, "MeService.ChangePassword")
+	defer span.End()
+
 	// check confirm password
 	if body.NewPassword != body.ConfirmPassword {
-		return errors.New("confirm password does not match")
+		return apperrors.Field("confirm_password", "does not match")
 	}
 
 	// get user first
-	user, err := s.userrepo.FindUserByID(userID)
+	user, err := s.userrepo.FindUserByID(ctx, userID)
 	if err != nil {
 		return err
 	}
 
 	// confirm hash password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.OldPassword)); err != nil {
-		return errors.New("old password does not match, please input right password")
+		return apperrors.Field("old_password", "does not match, please input right password")
 	}
 
 	// prepare update data
@@ -202,9 +224,90 @@ func (s *MeService) ChangePassword(userID string, body meschema.ChangePasswordPa
 		Password:  string(hashedPassword),
 		UpdatedAt: &t,
 	}
-	err = s.userrepo.UpdateUser(userID, newUser)
+	err = s.userrepo.UpdateUser(ctx, userID, newUser)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+func (s *MeService) GetTwoFactor(ctx context.Context, userID string) (*meschema.TwoFactorStatus, error) {
+	ctx, span := tracing.Start(ctx, "MeService.GetTwoFactor")
+	defer span.End()
+
+	// pending enrolment is reported as disabled
+	response := meschema.TwoFactorStatus{}
+	data, err := s.twoFactorRepo.FindTwoFactorByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &response, nil
+		}
+		return nil, err
+	}
+	if data.ConfirmedAt == nil {
+		return &response, nil
+	}
+
+	left, err := s.twoFactorRepo.FindCountRecoveryCodeLeft(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	response.Enabled = true
+	response.ConfirmedAt = data.ConfirmedAt
+	response.RecoveryCodesLeft = left
+	return &response, nil
+}
+
+func (s *MeService) EnrollTwoFactor(ctx context.Context, userID string) (*meschema.TwoFactorEnrollResponse, error) {
+	ctx, span := tracing.Start(ctx, "MeService.EnrollTwoFactor")
+	defer span.End()
+
+	// email is the account name shown in authenticator app
+	user, err := s.userrepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	secret, uri, err := s.twoFactor.Enroll(ctx, userID, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	return &meschema.TwoFactorEnrollResponse{
+		Secret:     secret,
+		OtpauthURI: uri,
+	}, nil
+}
+
+func (s *MeService) ConfirmTwoFactor(ctx context.Context, userID string, body meschema.TwoFactorCodePayload) (*meschema.TwoFactorRecoveryCodes, error) {
+	ctx, span := tracing.Start(ctx, "MeService.ConfirmTwoFactor")
+	defer span.End()
+
+	codes, err := s.twoFactor.Confirm(ctx, userID, body.Code)
+	if err != nil {
+		return nil, err
+	}
+	return &meschema.TwoFactorRecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+func (s *MeService) DisableTwoFactor(ctx context.Context, userID string, body meschema.TwoFactorDisablePayload) error {
+	ctx, span := tracing.Start(ctx, "MeService.DisableTwoFactor")
+	defer span.End()
+
+	// stolen access token alone must not be enough, so password and code are both asked
+	user, err := s.userrepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.Password)); err != nil {
+		return apperrors.Field("password", "does not match, please input right password")
+	}
+	if err := s.twoFactor.Verify(ctx, userID, body.Code); err != nil {
+		switch {
+		case errors.Is(err, twofactor.ErrInvalidCode):
+			return apperrors.Field("code", "is invalid")
+		case errors.Is(err, twofactor.ErrNotEnabled):
+			return apperrors.NotFound("two-factor authentication is not enabled")
+		}
+		return err
+	}
+	return s.twoFactor.Disable(ctx, userID)
+}
@@ -0,0 +1,119 @@
+package meusecase
+
+import (
+	"context"
+	"errors"
+	"kiraform/src/applications/apperrors"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/infras/tracing"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	meschema "kiraform/src/interfaces/rest/schemas/me"
+	"kiraform/src/utils"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SessionUsecase interface {
+	FindSessions(ctx context.Context, userID string, currentID string) ([]meschema.SessionSchema, error)
+	RevokeSession(ctx context.Context, userID string, ID string) error
+	RevokeOtherSessions(ctx context.Context, userID string, currentID string) (int64, error)
+	FindLoginHistories(ctx context.Context, userID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
+}
+
+type SessionService struct {
+	sessionRepo masterrepo.SessionRepository
+	historyRepo masterrepo.LoginHistoryRepository
+}
+
+func NewSessionUsecase(sessionRepo masterrepo.SessionRepository, historyRepo masterrepo.LoginHistoryRepository) *SessionService {
+	return &SessionService{
+		sessionRepo: sessionRepo,
+		historyRepo: historyRepo,
+	}
+}
+
+// FindSessions returns active sessions of user, currentID marks session of this request
+func (s *SessionService) FindSessions(ctx context.Context, userID string, currentID string) ([]meschema.SessionSchema, error) {
+	ctx, span := tracing.Start(ctx, "SessionService.FindSessions")
+	defer span.End()
+
+	data, err := s.sessionRepo.FindActiveSessions(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	response := []meschema.SessionSchema{}
+	for _, v := range data {
+		response = append(response, meschema.SessionSchema{
+			ID:         v.ID.String(),
+			Method:     v.Method,
+			IP:         v.IP,
+			UserAgent:  v.UserAgent,
+			CreatedAt:  v.CreatedAt,
+			LastSeenAt: v.LastSeenAt,
+			ExpiresAt:  v.ExpiresAt,
+			Current:    v.ID.String() == currentID,
+		})
+	}
+	return response, nil
+}
+
+func (s *SessionService) RevokeSession(ctx context.Context, userID string, ID string) error {
+	ctx, span := tracing.Start(ctx, "SessionService.RevokeSession")
+	defer span.End()
+
+	if err := s.sessionRepo.RevokeSession(ctx, userID, ID, time.Now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound("session is not found or already revoked")
+		}
+		return err
+	}
+	return nil
+}
+
+// RevokeOtherSessions signs out every other device, current session is kept
+func (s *SessionService) RevokeOtherSessions(ctx context.Context, userID string, currentID string) (int64, error) {
+	ctx, span := tracing.Start(ctx, "SessionService.RevokeOtherSessions")
+	defer span.End()
+
+	return s.sessionRepo.RevokeOtherSessions(ctx, userID, currentID, time.Now())
+}
+
+func (s *SessionService) FindLoginHistories(ctx context.Context, userID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "SessionService.FindLoginHistories")
+	defer span.End()
+
+	response := commonschema.ResponseList{
+		Parameters: *params,
+		TotalPage:  1,
+		Rows:       nil,
+	}
+
+	// get list data
+	rows, err := s.historyRepo.FindLoginHistories(ctx, userID, params)
+	if err != nil {
+		return nil, err
+	}
+	rows, response.NextCursor, response.PrevCursor = utils.CursorPage(rows, params, func(v meschema.LoginHistorySchema) (string, string) {
+		return utils.CursorTime(v.CreatedAt), v.ID
+	})
+
+	// get count data, client can skip it on large list
+	totalPage := 0
+	if !params.SkipCount {
+		count, err := s.historyRepo.FindCountLoginHistory(ctx, userID, params)
+		if err != nil {
+			return nil, err
+		}
+		totalPage = 1
+		if count > 0 {
+			totalPage = int(math.Ceil(float64(int(count)) / float64(params.Limit)))
+		}
+	}
+
+	// send response
+	response.TotalPage = totalPage
+	response.Rows = rows
+	return &response, nil
+}
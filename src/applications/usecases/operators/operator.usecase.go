@@ -0,0 +1,318 @@
+package operatorusecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/applications/models"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/applications/sessions"
+	"kiraform/src/infras/tracing"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// OperatorUsecase holds maintenance tasks run by operators from command line
+type OperatorUsecase interface {
+	CreateAdmin(ctx context.Context, email string, fullname string, password string) error
+	ResetPassword(ctx context.Context, email string, password string) error
+	GrantPackage(ctx context.Context, email string, code string, days int) error
+	DeactivateUser(ctx context.Context, email string) error
+	ExportCampaign(ctx context.Context, key string, w io.Writer) (int, error)
+}
+
+// minPasswordLength applies to passwords set by operator
+const minPasswordLength = 8
+
+// exportBatchSize is number of entries read at once while exporting
+const exportBatchSize = 500
+
+type OperatorService struct {
+	userRepo     masterrepo.UserRepository
+	roleRepo     masterrepo.RoleRepository
+	packageRepo  masterrepo.PackageRepository
+	campaignRepo masterrepo.CampaignRepository
+	tracker      *sessions.Tracker
+}
+
+func NewOperatorUsecase(userRepo masterrepo.UserRepository, roleRepo masterrepo.RoleRepository, packageRepo masterrepo.PackageRepository, campaignRepo masterrepo.CampaignRepository, tracker *sessions.Tracker) *OperatorService {
+	return &OperatorService{
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		packageRepo:  packageRepo,
+		campaignRepo: campaignRepo,
+		tracker:      tracker,
+	}
+}
+
+func (s *OperatorService) CreateAdmin(ctx context.Context, email string, fullname string, password string) error {
+	ctx, span := tracing.Start(ctx, "OperatorService.CreateAdmin")
+	defer span.End()
+
+	if len(password) < minPasswordLength {
+		return apperrors.Field("password", "must be at least 8 characters")
+	}
+
+	// check existing email
+	user, err := s.userRepo.FindUserByEmail(ctx, email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if user != nil {
+		return apperrors.Conflict("email is already taken")
+	}
+
+	// admin role comes from roles seeder
+	role, err := s.roleRepo.FindRoleByName(ctx, models.RoleAdmin)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound("admin role is not found, run seed roles first")
+		}
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	// preparing data user, profile and role
+	userID := uuid.New()
+	uuidStr := strings.Split(userID.String(), "-")
+	dataUser := models.Users{
+		ID:           userID,
+		UserIdentity: strings.ToUpper(uuidStr[0] + uuidStr[1]),
+		Email:        email,
+		Password:     string(hashedPassword),
+		Fullname:     fullname,
+		IsActive:     true,
+		CreatedAt:    time.Now(),
+	}
+
+	firstName, middleName, lastName := "", "", ""
+	nameParts := strings.Fields(fullname)
+	if len(nameParts) > 0 {
+		firstName = nameParts[0]
+	}
+	if len(nameParts) > 1 {
+		middleName = nameParts[1]
+	}
+	if len(nameParts) > 2 {
+		lastName = strings.Join(nameParts[2:], " ")
+	}
+
+	dataUserProfile := models.UserProfiles{
+		ID:         uuid.New(),
+		UserID:     userID,
+		FirstName:  firstName,
+		MiddleName: middleName,
+		LastName:   lastName,
+		CreatedAt:  time.Now(),
+	}
+	dataUserRole := models.UserRoles{
+		ID:        uuid.New(),
+		UserID:    userID,
+		RoleID:    role.ID,
+		CreatedAt: time.Now(),
+	}
+
+	// perform to insert data
+	if err := s.userRepo.CreateUser(ctx, dataUser, dataUserProfile, dataUserRole); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "admin created", "user_id", userID, "email", email)
+	return nil
+}
+
+func (s *OperatorService) ResetPassword(ctx context.Context, email string, password string) error {
+	ctx, span := tracing.Start(ctx, "OperatorService.ResetPassword")
+	defer span.End()
+
+	if len(password) < minPasswordLength {
+		return apperrors.Field("password", "must be at least 8 characters")
+	}
+
+	user, err := s.findUser(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	// perform to update password
+	now := time.Now()
+	if err := s.userRepo.UpdateUser(ctx, user.ID.String(), models.Users{Password: string(hashedPassword), UpdatedAt: &now}); err != nil {
+		return err
+	}
+
+	// old password may be leaked, so every device signs in again
+	if err := s.tracker.RevokeAll(ctx, user.ID.String()); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "password reset", "user_id", user.ID)
+	return nil
+}
+
+func (s *OperatorService) GrantPackage(ctx context.Context, email string, code string, days int) error {
+	ctx, span := tracing.Start(ctx, "OperatorService.GrantPackage")
+	defer span.End()
+
+	if days < 1 {
+		return apperrors.Field("days", "must be at least 1")
+	}
+
+	user, err := s.findUser(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	pkg, err := s.packageRepo.FindPackageByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound("package is not found")
+		}
+		return err
+	}
+
+	// granted package replaces active one of the user
+	now := time.Now()
+	data := models.UserPackages{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		PackageID:  pkg.ID,
+		ActiveDate: now,
+		ExpireDate: now.AddDate(0, 0, days),
+		Remark:     "granted by operator",
+		IsActive:   true,
+		CreatedAt:  now,
+	}
+	if err := s.packageRepo.GrantUserPackage(ctx, data); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "package granted", "user_id", user.ID, "package", pkg.Code, "expire_date", data.ExpireDate)
+	return nil
+}
+
+func (s *OperatorService) DeactivateUser(ctx context.Context, email string) error {
+	ctx, span := tracing.Start(ctx, "OperatorService.DeactivateUser")
+	defer span.End()
+
+	user, err := s.findUser(ctx, email)
+	if err != nil {
+		return err
+	}
+	if !user.IsActive && !user.IsPending {
+		return apperrors.Conflict("user is already inactive")
+	}
+
+	// inactive user can not login anymore and is signed out of every device
+	if err := s.userRepo.UpdateUserActive(ctx, user.ID.String(), false); err != nil {
+		return err
+	}
+	if err := s.tracker.RevokeAll(ctx, user.ID.String()); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "user deactivated", "user_id", user.ID)
+	return nil
+}
+
+// ExportCampaign writes every entry of campaign as csv, one column per form of the campaign,
+// entries are read in batches so large campaign is not loaded at once
+func (s *OperatorService) ExportCampaign(ctx context.Context, key string, w io.Writer) (int, error) {
+	ctx, span := tracing.Start(ctx, "OperatorService.ExportCampaign")
+	defer span.End()
+
+	campaign, err := s.campaignRepo.FindCampaignByKey(ctx, key, nil)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, apperrors.NotFound("campaign is not found")
+		}
+		return 0, err
+	}
+	campaignID := campaign.ID.String()
+
+	forms, err := s.campaignRepo.FindFormsByCampaign(ctx, campaignID)
+	if err != nil {
+		return 0, err
+	}
+
+	// write header
+	writer := csv.NewWriter(w)
+	header := []string{"id", "status", "submitted_at", "user_name", "user_email", "spam_reason"}
+	for _, v := range forms {
+		header = append(header, v.Title)
+	}
+	if err := writer.Write(header); err != nil {
+		return 0, err
+	}
+
+	total := 0
+	params := &commonschema.QueryParams{Limit: exportBatchSize, Pagination: "cursor"}
+	for {
+		entries, err := s.campaignRepo.FindFormEntries(ctx, campaign.WorkspaceID, campaignID, params, nil)
+		if err != nil {
+			return total, err
+		}
+		if len(entries) > exportBatchSize {
+			entries = entries[:exportBatchSize]
+		}
+
+		// answers of the whole batch are loaded at once
+		entryIDs := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			entryIDs = append(entryIDs, entry.ID)
+		}
+		details, err := s.campaignRepo.FindDetailFormEntries(ctx, entryIDs)
+		if err != nil {
+			return total, err
+		}
+
+		for _, entry := range entries {
+			// multiple answers of one form are joined in one cell
+			answers := map[string][]string{}
+			for _, v := range details[entry.ID] {
+				answers[v.CampaignFormID] = append(answers[v.CampaignFormID], v.Value)
+			}
+
+			row := []string{entry.ID, entry.Status, entry.CreatedAt, entry.UserName, entry.UserEmail, entry.SpamReason}
+			for _, v := range forms {
+				row = append(row, strings.Join(answers[v.ID.String()], "; "))
+			}
+			if err := writer.Write(row); err != nil {
+				return total, err
+			}
+			total++
+		}
+
+		if len(entries) < exportBatchSize {
+			break
+		}
+		last := entries[len(entries)-1]
+		params.CursorKey = &commonschema.CursorKey{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	writer.Flush()
+	return total, writer.Error()
+}
+
+func (s *OperatorService) findUser(ctx context.Context, email string) (*models.Users, error) {
+	user, err := s.userRepo.FindUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("user is not found")
+		}
+		return nil, err
+	}
+	return user, nil
+}
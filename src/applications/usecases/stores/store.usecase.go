@@ -1,10 +1,15 @@
 package storeusecase
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/applications/audit"
 	"kiraform/src/applications/models"
 	storerepo "kiraform/src/applications/repos/stores"
+	"kiraform/src/infras/configs"
+	"kiraform/src/infras/tracing"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
 	masterschema "kiraform/src/interfaces/rest/schemas/masters"
 	storeschema "kiraform/src/interfaces/rest/schemas/stores"
@@ -20,53 +25,59 @@ import (
 )
 
 type StoreUsecase interface {
-	FindStore(c echo.Context, userID string) (*storeschema.StoreResponse, error)
-	UpdateStore(userID string, body storeschema.StorePayload) error
-	FindStoreProductCategories(userID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
-	FindStoreProductCategory(userID string, ID string) (*storeschema.ProductCategoryResponse, error)
-	CreateStoreProductCategory(userID string, body storeschema.ProductCategoryPayload) error
-	UpdateStoreProductCategory(userID string, ID string, body storeschema.ProductCategoryPayload) error
-	DeleteStoreProductCategory(userID string, ID string) error
-	FindStoreProducts(c echo.Context, userID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
-	FindStoreProductsByStoreKey(c echo.Context, key string, params *commonschema.QueryParams, category_id *string) (*commonschema.ResponseList, error)
-	FindStoreProduct(c echo.Context, userID string, ID string) (*storeschema.ProductResponse, error)
-	CreateStoreProduct(userID string, body storeschema.ProductPayload) error
-	UpdateStoreProduct(userID string, ID string, body storeschema.ProductPayload) error
-	DeleteStoreProduct(userID string, ID string) error
-	FindStoreProductFormEntries(userID string, ID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
-	FindStoreByKey(c echo.Context, key string) (*storeschema.StoreResponse, error)
-	FindStoreCategoriesByKey(key string) ([]storeschema.ProductCategoryResponse, error)
+	FindStore(ctx context.Context, c echo.Context, userID string) (*storeschema.StoreResponse, error)
+	UpdateStore(ctx context.Context, actor commonschema.Actor, body storeschema.StorePayload) error
+	FindStoreProductCategories(ctx context.Context, userID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
+	FindStoreProductCategory(ctx context.Context, userID string, ID string) (*storeschema.ProductCategoryResponse, error)
+	CreateStoreProductCategory(ctx context.Context, actor commonschema.Actor, body storeschema.ProductCategoryPayload) error
+	UpdateStoreProductCategory(ctx context.Context, actor commonschema.Actor, ID string, body storeschema.ProductCategoryPayload) error
+	DeleteStoreProductCategory(ctx context.Context, actor commonschema.Actor, ID string) error
+	FindStoreProducts(ctx context.Context, c echo.Context, userID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
+	FindStoreProductsByStoreKey(ctx context.Context, c echo.Context, key string, params *commonschema.QueryParams, category_id *string) (*commonschema.ResponseList, error)
+	FindStoreProduct(ctx context.Context, c echo.Context, userID string, ID string) (*storeschema.ProductResponse, error)
+	CreateStoreProduct(ctx context.Context, actor commonschema.Actor, body storeschema.ProductPayload) error
+	UpdateStoreProduct(ctx context.Context, actor commonschema.Actor, ID string, body storeschema.ProductPayload) error
+	DeleteStoreProduct(ctx context.Context, actor commonschema.Actor, ID string) error
+	FindStoreProductFormEntries(ctx context.Context, userID string, ID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
+	FindStoreByKey(ctx context.Context, c echo.Context, key string) (*storeschema.StoreResponse, error)
+	FindStoreCategoriesByKey(ctx context.Context, key string) ([]storeschema.ProductCategoryResponse, error)
+	FindStoreTrash(ctx context.Context, userID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error)
+	RestoreStoreItem(ctx context.Context, actor commonschema.Actor, entityType string, ID string) error
 }
 
 type StoreService struct {
 	storeRepo storerepo.StoreRepository
+	audit     *audit.Recorder
+	storage   configs.StorageConfig
 }
 
-func NewStoreUsecase(storeRepo storerepo.StoreRepository) *StoreService {
+func NewStoreUsecase(storeRepo storerepo.StoreRepository, recorder *audit.Recorder, storage configs.StorageConfig) *StoreService {
 	return &StoreService{
 		storeRepo: storeRepo,
+		audit:     recorder,
+		storage:   storage,
 	}
 }
 
-func (s *StoreService) findStore(userID string, returnOriginalError bool) (*storeschema.StoreResponse, error) {
-	data, err := s.storeRepo.FindStoreByUser(userID)
+func (s *StoreService) findStore(ctx context.Context, userID string, returnOriginalError bool) (*storeschema.StoreResponse, error) {
+	data, err := s.storeRepo.FindStoreByUser(ctx, userID)
 	if err != nil {
 		if returnOriginalError {
 			return nil, err
 		} else {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, errors.New("store data not found, please create your store first")
+				return nil, apperrors.NotFound("store data not found, please create your store first")
 			}
 			return nil, err
 		}
 	}
 
-	totalCategories, err := s.storeRepo.FindCountStoreProductCategories(data.ID.String(), nil)
+	totalCategories, err := s.storeRepo.FindCountStoreProductCategories(ctx, data.ID.String(), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	totalProducts, err := s.storeRepo.FindCountStoreProducts(data.ID.String(), nil, nil)
+	totalProducts, err := s.storeRepo.FindCountStoreProducts(ctx, data.ID.String(), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -89,8 +100,11 @@ func (s *StoreService) findStore(userID string, returnOriginalError bool) (*stor
 	}, nil
 }
 
-func (s *StoreService) FindStore(c echo.Context, userID string) (*storeschema.StoreResponse, error) {
-	data, err := s.findStore(userID, false)
+func (s *StoreService) FindStore(ctx context.Context, c echo.Context, userID string) (*storeschema.StoreResponse, error) {
+	ctx, span := tracing.Start(ctx, "StoreService.FindStore")
+	defer span.End()
+
+	data, err := s.findStore(ctx, userID, false)
 	if err != nil {
 		return nil, err
 	}
@@ -100,8 +114,11 @@ func (s *StoreService) FindStore(c echo.Context, userID string) (*storeschema.St
 	return data, nil
 }
 
-func (s *StoreService) FindStoreByKey(c echo.Context, key string) (*storeschema.StoreResponse, error) {
-	data, err := s.storeRepo.FindStoreByKey(key)
+func (s *StoreService) FindStoreByKey(ctx context.Context, c echo.Context, key string) (*storeschema.StoreResponse, error) {
+	ctx, span := tracing.Start(ctx, "StoreService.FindStoreByKey")
+	defer span.End()
+
+	data, err := s.storeRepo.FindStoreByKey(ctx, key)
 	if err != nil {
 		return nil, err
 	}
@@ -128,12 +145,15 @@ func (s *StoreService) FindStoreByKey(c echo.Context, key string) (*storeschema.
 	return &store, nil
 }
 
-func (s *StoreService) UpdateStore(userID string, body storeschema.StorePayload) error {
+func (s *StoreService) UpdateStore(ctx context.Context, actor commonschema.Actor, body storeschema.StorePayload) error {
+	ctx, span := tracing.Start(ctx, "StoreService.UpdateStore")
+	defer span.End()
+
 	// check existing store by id
 	// if exists then update it
 	// otherwise insert new one with store_users
 
-	exists, err := s.findStore(userID, true)
+	exists, err := s.findStore(ctx, actor.UserID, true)
 	isExists := true
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -157,7 +177,7 @@ func (s *StoreService) UpdateStore(userID string, body storeschema.StorePayload)
 
 	// uploading image for store thumbnail
 	if body.Thumbnail != nil {
-		thumbnail, err := utils.UploadImage(*body.Thumbnail, "stores", store.Slug)
+		thumbnail, err := utils.UploadImage(s.storage.Dir, s.storage.MaxImageSize, *body.Thumbnail, "stores", store.Slug)
 		if err != nil {
 			return err
 		}
@@ -174,12 +194,28 @@ func (s *StoreService) UpdateStore(userID string, body storeschema.StorePayload)
 	if isExists {
 		now := time.Now()
 
+		// keep existing data for audit log
+		before, err := s.storeRepo.FindStoreByUser(ctx, actor.UserID)
+		if err != nil {
+			return err
+		}
+
 		// updating store data
 		store.UpdatedAt = &now
-		if err := s.storeRepo.UpdateStore(exists.ID, store); err != nil {
+		if err := s.storeRepo.UpdateStore(ctx, exists.ID, store); err != nil {
 			return err
 		}
 
+		after, _ := s.storeRepo.FindStoreByUser(ctx, actor.UserID)
+		s.audit.Record(ctx, actor, audit.Entry{
+			StoreID:    exists.ID,
+			Action:     audit.ActionUpdate,
+			EntityType: "store",
+			EntityID:   exists.ID,
+			Before:     before,
+			After:      after,
+		})
+
 		// updating store user data
 	} else {
 		store.ID = uuid.New()
@@ -191,7 +227,7 @@ func (s *StoreService) UpdateStore(userID string, body storeschema.StorePayload)
 		store.Status = "S2" // force to Active for this version
 
 		// preparing store user data
-		uuidUserID, err := uuid.Parse(userID)
+		uuidUserID, err := uuid.Parse(actor.UserID)
 		if err != nil {
 			return err
 		}
@@ -204,14 +240,22 @@ func (s *StoreService) UpdateStore(userID string, body storeschema.StorePayload)
 		}
 
 		// inserting store data
-		if err := s.storeRepo.CreateStore(store); err != nil {
+		if err := s.storeRepo.CreateStore(ctx, store); err != nil {
 			return err
 		}
 
 		// inserting store user data
-		if err := s.storeRepo.CreateStoreUser(storeUser); err != nil {
+		if err := s.storeRepo.CreateStoreUser(ctx, storeUser); err != nil {
 			return err
 		}
+
+		s.audit.Record(ctx, actor, audit.Entry{
+			StoreID:    store.ID.String(),
+			Action:     audit.ActionCreate,
+			EntityType: "store",
+			EntityID:   store.ID.String(),
+			After:      store,
+		})
 	}
 
 	// set as success response
@@ -219,21 +263,34 @@ func (s *StoreService) UpdateStore(userID string, body storeschema.StorePayload)
 	return nil
 }
 
-func (s *StoreService) FindStoreProductCategories(userID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+func (s *StoreService) FindStoreProductCategories(ctx context.Context, userID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "StoreService.FindStoreProductCategories")
+	defer span.End()
+
 	// check valid store
-	store, err := s.findStore(userID, false)
+	store, err := s.findStore(ctx, userID, false)
 	if err != nil {
 		return nil, err
 	}
 
 	// perform to get product categories
-	list, err := s.storeRepo.FindStoreProductCategories(store.ID, params)
+	list, err := s.storeRepo.FindStoreProductCategories(ctx, store.ID, params)
 	if err != nil {
 		return nil, err
 	}
 
 	// get count data
-	count, err := s.storeRepo.FindCountStoreProductCategories(store.ID, params)
+	count, err := s.storeRepo.FindCountStoreProductCategories(ctx, store.ID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// get total products of every category in the page at once
+	categoryIDs := make([]string, 0, len(list))
+	for _, v := range list {
+		categoryIDs = append(categoryIDs, v.ID.String())
+	}
+	totalProducts, err := s.storeRepo.FindCountStoreProductsByCategories(ctx, store.ID, categoryIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -241,17 +298,12 @@ func (s *StoreService) FindStoreProductCategories(userID string, params *commons
 	// convert to response schema
 	var data []storeschema.ProductCategoryResponse
 	for _, v := range list {
-		totalProducts, err := s.storeRepo.FindCountStoreProductsByCategory(store.ID, v.ID.String())
-		if err != nil {
-			return nil, err
-		}
-
 		data = append(data, storeschema.ProductCategoryResponse{
 			ID:            v.ID.String(),
 			Name:          v.Name,
 			Description:   v.Description,
 			CreatedAt:     v.CreatedAt,
-			TotalProducts: totalProducts,
+			TotalProducts: totalProducts[v.ID.String()],
 		})
 	}
 
@@ -271,15 +323,18 @@ func (s *StoreService) FindStoreProductCategories(userID string, params *commons
 	return &response, nil
 }
 
-func (s *StoreService) FindStoreProductCategory(userID string, ID string) (*storeschema.ProductCategoryResponse, error) {
+func (s *StoreService) FindStoreProductCategory(ctx context.Context, userID string, ID string) (*storeschema.ProductCategoryResponse, error) {
+	ctx, span := tracing.Start(ctx, "StoreService.FindStoreProductCategory")
+	defer span.End()
+
 	// check valid store
-	store, err := s.findStore(userID, false)
+	store, err := s.findStore(ctx, userID, false)
 	if err != nil {
 		return nil, err
 	}
 
 	// perform to get detail data
-	data, err := s.storeRepo.FindStoreProductCategory(store.ID, ID)
+	data, err := s.storeRepo.FindStoreProductCategory(ctx, store.ID, ID)
 	if err != nil {
 		return nil, err
 	}
@@ -293,9 +348,12 @@ func (s *StoreService) FindStoreProductCategory(userID string, ID string) (*stor
 	}, nil
 }
 
-func (s *StoreService) CreateStoreProductCategory(userID string, body storeschema.ProductCategoryPayload) error {
+func (s *StoreService) CreateStoreProductCategory(ctx context.Context, actor commonschema.Actor, body storeschema.ProductCategoryPayload) error {
+	ctx, span := tracing.Start(ctx, "StoreService.CreateStoreProductCategory")
+	defer span.End()
+
 	// check valid store
-	store, err := s.findStore(userID, false)
+	store, err := s.findStore(ctx, actor.UserID, false)
 	if err != nil {
 		return err
 	}
@@ -314,19 +372,35 @@ func (s *StoreService) CreateStoreProductCategory(userID string, body storeschem
 		Description: body.Description,
 		CreatedAt:   time.Now(),
 	}
-	err = s.storeRepo.CreateStoreProductCategory(data)
+	err = s.storeRepo.CreateStoreProductCategory(ctx, data)
 	if err != nil {
 		return err
 	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		StoreID:    store.ID,
+		Action:     audit.ActionCreate,
+		EntityType: "product_category",
+		EntityID:   data.ID.String(),
+		After:      data,
+	})
 
 	// return success response
 	// by flag as no-error
 	return nil
 }
 
-func (s *StoreService) UpdateStoreProductCategory(userID string, ID string, body storeschema.ProductCategoryPayload) error {
+func (s *StoreService) UpdateStoreProductCategory(ctx context.Context, actor commonschema.Actor, ID string, body storeschema.ProductCategoryPayload) error {
+	ctx, span := tracing.Start(ctx, "StoreService.UpdateStoreProductCategory")
+	defer span.End()
+
 	// check valid store
-	_, err := s.findStore(userID, false)
+	store, err := s.findStore(ctx, actor.UserID, false)
+	if err != nil {
+		return err
+	}
+
+	// check existing category
+	before, err := s.storeRepo.FindStoreProductCategory(ctx, store.ID, ID)
 	if err != nil {
 		return err
 	}
@@ -338,18 +412,37 @@ func (s *StoreService) UpdateStoreProductCategory(userID string, ID string, body
 		Description: body.Description,
 		UpdatedAt:   &now,
 	}
-	err = s.storeRepo.UpdateStoreProductCategory(ID, data)
+	err = s.storeRepo.UpdateStoreProductCategory(ctx, ID, data)
 	if err != nil {
 		return err
 	}
 
+	after, _ := s.storeRepo.FindStoreProductCategory(ctx, store.ID, ID)
+	s.audit.Record(ctx, actor, audit.Entry{
+		StoreID:    store.ID,
+		Action:     audit.ActionUpdate,
+		EntityType: "product_category",
+		EntityID:   ID,
+		Before:     before,
+		After:      after,
+	})
+
 	// return success response
 	// by flag as no-error
 	return nil
 }
 
-func (s *StoreService) DeleteStoreProductCategory(userID string, ID string) error {
-	_, err := s.findStore(userID, false)
+func (s *StoreService) DeleteStoreProductCategory(ctx context.Context, actor commonschema.Actor, ID string) error {
+	ctx, span := tracing.Start(ctx, "StoreService.DeleteStoreProductCategory")
+	defer span.End()
+
+	store, err := s.findStore(ctx, actor.UserID, false)
+	if err != nil {
+		return err
+	}
+
+	// check existing category
+	before, err := s.storeRepo.FindStoreProductCategory(ctx, store.ID, ID)
 	if err != nil {
 		return err
 	}
@@ -358,27 +451,52 @@ func (s *StoreService) DeleteStoreProductCategory(userID string, ID string) erro
 	now := time.Now()
 	data := models.StoreProductCategories{
 		Deleted:   true,
+		DeletedAt: &now,
+		DeletedBy: utils.NullableUUID(actor.UserID),
 		UpdatedAt: &now,
 	}
-	err = s.storeRepo.UpdateStoreProductCategory(ID, data)
+	err = s.storeRepo.UpdateStoreProductCategory(ctx, ID, data)
 	if err != nil {
 		return err
 	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		StoreID:    store.ID,
+		Action:     audit.ActionDelete,
+		EntityType: "product_category",
+		EntityID:   ID,
+		Before:     before,
+	})
 
 	// return success response
 	// by flag as no-error
 	return nil
 }
 
-func (s *StoreService) findStoreProducts(c echo.Context, storeID string, params *commonschema.QueryParams, category_id *string) (*commonschema.ResponseList, error) {
+func (s *StoreService) findStoreProducts(ctx context.Context, c echo.Context, storeID string, params *commonschema.QueryParams, category_id *string) (*commonschema.ResponseList, error) {
 	// perform to get product
-	list, err := s.storeRepo.FindStoreProducts(storeID, params, category_id)
+	list, err := s.storeRepo.FindStoreProducts(ctx, storeID, params, category_id)
 	if err != nil {
 		return nil, err
 	}
+	list, nextCursor, prevCursor := utils.CursorPage(list, params, func(v models.StoreProducts) (string, string) {
+		return utils.CursorTime(v.CreatedAt), v.ID.String()
+	})
 
-	// get count data
-	count, err := s.storeRepo.FindCountStoreProducts(storeID, params, category_id)
+	// get count data, client can skip it on large list
+	var count int64
+	if !params.SkipCount {
+		count, err = s.storeRepo.FindCountStoreProducts(ctx, storeID, params, category_id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// get images of every product in the page at once
+	productIDs := make([]string, 0, len(list))
+	for _, v := range list {
+		productIDs = append(productIDs, v.ID.String())
+	}
+	productImagesByID, err := s.storeRepo.FindImagesByProducts(ctx, productIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -393,10 +511,8 @@ func (s *StoreService) findStoreProducts(c echo.Context, storeID string, params
 
 		// generate url for latest image of product
 		thumbnail := ""
-		images, err := s.storeRepo.FindImagesByProduct(v.ID.String())
-		if err != nil {
-			return nil, err
-		} else if len(images) > 0 {
+		images := productImagesByID[v.ID.String()]
+		if len(images) > 0 {
 			thumbnail = images[0].FileName
 		}
 
@@ -452,65 +568,79 @@ func (s *StoreService) findStoreProducts(c echo.Context, storeID string, params
 	}
 
 	// prepare response list
-	totalPage := 1
-	if count > 0 && params.Limit > 0 {
-		totalPage = int(math.Ceil(float64(count) / float64(params.Limit)))
+	totalPage := 0
+	if !params.SkipCount {
+		totalPage = 1
+		if count > 0 && params.Limit > 0 {
+			totalPage = int(math.Ceil(float64(count) / float64(params.Limit)))
+		}
 	}
 
 	response := commonschema.ResponseList{
 		Parameters: *params,
 		TotalPage:  totalPage,
 		Rows:       data,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
 	}
 
 	// return success response
 	return &response, nil
 }
 
-func (s *StoreService) FindStoreProducts(c echo.Context, userID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+func (s *StoreService) FindStoreProducts(ctx context.Context, c echo.Context, userID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "StoreService.FindStoreProducts")
+	defer span.End()
+
 	// check valid store
-	store, err := s.findStore(userID, false)
+	store, err := s.findStore(ctx, userID, false)
 	if err != nil {
 		return nil, err
 	}
 
-	list, err := s.findStoreProducts(c, store.ID, params, nil)
+	list, err := s.findStoreProducts(ctx, c, store.ID, params, nil)
 	if err != nil {
 		return nil, err
 	}
 	return list, nil
 }
 
-func (s *StoreService) FindStoreProductsByStoreKey(c echo.Context, key string, params *commonschema.QueryParams, category_id *string) (*commonschema.ResponseList, error) {
+func (s *StoreService) FindStoreProductsByStoreKey(ctx context.Context, c echo.Context, key string, params *commonschema.QueryParams, category_id *string) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "StoreService.FindStoreProductsByStoreKey")
+	defer span.End()
+
 	// check valid store
-	store, err := s.storeRepo.FindStoreByKey(key)
+	store, err := s.storeRepo.FindStoreByKey(ctx, key)
 	if err != nil {
 		return nil, err
 	}
 
-	list, err := s.findStoreProducts(c, store.ID.String(), params, category_id)
+	list, err := s.findStoreProducts(ctx, c, store.ID.String(), params, category_id)
 	if err != nil {
 		return nil, err
 	}
 	return list, nil
 }
 
-func (s *StoreService) FindStoreProduct(c echo.Context, userID string, ID string) (*storeschema.ProductResponse, error) {
+func (s *StoreService) FindStoreProduct(ctx context.Context, c echo.Context, userID string, ID string) (*storeschema.ProductResponse, error) {
+	ctx, span := tracing.Start(ctx, "StoreService.FindStoreProduct")
+	defer span.End()
+
 	// check valid store
-	store, err := s.findStore(userID, false)
+	store, err := s.findStore(ctx, userID, false)
 	if err != nil {
 		return nil, err
 	}
 
 	// check existing product
-	data, err := s.storeRepo.FindStoreProduct(store.ID, ID)
+	data, err := s.storeRepo.FindStoreProduct(ctx, store.ID, ID)
 	if err != nil {
 		return nil, err
 	}
 
 	// generate url for thumbnail and entire images
 	thumbnail := ""
-	images, err := s.storeRepo.FindImagesByProduct(data.ID.String())
+	images, err := s.storeRepo.FindImagesByProduct(ctx, data.ID.String())
 	if err != nil {
 		return nil, err
 	} else if len(images) > 0 {
@@ -576,9 +706,12 @@ func (s *StoreService) FindStoreProduct(c echo.Context, userID string, ID string
 	return &product, nil
 }
 
-func (s *StoreService) CreateStoreProduct(userID string, body storeschema.ProductPayload) error {
+func (s *StoreService) CreateStoreProduct(ctx context.Context, actor commonschema.Actor, body storeschema.ProductPayload) error {
+	ctx, span := tracing.Start(ctx, "StoreService.CreateStoreProduct")
+	defer span.End()
+
 	// check valid store
-	store, err := s.findStore(userID, false)
+	store, err := s.findStore(ctx, actor.UserID, false)
 	if err != nil {
 		return err
 	}
@@ -589,7 +722,7 @@ func (s *StoreService) CreateStoreProduct(userID string, body storeschema.Produc
 		return err
 	}
 
-	uuidCategoryID, err := uuid.Parse(body.CategoryID)
+	uuidCategoryID, err := utils.ParseUUID(body.CategoryID, "category_id")
 	if err != nil {
 		return err
 	}
@@ -617,7 +750,7 @@ func (s *StoreService) CreateStoreProduct(userID string, body storeschema.Produc
 
 	campaignID := body.CampaignID
 	if campaignID != nil {
-		uuidCampaignID, err := uuid.Parse(*campaignID)
+		uuidCampaignID, err := utils.ParseUUID(*campaignID, "campaign_id")
 		if err != nil {
 			return err
 		}
@@ -629,9 +762,9 @@ func (s *StoreService) CreateStoreProduct(userID string, body storeschema.Produc
 	if body.Images != nil {
 		for i, v := range body.Images {
 			imageID := uuid.New()
-			fileName, err := utils.UploadImage(v.FileName, "products", fmt.Sprintf("%s-%s", data.Key, strings.ReplaceAll(imageID.String(), "-", "")))
+			fileName, err := utils.UploadImage(s.storage.Dir, s.storage.MaxImageSize, v.FileName, "products", fmt.Sprintf("%s-%s", data.Key, strings.ReplaceAll(imageID.String(), "-", "")))
 			if err != nil {
-				return fmt.Errorf("failure to upload image number %d with detail error: %s", i, err.Error())
+				return apperrors.Field(fmt.Sprintf("images[%d]", i), fmt.Sprintf("failed to upload: %s", err.Error()))
 			}
 			dataImages = append(dataImages, models.StoreProductImages{
 				ID:             imageID,
@@ -643,36 +776,46 @@ func (s *StoreService) CreateStoreProduct(userID string, body storeschema.Produc
 	}
 
 	// perform to insert data
-	err = s.storeRepo.CreateProduct(data)
+	err = s.storeRepo.CreateProduct(ctx, data)
 	if err != nil {
 		return err
 	}
 
-	err = s.storeRepo.CreateProductImages(dataImages)
+	err = s.storeRepo.CreateProductImages(ctx, dataImages)
 	if err != nil {
 		return err
 	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		StoreID:    store.ID,
+		Action:     audit.ActionCreate,
+		EntityType: "product",
+		EntityID:   data.ID.String(),
+		After:      data,
+	})
 
 	// return success response
 	// by set as no-error
 	return nil
 }
 
-func (s *StoreService) UpdateStoreProduct(userID string, ID string, body storeschema.ProductPayload) error {
+func (s *StoreService) UpdateStoreProduct(ctx context.Context, actor commonschema.Actor, ID string, body storeschema.ProductPayload) error {
+	ctx, span := tracing.Start(ctx, "StoreService.UpdateStoreProduct")
+	defer span.End()
+
 	// check valid store
-	store, err := s.findStore(userID, false)
+	store, err := s.findStore(ctx, actor.UserID, false)
 	if err != nil {
 		return err
 	}
 
 	// check existing product
-	product, err := s.storeRepo.FindStoreProduct(store.ID, ID)
+	product, err := s.storeRepo.FindStoreProduct(ctx, store.ID, ID)
 	if err != nil {
 		return err
 	}
 
 	// converting uuid-string data to uuid-type
-	uuidCategoryID, err := uuid.Parse(body.CategoryID)
+	uuidCategoryID, err := utils.ParseUUID(body.CategoryID, "category_id")
 	if err != nil {
 		return err
 	}
@@ -691,7 +834,7 @@ func (s *StoreService) UpdateStoreProduct(userID string, ID string, body storesc
 
 	campaignID := body.CampaignID
 	if campaignID != nil {
-		uuidCampaignID, err := uuid.Parse(*campaignID)
+		uuidCampaignID, err := utils.ParseUUID(*campaignID, "campaign_id")
 		if err != nil {
 			return err
 		}
@@ -699,13 +842,13 @@ func (s *StoreService) UpdateStoreProduct(userID string, ID string, body storesc
 	}
 
 	// perform to update data
-	err = s.storeRepo.UpdateStoreProduct(data, store.ID, ID)
+	err = s.storeRepo.UpdateStoreProduct(ctx, data, store.ID, ID)
 	if err != nil {
 		return err
 	}
 
 	// perform to delete images not exist from database
-	images, err := s.storeRepo.FindImagesByProduct(ID)
+	images, err := s.storeRepo.FindImagesByProduct(ctx, ID)
 	if err != nil {
 		return err
 	}
@@ -721,7 +864,7 @@ func (s *StoreService) UpdateStoreProduct(userID string, ID string, body storesc
 		if !isExists {
 			// delete data from database
 			// and remove image from directory
-			err = s.storeRepo.DeleteProductImage(v.ID.String())
+			err = s.storeRepo.DeleteProductImage(ctx, v.ID.String())
 			if err != nil {
 				return err
 			}
@@ -736,9 +879,9 @@ func (s *StoreService) UpdateStoreProduct(userID string, ID string, body storesc
 		for i, v := range body.Images {
 			if v.ID == nil {
 				imageID := uuid.New()
-				fileName, err := utils.UploadImage(v.FileName, "products", fmt.Sprintf("%s-%s", product.Key, strings.ReplaceAll(imageID.String(), "-", "")))
+				fileName, err := utils.UploadImage(s.storage.Dir, s.storage.MaxImageSize, v.FileName, "products", fmt.Sprintf("%s-%s", product.Key, strings.ReplaceAll(imageID.String(), "-", "")))
 				if err != nil {
-					return fmt.Errorf("failure to upload image number %d with detail error: %s", i, err.Error())
+					return apperrors.Field(fmt.Sprintf("images[%d]", i), fmt.Sprintf("failed to upload: %s", err.Error()))
 				}
 				dataImages = append(dataImages, models.StoreProductImages{
 					ID:             imageID,
@@ -750,54 +893,85 @@ func (s *StoreService) UpdateStoreProduct(userID string, ID string, body storesc
 		}
 	}
 
-	err = s.storeRepo.CreateProductImages(dataImages)
+	err = s.storeRepo.CreateProductImages(ctx, dataImages)
 	if err != nil {
 		return err
 	}
 
+	after, _ := s.storeRepo.FindStoreProduct(ctx, store.ID, ID)
+	s.audit.Record(ctx, actor, audit.Entry{
+		StoreID:    store.ID,
+		Action:     audit.ActionUpdate,
+		EntityType: "product",
+		EntityID:   ID,
+		Before:     product,
+		After:      after,
+	})
+
 	// return success response
 	// by set as no-error
 	return nil
 }
 
-func (s *StoreService) DeleteStoreProduct(userID string, ID string) error {
+func (s *StoreService) DeleteStoreProduct(ctx context.Context, actor commonschema.Actor, ID string) error {
+	ctx, span := tracing.Start(ctx, "StoreService.DeleteStoreProduct")
+	defer span.End()
+
 	// check valid store
-	store, err := s.findStore(userID, false)
+	store, err := s.findStore(ctx, actor.UserID, false)
+	if err != nil {
+		return err
+	}
+
+	// check existing product
+	before, err := s.storeRepo.FindStoreProduct(ctx, store.ID, ID)
 	if err != nil {
 		return err
 	}
 
-	// perform to set data as deleted
+	// perform to set data as deleted, images are moved to trash together with product
 	now := time.Now()
 	data := models.StoreProducts{
 		Deleted:   true,
+		DeletedAt: &now,
+		DeletedBy: utils.NullableUUID(actor.UserID),
 		UpdatedAt: &now,
 	}
-	err = s.storeRepo.UpdateStoreProduct(data, store.ID, ID)
+	err = s.storeRepo.DeleteStoreProduct(ctx, data, store.ID, ID)
 	if err != nil {
 		return err
 	}
+	s.audit.Record(ctx, actor, audit.Entry{
+		StoreID:    store.ID,
+		Action:     audit.ActionDelete,
+		EntityType: "product",
+		EntityID:   ID,
+		Before:     before,
+	})
 
 	// return success response
 	// by set as no-error
 	return nil
 }
 
-func (s *StoreService) FindStoreProductFormEntries(userID string, ID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+func (s *StoreService) FindStoreProductFormEntries(ctx context.Context, userID string, ID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "StoreService.FindStoreProductFormEntries")
+	defer span.End()
+
 	// check valid store
-	_, err := s.findStore(userID, false)
+	_, err := s.findStore(ctx, userID, false)
 	if err != nil {
 		return nil, err
 	}
 
 	// perform to get form entries of prroduct
-	list, err := s.storeRepo.FindStoreProductFormEntries(ID, params)
+	list, err := s.storeRepo.FindStoreProductFormEntries(ctx, ID, params)
 	if err != nil {
 		return nil, err
 	}
 
 	// get count data
-	count, err := s.storeRepo.FindCountStoreProductFormEntries(ID, params)
+	count, err := s.storeRepo.FindCountStoreProductFormEntries(ctx, ID, params)
 	if err != nil {
 		return nil, err
 	}
@@ -818,13 +992,16 @@ func (s *StoreService) FindStoreProductFormEntries(userID string, ID string, par
 	return &response, nil
 }
 
-func (s *StoreService) FindStoreCategoriesByKey(key string) ([]storeschema.ProductCategoryResponse, error) {
-	store, err := s.storeRepo.FindStoreByKey(key)
+func (s *StoreService) FindStoreCategoriesByKey(ctx context.Context, key string) ([]storeschema.ProductCategoryResponse, error) {
+	ctx, span := tracing.Start(ctx, "StoreService.FindStoreCategoriesByKey")
+	defer span.End()
+
+	store, err := s.storeRepo.FindStoreByKey(ctx, key)
 	if err != nil {
 		return nil, err
 	}
 
-	list, err := s.storeRepo.FindStoreProductCategories(store.ID.String(), nil)
+	list, err := s.storeRepo.FindStoreProductCategories(ctx, store.ID.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -842,3 +1019,118 @@ func (s *StoreService) FindStoreCategoriesByKey(key string) ([]storeschema.Produ
 
 	return data, nil
 }
+
+func (s *StoreService) FindStoreTrash(ctx context.Context, userID string, params *commonschema.QueryParams) (*commonschema.ResponseList, error) {
+	ctx, span := tracing.Start(ctx, "StoreService.FindStoreTrash")
+	defer span.End()
+
+	// check valid store
+	store, err := s.findStore(ctx, userID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// perform to get deleted data
+	list, err := s.storeRepo.FindTrash(ctx, store.ID, params)
+	if err != nil {
+		return nil, err
+	}
+	list, nextCursor, prevCursor := utils.CursorPage(list, params, func(v commonschema.TrashSchema) (string, string) {
+		return utils.CursorTime(v.DeletedAt), v.ID
+	})
+
+	// get count data, client can skip it on large list
+	totalPage := 0
+	if !params.SkipCount {
+		count, err := s.storeRepo.FindCountTrash(ctx, store.ID, params)
+		if err != nil {
+			return nil, err
+		}
+		totalPage = 1
+		if count > 0 && params.Limit > 0 {
+			totalPage = int(math.Ceil(float64(count) / float64(params.Limit)))
+		}
+	}
+
+	// return success response
+	return &commonschema.ResponseList{
+		Parameters: *params,
+		TotalPage:  totalPage,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		Rows:       list,
+	}, nil
+}
+
+func (s *StoreService) RestoreStoreItem(ctx context.Context, actor commonschema.Actor, entityType string, ID string) error {
+	ctx, span := tracing.Start(ctx, "StoreService.RestoreStoreItem")
+	defer span.End()
+
+	// check valid store
+	store, err := s.findStore(ctx, actor.UserID, false)
+	if err != nil {
+		return err
+	}
+
+	switch entityType {
+	case "product_category":
+		// check deleted data
+		before, err := s.storeRepo.FindDeletedStoreProductCategory(ctx, store.ID, ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("product category not found in trash")
+			}
+			return err
+		}
+
+		// perform to restore data
+		if err := s.storeRepo.RestoreStoreProductCategory(ctx, ID); err != nil {
+			return err
+		}
+
+		after, _ := s.storeRepo.FindStoreProductCategory(ctx, store.ID, ID)
+		s.audit.Record(ctx, actor, audit.Entry{
+			StoreID:    store.ID,
+			Action:     audit.ActionRestore,
+			EntityType: entityType,
+			EntityID:   ID,
+			Before:     before,
+			After:      after,
+		})
+		return nil
+	case "product":
+		// check deleted data
+		before, err := s.storeRepo.FindDeletedStoreProduct(ctx, store.ID, ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("product not found in trash")
+			}
+			return err
+		}
+
+		// product needs active category
+		if _, err := s.storeRepo.FindStoreProductCategory(ctx, store.ID, before.CategoryID.String()); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.Conflict("category of this product is deleted, restore the category first")
+			}
+			return err
+		}
+
+		// perform to restore product with its images
+		if err := s.storeRepo.RestoreStoreProduct(ctx, ID, before.DeletedAt); err != nil {
+			return err
+		}
+
+		after, _ := s.storeRepo.FindStoreProduct(ctx, store.ID, ID)
+		s.audit.Record(ctx, actor, audit.Entry{
+			StoreID:    store.ID,
+			Action:     audit.ActionRestore,
+			EntityType: entityType,
+			EntityID:   ID,
+			Before:     before,
+			After:      after,
+		})
+		return nil
+	}
+	return apperrors.Field("entity_type", "must be one of product_category, product")
+}
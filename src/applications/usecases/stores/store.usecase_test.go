@@ -0,0 +1,70 @@
+package storeusecase
+
+import (
+	"context"
+	"database/sql/driver"
+	storerepo "kiraform/src/applications/repos/stores"
+	"kiraform/src/infras/configs"
+	"kiraform/src/infras/dbtest"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	storeschema "kiraform/src/interfaces/rest/schemas/stores"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func TestFindStoreProductsQueryCount(t *testing.T) {
+	storeID := uuid.NewString()
+	categoryID := uuid.NewString()
+	campaignID := uuid.NewString()
+	dbtest.ConstantQueries(t, []int{1, 25}, func(t *testing.T, total int) []string {
+		products := &dbtest.Rows{Columns: []string{"id", "store_id", "category_id", "campaign_id", "name", "created_at"}}
+		images := &dbtest.Rows{Columns: []string{"id", "store_product_id", "file_name", "created_at"}}
+		for range total {
+			id := uuid.NewString()
+			products.Values = append(products.Values, []driver.Value{id, storeID, categoryID, campaignID, "Product", time.Now()})
+			for range 2 {
+				images.Values = append(images.Values, []driver.Value{uuid.NewString(), id, "image.png", time.Now()})
+			}
+		}
+
+		DB, recorder := dbtest.Open(t, func(query string) *dbtest.Rows {
+			switch {
+			case strings.Contains(query, "count(*)"):
+				return &dbtest.Rows{Columns: []string{"count"}, Values: [][]driver.Value{{int64(total)}}}
+			case strings.Contains(query, `FROM "store_products"`):
+				return products
+			case strings.Contains(query, `FROM "store_product_images"`):
+				return images
+			case strings.Contains(query, `FROM "stores"`):
+				return &dbtest.Rows{Columns: []string{"id", "name"}, Values: [][]driver.Value{{storeID, "Store"}}}
+			case strings.Contains(query, `FROM "store_product_categories"`):
+				return &dbtest.Rows{Columns: []string{"id", "name"}, Values: [][]driver.Value{{categoryID, "Category"}}}
+			case strings.Contains(query, `FROM "campaigns"`):
+				return &dbtest.Rows{Columns: []string{"id", "title"}, Values: [][]driver.Value{{campaignID, "Campaign"}}}
+			}
+			return nil
+		})
+
+		uc := NewStoreUsecase(storerepo.NewStoreRepository(DB), nil, configs.StorageConfig{})
+		c := echo.New().NewContext(httptest.NewRequest("GET", "/", nil), httptest.NewRecorder())
+		response, err := uc.findStoreProducts(context.Background(), c, storeID, &commonschema.QueryParams{Page: 1, Limit: 50}, nil)
+		if err != nil {
+			t.Fatalf("find store products: %v", err)
+		}
+		list := response.Rows.([]storeschema.ProductResponse)
+		if len(list) != total {
+			t.Fatalf("got %d products, want %d", len(list), total)
+		}
+		for _, v := range list {
+			if len(v.Images) != 2 || v.Category.Name != "Category" || v.Campaign == nil || v.Campaign.Title != "Campaign" {
+				t.Fatalf("got product with %d images, category %q and campaign %+v, want 2 images with category and campaign", len(v.Images), v.Category.Name, v.Campaign)
+			}
+		}
+		return recorder.Queries()
+	})
+}
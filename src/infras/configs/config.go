@@ -1,77 +1,486 @@
 package configs
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// minSecretKeyLength keeps signing key long enough for HS256
+const minSecretKeyLength = 32
+
+// maxLegacyWindow is the farthest cut-off of legacy tokens, so accepting them can not be left on
+const maxLegacyWindow = 7 * 24 * time.Hour
+
+// Config is loaded once on startup and injected into the parts needing it
 type Config struct {
-	APP_NAME  string
-	APP_PORT  string
-	APP_ENV   string
-	MIGRATION bool
-	SEEDER    bool
+	App     AppConfig
+	HTTP    HTTPConfig
+	DB      DBConfig
+	Auth    AuthConfig
+	Storage StorageConfig
+	Mail    MailConfig
+	Redis   RedisConfig
+	Captcha CaptchaConfig
+	Tracing TracingConfig
+	OIDC    OIDCConfig
+}
+
+type AppConfig struct {
+	Name      string
+	Env       string // dev or pro
+	Migration bool
+	Seeder    bool
+}
+
+// IsDev tells running on development environment
+func (c AppConfig) IsDev() bool {
+	return c.Env == "dev"
+}
+
+type HTTPConfig struct {
+	Port            string
+	ShutdownTimeout time.Duration
+	DrainDelay      time.Duration // readiness fails this long before server stops accepting requests
+	MetricsToken    string
+	TrustedProxies  []*net.IPNet // client ip is read from X-Forwarded-For only behind these proxies
+}
+
+type DBConfig struct {
+	Host string
+	Port string
+	User string
+	Pass string
+	Name string
+
+	MaxOpenConns     int
+	MaxIdleConns     int
+	ConnMaxLifetime  time.Duration
+	ConnMaxIdleTime  time.Duration
+	ConnectTimeout   time.Duration
+	StatementTimeout time.Duration
+}
+
+type AuthConfig struct {
+	SecretKey    string
+	TokenTTL     time.Duration
+	Issuer       string    // shown as account issuer in authenticator app
+	SigningAlg   string    // RS256 or EdDSA for new signing keys
+	LegacyUntil  time.Time // accept tokens signed by secret key before sessions and key rotation until this time, zero refuses them
+	MagicLinkURL string    // frontend page of passwordless link, empty sends code only
+	ResetURL     string    // frontend page of password reset link, empty sends token only
+}
+
+type StorageConfig struct {
+	Dir                string // relative to working directory, also served as url path
+	MaxImageSize       int
+	TrashRetentionDays int
+}
+
+type MailConfig struct {
+	Host string
+	Port int
+	User string
+	Pass string
+	From string
+}
+
+// Enabled tells mail server is configured
+func (c MailConfig) Enabled() bool {
+	return c.Host != ""
+}
+
+type RedisConfig struct {
+	Addr string
+	Pass string
+}
+
+type CaptchaConfig struct {
+	VerifyURL string
+	Secret    string
+}
+
+type TracingConfig struct {
+	Exporter    string
+	SampleRatio float64
+}
+
+type OIDCConfig struct {
+	Providers   []OIDCProvider
+	BaseURL     string // public url of this api, callback is <BaseURL>/api/oidc/<name>/callback
+	FrontendURL string // browser is sent here with token in url fragment after login
+}
+
+// Enabled tells any provider is configured
+func (c OIDCConfig) Enabled() bool {
+	return len(c.Providers) > 0
+}
 
-	DB_HOST string
-	DB_USER string
-	DB_PASS string
-	DB_NAME string
-	DB_PORT string
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	TrustEmail   bool // provider does not send email_verified but its emails are verified, e.g. enterprise directory
+}
 
-	SECRET_KEY string
+// oidcIssuers are presets of well known providers, microsoft needs its tenant
+var oidcIssuers = map[string]string{
+	"google":    "https://accounts.google.com",
+	"microsoft": "https://login.microsoftonline.com/%s/v2.0",
 }
 
-func Environment() Config {
+// Load reads config from environment and .env.<ENV> file, real environment wins over the file.
+// secrets can be read from file too by setting <KEY>_FILE, e.g. SECRET_KEY_FILE=/run/secrets/key
+// every invalid or missing key is reported at once
+func Load() (*Config, error) {
 	env := os.Getenv("ENV")
-	APP_ENV := "dev" // default
+	appEnv := "dev" // default
 	if env == "" {
 		env = "development"
-	} else {
-		APP_ENV = strings.ToLower(env[0:3])
+	} else if len(env) >= 3 {
+		appEnv = strings.ToLower(env[0:3])
 	}
 
-	err := godotenv.Load(fmt.Sprintf(".env.%s", env))
-	if err != nil {
-		log.Fatal("Error loading .env file")
+	// env file is optional, containers usually pass real environment
+	file := fmt.Sprintf(".env.%s", env)
+	if err := godotenv.Load(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load env file %s: %w", file, err)
+	}
+
+	l := &loader{}
+	config := &Config{
+		App: AppConfig{
+			Name:      l.string("APP_NAME", "kiraform"),
+			Env:       appEnv,
+			Migration: l.bool("MIGRATION", true),
+			Seeder:    l.bool("SEEDER", false),
+		},
+		HTTP: HTTPConfig{
+			Port:            l.required("APP_PORT"),
+			ShutdownTimeout: l.duration("SHUTDOWN_TIMEOUT", 15*time.Second),
+			DrainDelay:      l.duration("DRAIN_DELAY", 5*time.Second),
+			MetricsToken:    l.secret("METRICS_TOKEN"),
+			TrustedProxies:  l.ipNets("TRUSTED_PROXIES"),
+		},
+		DB: DBConfig{
+			Host: l.required("DB_HOST"),
+			Port: l.string("DB_PORT", "5432"),
+			User: l.required("DB_USER"),
+			Pass: l.secret("DB_PASS"),
+			Name: l.required("DB_NAME"),
+
+			// pool and timeouts, duration uses go format like 30s or 5m
+			MaxOpenConns:     l.int("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:     l.int("DB_MAX_IDLE_CONNS", 10),
+			ConnMaxLifetime:  l.duration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+			ConnMaxIdleTime:  l.duration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+			ConnectTimeout:   l.duration("DB_CONNECT_TIMEOUT", 5*time.Second),
+			StatementTimeout: l.duration("DB_STATEMENT_TIMEOUT", 30*time.Second),
+		},
+		Auth: AuthConfig{
+			SecretKey:    l.secret("SECRET_KEY"),
+			TokenTTL:     l.duration("AUTH_TOKEN_TTL", 24*time.Hour),
+			Issuer:       l.string("AUTH_ISSUER", "Kiraform"),
+			SigningAlg:   l.string("AUTH_SIGNING_ALG", "EdDSA"),
+			LegacyUntil:  l.date("AUTH_LEGACY_TOKENS_UNTIL"),
+			MagicLinkURL: l.string("AUTH_MAGIC_LINK_URL", ""),
+			ResetURL:     l.string("AUTH_RESET_URL", ""),
+		},
+		Storage: StorageConfig{
+			Dir:                l.string("STORAGE_DIR", "cdn"),
+			MaxImageSize:       l.int("STORAGE_MAX_IMAGE_SIZE", 2*1024*1024),
+			TrashRetentionDays: l.int("TRASH_RETENTION_DAYS", 30),
+		},
+		Mail: MailConfig{
+			Host: l.string("MAIL_HOST", ""),
+			Port: l.int("MAIL_PORT", 587),
+			User: l.string("MAIL_USER", ""),
+			Pass: l.secret("MAIL_PASS"),
+			From: l.string("MAIL_FROM", ""),
+		},
+		Redis: RedisConfig{
+			Addr: l.string("REDIS_ADDR", ""),
+			Pass: l.secret("REDIS_PASS"),
+		},
+		Captcha: CaptchaConfig{
+			VerifyURL: l.string("CAPTCHA_VERIFY_URL", "https://www.google.com/recaptcha/api/siteverify"),
+			Secret:    l.secret("CAPTCHA_SECRET"),
+		},
+		Tracing: TracingConfig{
+			Exporter:    l.string("TRACING_EXPORTER", ""),
+			SampleRatio: l.float("TRACING_SAMPLE_RATIO", 1),
+		},
+		OIDC: OIDCConfig{
+			BaseURL:     strings.TrimRight(l.string("OIDC_BASE_URL", ""), "/"),
+			FrontendURL: l.string("OIDC_FRONTEND_URL", ""),
+		},
 	}
+	config.OIDC.Providers = l.oidcProviders()
 
-	// load environment variable
-	MIGRATION := true
-	envMigration := os.Getenv("MIGRATION")
-	if envMigration != "" {
-		m, err := strconv.ParseBool(envMigration)
-		if err == nil {
-			MIGRATION = m
+	config.validate(l)
+	if len(l.errs) > 0 {
+		messages := []string{}
+		for _, err := range l.errs {
+			messages = append(messages, "  - "+err.Error())
 		}
+		return nil, fmt.Errorf("invalid config:\n%s", strings.Join(messages, "\n"))
 	}
+	return config, nil
+}
 
-	SEEDER := false
-	envSeeder := os.Getenv("SEEDER")
-	if envSeeder != "" {
-		s, err := strconv.ParseBool(envSeeder)
-		if err == nil {
-			SEEDER = s
+// validate checks values which are readable but not usable
+func (c *Config) validate(l *loader) {
+	if len(c.Auth.SecretKey) < minSecretKeyLength {
+		l.fail("SECRET_KEY", fmt.Sprintf("must be at least %d characters", minSecretKeyLength))
+	}
+	if c.Auth.TokenTTL <= 0 {
+		l.fail("AUTH_TOKEN_TTL", "must be positive")
+	}
+	if c.Auth.SigningAlg != "RS256" && c.Auth.SigningAlg != "EdDSA" {
+		l.fail("AUTH_SIGNING_ALG", "must be RS256 or EdDSA")
+	}
+	// legacy tokens lived a day, longer cut-off only leaves forged ones a way in
+	if c.Auth.LegacyUntil.After(time.Now().Add(maxLegacyWindow)) {
+		l.fail("AUTH_LEGACY_TOKENS_UNTIL", fmt.Sprintf("must be within %d days", int(maxLegacyWindow.Hours()/24)))
+	}
+	if c.HTTP.ShutdownTimeout <= 0 {
+		l.fail("SHUTDOWN_TIMEOUT", "must be positive")
+	}
+	if c.HTTP.DrainDelay < 0 {
+		l.fail("DRAIN_DELAY", "must not be negative")
+	}
+	if c.DB.MaxOpenConns < 1 {
+		l.fail("DB_MAX_OPEN_CONNS", "must be at least 1")
+	}
+	if c.DB.MaxIdleConns < 0 || c.DB.MaxIdleConns > c.DB.MaxOpenConns {
+		l.fail("DB_MAX_IDLE_CONNS", "must be between 0 and DB_MAX_OPEN_CONNS")
+	}
+
+	// storage dir is served under its own url path, so it must stay inside working directory
+	dir := filepath.Clean(c.Storage.Dir)
+	if filepath.IsAbs(dir) || dir == "." || strings.HasPrefix(dir, "..") {
+		l.fail("STORAGE_DIR", "must be a relative path inside working directory")
+	}
+	c.Storage.Dir = filepath.ToSlash(dir)
+	if c.Storage.MaxImageSize < 1 {
+		l.fail("STORAGE_MAX_IMAGE_SIZE", "must be at least 1 byte")
+	}
+	if c.Storage.TrashRetentionDays < 0 {
+		l.fail("TRASH_RETENTION_DAYS", "must not be negative")
+	}
+
+	if c.Mail.Enabled() && c.Mail.From == "" {
+		l.fail("MAIL_FROM", "is required when MAIL_HOST is set")
+	}
+
+	if c.OIDC.Enabled() {
+		if c.OIDC.BaseURL == "" {
+			l.fail("OIDC_BASE_URL", "is required when OIDC_PROVIDERS is set")
+		}
+		if c.OIDC.FrontendURL == "" {
+			l.fail("OIDC_FRONTEND_URL", "is required when OIDC_PROVIDERS is set")
 		}
 	}
 
-	return Config{
-		APP_NAME:  os.Getenv("APP_NAME"),
-		APP_PORT:  os.Getenv("APP_PORT"),
-		APP_ENV:   APP_ENV,
-		MIGRATION: MIGRATION,
-		SEEDER:    SEEDER,
+	switch c.Tracing.Exporter {
+	case "", "stdout", "otlp":
+	default:
+		l.fail("TRACING_EXPORTER", "must be empty, stdout or otlp")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		l.fail("TRACING_SAMPLE_RATIO", "must be between 0 and 1")
+	}
+}
+
+// loader reads typed variables and collects every error instead of stopping at the first one
+type loader struct {
+	errs []error
+}
+
+func (l *loader) fail(key string, message string) {
+	l.errs = append(l.errs, fmt.Errorf("%s %s", key, message))
+}
+
+func (l *loader) string(key string, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
 
-		DB_HOST: os.Getenv("DB_HOST"),
-		DB_USER: os.Getenv("DB_USER"),
-		DB_PASS: os.Getenv("DB_PASS"),
-		DB_NAME: os.Getenv("DB_NAME"),
-		DB_PORT: os.Getenv("DB_PORT"),
+func (l *loader) required(key string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		l.fail(key, "is required")
+	}
+	return value
+}
 
-		SECRET_KEY: os.Getenv("SECRET_KEY"),
+// secret reads value from <KEY>_FILE when it is set, so secret is not kept in environment
+func (l *loader) secret(key string) string {
+	path := os.Getenv(key + "_FILE")
+	if path == "" {
+		return os.Getenv(key)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		l.fail(key+"_FILE", fmt.Sprintf("can not be read: %v", err))
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+func (l *loader) bool(key string, def bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		l.fail(key, "must be true or false")
+		return def
+	}
+	return b
+}
+
+func (l *loader) int(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		l.fail(key, "must be an integer")
+		return def
+	}
+	return i
+}
+
+func (l *loader) float(key string, def float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		l.fail(key, "must be a number")
+		return def
 	}
+	return f
+}
+
+// duration reads value like 30s or 5m
+func (l *loader) duration(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		l.fail(key, "must be a duration like 30s or 5m")
+		return def
+	}
+	return d
+}
+
+// date reads value like 2006-01-02 as start of that day in utc, empty is zero time
+func (l *loader) date(key string) time.Time {
+	value := os.Getenv(key)
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		l.fail(key, "must be a date like 2006-01-02")
+		return time.Time{}
+	}
+	return t
+}
+
+// ipNets reads comma separated cidr ranges, single ip is taken as range of itself
+func (l *loader) ipNets(key string) []*net.IPNet {
+	ranges := []*net.IPNet{}
+	for _, value := range strings.Split(l.string(key, ""), ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		cidr := value
+		if ip := net.ParseIP(value); ip != nil {
+			if ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			l.fail(key, fmt.Sprintf("has invalid ip or cidr %q", value))
+			continue
+		}
+		ranges = append(ranges, ipNet)
+	}
+	return ranges
+}
+
+// oidcProviders reads OIDC_PROVIDERS=google,corp then OIDC_<NAME>_* of every listed provider
+func (l *loader) oidcProviders() []OIDCProvider {
+	providers := []OIDCProvider{}
+	for _, name := range strings.Split(l.string("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		if strings.Trim(name, "abcdefghijklmnopqrstuvwxyz0123456789") != "" {
+			l.fail("OIDC_PROVIDERS", fmt.Sprintf("name %q must be lowercase letters and digits", name))
+			continue
+		}
+
+		provider := OIDCProvider{
+			Name:         name,
+			Issuer:       l.string(prefix+"ISSUER", ""),
+			ClientID:     l.required(prefix + "CLIENT_ID"),
+			ClientSecret: l.secret(prefix + "CLIENT_SECRET"),
+			Scopes:       strings.Fields(l.string(prefix+"SCOPES", "openid email profile")),
+			TrustEmail:   l.bool(prefix+"TRUST_EMAIL", false),
+		}
+		if provider.Issuer == "" {
+			switch name {
+			case "google":
+				provider.Issuer = oidcIssuers[name]
+			case "microsoft":
+				provider.Issuer = fmt.Sprintf(oidcIssuers[name], l.required(prefix+"TENANT"))
+			default:
+				l.fail(prefix+"ISSUER", "is required for generic provider")
+			}
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+// LogSummary writes non secret values, useful to check what is actually loaded
+func (c *Config) LogSummary() {
+	slog.Info("config loaded",
+		"env", c.App.Env,
+		"port", c.HTTP.Port,
+		"trusted_proxies", len(c.HTTP.TrustedProxies),
+		"db_host", c.DB.Host,
+		"db_name", c.DB.Name,
+		"storage_dir", c.Storage.Dir,
+		"redis", c.Redis.Addr != "",
+		"mail", c.Mail.Enabled(),
+		"tracing", c.Tracing.Exporter,
+		"oidc_providers", len(c.OIDC.Providers),
+	)
 }
@@ -2,39 +2,54 @@ package configs
 
 import (
 	"fmt"
-	"kiraform/src/infras/migrations"
-	"log"
-	"strings"
+	"kiraform/src/infras/logger"
+	"kiraform/src/infras/metrics"
+	"kiraform/src/infras/tracing"
+	"log/slog"
+	"math"
+	"os"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 func Connection(config Config) *gorm.DB {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", config.DB_HOST, config.DB_PORT, config.DB_USER, config.DB_PASS, config.DB_NAME)
-
-	gormConfig := &gorm.Config{}
-	if config.APP_ENV != "" && strings.ToLower(config.APP_ENV) == "dev" {
-		// only show query log on dev mode
-		gormConfig = &gorm.Config{
-			Logger: logger.Default.LogMode(logger.Info),
-		}
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable connect_timeout=%d statement_timeout=%d",
+		config.DB.Host, config.DB.Port, config.DB.User, config.DB.Pass, config.DB.Name,
+		int(math.Ceil(config.DB.ConnectTimeout.Seconds())), config.DB.StatementTimeout.Milliseconds())
+
+	// every query is only logged on dev mode, otherwise slow and failed queries
+	gormConfig := &gorm.Config{
+		Logger: logger.NewGormLogger(config.App.IsDev()),
 	}
 
 	DB, err := gorm.Open(postgres.Open(dsn), gormConfig)
 	if err != nil {
-		log.Fatal("Failed to connect to database", err)
+		slog.Error("failed to connect to database", "error", err)
+		os.Exit(1)
 	}
 
-	// start migrating table
-	if config.MIGRATION {
-		migrations.Migrate(DB)
+	// configure connection pool
+	sqlDB, err := DB.DB()
+	if err != nil {
+		slog.Error("failed to get database pool", "error", err)
+		os.Exit(1)
+	}
+	sqlDB.SetMaxOpenConns(config.DB.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.DB.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(config.DB.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(config.DB.ConnMaxIdleTime)
+
+	// collect query timings and pool stats
+	if err := DB.Use(metrics.NewGormPlugin()); err != nil {
+		slog.Error("failed to register database metrics", "error", err)
+		os.Exit(1)
 	}
 
-	// start seeding data
-	if config.SEEDER {
-		migrations.Seeder(DB)
+	// trace every query under span of its request
+	if err := DB.Use(tracing.NewGormPlugin()); err != nil {
+		slog.Error("failed to register database tracing", "error", err)
+		os.Exit(1)
 	}
 
 	// send db to global connection
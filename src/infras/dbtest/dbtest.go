@@ -0,0 +1,189 @@
+// Package dbtest serves gorm from an in-process fake driver, so tests can
+// check which queries a usecase sends without a running postgres
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Rows is result of one query
+type Rows struct {
+	Columns []string
+	Values  [][]driver.Value
+}
+
+// Responder answers a query, nil means the query returns no row
+type Responder func(query string) *Rows
+
+// Recorder keeps every statement which reached the database
+type Recorder struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (r *Recorder) add(query string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries = append(r.queries, query)
+}
+
+func (r *Recorder) Queries() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.queries...)
+}
+
+// Open returns gorm with postgres dialect whose queries are answered by respond
+func Open(t testing.TB, respond Responder) (*gorm.DB, *Recorder) {
+	t.Helper()
+	recorder := &Recorder{}
+	sqlDB := sql.OpenDB(&connector{respond: respond, recorder: recorder})
+	t.Cleanup(func() {
+		sqlDB.Close()
+	})
+
+	DB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	return DB, recorder
+}
+
+// ConstantQueries runs fn once for every row count and fails when the number of
+// queries it sends changes with the row count, catching queries issued per row
+func ConstantQueries(t *testing.T, rowCounts []int, fn func(t *testing.T, rows int) []string) {
+	t.Helper()
+	var first []string
+	for i, rows := range rowCounts {
+		queries := fn(t, rows)
+		if i == 0 {
+			first = queries
+			continue
+		}
+		if len(queries) != len(first) {
+			t.Fatalf("%d rows sent %d queries, %d rows sent %d:\n%s\n\nvs\n\n%s",
+				rowCounts[0], len(first), rows, len(queries),
+				strings.Join(first, "\n"), strings.Join(queries, "\n"))
+		}
+	}
+}
+
+type connector struct {
+	respond  Responder
+	recorder *Recorder
+}
+
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{connector: c}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("dbtest: open through connector")
+}
+
+type conn struct {
+	connector *connector
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return tx{}, nil
+}
+
+// CheckNamedValue lets every argument through as is, the fake never reads them
+func (c *conn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.connector.recorder.add(query)
+	result := c.connector.respond(query)
+	if result == nil {
+		result = &Rows{}
+	}
+	return &rows{result: result}, nil
+}
+
+func (c *conn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.connector.recorder.add(query)
+	return driver.RowsAffected(0), nil
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, nil)
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, nil)
+}
+
+type tx struct{}
+
+func (tx) Commit() error {
+	return nil
+}
+
+func (tx) Rollback() error {
+	return nil
+}
+
+type rows struct {
+	result *Rows
+	next   int
+}
+
+func (r *rows) Columns() []string {
+	return r.result.Columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.Values) {
+		return io.EOF
+	}
+	copy(dest, r.result.Values[r.next])
+	r.next++
+	return nil
+}
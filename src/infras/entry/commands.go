@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	operatordi "kiraform/src/applications/dependencies/operators"
+	"kiraform/src/applications/jobs"
+	"kiraform/src/applications/keyring"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/applications/sessions"
+	"kiraform/src/infras/configs"
+	"kiraform/src/infras/mailer"
+	"kiraform/src/infras/migrations"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const usage = `usage: kiraform [command] [flags]
+
+commands:
+  serve                                          start http server (default)
+  migrate <command>                              manage versioned migrations, see migrate -h
+  seed [-only roles,forms,packages,users]        run all or selected seeders
+  create-admin -email e -name n [-password p]    create user with admin role
+  reset-password -email e [-password p]          set new password of user
+  grant-package -email e -package code -days n   activate package for user
+  deactivate-user -email e                       block user from logging in
+  export-campaign -key k [-out file]             write campaign entries as csv
+  purge-trash [-days n]                          delete trash older than n days now
+  rotate-keys [-alg RS256|EdDSA]                 sign new tokens with a fresh key
+
+password is read from the first line of stdin when -password is omitted,
+so it is not kept in shell history
+`
+
+// command runs with loaded config and its own arguments, returns exit code
+type command func(config configs.Config, args []string) int
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"serve":           serve,
+		"migrate":         migrateCommand,
+		"seed":            seedCommand,
+		"create-admin":    createAdminCommand,
+		"reset-password":  resetPasswordCommand,
+		"grant-package":   grantPackageCommand,
+		"deactivate-user": deactivateUserCommand,
+		"export-campaign": exportCampaignCommand,
+		"purge-trash":     purgeTrashCommand,
+		"rotate-keys":     rotateKeysCommand,
+		"help":            helpCommand,
+	}
+}
+
+func helpCommand(config configs.Config, args []string) int {
+	fmt.Print(usage)
+	return 0
+}
+
+// newFlags builds flag set of command, failing parse prints usage of all commands
+func newFlags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	return flags
+}
+
+// required reports missing flags, returns false when any of them is empty
+func required(values map[string]string) bool {
+	ok := true
+	for name, value := range values {
+		if strings.TrimSpace(value) == "" {
+			fmt.Fprintf(os.Stderr, "-%s is required\n", name)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// withDB connects database for command, stop signal cancels ctx and connection is closed after fn
+func withDB(config configs.Config, fn func(ctx context.Context, DB *gorm.DB) error) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	DB := configs.Connection(config)
+	defer func() {
+		if sqlDB, err := DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	if err := fn(ctx, DB); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// newOperator loads operator usecase, its tracker signs out users changed from command line
+func newOperator(config configs.Config, DB *gorm.DB) *operatordi.OperatorDependencies {
+	tracker := sessions.NewTracker(masterrepo.NewSessionRepository(DB), masterrepo.NewLoginHistoryRepository(DB), masterrepo.NewUserRepository(DB), mailer.New(config.Mail), config.Auth.Issuer)
+	return operatordi.NewOperatorDependencies(DB, tracker)
+}
+
+// readPassword takes password from flag, otherwise from first line of stdin
+func readPassword(value string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+	fmt.Fprint(os.Stderr, "password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func seedCommand(config configs.Config, args []string) int {
+	flags := newFlags("seed")
+	only := flags.String("only", "", "comma separated seeders, empty runs all")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	names := []string{}
+	for _, v := range strings.Split(*only, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			names = append(names, v)
+		}
+	}
+	if err := migrations.CheckSeeders(names); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	return withDB(config, func(ctx context.Context, DB *gorm.DB) error {
+		return migrations.Seed(DB.WithContext(ctx), names)
+	})
+}
+
+func createAdminCommand(config configs.Config, args []string) int {
+	flags := newFlags("create-admin")
+	email := flags.String("email", "", "email of admin")
+	name := flags.String("name", "", "full name of admin")
+	password := flags.String("password", "", "password of admin")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if !required(map[string]string{"email": *email, "name": *name}) {
+		return 2
+	}
+
+	secret, err := readPassword(*password)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return withDB(config, func(ctx context.Context, DB *gorm.DB) error {
+		if err := newOperator(config, DB).UC.CreateAdmin(ctx, *email, *name, secret); err != nil {
+			return err
+		}
+		fmt.Printf("admin %s is created\n", *email)
+		return nil
+	})
+}
+
+func resetPasswordCommand(config configs.Config, args []string) int {
+	flags := newFlags("reset-password")
+	email := flags.String("email", "", "email of user")
+	password := flags.String("password", "", "new password")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if !required(map[string]string{"email": *email}) {
+		return 2
+	}
+
+	secret, err := readPassword(*password)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return withDB(config, func(ctx context.Context, DB *gorm.DB) error {
+		if err := newOperator(config, DB).UC.ResetPassword(ctx, *email, secret); err != nil {
+			return err
+		}
+		fmt.Printf("password of %s is reset\n", *email)
+		return nil
+	})
+}
+
+func grantPackageCommand(config configs.Config, args []string) int {
+	flags := newFlags("grant-package")
+	email := flags.String("email", "", "email of user")
+	code := flags.String("package", "", "package code, e.g. GOLD")
+	days := flags.Int("days", 30, "active days of package")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if !required(map[string]string{"email": *email, "package": *code}) {
+		return 2
+	}
+
+	return withDB(config, func(ctx context.Context, DB *gorm.DB) error {
+		if err := newOperator(config, DB).UC.GrantPackage(ctx, *email, *code, *days); err != nil {
+			return err
+		}
+		fmt.Printf("package %s is granted to %s for %d days\n", strings.ToUpper(*code), *email, *days)
+		return nil
+	})
+}
+
+func deactivateUserCommand(config configs.Config, args []string) int {
+	flags := newFlags("deactivate-user")
+	email := flags.String("email", "", "email of user")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if !required(map[string]string{"email": *email}) {
+		return 2
+	}
+
+	return withDB(config, func(ctx context.Context, DB *gorm.DB) error {
+		if err := newOperator(config, DB).UC.DeactivateUser(ctx, *email); err != nil {
+			return err
+		}
+		fmt.Printf("user %s is deactivated\n", *email)
+		return nil
+	})
+}
+
+func exportCampaignCommand(config configs.Config, args []string) int {
+	flags := newFlags("export-campaign")
+	key := flags.String("key", "", "key of campaign")
+	out := flags.String("out", "", "output csv file, stdout when empty")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if !required(map[string]string{"key": *key}) {
+		return 2
+	}
+
+	return withDB(config, func(ctx context.Context, DB *gorm.DB) error {
+		w := os.Stdout
+		if *out != "" {
+			file, err := os.Create(*out)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			w = file
+		}
+
+		total, err := newOperator(config, DB).UC.ExportCampaign(ctx, *key, w)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "%d entries are exported\n", total)
+		return nil
+	})
+}
+
+func purgeTrashCommand(config configs.Config, args []string) int {
+	flags := newFlags("purge-trash")
+	days := flags.Int("days", config.Storage.TrashRetentionDays, "retention days, older trash is deleted")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	// zero would purge everything in trash, so it is not accepted here
+	if *days < 1 {
+		fmt.Fprintln(os.Stderr, "days must be at least 1")
+		return 2
+	}
+
+	return withDB(config, func(ctx context.Context, DB *gorm.DB) error {
+		return jobs.NewPurgeTrashJob(DB, time.Duration(*days)*24*time.Hour).Run(ctx)
+	})
+}
+
+func rotateKeysCommand(config configs.Config, args []string) int {
+	flags := newFlags("rotate-keys")
+	alg := flags.String("alg", config.Auth.SigningAlg, "algorithm of new key, RS256 or EdDSA")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *alg != keyring.AlgRS256 && *alg != keyring.AlgEdDSA {
+		fmt.Fprintln(os.Stderr, "alg must be RS256 or EdDSA")
+		return 2
+	}
+
+	// running servers keep verifying tokens of previous key until they expire
+	return withDB(config, func(ctx context.Context, DB *gorm.DB) error {
+		keys, err := keyring.New(masterrepo.NewSigningKeyRepository(DB), config.Auth)
+		if err != nil {
+			return err
+		}
+		key, err := keys.Rotate(ctx, *alg)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("signing key %s (%s) is current, previous key verifies tokens for %s\n", key.ID, key.Algorithm, config.Auth.TokenTTL)
+		return nil
+	})
+}
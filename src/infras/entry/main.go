@@ -1,51 +1,55 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"kiraform/src/infras/configs"
-	"kiraform/src/interfaces/rest/routes"
-	"log"
-	"path/filepath"
-	"strings"
-
-	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
-	echoSwagger "github.com/swaggo/echo-swagger"
+	"kiraform/src/infras/logger"
+	"kiraform/src/infras/tracing"
+	"log/slog"
+	"os"
+	"time"
 
 	_ "kiraform/docs"
 )
 
-func main() {
-	// define core entities
-	CONFIG := configs.Environment()
-	DB := configs.Connection(CONFIG)
-	e := echo.New()
-
-	// cors handler
-	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.DELETE, echo.OPTIONS},
-	}))
+// flushTimeout limits sending remaining traces before exit
+const flushTimeout = 5 * time.Second
 
-	// serve static file
-	cdnPath, err := filepath.Abs("./cdn")
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Type "Bearer " followed by a space and JWT token.
+func main() {
+	// define core entities, config is loaded once and passed down from here
+	CONFIG, err := configs.Load()
 	if err != nil {
-		log.Fatal("Failed to resolve CDN path:", err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	e.Static("/cdn", cdnPath)
-
-	// load swagger only for development environment
-	if strings.ToLower(CONFIG.APP_ENV) == "dev" {
-		// @securityDefinitions.apikey BearerAuth
-		// @in header
-		// @name Authorization
-		// @description Type "Bearer " followed by a space and JWT token.
-		e.GET("/docs/*", echoSwagger.WrapHandler)
+	logger.Setup(CONFIG.App.Env)
+	shutdownTracing, err := tracing.Setup(context.Background(), CONFIG.App.Name, CONFIG.Tracing.Exporter, CONFIG.Tracing.SampleRatio)
+	if err != nil {
+		slog.Error("failed to setup tracing", "error", err)
+		os.Exit(1)
 	}
 
-	// calling main route
-	routes.Routes(e, DB)
+	// server is started when no command is given
+	name, args := "serve", []string{}
+	if len(os.Args) > 1 {
+		name, args = os.Args[1], os.Args[2:]
+	}
+	command, ok := commands[name]
+	if !ok {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	code := command(*CONFIG, args)
 
-	// run applications
-	e.Logger.Fatal(e.Start(fmt.Sprintf(":%v", CONFIG.APP_PORT)))
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("failed to flush traces", "error", err)
+	}
+	cancel()
+	os.Exit(code)
 }
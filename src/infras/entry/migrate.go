@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"kiraform/src/infras/configs"
+	"kiraform/src/infras/migrations"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const migrateUsage = `usage: kiraform migrate <command> [flags]
+
+commands:
+  up                   apply every pending migration
+  down [-steps n]      roll back the last n migrations (default 1)
+  status               list migrations and when they are applied
+  create <name>        create empty up and down files
+
+flags of create:
+  -dir path            migration directory (default src/infras/migrations/sql)
+`
+
+// migrateCommand manages versioned migrations, returns exit code
+func migrateCommand(config configs.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, migrateUsage) }
+	steps := flags.Int("steps", 1, "number of migrations to roll back")
+	dir := flags.String("dir", "src/infras/migrations/sql", "migration directory")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	// commands on files only, database is not needed
+	switch args[0] {
+	case "create":
+		if flags.NArg() != 1 {
+			fmt.Fprint(os.Stderr, migrateUsage)
+			return 2
+		}
+		up, down, err := migrations.Create(*dir, flags.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("created %s\ncreated %s\n", up, down)
+		return 0
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	DB := configs.Connection(config)
+	migrator := migrations.NewMigrator(DB)
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, v := range applied {
+			fmt.Printf("applied %d_%s\n", v.Version, v.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("nothing to apply")
+		}
+	case "down":
+		if *steps < 1 {
+			fmt.Fprintln(os.Stderr, "steps must be at least 1")
+			return 2
+		}
+		reverted, err := migrator.Down(ctx, *steps)
+		for _, v := range reverted {
+			fmt.Printf("rolled back %d_%s\n", v.Version, v.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, v := range status {
+			state := "pending"
+			if v.AppliedAt != nil {
+				state = "applied " + v.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if v.Modified {
+				state += " (modified)"
+			}
+			if v.Missing {
+				state += " (file missing)"
+			}
+			fmt.Printf("%d_%s\t%s\n", v.Version, v.Name, state)
+		}
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+	return 0
+}
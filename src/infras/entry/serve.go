@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kiraform/src/applications/jobs"
+	"kiraform/src/applications/keyring"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/applications/sso"
+	"kiraform/src/applications/twofactor"
+	"kiraform/src/infras/configs"
+	"kiraform/src/infras/health"
+	"kiraform/src/infras/mailer"
+	"kiraform/src/infras/migrations"
+	"kiraform/src/infras/ratelimit"
+	"kiraform/src/interfaces/rest/middlewares"
+	"kiraform/src/interfaces/rest/routes"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	echoSwagger "github.com/swaggo/echo-swagger"
+)
+
+// serve runs http server and background jobs until stop signal, returns exit code
+func serve(config configs.Config, args []string) int {
+	config.LogSummary()
+	DB := configs.Connection(config)
+
+	// apply pending migrations
+	if config.App.Migration {
+		migrations.Migrate(DB)
+	}
+
+	// start seeding data
+	if config.App.Seeder {
+		migrations.Seeder(DB)
+	}
+	limiter := ratelimit.NewStore(config.Redis.Addr, config.Redis.Pass)
+	e := echo.New()
+	e.HTTPErrorHandler = middlewares.ErrorHandler
+	e.HideBanner = true
+
+	// client ip is taken from the connection unless request comes through a trusted proxy,
+	// otherwise forwarded headers set by client would bypass rate limits and fake audit ip
+	e.IPExtractor = echo.ExtractIPDirect()
+	if len(config.HTTP.TrustedProxies) > 0 {
+		trust := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+		for _, v := range config.HTTP.TrustedProxies {
+			trust = append(trust, echo.TrustIPRange(v))
+		}
+		e.IPExtractor = echo.ExtractIPFromXFFHeader(trust...)
+	}
+
+	// trace each request by its id and span, then log it and collect http metrics
+	e.Use(middlewares.RequestID())
+	e.Use(middlewares.Trace())
+	e.Use(middlewares.Observe())
+
+	// cors handler
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins: []string{"*"},
+		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.DELETE, echo.OPTIONS},
+	}))
+
+	// serve uploaded files, stored path is used as url path too
+	storagePath, err := filepath.Abs(config.Storage.Dir)
+	if err != nil {
+		slog.Error("failed to resolve storage path", "error", err)
+		return 1
+	}
+	e.Static("/"+config.Storage.Dir, storagePath)
+
+	// load swagger only for development environment
+	if config.App.IsDev() {
+		e.GET("/docs/*", echoSwagger.WrapHandler)
+	}
+
+	// stop signal cancels background workers and starts shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// purge expired trash in background
+	purgeTrash := jobs.NewPurgeTrashJob(DB, time.Duration(config.Storage.TrashRetentionDays)*24*time.Hour)
+	purgeTrash.Start(ctx)
+
+	// load signing keys, creating the first one on fresh database, then follow rotation
+	keys, err := keyring.New(masterrepo.NewSigningKeyRepository(DB), config.Auth)
+	if err != nil {
+		slog.Error("failed to create keyring", "error", err)
+		return 1
+	}
+	if err := keys.Init(ctx); err != nil {
+		slog.Error("failed to load signing keys", "error", err)
+		return 1
+	}
+	keys.Start(ctx)
+
+	twoFactor, err := twofactor.New(masterrepo.NewTwoFactorRepository(DB), config.Auth.SecretKey, config.Auth.Issuer)
+	if err != nil {
+		slog.Error("failed to create two-factor manager", "error", err)
+		return 1
+	}
+
+	// providers are discovered on first login, so unreachable provider does not stop boot
+	registry, err := sso.New(config.OIDC, config.Auth.SecretKey)
+	if err != nil {
+		slog.Error("failed to create sso registry", "error", err)
+		return 1
+	}
+
+	// emails are written to log when mail server is not configured
+	mail := mailer.New(config.Mail)
+
+	// calling main route
+	checker := health.NewChecker(ctx, DB, storagePath)
+	routes.Routes(e, DB, config, limiter, checker, keys, twoFactor, registry, mail)
+
+	// run applications
+	go func() {
+		slog.Info("server started", "port", config.HTTP.Port, "env", config.App.Env)
+		if err := e.Start(fmt.Sprintf(":%v", config.HTTP.Port)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("failed to start server", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	// wait for stop signal, then drain in-flight requests and workers within timeout
+	<-ctx.Done()
+	stop() // second signal kills the process right away
+	slog.Info("shutting down")
+	checker.Drain()
+
+	// keep serving until load balancer sees failing readiness and stops routing here
+	if config.HTTP.DrainDelay > 0 {
+		slog.Info("draining traffic", "delay", config.HTTP.DrainDelay.String())
+		time.Sleep(config.HTTP.DrainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.HTTP.ShutdownTimeout)
+	defer cancel()
+
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to drain http server", "error", err)
+	}
+	if err := purgeTrash.Wait(shutdownCtx); err != nil {
+		slog.Error("failed to wait background jobs", "error", err)
+	}
+	keys.Wait()
+	if err := limiter.Close(); err != nil {
+		slog.Error("failed to close rate limit store", "error", err)
+	}
+	if sqlDB, err := DB.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			slog.Error("failed to close database", "error", err)
+		}
+	}
+	slog.Info("server stopped")
+	return 0
+}
@@ -0,0 +1,135 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kiraform/src/infras/migrations"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// checkTimeout limits each readiness check so a hanging dependency fails the probe quickly
+const checkTimeout = 2 * time.Second
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+type Check struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Latency string `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string  `json:"status"`
+	Checks []Check `json:"checks,omitempty"`
+}
+
+// Checker reports liveness and readiness of the service
+type Checker struct {
+	DB         *gorm.DB
+	StorageDir string
+	migration  error // pending migrations found at startup, embedded files never change while running
+	draining   atomic.Bool
+}
+
+func NewChecker(ctx context.Context, DB *gorm.DB, storageDir string) *Checker {
+	checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	return &Checker{
+		DB:         DB,
+		StorageDir: storageDir,
+		migration:  pendingMigrations(checkCtx, DB),
+	}
+}
+
+// Drain marks service as shutting down, readiness fails so no new traffic is routed here
+func (h *Checker) Drain() {
+	h.draining.Store(true)
+}
+
+// Live only tells the process is able to respond
+func (h *Checker) Live() Report {
+	return Report{Status: StatusUp}
+}
+
+// Ready checks every dependency needed to serve requests
+func (h *Checker) Ready(ctx context.Context) Report {
+	report := Report{Status: StatusUp}
+	checks := []struct {
+		name string
+		fn   func(ctx context.Context) error
+	}{
+		{"shutdown", h.checkShutdown},
+		{"database", h.checkDatabase},
+		{"migration", h.checkMigration},
+		{"storage", h.checkStorage},
+	}
+
+	for _, v := range checks {
+		check := Check{Name: v.name, Status: StatusUp}
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		start := time.Now()
+		err := v.fn(checkCtx)
+		check.Latency = time.Since(start).String()
+		cancel()
+
+		if err != nil {
+			check.Status = StatusDown
+			check.Error = err.Error()
+			report.Status = StatusDown
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	return report
+}
+
+func (h *Checker) checkShutdown(ctx context.Context) error {
+	if h.draining.Load() {
+		return errors.New("service is shutting down")
+	}
+	return nil
+}
+
+func (h *Checker) checkDatabase(ctx context.Context) error {
+	sqlDB, err := h.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// checkMigration reports result computed at startup, replica started before migrating needs a restart
+func (h *Checker) checkMigration(ctx context.Context) error {
+	return h.migration
+}
+
+func pendingMigrations(ctx context.Context, DB *gorm.DB) error {
+	pending, err := migrations.Pending(ctx, DB)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("migrations not applied: %s", strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// checkStorage makes sure uploaded files can be written
+func (h *Checker) checkStorage(ctx context.Context) error {
+	file, err := os.CreateTemp(h.StorageDir, ".readyz-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
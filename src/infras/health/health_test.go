@@ -0,0 +1,62 @@
+package health
+
+import (
+	"context"
+	"kiraform/src/infras/dbtest"
+	"strings"
+	"testing"
+)
+
+func TestMigrationCheckedOnceAtStartup(t *testing.T) {
+	// schema table is missing, so every embedded migration is pending
+	DB, recorder := dbtest.Open(t, func(query string) *dbtest.Rows {
+		return nil
+	})
+	checker := NewChecker(context.Background(), DB, t.TempDir())
+	atStartup := len(recorder.Queries())
+	if atStartup == 0 {
+		t.Fatal("want migrations checked at startup")
+	}
+
+	for i := 0; i < 2; i++ {
+		report := checker.Ready(context.Background())
+		if report.Status != StatusDown {
+			t.Fatalf("status = %q, want %q", report.Status, StatusDown)
+		}
+		check := findCheck(t, report, "migration")
+		if check.Status != StatusDown || !strings.Contains(check.Error, "migrations not applied") {
+			t.Fatalf("migration check = %+v, want pending migrations", check)
+		}
+	}
+	for _, v := range recorder.Queries()[atStartup:] {
+		if strings.Contains(v, "schema_migrations") || strings.Contains(v, "information_schema") {
+			t.Fatalf("probe queried migrations again: %s", v)
+		}
+	}
+}
+
+func TestDrainFailsReadiness(t *testing.T) {
+	DB, _ := dbtest.Open(t, func(query string) *dbtest.Rows {
+		return nil
+	})
+	checker := NewChecker(context.Background(), DB, t.TempDir())
+	if check := findCheck(t, checker.Ready(context.Background()), "shutdown"); check.Status != StatusUp {
+		t.Fatalf("shutdown check = %+v before drain, want up", check)
+	}
+
+	checker.Drain()
+	if check := findCheck(t, checker.Ready(context.Background()), "shutdown"); check.Status != StatusDown {
+		t.Fatalf("shutdown check = %+v after drain, want down", check)
+	}
+}
+
+func findCheck(t *testing.T, report Report, name string) Check {
+	t.Helper()
+	for _, v := range report.Checks {
+		if v.Name == name {
+			return v
+		}
+	}
+	t.Fatalf("check %q not in report", name)
+	return Check{}
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type requestIDKey struct{}
+
+// New creates json logger, debug level is only enabled on dev mode
+func New(appEnv string) *slog.Logger {
+	level := slog.LevelInfo
+	if strings.ToLower(appEnv) == "dev" {
+		level = slog.LevelDebug
+	}
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+	return slog.New(&contextHandler{Handler: handler})
+}
+
+// Setup replaces default logger, log package also writes through it
+func Setup(appEnv string) *slog.Logger {
+	l := New(appEnv)
+	slog.SetDefault(l)
+	return l
+}
+
+// WithRequestID stores request id to be attached on every log using the context
+func WithRequestID(ctx context.Context, ID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, ID)
+}
+
+// RequestID returns request id of the context, empty when it is not a request
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	ID, _ := ctx.Value(requestIDKey{}).(string)
+	return ID
+}
+
+// contextHandler adds request_id and trace_id from context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ID := RequestID(ctx); ID != "" {
+		r.AddAttrs(slog.String("request_id", ID))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
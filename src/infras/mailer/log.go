@@ -0,0 +1,61 @@
+package mailer
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// LogMailer writes emails to log instead of sending them, for local development
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	slog.InfoContext(ctx, "email is not sent, mail server is not configured", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
+
+// MemoryMailer keeps sent emails, so tests can read the code or link sent to user
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Last returns latest email sent to address
+func (m *MemoryMailer) Last(to string) (Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To == to {
+			return m.messages[i], true
+		}
+	}
+	return Message{}, false
+}
+
+// Count returns number of emails sent to address
+func (m *MemoryMailer) Count(to string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, v := range m.messages {
+		if v.To == to {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,28 @@
+package mailer
+
+import (
+	"context"
+	"kiraform/src/infras/configs"
+	"log/slog"
+)
+
+// Message is plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails of the application
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New uses smtp server when it is configured, otherwise emails are written to log for local development
+func New(config configs.MailConfig) Mailer {
+	if !config.Enabled() {
+		slog.Warn("mail server is not configured, emails are written to log")
+		return NewLogMailer()
+	}
+	return NewSMTPMailer(config)
+}
@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"kiraform/src/infras/configs"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPMailer sends email through smtp server, STARTTLS is used when server offers it
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(config configs.MailConfig) *SMTPMailer {
+	m := &SMTPMailer{
+		addr: net.JoinHostPort(config.Host, strconv.Itoa(config.Port)),
+		from: config.From,
+	}
+	if config.User != "" {
+		m.auth = smtp.PlainAuth("", config.User, config.Pass, config.Host)
+	}
+	return m
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	// header values must not break into new header
+	for _, v := range []string{msg.To, msg.Subject} {
+		if strings.ContainsAny(v, "\r\n") {
+			return fmt.Errorf("mail header contains line break")
+		}
+	}
+
+	body := strings.Join([]string{
+		"From: " + m.from,
+		"To: " + msg.To,
+		"Subject: " + msg.Subject,
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		strings.ReplaceAll(msg.Body, "\n", "\r\n"),
+	}, "\r\n")
+
+	// net/smtp does not take context, so sending runs aside and caller stops waiting when it is done
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, []byte(body))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -1,28 +1,32 @@
 package migrations
 
 import (
-	"fmt"
-	"kiraform/src/applications/models"
-	"log"
+	"context"
+	"log/slog"
+	"os"
 
 	"gorm.io/gorm"
 )
 
+// Migrate applies pending versioned migrations on boot, replicas wait for each other by advisory lock
 func Migrate(DB *gorm.DB) {
-	err := DB.AutoMigrate(
-		&models.Users{}, &models.UserProfiles{},
-		&models.Roles{}, &models.Packages{},
-		&models.UserRoles{}, &models.UserPackages{},
-		&models.Workspaces{}, &models.Campaigns{}, &models.Forms{},
-		&models.CampaignSeos{}, &models.CampaignForms{}, &models.CampaignFormAttributes{},
-		&models.WorkspaceUsers{},
-		&models.FormEntries{}, &models.FormDetailEntries{},
-		&models.Billings{}, &models.BillingDetails{},
-		&models.Stores{}, &models.StoreUsers{},
-		&models.StoreProductCategories{}, &models.StoreProducts{}, &models.StoreProductImages{},
-	)
+	applied, err := NewMigrator(DB).Up(context.Background())
 	if err != nil {
-		log.Fatal(fmt.Printf("Error while migrating database: %v", err))
+		slog.Error("failed to migrate database", "error", err)
+		os.Exit(1)
 	}
-	fmt.Println("Database successfully migrated!")
+	slog.Info("database successfully migrated", "applied", len(applied))
+}
+
+// Pending returns name of migrations which are not applied yet
+func Pending(ctx context.Context, DB *gorm.DB) ([]string, error) {
+	migrations, err := NewMigrator(DB).Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pending := []string{}
+	for _, v := range migrations {
+		pending = append(pending, v.Name)
+	}
+	return pending, nil
 }
@@ -0,0 +1,387 @@
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+// lockKey is postgres advisory lock held while migrating, so replicas starting together do not race
+const lockKey int64 = 4_739_201_836
+
+// baselineVersion is schema created by AutoMigrate before versioned migrations were introduced
+const baselineVersion int64 = 20261019000000
+
+var (
+	fileName    = regexp.MustCompile(`^(\d{14})_([a-z0-9_]+)\.(up|down)\.sql$`)
+	nameInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+	// column definitions of baseline, constraints are skipped since they are not quoted after comma
+	createTable = regexp.MustCompile(`CREATE TABLE "(\w+)" \((.*)\);`)
+	columnName  = regexp.MustCompile(`(?:^|,)"(\w+)" `)
+)
+
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// SchemaMigrations records applied migration with checksum of its up file
+type SchemaMigrations struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	Checksum  string    `gorm:"type:varchar(64);not null"`
+	AppliedAt time.Time `gorm:"type:timestamp;not null"`
+}
+
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+	Modified  bool       `json:"modified"` // up file is changed after applied
+	Missing   bool       `json:"missing"`  // applied but its file is gone
+}
+
+type Migrator struct {
+	DB     *gorm.DB
+	Source fs.FS
+}
+
+// NewMigrator reads migration files embedded in the binary
+func NewMigrator(DB *gorm.DB) *Migrator {
+	source, _ := fs.Sub(embedded, "sql")
+	return &Migrator{DB: DB, Source: source}
+}
+
+// Load reads every migration file ordered by version, checksum covers up file only
+func (m *Migrator) Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(m.Source, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(m.Source, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+			sum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := []Migration{}
+	for _, v := range byVersion {
+		if strings.TrimSpace(v.Up) == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", v.Version, v.Name)
+		}
+		migrations = append(migrations, *v)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Up applies every pending migration in order, each one in its own transaction holding the lock
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	done := []Migration{}
+	for {
+		var applied *Migration
+		start := time.Now()
+		err := m.withLock(ctx, func(tx *gorm.DB) error {
+			if err := m.adoptBaseline(tx); err != nil {
+				return err
+			}
+
+			// pending is read again under the lock, replica which waited finds work of the other one done
+			pending, err := m.pending(tx, true)
+			if err != nil || len(pending) == 0 {
+				return err
+			}
+			v := pending[0]
+			if err := exec(tx, v.Up); err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s: %w", v.Version, v.Name, err)
+			}
+			applied = &v
+			return tx.Create(&SchemaMigrations{Version: v.Version, Name: v.Name, Checksum: v.Checksum, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, err
+		}
+		if applied == nil {
+			return done, nil
+		}
+		slog.InfoContext(ctx, "migration applied", "version", applied.Version, "name", applied.Name, "duration", time.Since(start))
+		done = append(done, *applied)
+	}
+}
+
+// Down rolls back the last applied migrations, each one in its own transaction holding the lock
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	migrations, err := m.Load()
+	if err != nil {
+		return nil, err
+	}
+	files := map[int64]Migration{}
+	for _, v := range migrations {
+		files[v.Version] = v
+	}
+
+	done := []Migration{}
+	for len(done) < steps {
+		var reverted *Migration
+		err := m.withLock(ctx, func(tx *gorm.DB) error {
+			var record SchemaMigrations
+			if err := tx.Order("version DESC").Take(&record).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+			v, ok := files[record.Version]
+			if !ok {
+				return fmt.Errorf("file of migration %d_%s is missing", record.Version, record.Name)
+			}
+			if strings.TrimSpace(v.Down) == "" {
+				return fmt.Errorf("migration %d_%s can not be rolled back, it has no down file", v.Version, v.Name)
+			}
+
+			if err := exec(tx, v.Down); err != nil {
+				return fmt.Errorf("failed to roll back migration %d_%s: %w", v.Version, v.Name, err)
+			}
+			reverted = &v
+			return tx.Where("version = ?", v.Version).Delete(&SchemaMigrations{}).Error
+		})
+		if err != nil {
+			return done, err
+		}
+		if reverted == nil {
+			break
+		}
+		slog.InfoContext(ctx, "migration rolled back", "version", reverted.Version, "name", reverted.Name)
+		done = append(done, *reverted)
+	}
+	return done, nil
+}
+
+// Status lists every migration known by files or schema table
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	DB := m.DB.WithContext(ctx)
+	migrations, err := m.Load()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(DB)
+	if err != nil {
+		return nil, err
+	}
+
+	status := []Status{}
+	for _, v := range migrations {
+		s := Status{Version: v.Version, Name: v.Name}
+		if record, ok := applied[v.Version]; ok {
+			s.AppliedAt = &record.AppliedAt
+			s.Modified = record.Checksum != v.Checksum
+			delete(applied, v.Version)
+		}
+		status = append(status, s)
+	}
+	for _, record := range applied {
+		status = append(status, Status{Version: record.Version, Name: record.Name, AppliedAt: &record.AppliedAt, Missing: true})
+	}
+	sort.Slice(status, func(i, j int) bool {
+		return status[i].Version < status[j].Version
+	})
+	return status, nil
+}
+
+// Pending returns migrations not applied yet without taking the lock
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	return m.pending(m.DB.WithContext(ctx), false)
+}
+
+// pending compares files with schema table, modified file fails when strict
+func (m *Migrator) pending(DB *gorm.DB, strict bool) ([]Migration, error) {
+	migrations, err := m.Load()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(DB)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []Migration{}
+	for _, v := range migrations {
+		record, ok := applied[v.Version]
+		if !ok {
+			pending = append(pending, v)
+			continue
+		}
+		if strict && record.Checksum != v.Checksum {
+			return nil, fmt.Errorf("migration %d_%s is modified after applied, create new migration instead", v.Version, v.Name)
+		}
+	}
+	return pending, nil
+}
+
+func (m *Migrator) applied(DB *gorm.DB) (map[int64]SchemaMigrations, error) {
+	applied := map[int64]SchemaMigrations{}
+	if !DB.Migrator().HasTable(&SchemaMigrations{}) {
+		return applied, nil
+	}
+
+	var records []SchemaMigrations
+	if err := DB.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	for _, v := range records {
+		applied[v.Version] = v
+	}
+	return applied, nil
+}
+
+// adoptBaseline marks baseline as applied on database created by AutoMigrate,
+// so existing tables are kept and only later migrations run, schema older than
+// baseline is refused since the columns it lacks would never be created
+func (m *Migrator) adoptBaseline(DB *gorm.DB) error {
+	var count int64
+	if err := DB.Model(&SchemaMigrations{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 || !DB.Migrator().HasTable("users") {
+		return nil
+	}
+
+	migrations, err := m.Load()
+	if err != nil {
+		return err
+	}
+	for _, v := range migrations {
+		if v.Version != baselineVersion {
+			continue
+		}
+		missing, err := missingColumns(DB, v.Up)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("existing schema is older than baseline, missing %s, upgrade it to the last auto migrated release first", strings.Join(missing, ", "))
+		}
+		slog.Info("existing schema found, baseline is marked as applied", "version", v.Version)
+		return DB.Create(&SchemaMigrations{Version: v.Version, Name: v.Name, Checksum: v.Checksum, AppliedAt: time.Now()}).Error
+	}
+	return nil
+}
+
+// missingColumns lists table.column created by baseline but absent in current schema
+func missingColumns(DB *gorm.DB, baseline string) ([]string, error) {
+	var rows []struct {
+		TableName  string
+		ColumnName string
+	}
+	if err := DB.Raw("SELECT table_name, column_name FROM information_schema.columns WHERE table_schema = current_schema()").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	existing := map[string]bool{}
+	for _, v := range rows {
+		existing[v.TableName+"."+v.ColumnName] = true
+	}
+
+	missing := []string{}
+	for _, table := range createTable.FindAllStringSubmatch(baseline, -1) {
+		for _, column := range columnName.FindAllStringSubmatch(table[2], -1) {
+			if key := table[1] + "." + column[1]; !existing[key] {
+				missing = append(missing, key)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// withLock runs fn in a transaction holding transaction level advisory lock, so fn needs no
+// connection besides the transaction and the lock is released by commit or rollback on any error
+func (m *Migrator) withLock(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockKey).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if err := tx.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT PRIMARY KEY, name VARCHAR(255) NOT NULL, checksum VARCHAR(64) NOT NULL, applied_at TIMESTAMP NOT NULL)").Error; err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// exec runs file content as is, bypassing placeholder parsing so it may contain many statements
+func exec(tx *gorm.DB, query string) error {
+	_, err := tx.Statement.ConnPool.ExecContext(tx.Statement.Context, query)
+	return err
+}
+
+// Create writes empty up and down files of new migration, versioned by current time
+func Create(dir string, name string) (string, string, error) {
+	name = strings.Trim(nameInvalid.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", "", errors.New("migration name is required")
+	}
+
+	version := time.Now().UTC().Format("20060102150405")
+	up := filepath.Join(dir, fmt.Sprintf("%s_%s.up.sql", version, name))
+	down := filepath.Join(dir, fmt.Sprintf("%s_%s.down.sql", version, name))
+	for path, content := range map[string]string{
+		up:   fmt.Sprintf("-- %s: apply changes\n", name),
+		down: fmt.Sprintf("-- %s: revert changes of the up file\n", name),
+	} {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return "", "", err
+		}
+		if _, err := file.WriteString(content); err != nil {
+			file.Close()
+			return "", "", err
+		}
+		if err := file.Close(); err != nil {
+			return "", "", err
+		}
+	}
+	return up, down, nil
+}
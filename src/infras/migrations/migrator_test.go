@@ -0,0 +1,85 @@
+package migrations
+
+import (
+	"context"
+	"database/sql/driver"
+	"kiraform/src/infras/dbtest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestUpWithSingleConnection(t *testing.T) {
+	source := fstest.MapFS{
+		"20261101000000_add_phone.up.sql":   {Data: []byte("ALTER TABLE users ADD COLUMN phone varchar(20);")},
+		"20261101000000_add_phone.down.sql": {Data: []byte("ALTER TABLE users DROP COLUMN phone;")},
+		"20261102000000_add_bio.up.sql":     {Data: []byte("ALTER TABLE users ADD COLUMN bio text;")},
+	}
+	migrator := &Migrator{Source: source}
+	files, err := migrator.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	// schema table answers with migrations inserted so far
+	var recorder *dbtest.Recorder
+	DB, recorder := dbtest.Open(t, func(query string) *dbtest.Rows {
+		switch {
+		case strings.Contains(query, "information_schema.tables"):
+			return &dbtest.Rows{Columns: []string{"count"}, Values: [][]driver.Value{{int64(1)}}}
+		case strings.Contains(query, `count(*) FROM "schema_migrations"`):
+			return &dbtest.Rows{Columns: []string{"count"}, Values: [][]driver.Value{{int64(1)}}}
+		case strings.Contains(query, `FROM "schema_migrations"`):
+			rows := &dbtest.Rows{Columns: []string{"version", "name", "checksum", "applied_at"}}
+			for i, v := range files {
+				if i < inserted(recorder) {
+					rows.Values = append(rows.Values, []driver.Value{v.Version, v.Name, v.Checksum, time.Now()})
+				}
+			}
+			return rows
+		}
+		return nil
+	})
+	sqlDB, err := DB.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	migrator.DB = DB
+
+	// lock and migration share one connection, so the only one of the pool is enough
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if len(applied) != 2 || applied[0].Name != "add_phone" || applied[1].Name != "add_bio" {
+		t.Fatalf("got applied %v, want add_phone then add_bio", applied)
+	}
+
+	locks := 0
+	for _, v := range recorder.Queries() {
+		if strings.Contains(v, "pg_advisory_xact_lock") {
+			locks++
+		}
+		if strings.Contains(v, "pg_advisory_lock") {
+			t.Fatalf("session lock %q outlives failed migration on pooled connection", v)
+		}
+	}
+	if locks != 3 {
+		t.Fatalf("got %d locked transactions, want one per migration and one finding nothing left", locks)
+	}
+}
+
+// inserted counts rows written into schema table
+func inserted(recorder *dbtest.Recorder) int {
+	count := 0
+	for _, v := range recorder.Queries() {
+		if strings.HasPrefix(v, `INSERT INTO "schema_migrations"`) {
+			count++
+		}
+	}
+	return count
+}
@@ -2,34 +2,80 @@ package migrations
 
 import (
 	"fmt"
+	"kiraform/src/applications/models"
 	"kiraform/src/infras/migrations/seeders"
-	"log"
+	"log/slog"
+	"os"
+	"slices"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// SeederNames are available seeders in the order they run
+var SeederNames = []string{"roles", "forms", "packages", "users"}
+
 func Seeder(DB *gorm.DB) {
-	var err error
+	if err := Seed(DB, nil); err != nil {
+		slog.Error("failed to seed data", "error", err)
+		os.Exit(1)
+	}
+}
+
+// CheckSeeders makes sure every selected seeder exists
+func CheckSeeders(only []string) error {
+	for _, v := range only {
+		if !slices.Contains(SeederNames, v) {
+			return fmt.Errorf("unknown seeder %q, available seeders are %v", v, SeederNames)
+		}
+	}
+	return nil
+}
+
+// Seed runs selected seeders, empty selection runs all of them
+func Seed(DB *gorm.DB, only []string) error {
+	if err := CheckSeeders(only); err != nil {
+		return err
+	}
+	selected := func(name string) bool {
+		return len(only) == 0 || slices.Contains(only, name)
+	}
 
-	roleID, err := seeders.Roles(DB)
-	if err != nil {
-		log.Fatal("Error while seeding data roles")
+	var roleID uuid.UUID
+	if selected("roles") {
+		id, err := seeders.Roles(DB)
+		if err != nil {
+			return fmt.Errorf("failed to seed roles: %w", err)
+		}
+		roleID = id
 	}
 
-	err = seeders.Forms(DB)
-	if err != nil {
-		log.Fatal("Error while seeding data forms")
+	if selected("forms") {
+		if err := seeders.Forms(DB); err != nil {
+			return fmt.Errorf("failed to seed forms: %w", err)
+		}
 	}
 
-	err = seeders.Packages(DB)
-	if err != nil {
-		log.Fatal("Error while seeding data packages")
+	if selected("packages") {
+		if err := seeders.Packages(DB); err != nil {
+			return fmt.Errorf("failed to seed packages: %w", err)
+		}
 	}
 
-	err = seeders.Users(DB, roleID)
-	if err != nil {
-		log.Fatal("Error while seeding data users")
+	if selected("users") {
+		// users need admin role which may be seeded before
+		if roleID == uuid.Nil {
+			var role models.Roles
+			if err := DB.Where("name = ?", models.RoleAdmin).First(&role).Error; err != nil {
+				return fmt.Errorf("failed to find admin role, seed roles first: %w", err)
+			}
+			roleID = role.ID
+		}
+		if err := seeders.Users(DB, roleID); err != nil {
+			return fmt.Errorf("failed to seed users: %w", err)
+		}
 	}
 
-	fmt.Println("Seeding data is complete!")
+	slog.Info("seeding data is complete")
+	return nil
 }
@@ -2,6 +2,7 @@ package seeders
 
 import (
 	"kiraform/src/applications/models"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,12 +11,13 @@ import (
 
 func Roles(DB *gorm.DB) (uuid.UUID, error) {
 	roles := []models.Roles{
-		{ID: uuid.New(), Name: "admin", Description: "Administrator", CreatedAt: time.Now()},
-		{ID: uuid.New(), Name: "user", Description: "User", CreatedAt: time.Now()},
+		{ID: uuid.New(), Name: models.RoleAdmin, Description: "Administrator", Permissions: strings.Join(models.Permissions, ","), CreatedAt: time.Now()},
+		{ID: uuid.New(), Name: models.RoleUser, Description: "User", CreatedAt: time.Now()},
 	}
 
-	for _, data := range roles {
-		if err := DB.FirstOrCreate(&data, models.Roles{Name: data.Name}).Error; err != nil {
+	// existing role is loaded back, so returned id is the stored one
+	for i := range roles {
+		if err := DB.FirstOrCreate(&roles[i], models.Roles{Name: roles[i].Name}).Error; err != nil {
 			return uuid.Nil, err
 		}
 	}
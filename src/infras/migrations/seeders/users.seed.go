@@ -30,7 +30,9 @@ func Users(DB *gorm.DB, roleID uuid.UUID) error {
 				RoleID:    roleID,
 				CreatedAt: time.Now(),
 			}
-			DB.Create(&userRole)
+			if err := DB.FirstOrCreate(&userRole, models.UserRoles{UserID: data.ID}).Error; err != nil {
+				return err
+			}
 		}
 	}
 
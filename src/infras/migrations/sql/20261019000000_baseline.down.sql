@@ -0,0 +1,27 @@
+-- drop every table of baseline
+
+DROP TABLE IF EXISTS "audit_logs" CASCADE;
+DROP TABLE IF EXISTS "billing_details" CASCADE;
+DROP TABLE IF EXISTS "billings" CASCADE;
+DROP TABLE IF EXISTS "campaign_visits" CASCADE;
+DROP TABLE IF EXISTS "form_detail_entries" CASCADE;
+DROP TABLE IF EXISTS "form_entries" CASCADE;
+DROP TABLE IF EXISTS "store_product_images" CASCADE;
+DROP TABLE IF EXISTS "store_products" CASCADE;
+DROP TABLE IF EXISTS "store_product_categories" CASCADE;
+DROP TABLE IF EXISTS "store_users" CASCADE;
+DROP TABLE IF EXISTS "stores" CASCADE;
+DROP TABLE IF EXISTS "workspace_users" CASCADE;
+DROP TABLE IF EXISTS "campaign_spam_settings" CASCADE;
+DROP TABLE IF EXISTS "campaign_form_attributes" CASCADE;
+DROP TABLE IF EXISTS "campaign_forms" CASCADE;
+DROP TABLE IF EXISTS "campaign_seos" CASCADE;
+DROP TABLE IF EXISTS "forms" CASCADE;
+DROP TABLE IF EXISTS "campaigns" CASCADE;
+DROP TABLE IF EXISTS "workspaces" CASCADE;
+DROP TABLE IF EXISTS "user_packages" CASCADE;
+DROP TABLE IF EXISTS "user_roles" CASCADE;
+DROP TABLE IF EXISTS "packages" CASCADE;
+DROP TABLE IF EXISTS "roles" CASCADE;
+DROP TABLE IF EXISTS "user_profiles" CASCADE;
+DROP TABLE IF EXISTS "users" CASCADE;
@@ -0,0 +1,65 @@
+-- schema of models before versioned migrations, generated by `migrate baseline`
+
+CREATE TABLE "users" ("id" uuid,"user_identity" varchar(100),"email" varchar(100) NOT NULL,"password" varchar(100) NOT NULL,"fullname" varchar(255) NOT NULL,"is_active" boolean DEFAULT false,"deleted" boolean DEFAULT false,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "uni_users_user_identity" UNIQUE ("user_identity"),CONSTRAINT "uni_users_email" UNIQUE ("email"));
+CREATE TABLE "user_profiles" ("id" uuid,"user_id" uuid NOT NULL,"first_name" varchar(100) NOT NULL,"middle_name" varchar(100),"last_name" varchar(100),"address" varchar(255),"phone" varchar(20),"province" varchar(50),"city" varchar(70),"district" varchar(70),"sub_district" varchar(70),"avatar" varchar(100),"remark" varchar(255),"deleted" boolean DEFAULT false,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "fk_user_profiles_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE);
+CREATE TABLE "roles" ("id" uuid,"name" varchar(50) NOT NULL,"description" varchar(100),"deleted" boolean DEFAULT false,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_roles_name" ON "roles" ("name");
+CREATE TABLE "packages" ("id" uuid,"code" varchar(50) NOT NULL,"name" varchar(100) NOT NULL,"description" text,"deleted" boolean DEFAULT false,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_packages_code" ON "packages" ("code");
+CREATE TABLE "user_roles" ("id" uuid,"user_id" uuid NOT NULL,"role_id" uuid NOT NULL,"deleted" boolean DEFAULT false,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "fk_user_roles_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE,CONSTRAINT "fk_user_roles_role" FOREIGN KEY ("role_id") REFERENCES "roles"("id") ON DELETE CASCADE);
+CREATE TABLE "user_packages" ("id" uuid,"user_id" uuid NOT NULL,"package_id" uuid NOT NULL,"active_date" timestamp,"expire_date" timestamp,"remark" text,"is_active" boolean DEFAULT false,"deleted" boolean DEFAULT false,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "fk_user_packages_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE,CONSTRAINT "fk_user_packages_package" FOREIGN KEY ("package_id") REFERENCES "packages"("id") ON DELETE CASCADE);
+CREATE TABLE "workspaces" ("id" uuid,"key" varchar(100) NOT NULL,"title" varchar(255) NOT NULL,"slug" varchar(255) NOT NULL,"description" text,"thumbnail" varchar(100),"is_publish" boolean DEFAULT false,"timezone" varchar(50) DEFAULT 'UTC',"deleted" boolean DEFAULT false,"deleted_at" timestamp,"deleted_by" uuid,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "uni_workspaces_key" UNIQUE ("key"));
+CREATE INDEX IF NOT EXISTS "idx_workspaces_deleted_at" ON "workspaces" ("deleted_at");
+CREATE TABLE "campaigns" ("id" uuid,"workspace_id" uuid NOT NULL,"key" varchar(100) NOT NULL,"title" varchar(255) NOT NULL,"slug" varchar(255) NOT NULL,"description" text,"thumbnail" varchar(100),"is_publish" boolean DEFAULT false,"deleted" boolean DEFAULT false,"deleted_at" timestamp,"deleted_by" uuid,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "fk_campaigns_workspace" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id") ON DELETE CASCADE,CONSTRAINT "uni_campaigns_key" UNIQUE ("key"));
+CREATE INDEX IF NOT EXISTS "idx_campaigns_deleted_at" ON "campaigns" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_campaigns_workspace_created" ON "campaigns" ("workspace_id","created_at");
+CREATE TABLE "forms" ("id" uuid,"code" varchar(20) NOT NULL,"name" varchar(50) NOT NULL,"description" text,"deleted" boolean DEFAULT false,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_forms_code" ON "forms" ("code");
+CREATE TABLE "campaign_seos" ("id" uuid,"campaign_id" uuid NOT NULL,"platform" varchar(50) NOT NULL,"event" varchar(50) NOT NULL,"access_key" text NOT NULL,"deleted" boolean DEFAULT false,"deleted_at" timestamp,"deleted_by" uuid,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "fk_campaign_seos_campaign" FOREIGN KEY ("campaign_id") REFERENCES "campaigns"("id") ON DELETE CASCADE);
+CREATE INDEX IF NOT EXISTS "idx_campaign_seos_deleted_at" ON "campaign_seos" ("deleted_at");
+CREATE TABLE "campaign_forms" ("id" uuid,"campaign_id" uuid NOT NULL,"form_id" uuid NOT NULL,"title" varchar(100) NOT NULL,"description" text,"placeholder" varchar(150),"default_value" varchar(150),"is_required" boolean DEFAULT false,"is_multiple" boolean DEFAULT false,"deleted" boolean DEFAULT false,"deleted_at" timestamp,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "fk_campaign_forms_campaign" FOREIGN KEY ("campaign_id") REFERENCES "campaigns"("id") ON DELETE CASCADE,CONSTRAINT "fk_campaign_forms_form" FOREIGN KEY ("form_id") REFERENCES "forms"("id") ON DELETE CASCADE);
+CREATE TABLE "campaign_form_attributes" ("id" uuid,"campaign_form_id" uuid NOT NULL,"label" varchar(50) NOT NULL,"value" varchar(50) NOT NULL,"is_default" boolean DEFAULT false,"deleted" boolean DEFAULT false,"deleted_at" timestamp,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "fk_campaign_form_attributes_campaign_form" FOREIGN KEY ("campaign_form_id") REFERENCES "campaign_forms"("id") ON DELETE CASCADE);
+CREATE TABLE "campaign_spam_settings" ("id" uuid,"campaign_id" uuid NOT NULL,"honeypot" boolean DEFAULT false,"min_submit_seconds" bigint DEFAULT 0,"captcha" boolean DEFAULT false,"duplicate_check" boolean DEFAULT false,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "fk_campaign_spam_settings_campaign" FOREIGN KEY ("campaign_id") REFERENCES "campaigns"("id") ON DELETE CASCADE,CONSTRAINT "uni_campaign_spam_settings_campaign_id" UNIQUE ("campaign_id"));
+CREATE TABLE "workspace_users" ("id" uuid,"workspace_id" uuid NOT NULL,"user_id" uuid NOT NULL,"status" char(2) DEFAULT 'S1',"deleted" boolean DEFAULT false,"created_at" timestamp,"updated_at" timestamp DEFAULT null,PRIMARY KEY ("id"),CONSTRAINT "fk_workspace_users_workspace" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id") ON DELETE CASCADE,CONSTRAINT "fk_workspace_users_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE);
+CREATE TABLE "stores" ("id" uuid,"key" text,"name" varchar NOT NULL,"slug" varchar NOT NULL,"category" varchar NOT NULL,"description" text,"thumbnail" text,"operational_hour" text,"address" text,"phone" varchar(20),"email" varchar(70),"status" char(2) NOT NULL,"deleted" boolean DEFAULT false,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "uni_stores_key" UNIQUE ("key"));
+CREATE TABLE "store_users" ("id" uuid,"store_id" uuid NOT NULL,"user_id" uuid NOT NULL,"remark" text,"deleted" boolean DEFAULT false,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "fk_store_users_store" FOREIGN KEY ("store_id") REFERENCES "stores"("id") ON DELETE CASCADE,CONSTRAINT "fk_store_users_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE);
+CREATE TABLE "store_product_categories" ("id" uuid,"store_id" uuid NOT NULL,"name" varchar,"description" varchar,"deleted" boolean DEFAULT false,"deleted_at" timestamp,"deleted_by" uuid,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "fk_store_product_categories_store" FOREIGN KEY ("store_id") REFERENCES "stores"("id") ON DELETE CASCADE);
+CREATE INDEX IF NOT EXISTS "idx_store_product_categories_deleted_at" ON "store_product_categories" ("deleted_at");
+CREATE TABLE "store_products" ("id" uuid,"store_id" uuid NOT NULL,"category_id" uuid NOT NULL,"campaign_id" uuid,"key" varchar NOT NULL,"name" varchar NOT NULL,"slug" varchar NOT NULL,"description" text,"price" numeric DEFAULT 0,"status" char(2) DEFAULT 'S1',"deleted" boolean DEFAULT false,"deleted_at" timestamp,"deleted_by" uuid,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "fk_store_products_store" FOREIGN KEY ("store_id") REFERENCES "stores"("id") ON DELETE CASCADE,CONSTRAINT "fk_store_products_category" FOREIGN KEY ("category_id") REFERENCES "store_product_categories"("id") ON DELETE CASCADE,CONSTRAINT "fk_store_products_campaign" FOREIGN KEY ("campaign_id") REFERENCES "campaigns"("id") ON DELETE CASCADE,CONSTRAINT "uni_store_products_key" UNIQUE ("key"));
+CREATE INDEX IF NOT EXISTS "idx_store_products_deleted_at" ON "store_products" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_store_products_store_created" ON "store_products" ("store_id","created_at");
+CREATE TABLE "store_product_images" ("id" uuid,"store_product_id" uuid NOT NULL,"file_name" varchar,"file_ext" varchar,"file_size" varchar,"file_path" varchar,"deleted" boolean DEFAULT false,"deleted_at" timestamp,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "fk_store_product_images_store_product" FOREIGN KEY ("store_product_id") REFERENCES "store_products"("id"));
+CREATE TABLE "form_entries" ("id" uuid,"user_id" uuid,"campaign_id" uuid NOT NULL,"product_id" uuid,"status" char(2) DEFAULT 'S1',"remark" text,"spam_reason" varchar(255),"content_hash" char(64),"reviewed_by" uuid,"reviewed_at" timestamp,"deleted" boolean DEFAULT false,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "fk_form_entries_reviewer" FOREIGN KEY ("reviewed_by") REFERENCES "users"("id") ON DELETE SET NULL,CONSTRAINT "fk_form_entries_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE,CONSTRAINT "fk_form_entries_campaign" FOREIGN KEY ("campaign_id") REFERENCES "campaigns"("id") ON DELETE CASCADE,CONSTRAINT "fk_form_entries_product" FOREIGN KEY ("product_id") REFERENCES "store_products"("id") ON DELETE CASCADE);
+CREATE INDEX IF NOT EXISTS "idx_form_entries_campaign_hash" ON "form_entries" ("campaign_id","content_hash");
+CREATE INDEX IF NOT EXISTS "idx_form_entries_campaign_created" ON "form_entries" ("campaign_id","created_at");
+CREATE INDEX IF NOT EXISTS "idx_form_entries_user_created" ON "form_entries" ("user_id","created_at");
+CREATE TABLE "form_detail_entries" ("id" uuid,"form_entry_id" uuid NOT NULL,"campaign_form_id" uuid NOT NULL,"campaign_form_attribute_id" uuid,"value" text,"deleted" boolean DEFAULT false,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "fk_form_detail_entries_form_entry" FOREIGN KEY ("form_entry_id") REFERENCES "form_entries"("id") ON DELETE Cascade,CONSTRAINT "fk_form_detail_entries_campaign_form" FOREIGN KEY ("campaign_form_id") REFERENCES "campaign_forms"("id") ON DELETE CASCADE);
+CREATE TABLE "campaign_visits" ("id" uuid,"campaign_id" uuid NOT NULL,"visit_date" date NOT NULL,"total" bigint DEFAULT 0,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "fk_campaign_visits_campaign" FOREIGN KEY ("campaign_id") REFERENCES "campaigns"("id") ON DELETE CASCADE);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_campaign_visit_date" ON "campaign_visits" ("campaign_id","visit_date");
+CREATE TABLE "billings" ("id" uuid,"user_id" uuid NOT NULL,"billing_number" varchar(50) NOT NULL,"total_price" numeric DEFAULT 0,"total_qty" numeric DEFAULT 0,"tax" numeric DEFAULT 0,"discount" numeric DEFAULT 0,"grand_total" numeric DEFAULT 0,"remark" text,"status" char(2) NOT NULL DEFAULT 'S1',"deleted" boolean DEFAULT false,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "fk_billings_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE);
+CREATE TABLE "billing_details" ("id" uuid,"billing_id" uuid NOT NULL,"item" varchar(120) NOT NULL,"qty" numeric DEFAULT 0,"total" numeric DEFAULT 0,"remark" text,"deleted" boolean DEFAULT false,"created_at" timestamp,"updated_at" timestamp,PRIMARY KEY ("id"),CONSTRAINT "fk_billing_details_billing" FOREIGN KEY ("billing_id") REFERENCES "billings"("id") ON DELETE CASCADE);
+CREATE TABLE "audit_logs" ("id" uuid,"workspace_id" uuid,"store_id" uuid,"actor_id" uuid,"action" varchar(20) NOT NULL,"entity_type" varchar(50) NOT NULL,"entity_id" varchar(50) NOT NULL,"before" jsonb,"after" jsonb,"changes" jsonb,"ip" varchar(45),"user_agent" text,"request_id" varchar(128),"created_at" timestamp,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_request_id" ON "audit_logs" ("request_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_entity_id" ON "audit_logs" ("entity_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_actor_id" ON "audit_logs" ("actor_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_store_created" ON "audit_logs" ("store_id","created_at");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_workspace_created" ON "audit_logs" ("workspace_id","created_at");
+COMMENT ON COLUMN "workspaces"."key" IS 'Generate by system';
+COMMENT ON COLUMN "workspaces"."timezone" IS 'IANA timezone used for analytics';
+COMMENT ON COLUMN "campaigns"."key" IS 'Generate by system';
+COMMENT ON COLUMN "campaign_forms"."deleted_at" IS 'Same as deleted_at of parent when deleted together';
+COMMENT ON COLUMN "campaign_form_attributes"."deleted_at" IS 'Same as deleted_at of parent when deleted together';
+COMMENT ON COLUMN "campaign_spam_settings"."min_submit_seconds" IS '0=DISABLED';
+COMMENT ON COLUMN "workspace_users"."status" IS 'S1=INVITED,S2=REQUESTED,S3=APPROVED,S4=REJECTED,S5=OWNER';
+COMMENT ON COLUMN "stores"."status" IS 'S1=PENDING,S2=ACTIVE,S3=INACTIVE';
+COMMENT ON COLUMN "store_products"."status" IS 'S1=DRAFT';
+COMMENT ON COLUMN "store_product_images"."deleted_at" IS 'Same as deleted_at of parent when deleted together';
+COMMENT ON COLUMN "form_entries"."status" IS 'S1=PENDING,S2=APPROVED';
+COMMENT ON COLUMN "form_entries"."content_hash" IS 'Fingerprint of answers';
+COMMENT ON COLUMN "form_detail_entries"."campaign_form_attribute_id" IS 'ID for form attribute, it can be null for form type that have no attributes';
+COMMENT ON COLUMN "campaign_visits"."visit_date" IS 'Date in workspace timezone';
+COMMENT ON COLUMN "billings"."billing_number" IS 'Running number generated by system';
+COMMENT ON COLUMN "billings"."status" IS 'S1=PENDING,S2=PAID,S3=CANCELED';
+COMMENT ON COLUMN "audit_logs"."action" IS 'create,update,delete';
+COMMENT ON COLUMN "audit_logs"."changes" IS 'Changed fields with old and new value';
+CREATE INDEX IF NOT EXISTS idx_form_detail_entries_value_fts ON form_detail_entries USING GIN (to_tsvector('simple', COALESCE(value, '')));
@@ -0,0 +1,14 @@
+CREATE TABLE "signing_keys" (
+    "id" varchar(64),
+    "algorithm" varchar(10) NOT NULL,
+    "private_key" text NOT NULL,
+    "public_key" text NOT NULL,
+    "created_at" timestamp NOT NULL,
+    "retired_at" timestamp,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_signing_keys_retired_at" ON "signing_keys" ("retired_at");
+-- only one key signs new tokens at a time
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_signing_keys_current" ON "signing_keys" ((retired_at IS NULL)) WHERE retired_at IS NULL;
+COMMENT ON COLUMN "signing_keys"."algorithm" IS 'RS256,EdDSA';
+COMMENT ON COLUMN "signing_keys"."private_key" IS 'Encrypted by secret key';
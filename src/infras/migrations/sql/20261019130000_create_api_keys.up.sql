@@ -0,0 +1,22 @@
+CREATE TABLE "api_keys" (
+    "id" uuid,
+    "workspace_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "name" varchar(100) NOT NULL,
+    "prefix" varchar(16) NOT NULL,
+    "key_hash" char(64) NOT NULL,
+    "scopes" varchar(255) NOT NULL,
+    "expires_at" timestamp NOT NULL,
+    "last_used_at" timestamp,
+    "revoked_at" timestamp,
+    "created_at" timestamp,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "uni_api_keys_key_hash" UNIQUE ("key_hash"),
+    CONSTRAINT "fk_api_keys_workspace" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_api_keys_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_api_keys_workspace" ON "api_keys" ("workspace_id");
+COMMENT ON COLUMN "api_keys"."user_id" IS 'Creator, key acts on behalf of this user';
+COMMENT ON COLUMN "api_keys"."prefix" IS 'Shown to tell keys apart';
+COMMENT ON COLUMN "api_keys"."key_hash" IS 'SHA-256 of key';
+COMMENT ON COLUMN "api_keys"."scopes" IS 'Comma separated scopes';
@@ -0,0 +1,3 @@
+ALTER TABLE "workspaces" DROP COLUMN IF EXISTS "require_two_factor";
+DROP TABLE IF EXISTS "user_recovery_codes";
+DROP TABLE IF EXISTS "user_two_factors";
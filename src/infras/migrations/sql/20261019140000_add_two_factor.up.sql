@@ -0,0 +1,30 @@
+CREATE TABLE "user_two_factors" (
+    "id" uuid,
+    "user_id" uuid NOT NULL,
+    "secret" text NOT NULL,
+    "confirmed_at" timestamp,
+    "last_used_step" bigint DEFAULT 0,
+    "created_at" timestamp,
+    "updated_at" timestamp,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "uni_user_two_factors_user_id" UNIQUE ("user_id"),
+    CONSTRAINT "fk_user_two_factors_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE
+);
+COMMENT ON COLUMN "user_two_factors"."secret" IS 'TOTP secret encrypted by secret key';
+COMMENT ON COLUMN "user_two_factors"."confirmed_at" IS 'Null until first code is confirmed';
+COMMENT ON COLUMN "user_two_factors"."last_used_step" IS 'Time step of last accepted code, refuses replay';
+
+CREATE TABLE "user_recovery_codes" (
+    "id" uuid,
+    "user_id" uuid NOT NULL,
+    "code_hash" char(64) NOT NULL,
+    "used_at" timestamp,
+    "created_at" timestamp,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_user_recovery_codes_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_user_recovery_codes_user_id" ON "user_recovery_codes" ("user_id");
+COMMENT ON COLUMN "user_recovery_codes"."code_hash" IS 'SHA-256 of code';
+
+ALTER TABLE "workspaces" ADD COLUMN "require_two_factor" bool DEFAULT false;
+COMMENT ON COLUMN "workspaces"."require_two_factor" IS 'Members must sign in with two-factor code';
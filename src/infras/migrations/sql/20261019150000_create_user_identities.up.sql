@@ -0,0 +1,15 @@
+CREATE TABLE "user_identities" (
+    "id" uuid,
+    "user_id" uuid NOT NULL,
+    "provider" varchar(50) NOT NULL,
+    "subject" varchar(255) NOT NULL,
+    "email" varchar(100),
+    "created_at" timestamp,
+    "last_login_at" timestamp,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_user_identities_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_identities_provider_subject" ON "user_identities" ("provider", "subject");
+CREATE INDEX IF NOT EXISTS "idx_user_identities_user_id" ON "user_identities" ("user_id");
+COMMENT ON COLUMN "user_identities"."subject" IS 'Subject of id token, stable id of user at provider';
+COMMENT ON COLUMN "user_identities"."email" IS 'Email at provider when identity was linked';
@@ -0,0 +1,18 @@
+CREATE TABLE "login_codes" (
+    "id" uuid,
+    "user_id" uuid NOT NULL,
+    "email" varchar(100) NOT NULL,
+    "code_hash" char(64) NOT NULL,
+    "expires_at" timestamp NOT NULL,
+    "used_at" timestamp,
+    "created_at" timestamp,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_login_codes_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_login_codes_email" ON "login_codes" ("email");
+COMMENT ON COLUMN "login_codes"."email" IS 'Lowercase email the code is requested for';
+COMMENT ON COLUMN "login_codes"."code_hash" IS 'SHA-256 of code';
+COMMENT ON COLUMN "login_codes"."used_at" IS 'Set when code or link is used, either works once';
+
+ALTER TABLE "users" ADD COLUMN "is_pending" boolean DEFAULT false;
+COMMENT ON COLUMN "users"."is_pending" IS 'Created by passwordless login, active once email is verified';
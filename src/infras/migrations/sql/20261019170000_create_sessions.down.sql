@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "login_histories";
+DROP TABLE IF EXISTS "user_sessions";
@@ -0,0 +1,37 @@
+CREATE TABLE "user_sessions" (
+    "id" uuid,
+    "user_id" uuid NOT NULL,
+    "method" varchar(50) NOT NULL,
+    "ip" varchar(45),
+    "user_agent" text,
+    "created_at" timestamp,
+    "last_seen_at" timestamp,
+    "expires_at" timestamp NOT NULL,
+    "revoked_at" timestamp,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_user_sessions_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_user_sessions_user_id" ON "user_sessions" ("user_id");
+COMMENT ON COLUMN "user_sessions"."method" IS 'password, email_code, magic_link or sso:<provider>';
+COMMENT ON COLUMN "user_sessions"."expires_at" IS 'Same as expiry of its access token';
+
+CREATE TABLE "login_histories" (
+    "id" uuid,
+    "user_id" uuid,
+    "email" varchar(100),
+    "method" varchar(50) NOT NULL,
+    "success" boolean NOT NULL,
+    "reason" varchar(100),
+    "ip" varchar(45),
+    "user_agent" text,
+    "device_hash" char(64),
+    "new_device" boolean DEFAULT false,
+    "session_id" uuid,
+    "created_at" timestamp,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_login_histories_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_login_histories_user_created" ON "login_histories" ("user_id", "created_at");
+COMMENT ON COLUMN "login_histories"."user_id" IS 'Null when email is unknown';
+COMMENT ON COLUMN "login_histories"."reason" IS 'Why login failed';
+COMMENT ON COLUMN "login_histories"."device_hash" IS 'SHA-256 of user agent, tells known device apart';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "idx_user_roles_user_role";
+ALTER TABLE "roles" DROP COLUMN IF EXISTS "permissions";
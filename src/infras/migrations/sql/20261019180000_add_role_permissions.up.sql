@@ -0,0 +1,12 @@
+ALTER TABLE "roles" ADD COLUMN "permissions" varchar(500) NOT NULL DEFAULT '';
+COMMENT ON COLUMN "roles"."permissions" IS 'Comma separated permissions';
+UPDATE "roles" SET "permissions" = 'users:manage,roles:manage,workspaces:manage,audit:read' WHERE LOWER("name") = 'admin';
+
+-- user can hold several roles, each of them once
+UPDATE "user_roles" SET "deleted" = true WHERE "id" IN (
+    SELECT "id" FROM (
+        SELECT "id", ROW_NUMBER() OVER (PARTITION BY "user_id", "role_id" ORDER BY "created_at") AS "rn"
+        FROM "user_roles" WHERE "deleted" = false
+    ) AS "duplicates" WHERE "rn" > 1
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_roles_user_role" ON "user_roles" ("user_id", "role_id") WHERE "deleted" = false;
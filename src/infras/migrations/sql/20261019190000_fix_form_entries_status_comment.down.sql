@@ -0,0 +1 @@
+COMMENT ON COLUMN "form_entries"."status" IS 'S1=PENDING,S2=APPROVED';
@@ -0,0 +1,2 @@
+-- baseline cut the comment at the first semicolon, which gorm reads as tag separator
+COMMENT ON COLUMN "form_entries"."status" IS 'S1=PENDING,S2=APPROVED,S3=REJECTED,S4=SPAM';
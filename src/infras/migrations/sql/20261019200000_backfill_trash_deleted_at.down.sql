@@ -0,0 +1 @@
+-- backfilled deleted_at can not be told apart from recorded one, nothing to revert
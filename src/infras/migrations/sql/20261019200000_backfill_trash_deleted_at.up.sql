@@ -0,0 +1,7 @@
+-- rows deleted before deleted_at was recorded start their trash retention now,
+-- so purge does not remove them right away by an old updated_at
+UPDATE "workspaces" SET "deleted_at" = (NOW() AT TIME ZONE 'UTC') WHERE "deleted" = true AND "deleted_at" IS NULL;
+UPDATE "campaigns" SET "deleted_at" = (NOW() AT TIME ZONE 'UTC') WHERE "deleted" = true AND "deleted_at" IS NULL;
+UPDATE "campaign_seos" SET "deleted_at" = (NOW() AT TIME ZONE 'UTC') WHERE "deleted" = true AND "deleted_at" IS NULL;
+UPDATE "store_product_categories" SET "deleted_at" = (NOW() AT TIME ZONE 'UTC') WHERE "deleted" = true AND "deleted_at" IS NULL;
+UPDATE "store_products" SET "deleted_at" = (NOW() AT TIME ZONE 'UTC') WHERE "deleted" = true AND "deleted_at" IS NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE "api_keys" ADD COLUMN "created_with_mfa" bool DEFAULT false;
+COMMENT ON COLUMN "api_keys"."created_with_mfa" IS 'Creator signed in with two-factor code, required by workspace requiring it';
@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"log/slog"
+	"time"
+)
+
+// FallbackStore counts in Primary and switches to Fallback for the calls Primary fails,
+// so login and code limits still hold on this instance while redis is down
+type FallbackStore struct {
+	Primary  Store
+	Fallback Store
+}
+
+func NewFallbackStore(primary Store, fallback Store) *FallbackStore {
+	return &FallbackStore{Primary: primary, Fallback: fallback}
+}
+
+func (s *FallbackStore) Hit(key string, window time.Duration) (int, time.Duration, error) {
+	count, ttl, err := s.Primary.Hit(key, window)
+	if err != nil {
+		slog.Error("rate limit store failed, counting in memory", "error", err)
+		return s.Fallback.Hit(key, window)
+	}
+	return count, ttl, nil
+}
+
+// Get returns the higher count of both stores, so hits counted in memory during outage are not lost
+// once primary is back
+func (s *FallbackStore) Get(key string) (int, time.Duration, error) {
+	fallbackCount, fallbackTTL, fallbackErr := s.Fallback.Get(key)
+	count, ttl, err := s.Primary.Get(key)
+	if err != nil {
+		slog.Error("rate limit store failed, counting in memory", "error", err)
+		return fallbackCount, fallbackTTL, fallbackErr
+	}
+	if fallbackErr == nil && fallbackCount > count {
+		return fallbackCount, fallbackTTL, nil
+	}
+	return count, ttl, nil
+}
+
+func (s *FallbackStore) Reset(key string) error {
+	fallbackErr := s.Fallback.Reset(key)
+	if err := s.Primary.Reset(key); err != nil {
+		return err
+	}
+	return fallbackErr
+}
+
+func (s *FallbackStore) Close() error {
+	fallbackErr := s.Fallback.Close()
+	if err := s.Primary.Close(); err != nil {
+		return err
+	}
+	return fallbackErr
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// brokenStore fails every call while down, otherwise counts in memory
+type brokenStore struct {
+	*MemoryStore
+	down bool
+}
+
+func (s *brokenStore) Hit(key string, window time.Duration) (int, time.Duration, error) {
+	if s.down {
+		return 0, 0, errors.New("connection refused")
+	}
+	return s.MemoryStore.Hit(key, window)
+}
+
+func (s *brokenStore) Get(key string) (int, time.Duration, error) {
+	if s.down {
+		return 0, 0, errors.New("connection refused")
+	}
+	return s.MemoryStore.Get(key)
+}
+
+func TestFallbackStoreKeepsCounting(t *testing.T) {
+	primary := &brokenStore{MemoryStore: NewMemoryStore()}
+	store := NewFallbackStore(primary, NewMemoryStore())
+	t.Cleanup(func() {
+		store.Close()
+	})
+
+	if count, _, err := store.Hit("login", time.Minute); err != nil || count != 1 {
+		t.Fatalf("got count %d error %v, want 1 counted by primary", count, err)
+	}
+
+	// outage does not lift the limit
+	primary.down = true
+	for want := 1; want <= 3; want++ {
+		count, _, err := store.Hit("login", time.Minute)
+		if err != nil || count != want {
+			t.Fatalf("got count %d error %v, want %d counted in memory", count, err, want)
+		}
+	}
+	if count, _, err := store.Get("login"); err != nil || count != 3 {
+		t.Fatalf("got count %d error %v, want 3", count, err)
+	}
+
+	// once primary is back, hits of the outage still count
+	primary.down = false
+	if count, _, err := store.Get("login"); err != nil || count != 3 {
+		t.Fatalf("got count %d error %v, want higher count of both stores", count, err)
+	}
+	if err := store.Reset("login"); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if count, _, _ := store.Get("login"); count != 0 {
+		t.Fatalf("got count %d after reset, want 0", count)
+	}
+}
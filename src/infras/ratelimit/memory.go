@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	count   int
+	resetAt time.Time
+}
+
+// MemoryStore is a Store for single instance deployment
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	cleanedAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   map[string]*bucket{},
+		cleanedAt: time.Now(),
+	}
+}
+
+func (s *MemoryStore) Hit(key string, window time.Duration) (int, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.cleanup(now)
+
+	b, ok := s.buckets[key]
+	if !ok || !now.Before(b.resetAt) {
+		b = &bucket{resetAt: now.Add(window)}
+		s.buckets[key] = b
+	}
+	b.count++
+
+	return b.count, b.resetAt.Sub(now), nil
+}
+
+func (s *MemoryStore) Get(key string) (int, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	b, ok := s.buckets[key]
+	if !ok || !now.Before(b.resetAt) {
+		return 0, 0, nil
+	}
+
+	return b.count, b.resetAt.Sub(now), nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.buckets, key)
+	return nil
+}
+
+// Close does nothing, counters live in memory
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// cleanup removes expired buckets at most once a minute
+func (s *MemoryStore) cleanup(now time.Time) {
+	if now.Sub(s.cleanedAt) < time.Minute {
+		return
+	}
+
+	for k, v := range s.buckets {
+		if !now.Before(v.resetAt) {
+			delete(s.buckets, k)
+		}
+	}
+	s.cleanedAt = now
+}
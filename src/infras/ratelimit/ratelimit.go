@@ -0,0 +1,32 @@
+package ratelimit
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Store keeps hit counters of fixed window buckets
+type Store interface {
+	// Hit increments counter of key and returns current count with time left until the window resets
+	Hit(key string, window time.Duration) (int, time.Duration, error)
+
+	// Get returns current count of key without incrementing it
+	Get(key string) (int, time.Duration, error)
+
+	// Reset removes counter of key
+	Reset(key string) error
+
+	// Close releases connection of the store on shutdown
+	Close() error
+}
+
+// NewStore uses redis when address is provided, otherwise keep counters in memory,
+// redis falls back to memory when it fails so limits are never lifted by outage
+func NewStore(redisAddr string, redisPass string) Store {
+	if redisAddr == "" {
+		return NewMemoryStore()
+	}
+
+	slog.Info("rate limit uses redis", "addr", redisAddr)
+	return NewFallbackStore(NewRedisStore(redisAddr, redisPass), NewMemoryStore())
+}
@@ -0,0 +1,242 @@
+package ratelimit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const redisTimeout = 2 * time.Second
+
+// RedisStore is a Store shared by multiple instances
+// it speaks plain RESP, so any redis compatible server can be used
+type RedisStore struct {
+	mu     sync.Mutex
+	addr   string
+	pass   string
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func NewRedisStore(addr string, pass string) *RedisStore {
+	return &RedisStore{
+		addr: addr,
+		pass: pass,
+	}
+}
+
+func (s *RedisStore) Hit(key string, window time.Duration) (int, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reply, err := s.do("INCR", key)
+	if err != nil {
+		return 0, 0, err
+	}
+	count, _ := reply.(int64)
+
+	// first hit starts the window
+	if count == 1 {
+		if _, err := s.do("PEXPIRE", key, strconv.FormatInt(window.Milliseconds(), 10)); err != nil {
+			return 0, 0, err
+		}
+		return int(count), window, nil
+	}
+
+	ttl, err := s.ttl(key)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// key without expiry will never reset, set it again
+	if ttl < 0 {
+		if _, err := s.do("PEXPIRE", key, strconv.FormatInt(window.Milliseconds(), 10)); err != nil {
+			return 0, 0, err
+		}
+		ttl = window
+	}
+
+	return int(count), ttl, nil
+}
+
+func (s *RedisStore) Get(key string) (int, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reply, err := s.do("GET", key)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	value, ok := reply.(string)
+	if !ok {
+		return 0, 0, nil
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ttl, err := s.ttl(key)
+	if err != nil {
+		return 0, 0, err
+	}
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	return count, ttl, nil
+}
+
+func (s *RedisStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.do("DEL", key)
+	return err
+}
+
+func (s *RedisStore) ttl(key string) (time.Duration, error) {
+	reply, err := s.do("PTTL", key)
+	if err != nil {
+		return 0, err
+	}
+	ms, _ := reply.(int64)
+	if ms < 0 {
+		return -1, nil
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// do sends single command and reads the reply, connection is dropped on any error
+func (s *RedisStore) do(args ...string) (any, error) {
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+
+	reply, err := s.roundTrip(args...)
+	if err != nil {
+		var replyErr redisError
+		if !errors.As(err, &replyErr) {
+			s.close()
+		}
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (s *RedisStore) connect() error {
+	if s.conn != nil {
+		return nil
+	}
+
+	conn, err := net.DialTimeout("tcp", s.addr, redisTimeout)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	s.reader = bufio.NewReader(conn)
+
+	if s.pass != "" {
+		if _, err := s.roundTrip("AUTH", s.pass); err != nil {
+			s.close()
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *RedisStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.close()
+	return nil
+}
+
+func (s *RedisStore) close() {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.conn = nil
+	s.reader = nil
+}
+
+func (s *RedisStore) roundTrip(args ...string) (any, error) {
+	if err := s.conn.SetDeadline(time.Now().Add(redisTimeout)); err != nil {
+		return nil, err
+	}
+
+	// write command as array of bulk strings
+	cmd := fmt.Sprintf("*%d\r\n", len(args))
+	for _, v := range args {
+		cmd += fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+	}
+	if _, err := s.conn.Write([]byte(cmd)); err != nil {
+		return nil, err
+	}
+
+	return readReply(s.reader)
+}
+
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// readReply parses one RESP reply, nil bulk string is returned as nil
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 {
+		return nil, errors.New("redis: invalid reply")
+	}
+	prefix, body := line[0], line[1:len(line)-2]
+
+	switch prefix {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:size]), nil
+	case '*':
+		size, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		items := make([]any, size)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+
+	return nil, fmt.Errorf("redis: unknown reply type %q", prefix)
+}
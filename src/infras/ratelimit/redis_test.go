@@ -0,0 +1,281 @@
+package ratelimit
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is a local stand-in speaking the commands RedisStore sends
+type fakeRedis struct {
+	listener net.Listener
+	pass     string
+
+	mu      sync.Mutex
+	values  map[string]int64
+	expires map[string]time.Time
+	conns   []net.Conn
+	auths   int
+}
+
+func newFakeRedis(t *testing.T, pass string) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	f := &fakeRedis{
+		listener: listener,
+		pass:     pass,
+		values:   map[string]int64{},
+		expires:  map[string]time.Time{},
+	}
+	go f.serve()
+	t.Cleanup(func() {
+		listener.Close()
+		f.dropConns()
+	})
+	return f
+}
+
+func (f *fakeRedis) addr() string {
+	return f.listener.Addr().String()
+}
+
+// dropConns closes every client connection, as a restarted server would
+func (f *fakeRedis) dropConns() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, conn := range f.conns {
+		conn.Close()
+	}
+	f.conns = nil
+}
+
+func (f *fakeRedis) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.conns = append(f.conns, conn)
+		f.mu.Unlock()
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	authed := f.pass == ""
+	for {
+		reply, err := readReply(reader)
+		if err != nil {
+			return
+		}
+		items, _ := reply.([]any)
+		args := make([]string, 0, len(items))
+		for _, v := range items {
+			s, _ := v.(string)
+			args = append(args, s)
+		}
+		if len(args) == 0 {
+			return
+		}
+
+		name := strings.ToUpper(args[0])
+		if !authed && name != "AUTH" {
+			fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+		if name == "AUTH" {
+			f.mu.Lock()
+			f.auths++
+			f.mu.Unlock()
+			if len(args) != 2 || args[1] != f.pass {
+				fmt.Fprint(conn, "-WRONGPASS invalid password\r\n")
+				continue
+			}
+			authed = true
+			fmt.Fprint(conn, "+OK\r\n")
+			continue
+		}
+		fmt.Fprint(conn, f.exec(name, args[1:]))
+	}
+}
+
+func (f *fakeRedis) exec(name string, args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := args[0]
+	if at, ok := f.expires[key]; ok && !time.Now().Before(at) {
+		delete(f.values, key)
+		delete(f.expires, key)
+	}
+	value, exists := f.values[key]
+
+	switch name {
+	case "INCR":
+		f.values[key] = value + 1
+		return fmt.Sprintf(":%d\r\n", value+1)
+	case "GET":
+		if !exists {
+			return "$-1\r\n"
+		}
+		s := strconv.FormatInt(value, 10)
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+	case "PEXPIRE":
+		if !exists {
+			return ":0\r\n"
+		}
+		ms, _ := strconv.ParseInt(args[1], 10, 64)
+		f.expires[key] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		return ":1\r\n"
+	case "PTTL":
+		if !exists {
+			return ":-2\r\n"
+		}
+		at, ok := f.expires[key]
+		if !ok {
+			return ":-1\r\n"
+		}
+		return fmt.Sprintf(":%d\r\n", time.Until(at).Milliseconds())
+	case "DEL":
+		if !exists {
+			return ":0\r\n"
+		}
+		delete(f.values, key)
+		delete(f.expires, key)
+		return ":1\r\n"
+	}
+	return fmt.Sprintf("-ERR unknown command '%s'\r\n", name)
+}
+
+func TestRedisStoreHit(t *testing.T) {
+	server := newFakeRedis(t, "")
+	store := NewRedisStore(server.addr(), "")
+	defer store.Close()
+
+	for want := 1; want <= 3; want++ {
+		count, ttl, err := store.Hit("ip:1.2.3.4", time.Minute)
+		if err != nil {
+			t.Fatalf("hit %d: %v", want, err)
+		}
+		if count != want {
+			t.Fatalf("hit %d: got count %d", want, count)
+		}
+		if ttl <= 0 || ttl > time.Minute {
+			t.Fatalf("hit %d: got ttl %s, want within the window", want, ttl)
+		}
+	}
+
+	count, ttl, err := store.Get("ip:1.2.3.4")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if count != 3 || ttl <= 0 {
+		t.Fatalf("get: got count %d ttl %s, want 3 with ttl left", count, ttl)
+	}
+
+	// other key has its own counter
+	if count, _, err := store.Hit("ip:5.6.7.8", time.Minute); err != nil || count != 1 {
+		t.Fatalf("hit of other key: got count %d err %v, want 1", count, err)
+	}
+}
+
+func TestRedisStoreWindowResets(t *testing.T) {
+	server := newFakeRedis(t, "")
+	store := NewRedisStore(server.addr(), "")
+	defer store.Close()
+
+	window := 50 * time.Millisecond
+	for range 2 {
+		if _, _, err := store.Hit("login", window); err != nil {
+			t.Fatalf("hit: %v", err)
+		}
+	}
+	time.Sleep(2 * window)
+
+	count, _, err := store.Get("login")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("got count %d after window, want 0", count)
+	}
+	if count, _, err := store.Hit("login", window); err != nil || count != 1 {
+		t.Fatalf("hit after window: got count %d err %v, want 1", count, err)
+	}
+}
+
+func TestRedisStoreReset(t *testing.T) {
+	server := newFakeRedis(t, "")
+	store := NewRedisStore(server.addr(), "")
+	defer store.Close()
+
+	if _, _, err := store.Hit("login_failed:a@example.com", time.Minute); err != nil {
+		t.Fatalf("hit: %v", err)
+	}
+	if err := store.Reset("login_failed:a@example.com"); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	count, ttl, err := store.Get("login_failed:a@example.com")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if count != 0 || ttl != 0 {
+		t.Fatalf("got count %d ttl %s after reset, want nothing", count, ttl)
+	}
+}
+
+func TestRedisStoreAuth(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+
+	store := NewRedisStore(server.addr(), "secret")
+	defer store.Close()
+	if count, _, err := store.Hit("key", time.Minute); err != nil || count != 1 {
+		t.Fatalf("hit with password: got count %d err %v, want 1", count, err)
+	}
+
+	wrong := NewRedisStore(server.addr(), "wrong")
+	defer wrong.Close()
+	if _, _, err := wrong.Hit("key", time.Minute); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Fatalf("hit with wrong password: got err %v, want WRONGPASS", err)
+	}
+}
+
+func TestRedisStoreReconnects(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	store := NewRedisStore(server.addr(), "secret")
+	defer store.Close()
+
+	if _, _, err := store.Hit("key", time.Minute); err != nil {
+		t.Fatalf("hit: %v", err)
+	}
+
+	// first command on a dropped connection fails, the next one dials and authenticates again
+	server.dropConns()
+	if _, _, err := store.Hit("key", time.Minute); err == nil {
+		t.Fatal("hit on dropped connection: got no error")
+	}
+	count, _, err := store.Hit("key", time.Minute)
+	if err != nil {
+		t.Fatalf("hit after reconnect: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("hit after reconnect: got count %d, want 2", count)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.auths != 2 {
+		t.Fatalf("got %d AUTH commands, want 2", server.auths)
+	}
+}
@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+var rowsAffected = attribute.Key("db.rows_affected")
+
+// GormPlugin creates span for every query, parent span is taken from query context
+type GormPlugin struct{}
+
+func NewGormPlugin() *GormPlugin {
+	return &GormPlugin{}
+}
+
+func (p *GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p *GormPlugin) Initialize(DB *gorm.DB) error {
+	cb := DB.Callback()
+	hooks := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for _, v := range hooks {
+		if err := v.before("tracing:before_"+v.operation, before(v.operation)); err != nil {
+			return err
+		}
+		if err := v.after("tracing:after_"+v.operation, after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func before(operation string) func(*gorm.DB) {
+	return func(DB *gorm.DB) {
+		_, span := Tracer().Start(DB.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationName(operation)),
+		)
+		DB.InstanceSet(spanKey, span)
+	}
+}
+
+func after(DB *gorm.DB) {
+	value, ok := DB.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	// statement is kept with placeholders, values are not recorded
+	span.SetAttributes(
+		semconv.DBCollectionName(DB.Statement.Table),
+		semconv.DBQueryText(DB.Statement.SQL.String()),
+		rowsAffected.Int64(DB.Statement.RowsAffected),
+	)
+	if DB.Error != nil && !errors.Is(DB.Error, gorm.ErrRecordNotFound) {
+		Fail(span, DB.Error)
+	}
+}
@@ -0,0 +1,136 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"kiraform/src/applications/apperrors"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// codes for http errors raised directly by echo or handlers
+var statusCodes = map[int]apperrors.Code{
+	http.StatusBadRequest:            apperrors.CodeBadRequest,
+	http.StatusUnauthorized:          apperrors.CodeUnauthorized,
+	http.StatusForbidden:             apperrors.CodeForbidden,
+	http.StatusNotFound:              apperrors.CodeNotFound,
+	http.StatusConflict:              apperrors.CodeConflict,
+	http.StatusUnprocessableEntity:   apperrors.CodeValidation,
+	http.StatusTooManyRequests:       apperrors.CodeTooMany,
+	http.StatusInternalServerError:   apperrors.CodeInternal,
+	http.StatusMethodNotAllowed:      "METHOD_NOT_ALLOWED",
+	http.StatusRequestEntityTooLarge: "PAYLOAD_TOO_LARGE",
+}
+
+// ErrorHandler renders every error as commonschema.ResponseHTTP with stable error code
+func ErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	appErr := toAppError(err)
+	if appErr.Status >= http.StatusInternalServerError {
+		slog.ErrorContext(c.Request().Context(), "request failed", "method", c.Request().Method, "route", c.Path(), "error", err)
+	}
+
+	// convert field errors
+	var fields []commonschema.ErrorField
+	for _, v := range appErr.Fields {
+		fields = append(fields, commonschema.ErrorField{Field: v.Field, Message: v.Message})
+	}
+
+	response := commonschema.ResponseHTTP{
+		Code:    appErr.Status,
+		Message: appErr.Message,
+		Error: commonschema.ErrorDetail{
+			Code:   string(appErr.Code),
+			Fields: fields,
+		},
+	}
+
+	// tell client when it can try again, rounded up to whole second
+	if appErr.RetryAfter > 0 {
+		seconds := int(math.Ceil(appErr.RetryAfter.Seconds()))
+		c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(response.Code)
+	} else {
+		err = c.JSON(response.Code, response)
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "failed to send error response", "error", err)
+	}
+}
+
+func toAppError(err error) *apperrors.Error {
+	// error from usecase
+	if e := apperrors.As(err); e != nil {
+		return e
+	}
+
+	// error from request validation
+	if e := apperrors.FromValidator(err); e != nil {
+		return e
+	}
+
+	// error from echo or handler, message can be string or error
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		if inner, ok := he.Message.(error); ok {
+			if e := apperrors.As(inner); e != nil {
+				return e
+			}
+			if e := apperrors.FromValidator(inner); e != nil {
+				return e
+			}
+		}
+
+		code, ok := statusCodes[he.Code]
+		if !ok {
+			code = apperrors.CodeBadRequest
+			if he.Code >= http.StatusInternalServerError {
+				code = apperrors.CodeInternal
+			}
+		}
+
+		message := http.StatusText(he.Code)
+		switch m := he.Message.(type) {
+		case string:
+			message = m
+		case error:
+			message = m.Error()
+		case nil:
+		default:
+			message = fmt.Sprint(m)
+		}
+		return &apperrors.Error{Code: code, Status: he.Code, Message: message, Err: err}
+	}
+
+	// error from repository
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.NotFound("data is not found").Wrap(err)
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505": // unique_violation
+			return apperrors.Conflict("data already exists").Wrap(err)
+		case "23503": // foreign_key_violation
+			return apperrors.BadRequest("related data is not found").Wrap(err)
+		case "22P02": // invalid_text_representation, eg. malformed uuid
+			return apperrors.BadRequest("invalid input value").Wrap(err)
+		}
+	}
+
+	return apperrors.Internal(err)
+}
@@ -0,0 +1,70 @@
+package middlewares
+
+import (
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/infras/ratelimit"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type RateLimitConfig struct {
+	Name    string // bucket name, keep it unique for each route group
+	Limit   int
+	Window  time.Duration
+	KeyFunc func(c echo.Context) string // empty key skips the limit
+}
+
+// ByIP limits request per client address
+func ByIP(c echo.Context) string {
+	return "ip:" + c.RealIP()
+}
+
+// ByUser limits request per logged account, must be registered after VerifyToken
+func ByUser(c echo.Context) string {
+	userID, ok := c.Get("user_id").(string)
+	if !ok || userID == "" {
+		return ""
+	}
+	return "user:" + userID
+}
+
+// RateLimit rejects request with 429 when bucket of the key is full
+func RateLimit(store ratelimit.Store, config RateLimitConfig) echo.MiddlewareFunc {
+	if config.KeyFunc == nil {
+		config.KeyFunc = ByIP
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := config.KeyFunc(c)
+			if key == "" {
+				return next(c)
+			}
+
+			count, ttl, err := store.Hit("rl:"+config.Name+":"+key, config.Window)
+			if err != nil {
+				// store falls back to memory on its own, error here means counting is not possible at all
+				slog.ErrorContext(c.Request().Context(), "rate limit store failed", "error", err)
+				return next(c)
+			}
+
+			remaining := config.Limit - count
+			if remaining < 0 {
+				remaining = 0
+			}
+			header := c.Response().Header()
+			header.Set("X-RateLimit-Limit", strconv.Itoa(config.Limit))
+			header.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+			header.Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(ttl.Seconds()))))
+
+			if count > config.Limit {
+				return apperrors.TooManyRequests("too many requests, please try again later", ttl)
+			}
+			return next(c)
+		}
+	}
+}
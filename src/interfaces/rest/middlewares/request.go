@@ -0,0 +1,81 @@
+package middlewares
+
+import (
+	"kiraform/src/infras/logger"
+	"kiraform/src/infras/metrics"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const headerRequestID = "X-Request-ID"
+
+// RequestID reuses request id sent by proxy or creates new one, then shares it to response,
+// echo context and request context so logs from usecases and repositories can be traced
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ID := c.Request().Header.Get(headerRequestID)
+			if ID == "" || len(ID) > 128 {
+				ID = uuid.NewString()
+			}
+
+			c.Response().Header().Set(headerRequestID, ID)
+			c.Set("request_id", ID)
+			c.SetRequest(c.Request().WithContext(logger.WithRequestID(c.Request().Context(), ID)))
+			return next(c)
+		}
+	}
+}
+
+// Observe writes access log and http metrics, must be registered after RequestID
+func Observe() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			metrics.HTTPRequestsInFlight.Inc()
+			defer metrics.HTTPRequestsInFlight.Dec()
+
+			// render error here so final status is known
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			// unmatched path keeps metric labels bounded
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			status := c.Response().Status
+			latency := time.Since(start)
+			metrics.HTTPRequestDuration.WithLabelValues(c.Request().Method, route, strconv.Itoa(status)).Observe(latency.Seconds())
+
+			// skip probes and scraping from access log
+			switch route {
+			case "/healthz", "/readyz", "/metrics":
+				return nil
+			}
+
+			level := slog.LevelInfo
+			if status >= 500 {
+				level = slog.LevelError
+			} else if status >= 400 {
+				level = slog.LevelWarn
+			}
+			slog.Log(c.Request().Context(), level, "request",
+				"method", c.Request().Method,
+				"route", route,
+				"uri", c.Request().RequestURI,
+				"status", status,
+				"latency", latency,
+				"bytes", c.Response().Size,
+				"ip", c.RealIP(),
+				"user_agent", c.Request().UserAgent(),
+			)
+			return nil
+		}
+	}
+}
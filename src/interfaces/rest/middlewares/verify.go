@@ -2,58 +2,158 @@ package middlewares
 
 import (
 	"fmt"
-	"kiraform/src/infras/configs"
+	"kiraform/src/applications/keyring"
+	"kiraform/src/applications/models"
+	"kiraform/src/applications/sessions"
+	masterusecase "kiraform/src/applications/usecases/masters"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 )
 
-func VerifyToken(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		// get authorization header
-		token := c.Request().Header.Get("authorization")
-		if token == "" {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Missing auhtorization header")
-		}
+// APIKeyRole is set as role of api key requests, it has no permission so admin shortcuts never apply to them
+const APIKeyRole = "api_key"
 
-		// check if token contain bearer
-		hasBearer := strings.HasPrefix(token, "Bearer ")
-		if !hasBearer {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Missing bearer token")
-		}
-		tokenArr := strings.Split(token, " ")
-		if len(tokenArr) < 1 {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Missing bearer token")
-		}
-		token = tokenArr[1]
+// APIKeyScopes maps "METHOD /path" of routes open to api keys to the scopes accepted there,
+// any route not listed here refuses api keys
+type APIKeyScopes map[string][]string
+
+// Allow opens route for api keys having any of the scopes
+func (s APIKeyScopes) Allow(route *echo.Route, scopes ...string) {
+	s[route.Method+" "+route.Path] = scopes
+}
 
-		// check valid token
-		jwtSecret := []byte(configs.Environment().SECRET_KEY)
-		decode, err := jwt.Parse(token, func(token *jwt.Token) (any, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+// APIKeyAuth lets VerifyToken accept workspace api keys besides access tokens
+type APIKeyAuth struct {
+	Usecase masterusecase.APIKeyUsecase
+	Scopes  APIKeyScopes
+}
+
+// VerifyToken accepts request with valid bearer token signed by current or previous key of keyring
+// whose session is not revoked and whose user is active, or with api key when apiKeys is given
+func VerifyToken(keys *keyring.Keyring, apiKeys *APIKeyAuth, tracker *sessions.Tracker) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// get authorization header
+			token := c.Request().Header.Get("authorization")
+			if token == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Missing auhtorization header")
 			}
-			return jwtSecret, nil
-		})
-		if err != nil {
-			return echo.NewHTTPError(http.StatusUnauthorized, err)
-		}
 
-		if claims, ok := decode.Claims.(jwt.MapClaims); ok && decode.Valid {
-			for key, val := range claims {
-				if key == "id" {
-					// convert id as user id to prevent ambigous naming
-					key = "user_id"
+			// check if token contain bearer
+			hasBearer := strings.HasPrefix(token, "Bearer ")
+			if !hasBearer {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Missing bearer token")
+			}
+			tokenArr := strings.Split(token, " ")
+			if len(tokenArr) < 1 {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Missing bearer token")
+			}
+			token = tokenArr[1]
+
+			// api key is told apart by its prefix
+			if strings.HasPrefix(token, masterusecase.APIKeyPrefix) {
+				if apiKeys == nil {
+					return echo.NewHTTPError(http.StatusForbidden, "Api key is not accepted here")
+				}
+				if err := apiKeys.verify(c, token); err != nil {
+					return err
 				}
-				c.Set(key, fmt.Sprintf("%v", val))
+				return next(c)
+			}
+
+			// check valid token
+			decode, err := keys.Parse(c.Request().Context(), token, jwt.MapClaims{})
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, err)
 			}
-		} else {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid access token")
+
+			if claims, ok := decode.Claims.(jwt.MapClaims); ok && decode.Valid {
+				// challenge of two-factor login is signed by the same keys, it must not pass as access token
+				if typ, _ := claims["typ"].(string); typ != "" && typ != keyring.TokenAccess {
+					return echo.NewHTTPError(http.StatusUnauthorized, "Invalid access token")
+				}
+
+				// token without sid can not be revoked, only legacy token of secret key has none
+				// and keyring accepts it until the legacy cut-off
+				sid, _ := claims["sid"].(string)
+				userID, _ := claims["id"].(string)
+				legacy := keyring.IsLegacy(decode)
+				if legacy && sid == "" {
+					err = tracker.VerifyLegacy(c.Request().Context(), userID)
+				} else {
+					err = tracker.Verify(c.Request().Context(), sid, userID)
+				}
+				if err != nil {
+					return err
+				}
+				for key, val := range claims {
+					if key == "id" {
+						// convert id as user id to prevent ambigous naming
+						key = "user_id"
+					}
+					// roles and permissions are kept as list
+					if list, ok := val.([]any); ok {
+						c.Set(key, claimList(list))
+						continue
+					}
+					c.Set(key, fmt.Sprintf("%v", val))
+				}
+
+				// legacy token carries one role_name, admin of it keeps every permission until the cut-off
+				if roleName, ok := claims["role_name"].(string); ok && legacy && claims["roles"] == nil {
+					c.Set("roles", []string{roleName})
+					if strings.EqualFold(roleName, models.RoleAdmin) {
+						c.Set("permissions", models.Permissions)
+					}
+				}
+			} else {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid access token")
+			}
+
+			// allow this request
+			return next(c)
 		}
+	}
+}
+
+// verify checks api key, its scope for this route and its workspace, then acts as creator of the key
+func (a *APIKeyAuth) verify(c echo.Context, token string) error {
+	scopes, ok := a.Scopes[c.Request().Method+" "+c.Path()]
+	if !ok {
+		return echo.NewHTTPError(http.StatusForbidden, "Api key is not accepted here")
+	}
+
+	key, err := a.Usecase.Authenticate(c.Request().Context(), token)
+	if err != nil {
+		return err
+	}
+	granted := masterusecase.APIKeyScopes(key)
+	if !slices.ContainsFunc(scopes, func(scope string) bool { return slices.Contains(granted, scope) }) {
+		return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("Api key needs %s scope", strings.Join(scopes, " or ")))
+	}
+
+	// every route open to api keys is bound to a workspace
+	if c.Param("workspace_id") != key.WorkspaceID.String() {
+		return echo.NewHTTPError(http.StatusForbidden, "Api key belongs to another workspace")
+	}
+
+	c.Set("user_id", key.UserID.String())
+	c.Set("roles", []string{APIKeyRole})
+	c.Set("permissions", []string{})
+	c.Set("api_key_id", key.ID.String())
+	c.Set("mfa", strconv.FormatBool(key.CreatedWithMFA))
+	return nil
+}
 
-		// allow this request
-		return next(c)
+func claimList(list []any) []string {
+	values := make([]string, 0, len(list))
+	for _, v := range list {
+		values = append(values, fmt.Sprintf("%v", v))
 	}
+	return values
 }
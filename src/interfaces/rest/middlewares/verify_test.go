@@ -0,0 +1,140 @@
+package middlewares
+
+import (
+	"context"
+	"database/sql/driver"
+	"kiraform/src/applications/keyring"
+	"kiraform/src/applications/models"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/applications/sessions"
+	"kiraform/src/infras/configs"
+	"kiraform/src/infras/dbtest"
+	"kiraform/src/infras/mailer"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const testSecretKey = "test secret key of at least 32 characters"
+
+// memorySigningKeys keeps signing keys of keyring in memory
+type memorySigningKeys struct {
+	keys []models.SigningKeys
+}
+
+func (r *memorySigningKeys) FindSigningKeys(ctx context.Context, retiredAfter time.Time) ([]models.SigningKeys, error) {
+	return r.keys, nil
+}
+
+func (r *memorySigningKeys) CreateFirstSigningKey(ctx context.Context, key models.SigningKeys) (bool, error) {
+	if len(r.keys) > 0 {
+		return false, nil
+	}
+	r.keys = append(r.keys, key)
+	return true, nil
+}
+
+func (r *memorySigningKeys) RotateSigningKey(ctx context.Context, key models.SigningKeys, purgeBefore time.Time) error {
+	r.keys = append([]models.SigningKeys{key}, r.keys...)
+	return nil
+}
+
+// verifyRequest sends token through VerifyToken, user and session of any id are active
+func verifyRequest(t *testing.T, legacyUntil time.Time, token func(keys *keyring.Keyring) string) (int, echo.Context) {
+	t.Helper()
+	config := configs.AuthConfig{SecretKey: testSecretKey, TokenTTL: time.Hour, Issuer: "Kiraform", SigningAlg: "EdDSA", LegacyUntil: legacyUntil}
+	keys, err := keyring.New(&memorySigningKeys{}, config)
+	if err != nil {
+		t.Fatalf("keyring: %v", err)
+	}
+	if err := keys.Init(context.Background()); err != nil {
+		t.Fatalf("keyring init: %v", err)
+	}
+
+	DB, _ := dbtest.Open(t, func(query string) *dbtest.Rows {
+		switch {
+		case strings.Contains(query, `FROM "users"`):
+			return &dbtest.Rows{Columns: []string{"id", "is_active", "deleted"}, Values: [][]driver.Value{{uuid.NewString(), true, false}}}
+		case strings.Contains(query, `FROM "user_sessions"`):
+			return &dbtest.Rows{Columns: []string{"id", "user_id", "expires_at"}, Values: [][]driver.Value{{uuid.NewString(), testUserID, time.Now().Add(time.Hour)}}}
+		}
+		return nil
+	})
+	tracker := sessions.NewTracker(masterrepo.NewSessionRepository(DB), masterrepo.NewLoginHistoryRepository(DB), masterrepo.NewUserRepository(DB), mailer.NewMemoryMailer(), config.Issuer)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token(keys))
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+	err = VerifyToken(keys, nil, tracker)(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})(c)
+	if err != nil {
+		return toAppError(err).Status, c
+	}
+	return http.StatusOK, c
+}
+
+var testUserID = uuid.NewString()
+
+// signed issues access token by keyring, empty sid is like token issued before sessions were recorded
+func signed(t *testing.T, sid string) func(keys *keyring.Keyring) string {
+	return func(keys *keyring.Keyring) string {
+		claims := jwt.MapClaims{"id": testUserID, "roles": []string{"user"}, "exp": time.Now().Add(time.Hour).Unix()}
+		if sid != "" {
+			claims["sid"] = sid
+		}
+		token, err := keys.Sign(claims)
+		if err != nil {
+			t.Fatalf("sign token: %v", err)
+		}
+		return token
+	}
+}
+
+// legacyToken is token signed by secret key before key rotation, it has no kid, sid nor roles
+func legacyToken(t *testing.T, roleName string) func(keys *keyring.Keyring) string {
+	return func(*keyring.Keyring) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"id":        testUserID,
+			"role_name": roleName,
+			"exp":       time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte(testSecretKey))
+		if err != nil {
+			t.Fatalf("sign legacy token: %v", err)
+		}
+		return token
+	}
+}
+
+func TestVerifyTokenRefusesTokenWithoutSession(t *testing.T) {
+	if status, _ := verifyRequest(t, time.Time{}, signed(t, uuid.NewString())); status != http.StatusOK {
+		t.Fatalf("got status %d, want token with session accepted", status)
+	}
+
+	// even within legacy cut-off, token of signing keys must carry sid
+	if status, _ := verifyRequest(t, time.Now().Add(time.Hour), signed(t, "")); status != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want unauthorized", status)
+	}
+}
+
+func TestVerifyTokenLegacyCutOff(t *testing.T) {
+	status, c := verifyRequest(t, time.Now().Add(time.Hour), legacyToken(t, "admin"))
+	if status != http.StatusOK {
+		t.Fatalf("got status %d, want legacy token accepted before cut-off", status)
+	}
+	if permissions, _ := c.Get("permissions").([]string); len(permissions) != len(models.Permissions) {
+		t.Fatalf("got permissions %v, want every permission of legacy admin", permissions)
+	}
+
+	for name, until := range map[string]time.Time{"after cut-off": time.Now().Add(-time.Second), "without cut-off": {}} {
+		if status, _ := verifyRequest(t, until, legacyToken(t, "admin")); status != http.StatusUnauthorized {
+			t.Fatalf("%s got status %d, want unauthorized", name, status)
+		}
+	}
+}
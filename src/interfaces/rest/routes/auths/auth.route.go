@@ -1,11 +1,20 @@
 package authroute
 
 import (
-	"errors"
 	authdi "kiraform/src/applications/dependencies/auths"
+	"kiraform/src/applications/helpers"
+	"kiraform/src/applications/keyring"
+	"kiraform/src/applications/sessions"
+	"kiraform/src/applications/twofactor"
+	"kiraform/src/infras/configs"
+	"kiraform/src/infras/mailer"
+	"kiraform/src/infras/ratelimit"
+	"kiraform/src/interfaces/rest/middlewares"
 	authschema "kiraform/src/interfaces/rest/schemas/auths"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"kiraform/src/utils"
 	"net/http"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
@@ -26,23 +35,36 @@ func NewAuthHandler(DB *gorm.DB, validator *validator.Validate, dependencies aut
 	}
 }
 
-func NewAuthHTTP(g *echo.Group, DB *gorm.DB) {
-	validator := validator.New()
-	h := NewAuthHandler(DB, validator, *authdi.NewAuthDependencies(DB))
+func NewAuthHTTP(g *echo.Group, DB *gorm.DB, limiter ratelimit.Store, config configs.AuthConfig, keys *keyring.Keyring, twoFactor *twofactor.Manager, mail mailer.Mailer, tracker *sessions.Tracker) {
+	validator := utils.NewValidator()
+	h := NewAuthHandler(DB, validator, *authdi.NewAuthDependencies(DB, limiter, config, keys, twoFactor, mail, tracker))
+
+	// limit guessing password and mass registration from same address
+	limit := middlewares.RateLimit(limiter, middlewares.RateLimitConfig{
+		Name:   "auth",
+		Limit:  10,
+		Window: time.Minute,
+	})
 
 	// define endpoints
-	g.POST("/login", h.Login)
-	g.POST("/register", h.Register)
+	g.POST("/login", h.Login, limit)
+	g.POST("/login/2fa", h.LoginTwoFactor, limit)
+	g.POST("/register", h.Register, limit)
+	g.POST("/login/passwordless", h.RequestPasswordless, limit)
+	g.POST("/login/passwordless/code", h.LoginPasswordless, limit)
+	g.POST("/login/passwordless/link", h.LoginMagicLink, limit)
+	g.POST("/password/reset", h.ResetPassword, limit)
 }
 
 // @Summary      Login
-// @Description  User login
+// @Description  User login, user with two-factor authentication gets challenge token to be sent to /api/login/2fa
 // @Tags         Authentication
 // @Accept       json
 // @Produce      json
 // @Param        loginPayload  body      authschema.LoginPayload   true  "Login credentials"
 // @Success      200  {object} commonschema.ResponseHTTP "Login success"
 // @Failure      400  {object} commonschema.ResponseHTTP "Login failure"
+// @Failure      429  {object} commonschema.ResponseHTTP "Too many requests"
 // @Router       /api/login [post]
 func (h *AuthHandler) Login(c echo.Context) error {
 	var body authschema.LoginPayload
@@ -52,17 +74,68 @@ func (h *AuthHandler) Login(c echo.Context) error {
 	}
 
 	if err := h.Validator.Struct(body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// call usecase for busines validation
-	signedToken, err := h.Dependencies.UC.Login(body)
+	result, err := h.Dependencies.UC.Login(c.Request().Context(), helpers.Actor(c), body)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return echo.NewHTTPError(http.StatusNotFound, err.Error())
-		} else {
-			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
+	}
+	return loginResponse(c, result)
+}
+
+// loginResponse sends access token, or challenge token when code of authenticator is still needed
+func loginResponse(c echo.Context, result *authschema.LoginResult) error {
+	if result.ChallengeToken != "" {
+		response := commonschema.ResponseHTTP{
+			Code:    http.StatusOK,
+			Message: "Two-factor code is required",
+			Data: map[string]any{
+				"two_factor_required": true,
+				"challenge_token":     result.ChallengeToken,
+			},
 		}
+		return c.JSON(response.Code, response)
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Login success",
+		Data: map[string]any{
+			"access_token":  result.AccessToken,
+			"refresh_token": result.AccessToken,
+		},
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Summary      Login Two-Factor
+// @Description  Finish login with challenge token and code of authenticator app or a recovery code
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        loginTwoFactorPayload  body      authschema.LoginTwoFactorPayload   true  "Challenge token and code"
+// @Success      200  {object} commonschema.ResponseHTTP "Login success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Login failure"
+// @Failure      429  {object} commonschema.ResponseHTTP "Too many requests"
+// @Router       /api/login/2fa [post]
+func (h *AuthHandler) LoginTwoFactor(c echo.Context) error {
+	var body authschema.LoginTwoFactorPayload
+
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid JSON")
+	}
+
+	if err := h.Validator.Struct(body); err != nil {
+		return err
+	}
+
+	// call usecase for busines validation
+	signedToken, err := h.Dependencies.UC.LoginTwoFactor(c.Request().Context(), helpers.Actor(c), body)
+	if err != nil {
+		return err
 	}
 
 	// send response
@@ -85,6 +158,7 @@ func (h *AuthHandler) Login(c echo.Context) error {
 // @Param        registerPayload  body      authschema.RegisterPayload   true  "Register credentials"
 // @Success      200  {object} commonschema.ResponseHTTP "Registration success"
 // @Failure      400  {object} commonschema.ResponseHTTP "Registration failure"
+// @Failure      429  {object} commonschema.ResponseHTTP "Too many requests"
 // @Router       /api/register [post]
 func (h *AuthHandler) Register(c echo.Context) error {
 	var body authschema.RegisterPayload
@@ -94,13 +168,13 @@ func (h *AuthHandler) Register(c echo.Context) error {
 	}
 
 	if err := h.Validator.Struct(body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// call usecase for business validation
-	msg, err := h.Dependencies.UC.Register(body)
+	msg, err := h.Dependencies.UC.Register(c.Request().Context(), body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send response
@@ -110,3 +184,132 @@ func (h *AuthHandler) Register(c echo.Context) error {
 	}
 	return c.JSON(response.Code, response)
 }
+
+// @Summary      Request Passwordless Login
+// @Description  Send one-time login code, and magic link when it is configured, to email. New email gets an account which is activated by its first login
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        passwordlessPayload  body      authschema.PasswordlessPayload   true  "Email"
+// @Success      200  {object} commonschema.ResponseHTTP "Code is sent"
+// @Failure      400  {object} commonschema.ResponseHTTP "Invalid email"
+// @Failure      429  {object} commonschema.ResponseHTTP "Too many requests"
+// @Router       /api/login/passwordless [post]
+func (h *AuthHandler) RequestPasswordless(c echo.Context) error {
+	var body authschema.PasswordlessPayload
+
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid JSON")
+	}
+
+	if err := h.Validator.Struct(body); err != nil {
+		return err
+	}
+
+	// call usecase for busines validation
+	msg, err := h.Dependencies.UC.RequestPasswordless(c.Request().Context(), body)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: *msg,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Summary      Passwordless Login With Code
+// @Description  Exchange one-time login code for access token, or challenge token when two-factor code is required
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        passwordlessCodePayload  body      authschema.PasswordlessCodePayload   true  "Email and code"
+// @Success      200  {object} commonschema.ResponseHTTP "Login success"
+// @Failure      401  {object} commonschema.ResponseHTTP "Invalid code"
+// @Failure      429  {object} commonschema.ResponseHTTP "Too many requests"
+// @Router       /api/login/passwordless/code [post]
+func (h *AuthHandler) LoginPasswordless(c echo.Context) error {
+	var body authschema.PasswordlessCodePayload
+
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid JSON")
+	}
+
+	if err := h.Validator.Struct(body); err != nil {
+		return err
+	}
+
+	// call usecase for busines validation
+	result, err := h.Dependencies.UC.LoginPasswordless(c.Request().Context(), helpers.Actor(c), body)
+	if err != nil {
+		return err
+	}
+	return loginResponse(c, result)
+}
+
+// @Summary      Passwordless Login With Magic Link
+// @Description  Exchange token of magic link for access token, or challenge token when two-factor code is required
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        magicLinkPayload  body      authschema.MagicLinkPayload   true  "Token of magic link"
+// @Success      200  {object} commonschema.ResponseHTTP "Login success"
+// @Failure      401  {object} commonschema.ResponseHTTP "Invalid link"
+// @Failure      429  {object} commonschema.ResponseHTTP "Too many requests"
+// @Router       /api/login/passwordless/link [post]
+func (h *AuthHandler) LoginMagicLink(c echo.Context) error {
+	var body authschema.MagicLinkPayload
+
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid JSON")
+	}
+
+	if err := h.Validator.Struct(body); err != nil {
+		return err
+	}
+
+	// call usecase for busines validation
+	result, err := h.Dependencies.UC.LoginMagicLink(c.Request().Context(), helpers.Actor(c), body)
+	if err != nil {
+		return err
+	}
+	return loginResponse(c, result)
+}
+
+// @Summary      Reset Password
+// @Description  Set new password with token of reset link sent when admin forces password reset, every session of the user is signed out
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        resetPasswordPayload  body      authschema.ResetPasswordPayload   true  "Token of reset link and new password"
+// @Success      200  {object} commonschema.ResponseHTTP "Password is changed"
+// @Failure      400  {object} commonschema.ResponseHTTP "Invalid password"
+// @Failure      401  {object} commonschema.ResponseHTTP "Invalid link"
+// @Failure      429  {object} commonschema.ResponseHTTP "Too many requests"
+// @Router       /api/password/reset [post]
+func (h *AuthHandler) ResetPassword(c echo.Context) error {
+	var body authschema.ResetPasswordPayload
+
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid JSON")
+	}
+
+	if err := h.Validator.Struct(body); err != nil {
+		return err
+	}
+
+	// call usecase for busines validation
+	msg, err := h.Dependencies.UC.ResetPassword(c.Request().Context(), helpers.Actor(c), body)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: *msg,
+	}
+	return c.JSON(response.Code, response)
+}
@@ -0,0 +1,17 @@
+package authroute
+
+import (
+	"kiraform/src/applications/keyring"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// NewJWKSHTTP publishes public signing keys outside /api, so other services can verify access tokens
+func NewJWKSHTTP(e *echo.Echo, keys *keyring.Keyring) {
+	e.GET("/.well-known/jwks.json", func(c echo.Context) error {
+		// short cache, rotated key must show up soon
+		c.Response().Header().Set("Cache-Control", "public, max-age=300")
+		return c.JSON(http.StatusOK, keys.JWKS())
+	})
+}
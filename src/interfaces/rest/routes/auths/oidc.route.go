@@ -0,0 +1,164 @@
+package authroute
+
+import (
+	"errors"
+	"kiraform/src/applications/apperrors"
+	authdi "kiraform/src/applications/dependencies/auths"
+	"kiraform/src/applications/helpers"
+	"kiraform/src/applications/keyring"
+	"kiraform/src/applications/sessions"
+	"kiraform/src/applications/sso"
+	"kiraform/src/applications/twofactor"
+	"kiraform/src/infras/configs"
+	"kiraform/src/infras/mailer"
+	"kiraform/src/infras/ratelimit"
+	"kiraform/src/interfaces/rest/middlewares"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type OIDCHandler struct {
+	DB           *gorm.DB
+	Registry     *sso.Registry
+	Dependencies authdi.AuthDependencies
+}
+
+func NewOIDCHandler(DB *gorm.DB, registry *sso.Registry, dependencies authdi.AuthDependencies) *OIDCHandler {
+	return &OIDCHandler{
+		DB:           DB,
+		Registry:     registry,
+		Dependencies: dependencies,
+	}
+}
+
+func NewOIDCHTTP(g *echo.Group, DB *gorm.DB, limiter ratelimit.Store, config configs.AuthConfig, keys *keyring.Keyring, twoFactor *twofactor.Manager, mail mailer.Mailer, tracker *sessions.Tracker, registry *sso.Registry) {
+	h := NewOIDCHandler(DB, registry, *authdi.NewAuthDependencies(DB, limiter, config, keys, twoFactor, mail, tracker))
+
+	// every login calls the provider, so it is limited like password login
+	limit := middlewares.RateLimit(limiter, middlewares.RateLimitConfig{
+		Name:   "oidc",
+		Limit:  20,
+		Window: time.Minute,
+	})
+
+	// define endpoints
+	g.GET("/oidc/providers", h.Providers)
+	g.GET("/oidc/:provider/login", h.Login, limit)
+	g.GET("/oidc/:provider/callback", h.Callback, limit)
+}
+
+// @Summary      SSO Providers
+// @Description  Name of configured single sign-on providers
+// @Tags         Authentication
+// @Produce      json
+// @Success      200  {object} commonschema.ResponseHTTP "List of providers"
+// @Router       /api/oidc/providers [get]
+func (h *OIDCHandler) Providers(c echo.Context) error {
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Get SSO providers",
+		Data:    h.Registry.Providers(),
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Summary      SSO Login
+// @Description  Redirect browser to login page of provider
+// @Tags         Authentication
+// @Param        provider  path  string  true  "Provider name"
+// @Success      302  "Redirect to provider"
+// @Failure      404  {object} commonschema.ResponseHTTP "Unknown provider"
+// @Failure      429  {object} commonschema.ResponseHTTP "Too many requests"
+// @Router       /api/oidc/{provider}/login [get]
+func (h *OIDCHandler) Login(c echo.Context) error {
+	authURL, state, err := h.Registry.Start(c.Request().Context(), c.Param("provider"))
+	if err != nil {
+		if errors.Is(err, sso.ErrUnknownProvider) {
+			return apperrors.NotFound(err.Error())
+		}
+		return err
+	}
+
+	// state stays in browser, callback is refused from any other browser
+	c.SetCookie(h.stateCookie(state, int(sso.StateTTL.Seconds())))
+	return c.Redirect(http.StatusFound, authURL)
+}
+
+// @Summary      SSO Callback
+// @Description  Provider sends browser back here, then browser is sent to frontend with access_token, or challenge_token when two-factor code is required, or error in url fragment
+// @Tags         Authentication
+// @Param        provider  path   string  true  "Provider name"
+// @Param        code      query  string  true  "Authorization code"
+// @Param        state     query  string  true  "State of login"
+// @Success      302  "Redirect to frontend"
+// @Failure      429  {object} commonschema.ResponseHTTP "Too many requests"
+// @Router       /api/oidc/{provider}/callback [get]
+func (h *OIDCHandler) Callback(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	// state is used once
+	c.SetCookie(h.stateCookie("", -1))
+
+	// user cancelled or provider refused login
+	if reason := c.QueryParam("error"); reason != "" {
+		return h.redirect(c, url.Values{"error": {reason}})
+	}
+
+	cookie, err := c.Cookie(sso.StateCookie)
+	if err != nil {
+		return h.redirect(c, url.Values{"error": {sso.ErrInvalidState.Error()}})
+	}
+
+	identity, err := h.Registry.Finish(ctx, c.Param("provider"), c.QueryParam("code"), c.QueryParam("state"), cookie.Value)
+	if err != nil {
+		switch {
+		case errors.Is(err, sso.ErrUnknownProvider), errors.Is(err, sso.ErrInvalidState):
+			return h.redirect(c, url.Values{"error": {err.Error()}})
+		}
+		slog.ErrorContext(ctx, "failed to finish sso login", "provider", c.Param("provider"), "error", err)
+		return h.redirect(c, url.Values{"error": {"failed to sign in with provider"}})
+	}
+
+	// call usecase for busines validation
+	result, err := h.Dependencies.UC.SSOLogin(ctx, helpers.Actor(c), *identity)
+	if err != nil {
+		var appErr *apperrors.Error
+		if errors.As(err, &appErr) {
+			return h.redirect(c, url.Values{"error": {appErr.Message}})
+		}
+		slog.ErrorContext(ctx, "failed to sign in sso user", "provider", identity.Provider, "error", err)
+		return h.redirect(c, url.Values{"error": {"failed to sign in with provider"}})
+	}
+
+	// password step is replaced by provider, code of authenticator is still needed
+	if result.ChallengeToken != "" {
+		return h.redirect(c, url.Values{
+			"two_factor_required": {"true"},
+			"challenge_token":     {result.ChallengeToken},
+		})
+	}
+	return h.redirect(c, url.Values{"access_token": {result.AccessToken}})
+}
+
+// redirect sends browser to frontend, values are put in fragment so they are not sent to any server
+func (h *OIDCHandler) redirect(c echo.Context, values url.Values) error {
+	return c.Redirect(http.StatusFound, h.Registry.FrontendURL()+"#"+values.Encode())
+}
+
+func (h *OIDCHandler) stateCookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     sso.StateCookie,
+		Value:    value,
+		Path:     "/api/oidc",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   h.Registry.SecureCookie(),
+		SameSite: http.SameSiteLaxMode, // cookie must come with redirect from provider
+	}
+}
@@ -0,0 +1,63 @@
+package healthroute
+
+import (
+	"kiraform/src/infras/health"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type HealthHandler struct {
+	Checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{
+		Checker: checker,
+	}
+}
+
+// NewHealthHTTP registers probes outside /api, so they skip auth and rate limit
+func NewHealthHTTP(e *echo.Echo, checker *health.Checker) {
+	h := NewHealthHandler(checker)
+	e.GET("/healthz", h.Liveness)
+	e.GET("/readyz", h.Readiness)
+}
+
+// @Summary      Liveness Probe
+// @Description  Tell the process is running and able to respond
+// @Tags         Health
+// @Produce  	 json
+// @Success      200  {object} commonschema.ResponseHTTP{data=health.Report} "Service is alive"
+// @Router       /healthz [get]
+func (h *HealthHandler) Liveness(c echo.Context) error {
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Service is alive",
+		Data:    h.Checker.Live(),
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Summary      Readiness Probe
+// @Description  Check database connection, migration state and writable storage
+// @Tags         Health
+// @Produce  	 json
+// @Success      200  {object} commonschema.ResponseHTTP{data=health.Report} "Service is ready"
+// @Failure      503  {object} commonschema.ResponseHTTP{data=health.Report} "Service is not ready"
+// @Router       /readyz [get]
+func (h *HealthHandler) Readiness(c echo.Context) error {
+	report := h.Checker.Ready(c.Request().Context())
+
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Service is ready",
+		Data:    report,
+	}
+	if report.Status != health.StatusUp {
+		response.Code = http.StatusServiceUnavailable
+		response.Message = "Service is not ready"
+	}
+	return c.JSON(response.Code, response)
+}
@@ -0,0 +1,24 @@
+package healthroute
+
+import (
+	"crypto/subtle"
+	"kiraform/src/applications/apperrors"
+	"kiraform/src/infras/metrics"
+
+	"github.com/labstack/echo/v4"
+)
+
+// NewMetricsHTTP registers prometheus scrape endpoint outside /api,
+// bearer token is required when it is configured
+func NewMetricsHTTP(e *echo.Echo, token string) {
+	handler := echo.WrapHandler(metrics.Handler())
+	e.GET("/metrics", func(c echo.Context) error {
+		if token != "" {
+			auth := c.Request().Header.Get("Authorization")
+			if subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+token)) != 1 {
+				return apperrors.Unauthorized("invalid metrics token")
+			}
+		}
+		return handler(c)
+	})
+}
@@ -0,0 +1,149 @@
+package masterroute
+
+import (
+	masterdi "kiraform/src/applications/dependencies/masters"
+	"kiraform/src/applications/helpers"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"kiraform/src/utils"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type APIKeyHandler struct {
+	DB           *gorm.DB
+	Validator    *validator.Validate
+	Dependencies masterdi.APIKeyDependencies
+}
+
+func NewAPIKeyHandler(DB *gorm.DB, validator *validator.Validate, dependencies masterdi.APIKeyDependencies) *APIKeyHandler {
+	return &APIKeyHandler{
+		DB:           DB,
+		Validator:    validator,
+		Dependencies: dependencies,
+	}
+}
+
+func NewAPIKeyHTTP(g *echo.Group, DB *gorm.DB) {
+	validator := utils.NewValidator()
+	h := NewAPIKeyHandler(DB, validator, *masterdi.NewAPIKeyDependencies(DB))
+
+	// api keys are managed by members only, never by api keys themselves
+	k := g.Group("/workspaces/api_keys")
+	k.GET("/:workspace_id", h.FindAPIKeys)
+	k.POST("/:workspace_id", h.CreateAPIKey)
+	k.DELETE("/:workspace_id/:id", h.RevokeAPIKey)
+}
+
+// @Security BearerAuth
+// @Summary      List API Keys
+// @Description  Get api keys of workspace which are not revoked
+// @Tags         Master - Workspace API Keys
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 workspace_id path string true "Workspace ID"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/workspaces/api_keys/{workspace_id} [get]
+func (h *APIKeyHandler) FindAPIKeys(c echo.Context) error {
+	workspaceID := c.Param("workspace_id")
+
+	// check allowed access
+	err := helpers.CheckAllowedWorkspace(c, workspaceID, h.Dependencies.DB)
+	if err != nil {
+		return err
+	}
+
+	// perform to get data
+	data, err := h.Dependencies.UC.FindAPIKeys(c.Request().Context(), workspaceID)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    data,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Create API Key
+// @Description  Create api key acting on your behalf within this workspace, the key is shown only once
+// @Tags         Master - Workspace API Keys
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 workspace_id path string true "Workspace ID"
+// @Param        apiKeyPayload  body      masterschema.APIKeyPayload   true  "API key payload"
+// @Success      201  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/workspaces/api_keys/{workspace_id} [post]
+func (h *APIKeyHandler) CreateAPIKey(c echo.Context) error {
+	// define data
+	var body masterschema.APIKeyPayload
+	workspaceID := c.Param("workspace_id")
+
+	// check allowed access
+	err := helpers.CheckAllowedWorkspace(c, workspaceID, h.Dependencies.DB)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid body")
+	}
+
+	if err := h.Validator.Struct(body); err != nil {
+		return err
+	}
+
+	// call usecase for business validation
+	data, err := h.Dependencies.UC.CreateAPIKey(c.Request().Context(), helpers.Actor(c), workspaceID, body, helpers.TwoFactorSigned(c))
+	if err != nil {
+		return err
+	}
+
+	// send success response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusCreated,
+		Message: "Data is successfully created, store the key now since it is not shown again",
+		Data:    data,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Revoke API Key
+// @Description  Revoke api key, requests using it are refused right away
+// @Tags         Master - Workspace API Keys
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 workspace_id path string true "Workspace ID"
+// @Param 		 id path string true "ID of api key"
+// @Success      204  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/workspaces/api_keys/{workspace_id}/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c echo.Context) error {
+	workspaceID := c.Param("workspace_id")
+	ID := c.Param("id")
+
+	// check allowed access
+	err := helpers.CheckAllowedWorkspace(c, workspaceID, h.Dependencies.DB)
+	if err != nil {
+		return err
+	}
+
+	// call usecase for business validation
+	err = h.Dependencies.UC.RevokeAPIKey(c.Request().Context(), helpers.Actor(c), workspaceID, ID)
+	if err != nil {
+		return err
+	}
+
+	// send success response
+	return c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,127 @@
+package masterroute
+
+import (
+	masterdi "kiraform/src/applications/dependencies/masters"
+	"kiraform/src/applications/helpers"
+	"kiraform/src/applications/models"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"kiraform/src/utils"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type AuditHandler struct {
+	DB           *gorm.DB
+	Validator    *validator.Validate
+	Dependencies masterdi.AuditDependencies
+}
+
+func NewAuditHandler(DB *gorm.DB, validator *validator.Validate, dependencies masterdi.AuditDependencies) *AuditHandler {
+	return &AuditHandler{
+		DB:           DB,
+		Validator:    validator,
+		Dependencies: dependencies,
+	}
+}
+
+func NewAuditHTTP(g *echo.Group, DB *gorm.DB) {
+	validator := utils.NewValidator()
+	h := NewAuditHandler(DB, validator, *masterdi.NewAuditDependencies(DB))
+
+	// define endpoints
+	a := g.Group("/audits")
+	a.GET("", h.FindAuditLogs)
+	a.GET("/:workspace_id", h.FindWorkspaceAuditLogs)
+}
+
+// @Security BearerAuth
+// @Summary      List Audit Logs
+// @Description  Get audit logs of all workspaces and stores, needs audit:read permission
+// @Tags         Master - Audit Logs
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 page query int true "Page of list data"
+// @Param 		 limit query int true "Limitting data you want to get"
+// @Param 		 search query string false "Find your data with keywords"
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(created_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
+// @Param 		 pagination query string false "Pagination mode, offset or cursor" default(offset)
+// @Param 		 cursor query string false "Cursor token from next_cursor or prev_cursor"
+// @Param 		 count query bool false "Set false to skip total_page count, default false on cursor pagination"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Failure      403  {object} commonschema.ResponseHTTP "Permission is required"
+// @Router       /api/audits [get]
+func (h *AuditHandler) FindAuditLogs(c echo.Context) error {
+	// only user with permission can see every audit log
+	if err := helpers.CheckPermission(c, models.PermissionAuditRead); err != nil {
+		return err
+	}
+
+	// perform to get data
+	params, err := utils.QParams(c, masterschema.AuditLogQuerySpec)
+	if err != nil {
+		return err
+	}
+	list, err := h.Dependencies.UC.FindAuditLogs(c.Request().Context(), nil, params)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    list,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      List Workspace Audit Logs
+// @Description  Get audit logs of workspace, its campaigns and seo settings
+// @Tags         Master - Audit Logs
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 workspace_id path string true "Workspace ID"
+// @Param 		 page query int true "Page of list data"
+// @Param 		 limit query int true "Limitting data you want to get"
+// @Param 		 search query string false "Find your data with keywords"
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(created_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
+// @Param 		 pagination query string false "Pagination mode, offset or cursor" default(offset)
+// @Param 		 cursor query string false "Cursor token from next_cursor or prev_cursor"
+// @Param 		 count query bool false "Set false to skip total_page count, default false on cursor pagination"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/audits/{workspace_id} [get]
+func (h *AuditHandler) FindWorkspaceAuditLogs(c echo.Context) error {
+	workspaceID := c.Param("workspace_id")
+
+	// check allowed access
+	if err := helpers.CheckAllowedWorkspace(c, workspaceID, h.Dependencies.DB); err != nil {
+		return err
+	}
+
+	// perform to get data
+	params, err := utils.QParams(c, masterschema.AuditLogQuerySpec)
+	if err != nil {
+		return err
+	}
+	list, err := h.Dependencies.UC.FindAuditLogs(c.Request().Context(), &workspaceID, params)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    list,
+	}
+	return c.JSON(response.Code, response)
+}
@@ -1,9 +1,10 @@
 package masterroute
 
 import (
-	"errors"
 	masterdi "kiraform/src/applications/dependencies/masters"
 	"kiraform/src/applications/helpers"
+	"kiraform/src/applications/models"
+	"kiraform/src/interfaces/rest/middlewares"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
 	masterschema "kiraform/src/interfaces/rest/schemas/masters"
 	"kiraform/src/utils"
@@ -28,24 +29,31 @@ func NewCampaignHandler(DB *gorm.DB, validator *validator.Validate, dependencies
 	}
 }
 
-func NewCampaignHTTP(g *echo.Group, DB *gorm.DB) {
-	validator := validator.New()
+func NewCampaignHTTP(g *echo.Group, DB *gorm.DB, scopes middlewares.APIKeyScopes) {
+	validator := utils.NewValidator()
 	h := NewCampaignHandler(DB, validator, *masterdi.NewCampaignDependencies(DB))
 
 	// define endpoints
 	c := g.Group("/campaigns")
-	c.GET("/:workspace_id", h.FindCampaigns)
+	scopes.Allow(c.GET("/:workspace_id", h.FindCampaigns), models.ScopeEntriesRead, models.ScopeCampaignsWrite)
 	c.GET("/dashboard/:workspace_id", h.CampaignDashboard)
-	c.GET("/detail/:workspace_id/:id", h.FindCampaign)
-	c.POST("/:workspace_id", h.CreateCampaign)
-	c.PUT("/:workspace_id/:id", h.UpdateCampaign)
-	c.DELETE("/:workspace_id/:id", h.DeleteCampaign)
+	scopes.Allow(c.GET("/detail/:workspace_id/:id", h.FindCampaign), models.ScopeEntriesRead, models.ScopeCampaignsWrite)
+	scopes.Allow(c.POST("/:workspace_id", h.CreateCampaign), models.ScopeCampaignsWrite)
+	scopes.Allow(c.PUT("/:workspace_id/:id", h.UpdateCampaign), models.ScopeCampaignsWrite)
+	scopes.Allow(c.DELETE("/:workspace_id/:id", h.DeleteCampaign), models.ScopeCampaignsWrite)
 
 	// for analytic pages
 	a := c.Group("/analytics")
 	a.GET("/dashboard/:workspace_id/:campaign_id", h.DashboardAnalytics)
-	a.GET("/form_entries/:workspace_id/:campaign_id", h.FindFormEntries)
-	a.GET("/form_entries/:workspace_id/:campaign_id/:id", h.FindDetailFormEntry)
+	a.GET("/fields/:workspace_id/:campaign_id", h.FieldAnalytics)
+	scopes.Allow(a.GET("/form_entries/:workspace_id/:campaign_id", h.FindFormEntries), models.ScopeEntriesRead)
+	scopes.Allow(a.GET("/form_entries/:workspace_id/:campaign_id/:id", h.FindDetailFormEntry), models.ScopeEntriesRead)
+	a.PUT("/form_entries/:workspace_id/:campaign_id/:id", h.ReviewFormEntry)
+
+	// for anti-spam of public submission
+	sp := c.Group("/spam_settings")
+	scopes.Allow(sp.GET("/:workspace_id/:campaign_id", h.FindSpamSetting), models.ScopeCampaignsWrite)
+	scopes.Allow(sp.PUT("/:workspace_id/:campaign_id", h.UpdateSpamSetting), models.ScopeCampaignsWrite)
 
 	// for campaign seos
 	s := c.Group("/seos")
@@ -66,7 +74,11 @@ func NewCampaignHTTP(g *echo.Group, DB *gorm.DB) {
 // @Param 		 page query int true "Page of list data"
 // @Param 		 limit query int true "Limitting data you want to get"
 // @Param 		 search query string false "Find your data with keywords"
-// @Param 		 orderBy query string false "Ordering data" example(created_at:desc)
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(created_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
+// @Param 		 pagination query string false "Pagination mode, offset or cursor" default(offset)
+// @Param 		 cursor query string false "Cursor token from next_cursor or prev_cursor"
+// @Param 		 count query bool false "Set false to skip total_page count, default false on cursor pagination"
 // @Success      200  {object} commonschema.ResponseHTTP "Request success"
 // @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
 // @Router       /api/campaigns/{workspace_id} [get]
@@ -75,14 +87,16 @@ func (h *CampaignHandler) FindCampaigns(c echo.Context) error {
 	// so this endpoint require workspace_id to get data
 	// do not get all data direclty
 	workspaceID := c.Param("workspace_id")
-	params := utils.QParams(c)
+	params, err := utils.QParams(c, masterschema.CampaignQuerySpec)
+	if err != nil {
+		return err
+	}
 	response := commonschema.ResponseHTTP{Code: http.StatusBadRequest}
 
 	// send to usecase to get data
-	list, err := h.Dependencies.UC.FindCampaigns(workspaceID, params)
+	list, err := h.Dependencies.UC.FindCampaigns(c.Request().Context(), workspaceID, params)
 	if err != nil {
-		response.Message = err.Error()
-		return c.JSON(response.Code, response)
+		return err
 	}
 
 	// send response
@@ -111,27 +125,27 @@ func (h *CampaignHandler) FindCampaign(c echo.Context) error {
 	// check allowed user
 	err := helpers.CheckAllowedCampaign(c, workspaceID, ID, h.Dependencies.DB)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// get existing data
-	campaign, err := h.Dependencies.UC.FindCampaign(workspaceID, ID)
+	campaign, err := h.Dependencies.UC.FindCampaign(c.Request().Context(), workspaceID, ID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, "Data is not found")
+		return err
 	}
 
 	// get detail form by this campaign
-	forms, err := h.Dependencies.UC.FindFormsByCampaign(campaign.ID.String())
+	forms, err := h.Dependencies.UC.FindFormsByCampaign(c.Request().Context(), campaign.ID.String())
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// get attributes each form
 	// handle if forms is null, because there is possibility for it
 	for i, v := range forms {
-		attr, err := h.Dependencies.UC.FindFormAttributes(v.ID.String())
+		attr, err := h.Dependencies.UC.FindFormAttributes(c.Request().Context(), v.ID.String())
 		if err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest, err)
+			return err
 		}
 		forms[i].Attributes = attr
 	}
@@ -163,13 +177,13 @@ func (h *CampaignHandler) CampaignDashboard(c echo.Context) error {
 	// check allowed user
 	err := helpers.CheckAllowedWorkspace(c, workspaceID, h.Dependencies.DB)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// get existing data
-	dashboard, err := h.Dependencies.UC.CampaignDashboard(workspaceID)
+	dashboard, err := h.Dependencies.UC.CampaignDashboard(c.Request().Context(), workspaceID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, "Data is not found")
+		return err
 	}
 
 	// send response
@@ -199,7 +213,7 @@ func (h *CampaignHandler) CreateCampaign(c echo.Context) error {
 	// check allowed user
 	err := helpers.CheckAllowedWorkspace(c, workspaceID, h.Dependencies.DB)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	if err := c.Bind(&body); err != nil {
@@ -207,13 +221,13 @@ func (h *CampaignHandler) CreateCampaign(c echo.Context) error {
 	}
 
 	if err := h.Validator.Struct(body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send to usecase for insert logic
-	err = h.Dependencies.UC.CreateCampaign(workspaceID, body)
+	err = h.Dependencies.UC.CreateCampaign(c.Request().Context(), helpers.Actor(c), workspaceID, body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send success response
@@ -244,7 +258,7 @@ func (h *CampaignHandler) UpdateCampaign(c echo.Context) error {
 	// check allowed user
 	err := helpers.CheckAllowedCampaign(c, workspaceID, ID, h.Dependencies.DB)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// check for valid body
@@ -253,13 +267,13 @@ func (h *CampaignHandler) UpdateCampaign(c echo.Context) error {
 	}
 
 	if err := h.Validator.Struct(body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send to usecase for update logic
-	err = h.Dependencies.UC.UpdateCampaign(workspaceID, ID, body)
+	err = h.Dependencies.UC.UpdateCampaign(c.Request().Context(), helpers.Actor(c), workspaceID, ID, body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err)
+		return err
 	}
 	return c.JSON(http.StatusNoContent, nil)
 }
@@ -282,12 +296,12 @@ func (h *CampaignHandler) DeleteCampaign(c echo.Context) error {
 	// check allowed user
 	err := helpers.CheckAllowedCampaign(c, workspaceID, ID, h.Dependencies.DB)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send to usecase to do delete process
-	if err := h.Dependencies.UC.DeleteCampaign(workspaceID, ID); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := h.Dependencies.UC.DeleteCampaign(c.Request().Context(), helpers.Actor(c), workspaceID, ID); err != nil {
+		return err
 	}
 	return c.JSON(http.StatusNoContent, nil)
 }
@@ -301,21 +315,24 @@ func (h *CampaignHandler) DeleteCampaign(c echo.Context) error {
 // @Param 		 campaign_id path string true "Campaign ID"
 // @Param 		 page query int true "Page of list data"
 // @Param 		 limit query int true "Limitting data you want to get"
-// @Param 		 search query string false "Find your data with keywords"
-// @Param 		 orderBy query string false "Ordering data" example(created_at:desc)
+// @Param 		 search query string false "Find by platform or event"
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(created_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
 // @Success      200  {object} commonschema.ResponseHTTP "Request success"
 // @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
 // @Router       /api/campaigns/seos/{campaign_id} [get]
 func (h *CampaignHandler) FindCampaignSeos(c echo.Context) error {
 	campaignID := c.Param("campaign_id")
-	params := utils.QParams(c)
+	params, err := utils.QParams(c, masterschema.CampaignSeoQuerySpec)
+	if err != nil {
+		return err
+	}
 	response := commonschema.ResponseHTTP{Code: http.StatusBadRequest}
 
 	// send to usecase to get data
-	list, err := h.Dependencies.UC.FindCampaignSeos(campaignID, params)
+	list, err := h.Dependencies.UC.FindCampaignSeos(c.Request().Context(), campaignID, params)
 	if err != nil {
-		response.Message = err.Error()
-		return c.JSON(response.Code, response)
+		return err
 	}
 
 	// send response
@@ -342,7 +359,7 @@ func (h *CampaignHandler) FindCampaignSeo(c echo.Context) error {
 	ID := c.Param("id")
 
 	// get existing data
-	campaignSeo, err := h.Dependencies.UC.FindCampaignSeoByID(campaignID, ID)
+	campaignSeo, err := h.Dependencies.UC.FindCampaignSeoByID(c.Request().Context(), campaignID, ID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "Data is not found")
 	}
@@ -376,13 +393,13 @@ func (h *CampaignHandler) CreateCampaignSeo(c echo.Context) error {
 	}
 
 	if err := h.Validator.Struct(body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send to usecase for insert logic
-	err := h.Dependencies.UC.CreateCampaignSeo(campaignID, body)
+	err := h.Dependencies.UC.CreateCampaignSeo(c.Request().Context(), helpers.Actor(c), campaignID, body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send success response
@@ -416,13 +433,13 @@ func (h *CampaignHandler) UpdateCampaignSeo(c echo.Context) error {
 	}
 
 	if err := h.Validator.Struct(body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send to usecase for update logic
-	err := h.Dependencies.UC.UpdateCampaignSeo(campaignID, ID, body)
+	err := h.Dependencies.UC.UpdateCampaignSeo(c.Request().Context(), helpers.Actor(c), campaignID, ID, body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err)
+		return err
 	}
 	return c.JSON(http.StatusNoContent, nil)
 }
@@ -444,20 +461,23 @@ func (h *CampaignHandler) DeleteCampaignSeo(c echo.Context) error {
 	ID := c.Param("id")
 
 	// send to usecase to do delete process
-	if err := h.Dependencies.UC.DeleteCampaignSeo(campaignID, ID); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := h.Dependencies.UC.DeleteCampaignSeo(c.Request().Context(), helpers.Actor(c), campaignID, ID); err != nil {
+		return err
 	}
 	return c.JSON(http.StatusNoContent, nil)
 }
 
 // @Security BearerAuth
 // @Summary      Form Entries Graphic
-// @Description  Get line-graph for form entries for last 30days
+// @Description  Get line-graph for form entries grouped by day, week or month in workspace timezone
 // @Tags         Master - Campaign Analytics
 // @Accept  	 json
 // @Produce  	 json
 // @Param 		 workspace_id path string true "Workspace ID"
 // @Param 		 campaign_id path string true "Campaign ID"
+// @Param 		 start_date query string false "Start date (YYYY-MM-DD), default 60 days ago"
+// @Param 		 end_date query string false "End date (YYYY-MM-DD), default today"
+// @Param 		 group_by query string false "Grouping of data" Enums(day, week, month)
 // @Success      200  {object} commonschema.ResponseHTTP "Request success"
 // @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
 // @Router       /api/campaigns/analytics/dashboard/{workspace_id}/{campaign_id} [get]
@@ -465,18 +485,64 @@ func (h *CampaignHandler) DashboardAnalytics(c echo.Context) error {
 	// get parameters
 	workspaceID := c.Param("workspace_id")
 	campaignID := c.Param("campaign_id")
+	params, err := utils.AParams(c)
+	if err != nil {
+		return err
+	}
 
 	// check allowed user
-	err := helpers.CheckAllowedCampaign(c, workspaceID, campaignID, h.Dependencies.DB)
+	err = helpers.CheckAllowedCampaign(c, workspaceID, campaignID, h.Dependencies.DB)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// get data form entries by workspace and campaign group by date
-	// get only for last 60 days (for this version)
-	data, err := h.Dependencies.UC.FindSummaryEntriesByDate(workspaceID, campaignID)
+	data, err := h.Dependencies.UC.FindSummaryEntriesByDate(c.Request().Context(), workspaceID, campaignID, params)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    data,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Field Analytics
+// @Description  Get answer distribution, numeric stats and completion rate for each field
+// @Tags         Master - Campaign Analytics
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 workspace_id path string true "Workspace ID"
+// @Param 		 campaign_id path string true "Campaign ID"
+// @Param 		 start_date query string false "Start date (YYYY-MM-DD), default 60 days ago"
+// @Param 		 end_date query string false "End date (YYYY-MM-DD), default today"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/campaigns/analytics/fields/{workspace_id}/{campaign_id} [get]
+func (h *CampaignHandler) FieldAnalytics(c echo.Context) error {
+	// get parameters
+	workspaceID := c.Param("workspace_id")
+	campaignID := c.Param("campaign_id")
+	params, err := utils.AParams(c)
+	if err != nil {
+		return err
+	}
+
+	// check allowed user
+	err = helpers.CheckAllowedCampaign(c, workspaceID, campaignID, h.Dependencies.DB)
+	if err != nil {
+		return err
+	}
+
+	// get breakdown of answers for each field
+	data, err := h.Dependencies.UC.FindFieldAnalytics(c.Request().Context(), workspaceID, campaignID, params)
+	if err != nil {
+		return err
 	}
 
 	// send response
@@ -498,7 +564,16 @@ func (h *CampaignHandler) DashboardAnalytics(c echo.Context) error {
 // @Param 		 campaign_id path string true "Campaign ID"
 // @Param 		 page query int true "Page of list data"
 // @Param 		 limit query int true "Limitting data you want to get"
-// @Param 		 search query string false "Find your data with keywords"
+// @Param 		 search query string false "Find your data with keywords, including answers"
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(created_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
+// @Param 		 status query string false "Filter by status (S1, S2, S3, S4)"
+// @Param 		 start_date query string false "Filter entries from date (YYYY-MM-DD)"
+// @Param 		 end_date query string false "Filter entries until date (YYYY-MM-DD)"
+// @Param 		 field[campaign_form_id] query string false "Filter by answer with operator eq, contains, gt, gte, lt or lte" example(contains:1234)
+// @Param 		 pagination query string false "Pagination mode, offset or cursor" default(offset)
+// @Param 		 cursor query string false "Cursor token from next_cursor or prev_cursor"
+// @Param 		 count query bool false "Set false to skip total_page count, default false on cursor pagination"
 // @Success      200  {object} commonschema.ResponseHTTP "Request success"
 // @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
 // @Router       /api/campaigns/analytics/form_entries/{workspace_id}/{campaign_id} [get]
@@ -506,20 +581,26 @@ func (h *CampaignHandler) FindFormEntries(c echo.Context) error {
 	// get parameters
 	workspaceID := c.Param("workspace_id")
 	campaignID := c.Param("campaign_id")
-	params := utils.QParams(c)
+	params, err := utils.QParams(c, masterschema.FormEntryQuerySpec)
+	if err != nil {
+		return err
+	}
 	response := commonschema.ResponseHTTP{Code: http.StatusBadRequest}
+	filter, err := utils.EParams(c)
+	if err != nil {
+		return err
+	}
 
 	// check allowed user
-	err := helpers.CheckAllowedCampaign(c, workspaceID, campaignID, h.Dependencies.DB)
+	err = helpers.CheckAllowedCampaign(c, workspaceID, campaignID, h.Dependencies.DB)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send to usecase to get data
-	list, err := h.Dependencies.UC.FindFormEntries(workspaceID, campaignID, params)
+	list, err := h.Dependencies.UC.FindFormEntries(c.Request().Context(), workspaceID, campaignID, params, filter)
 	if err != nil {
-		response.Message = err.Error()
-		return c.JSON(response.Code, response)
+		return err
 	}
 
 	// send response
@@ -551,17 +632,13 @@ func (h *CampaignHandler) FindDetailFormEntry(c echo.Context) error {
 	// check allowed user
 	err := helpers.CheckAllowedCampaign(c, workspaceID, campaignID, h.Dependencies.DB)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send to usecase to get data
-	data, err := h.Dependencies.UC.FindFormEntry(c, ID)
+	data, err := h.Dependencies.UC.FindFormEntry(c.Request().Context(), c, ID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return echo.NewHTTPError(http.StatusNotFound, err.Error())
-		} else {
-			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-		}
+		return err
 	}
 
 	// send response
@@ -570,3 +647,124 @@ func (h *CampaignHandler) FindDetailFormEntry(c echo.Context) error {
 	response.Data = data
 	return c.JSON(response.Code, response)
 }
+
+// @Security BearerAuth
+// @Summary      Review Form Entry
+// @Description  Approve or reject user entry for each campaign
+// @Tags         Master - Campaign Analytics
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 workspace_id path string true "Workspace ID"
+// @Param 		 campaign_id path string true "Campaign ID"
+// @Param 		 id path string true "ID"
+// @Param        formEntryReviewPayload  body      masterschema.FormEntryReviewPayload   true  "form entry review payload"
+// @Success      204  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/campaigns/analytics/form_entries/{workspace_id}/{campaign_id}/{id} [put]
+func (h *CampaignHandler) ReviewFormEntry(c echo.Context) error {
+	// get payload and parameters
+	workspaceID := c.Param("workspace_id")
+	campaignID := c.Param("campaign_id")
+	ID := c.Param("id")
+	var body masterschema.FormEntryReviewPayload
+
+	// check allowed user
+	err := helpers.CheckAllowedCampaign(c, workspaceID, campaignID, h.Dependencies.DB)
+	if err != nil {
+		return err
+	}
+
+	// check for valid body
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid body payload")
+	}
+
+	if err := h.Validator.Struct(body); err != nil {
+		return err
+	}
+
+	// send to usecase for review logic
+	err = h.Dependencies.UC.ReviewFormEntry(c.Request().Context(), helpers.Actor(c), campaignID, ID, body)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusNoContent, nil)
+}
+
+// @Security BearerAuth
+// @Summary      Spam Setting
+// @Description  Get anti-spam setting of public submission for this campaign
+// @Tags         Master - Campaigns
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 workspace_id path string true "Workspace ID"
+// @Param 		 campaign_id path string true "Campaign ID"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/campaigns/spam_settings/{workspace_id}/{campaign_id} [get]
+func (h *CampaignHandler) FindSpamSetting(c echo.Context) error {
+	// get parameters
+	workspaceID := c.Param("workspace_id")
+	campaignID := c.Param("campaign_id")
+
+	// check allowed user
+	err := helpers.CheckAllowedCampaign(c, workspaceID, campaignID, h.Dependencies.DB)
+	if err != nil {
+		return err
+	}
+
+	// get existing data
+	data, err := h.Dependencies.UC.FindSpamSetting(c.Request().Context(), campaignID)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    data,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Update Spam Setting
+// @Description  Enable honeypot, minimum time to submit, captcha or duplicate check for public submission
+// @Tags         Master - Campaigns
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 workspace_id path string true "Workspace ID"
+// @Param 		 campaign_id path string true "Campaign ID"
+// @Param        campaignSpamSettingPayload  body      masterschema.CampaignSpamSettingPayload   true  "campaign spam setting payload"
+// @Success      204  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/campaigns/spam_settings/{workspace_id}/{campaign_id} [put]
+func (h *CampaignHandler) UpdateSpamSetting(c echo.Context) error {
+	// get payload and parameters
+	workspaceID := c.Param("workspace_id")
+	campaignID := c.Param("campaign_id")
+	var body masterschema.CampaignSpamSettingPayload
+
+	// check allowed user
+	err := helpers.CheckAllowedCampaign(c, workspaceID, campaignID, h.Dependencies.DB)
+	if err != nil {
+		return err
+	}
+
+	// check for valid body
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid body payload")
+	}
+
+	if err := h.Validator.Struct(body); err != nil {
+		return err
+	}
+
+	// send to usecase to save setting
+	err = h.Dependencies.UC.UpdateSpamSetting(c.Request().Context(), helpers.Actor(c), campaignID, body)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusNoContent, nil)
+}
@@ -3,6 +3,7 @@ package masterroute
 import (
 	masterdi "kiraform/src/applications/dependencies/masters"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
 	"kiraform/src/utils"
 	"net/http"
 
@@ -26,7 +27,7 @@ func NewFormHandler(DB *gorm.DB, validator *validator.Validate, dependencies mas
 }
 
 func NewFormHTTP(g *echo.Group, DB *gorm.DB) {
-	validator := validator.New()
+	validator := utils.NewValidator()
 	h := NewFormHandler(DB, validator, *masterdi.NewFormDependencies(DB))
 
 	// define endpoints
@@ -44,7 +45,8 @@ func NewFormHTTP(g *echo.Group, DB *gorm.DB) {
 // @Param 		 page query int true "Page of list data"
 // @Param 		 limit query int true "Limitting data you want to get"
 // @Param 		 search query string false "Find your data with keywords"
-// @Param 		 orderBy query string false "Ordering data" example(created_at:desc)
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(created_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
 // @Success      200  {object} commonschema.ResponseHTTP "Request success"
 // @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
 // @Router       /api/forms [get]
@@ -53,11 +55,13 @@ func (h *FormHandler) FindForms(c echo.Context) error {
 	response := commonschema.ResponseHTTP{Code: http.StatusBadRequest}
 
 	// perform to get data
-	params := utils.QParams(c)
-	list, err := h.Dependencies.UC.FindForms(params)
+	params, err := utils.QParams(c, masterschema.FormQuerySpec)
 	if err != nil {
-		response.Message = err.Error()
-		return c.JSON(response.Code, response)
+		return err
+	}
+	list, err := h.Dependencies.UC.FindForms(c.Request().Context(), params)
+	if err != nil {
+		return err
 	}
 
 	// send response
@@ -83,7 +87,7 @@ func (h *FormHandler) FindForm(c echo.Context) error {
 
 	// get data by id
 	id := c.Param("id")
-	data, err := h.Dependencies.UC.FindFormByID(id)
+	data, err := h.Dependencies.UC.FindFormByID(c.Request().Context(), id)
 	if err != nil {
 		response.Message = err.Error()
 		return c.JSON(http.StatusNotFound, response)
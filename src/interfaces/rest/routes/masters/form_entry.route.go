@@ -3,11 +3,17 @@ package masterroute
 import (
 	"fmt"
 	masterdi "kiraform/src/applications/dependencies/masters"
+	"kiraform/src/applications/keyring"
+	"kiraform/src/applications/sessions"
+	"kiraform/src/infras/configs"
+	"kiraform/src/infras/ratelimit"
 	"kiraform/src/interfaces/rest/middlewares"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
 	masterschema "kiraform/src/interfaces/rest/schemas/masters"
 	"kiraform/src/utils"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
@@ -28,19 +34,29 @@ func NewFormEntryHandler(DB *gorm.DB, validator *validator.Validate, dependencie
 	}
 }
 
-func NewFormEntryHTTP(g *echo.Group, DB *gorm.DB) {
-	validator := validator.New()
-	h := NewFormEntryHandler(DB, validator, *masterdi.NewFormEntryDependencies(DB))
+func NewFormEntryHTTP(g *echo.Group, DB *gorm.DB, limiter ratelimit.Store, config configs.Config, keys *keyring.Keyring, tracker *sessions.Tracker) {
+	validator := utils.NewValidator()
+	h := NewFormEntryHandler(DB, validator, *masterdi.NewFormEntryDependencies(DB, limiter, config))
 
 	// define [unauthrozid] endpoints
 	fe := g.Group("/form_entries")
 	fe.GET("/:campaign_key", h.PreviewForm)
-	fe.POST("/:campaign_id", h.EntryForm)
+	fe.POST("/:campaign_id", h.EntryForm, middlewares.RateLimit(limiter, middlewares.RateLimitConfig{
+		Name:   "form_entry",
+		Limit:  20,
+		Window: time.Minute,
+	}))
 
 	// define [authorized] endpointes
 	// pfe = private_form_entries
 	pfe := g.Group("/form_entries") // re-define
-	pfe.Use(middlewares.VerifyToken)
+	pfe.Use(middlewares.VerifyToken(keys, nil, tracker))
+	pfe.Use(middlewares.RateLimit(limiter, middlewares.RateLimitConfig{
+		Name:    "private",
+		Limit:   300,
+		Window:  time.Minute,
+		KeyFunc: middlewares.ByUser,
+	}))
 
 	pfe.GET("/history", h.GetHistory)
 	pfe.GET("/history/:id", h.GetDetailHistory)
@@ -61,29 +77,41 @@ func (h *FormEntryHandler) PreviewForm(c echo.Context) error {
 
 	// send to usecase to validate and get detail of campaign
 	isPublish := true // find only published campaign
-	data, err := h.Dependencies.UCcampaign.FindCampaignByKey(campaignKey, &isPublish)
+	data, err := h.Dependencies.UCcampaign.FindCampaignByKey(c.Request().Context(), campaignKey, &isPublish)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, nil)
 	}
 
 	// get detail form by this campaign
-	forms, err := h.Dependencies.UCcampaign.FindFormsByCampaign(data.ID.String())
+	forms, err := h.Dependencies.UCcampaign.FindFormsByCampaign(c.Request().Context(), data.ID.String())
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// get attributes each form
 	// handle if forms is null, because there is possibility for it
 	for i, v := range forms {
-		attr, err := h.Dependencies.UCcampaign.FindFormAttributes(v.ID.String())
+		attr, err := h.Dependencies.UCcampaign.FindFormAttributes(c.Request().Context(), v.ID.String())
 		if err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest, err)
+			return err
 		}
 		forms[i].Attributes = attr
 	}
 
+	// count visit for conversion analytics, failing here must not block the form
+	if err := h.Dependencies.UCcampaign.RecordVisit(c.Request().Context(), data.ID.String()); err != nil {
+		slog.ErrorContext(c.Request().Context(), "failed to record campaign visit", "campaign_id", data.ID.String(), "error", err)
+	}
+
+	// issue form token and tell which anti-spam inputs are required
+	guard, err := h.Dependencies.UC.FormEntryGuard(c.Request().Context(), data.ID.String())
+	if err != nil {
+		return err
+	}
+
 	// prepare response
 	data.Forms = forms
+	data.Guard = guard
 	response := commonschema.ResponseHTTP{
 		Code:    http.StatusOK,
 		Message: "Request success",
@@ -99,9 +127,13 @@ func (h *FormEntryHandler) PreviewForm(c echo.Context) error {
 // @Produce  	 json
 // @Param 		 campaign_id path string true "Campaign ID"
 // @Param 		 product_id query string false "Product ID"
+// @Param 		 X-Form-Token header string false "Form token from preview form"
+// @Param 		 X-Captcha-Token header string false "Solved captcha token, required when campaign enables captcha"
+// @Param 		 X-Honeypot header string false "Value of hidden honeypot field, must be empty"
 // @Param        formEntryPayload  body      []masterschema.FormEntryPayload   true  "form entry payload"
 // @Success      200  {object} commonschema.ResponseHTTP "Request success"
 // @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Failure      429  {object} commonschema.ResponseHTTP "Too many requests"
 // @Router       /api/form_entries/{campaign_id} [post]
 func (h *FormEntryHandler) EntryForm(c echo.Context) error {
 	// get parameters
@@ -109,6 +141,12 @@ func (h *FormEntryHandler) EntryForm(c echo.Context) error {
 	userID, _ := c.Get("user_id").(string)
 	var body []masterschema.FormEntryPayload
 	productID := c.QueryParam("product_id")
+	guard := masterschema.FormEntryGuardPayload{
+		Honeypot:     c.Request().Header.Get("X-Honeypot"),
+		FormToken:    c.Request().Header.Get("X-Form-Token"),
+		CaptchaToken: c.Request().Header.Get("X-Captcha-Token"),
+		RemoteIP:     c.RealIP(),
+	}
 
 	// validate body
 	if err := c.Bind(&body); err != nil {
@@ -122,9 +160,10 @@ func (h *FormEntryHandler) EntryForm(c echo.Context) error {
 	}
 
 	// send to usecase for business process
-	err := h.Dependencies.UC.EntryForm(campaignID, &userID, body, &productID)
+	// suspected spam gets the same response, so bots can not learn from it
+	err := h.Dependencies.UC.EntryForm(c.Request().Context(), campaignID, &userID, body, &productID, guard)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err)
+		return err
 	}
 
 	// send success response
@@ -142,8 +181,16 @@ func (h *FormEntryHandler) EntryForm(c echo.Context) error {
 // @Produce  	 json
 // @Param 		 page query int true "Page of list data"
 // @Param 		 limit query int true "Limitting data you want to get"
-// @Param 		 search query string false "Find your data with keywords"
-// @Param 		 orderBy query string false "Ordering data" example(created_at:desc)
+// @Param 		 search query string false "Find your data with keywords, including answers"
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(created_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
+// @Param 		 status query string false "Filter by status (S1, S2, S3, S4)"
+// @Param 		 start_date query string false "Filter entries from date (YYYY-MM-DD)"
+// @Param 		 end_date query string false "Filter entries until date (YYYY-MM-DD)"
+// @Param 		 field[campaign_form_id] query string false "Filter by answer with operator eq, contains, gt, gte, lt or lte" example(contains:1234)
+// @Param 		 pagination query string false "Pagination mode, offset or cursor" default(offset)
+// @Param 		 cursor query string false "Cursor token from next_cursor or prev_cursor"
+// @Param 		 count query bool false "Set false to skip total_page count, default false on cursor pagination"
 // @Success      200  {object} commonschema.ResponseHTTP "Request success"
 // @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
 // @Router       /api/form_entries/history [get]
@@ -153,12 +200,19 @@ func (h *FormEntryHandler) GetHistory(c echo.Context) error {
 	if !ok {
 		return echo.NewHTTPError(http.StatusBadRequest, "Your account is not auhtorized yet")
 	}
-	params := utils.QParams(c)
+	params, err := utils.QParams(c, masterschema.HistoryQuerySpec)
+	if err != nil {
+		return err
+	}
+	filter, err := utils.EParams(c)
+	if err != nil {
+		return err
+	}
 
 	// get history data
-	data, err := h.Dependencies.UC.GetHistory(userID, params)
+	data, err := h.Dependencies.UC.GetHistory(c.Request().Context(), userID, params, filter)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send response
@@ -185,9 +239,9 @@ func (h *FormEntryHandler) GetDetailHistory(c echo.Context) error {
 	}
 
 	// get detail of history
-	data, err := h.Dependencies.UC.GetDetailHistory(userID, ID)
+	data, err := h.Dependencies.UC.GetDetailHistory(c.Request().Context(), userID, ID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err)
+		return err
 	}
 
 	// send response
@@ -0,0 +1,252 @@
+package masterroute
+
+import (
+	masterdi "kiraform/src/applications/dependencies/masters"
+	"kiraform/src/applications/helpers"
+	"kiraform/src/applications/models"
+	"kiraform/src/applications/sessions"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"kiraform/src/utils"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type RoleHandler struct {
+	DB           *gorm.DB
+	Validator    *validator.Validate
+	Dependencies masterdi.RoleDependencies
+}
+
+func NewRoleHandler(DB *gorm.DB, validator *validator.Validate, dependencies masterdi.RoleDependencies) *RoleHandler {
+	return &RoleHandler{
+		DB:           DB,
+		Validator:    validator,
+		Dependencies: dependencies,
+	}
+}
+
+func NewRoleHTTP(g *echo.Group, DB *gorm.DB, tracker *sessions.Tracker) {
+	validator := utils.NewValidator()
+	h := NewRoleHandler(DB, validator, *masterdi.NewRoleDependencies(DB, tracker))
+
+	// define endpoints, every one of them needs roles:manage permission
+	r := g.Group("/roles")
+	r.GET("", h.FindRoles)
+	r.GET("/permissions", h.FindPermissions)
+	r.GET("/:id", h.FindRole)
+	r.POST("", h.CreateRole)
+	r.PUT("/:id", h.UpdateRole)
+	r.DELETE("/:id", h.DeleteRole)
+}
+
+// @Security BearerAuth
+// @Summary      List Roles
+// @Description  Get global roles with their permissions and total holder, needs roles:manage permission
+// @Tags         Master - Roles
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 page query int true "Page of list data"
+// @Param 		 limit query int true "Limitting data you want to get"
+// @Param 		 search query string false "Find by name or description"
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(name:asc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
+// @Param 		 pagination query string false "Pagination mode, offset or cursor" default(offset)
+// @Param 		 cursor query string false "Cursor token from next_cursor or prev_cursor"
+// @Param 		 count query bool false "Set false to skip total_page count, default false on cursor pagination"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Failure      403  {object} commonschema.ResponseHTTP "Permission is required"
+// @Router       /api/roles [get]
+func (h *RoleHandler) FindRoles(c echo.Context) error {
+	// only user with permission can manage roles
+	if err := helpers.CheckPermission(c, models.PermissionRolesManage); err != nil {
+		return err
+	}
+
+	// perform to get data
+	params, err := utils.QParams(c, masterschema.RoleQuerySpec)
+	if err != nil {
+		return err
+	}
+	list, err := h.Dependencies.UC.FindRoles(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    list,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      List Permissions
+// @Description  Get every permission which can be granted to roles, needs roles:manage permission
+// @Tags         Master - Roles
+// @Accept  	 json
+// @Produce  	 json
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      403  {object} commonschema.ResponseHTTP "Permission is required"
+// @Router       /api/roles/permissions [get]
+func (h *RoleHandler) FindPermissions(c echo.Context) error {
+	// only user with permission can manage roles
+	if err := helpers.CheckPermission(c, models.PermissionRolesManage); err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    models.Permissions,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Detail Role
+// @Description  Get role with its permissions and total holder, needs roles:manage permission
+// @Tags         Master - Roles
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 id path string true "Role ID"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      403  {object} commonschema.ResponseHTTP "Permission is required"
+// @Failure      404  {object} commonschema.ResponseHTTP "Role not found"
+// @Router       /api/roles/{id} [get]
+func (h *RoleHandler) FindRole(c echo.Context) error {
+	// only user with permission can manage roles
+	if err := helpers.CheckPermission(c, models.PermissionRolesManage); err != nil {
+		return err
+	}
+
+	// perform to get data
+	data, err := h.Dependencies.UC.FindRole(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    data,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Create Role
+// @Description  Create global role with named permissions, needs roles:manage permission
+// @Tags         Master - Roles
+// @Accept  	 json
+// @Produce  	 json
+// @Param        rolePayload  body      masterschema.RolePayload   true  "Role payload"
+// @Success      201  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Failure      403  {object} commonschema.ResponseHTTP "Permission is required"
+// @Router       /api/roles [post]
+func (h *RoleHandler) CreateRole(c echo.Context) error {
+	// only user with permission can manage roles
+	if err := helpers.CheckPermission(c, models.PermissionRolesManage); err != nil {
+		return err
+	}
+
+	var body masterschema.RolePayload
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid body")
+	}
+
+	if err := h.Validator.Struct(body); err != nil {
+		return err
+	}
+
+	// call usecase for business validation
+	data, err := h.Dependencies.UC.CreateRole(c.Request().Context(), helpers.Actor(c), body)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusCreated,
+		Message: "Data is successfully created",
+		Data:    data,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Update Role
+// @Description  Update role, its holders are signed out when its name or permissions change, built-in roles can not be renamed and admin keeps every permission, needs roles:manage permission
+// @Tags         Master - Roles
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 id path string true "Role ID"
+// @Param        rolePayload  body      masterschema.RolePayload   true  "Role payload"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Failure      403  {object} commonschema.ResponseHTTP "Permission is required"
+// @Failure      404  {object} commonschema.ResponseHTTP "Role not found"
+// @Router       /api/roles/{id} [put]
+func (h *RoleHandler) UpdateRole(c echo.Context) error {
+	// only user with permission can manage roles
+	if err := helpers.CheckPermission(c, models.PermissionRolesManage); err != nil {
+		return err
+	}
+
+	var body masterschema.RolePayload
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid body")
+	}
+
+	if err := h.Validator.Struct(body); err != nil {
+		return err
+	}
+
+	// call usecase for business validation
+	if err := h.Dependencies.UC.UpdateRole(c.Request().Context(), helpers.Actor(c), c.Param("id"), body); err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Data is successfully updated",
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Delete Role
+// @Description  Delete role which nobody holds, built-in roles can not be deleted, needs roles:manage permission
+// @Tags         Master - Roles
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 id path string true "Role ID"
+// @Success      204  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      403  {object} commonschema.ResponseHTTP "Permission is required"
+// @Failure      404  {object} commonschema.ResponseHTTP "Role not found"
+// @Failure      409  {object} commonschema.ResponseHTTP "Role is built-in or still held"
+// @Router       /api/roles/{id} [delete]
+func (h *RoleHandler) DeleteRole(c echo.Context) error {
+	// only user with permission can manage roles
+	if err := helpers.CheckPermission(c, models.PermissionRolesManage); err != nil {
+		return err
+	}
+
+	// call usecase for business validation
+	if err := h.Dependencies.UC.DeleteRole(c.Request().Context(), helpers.Actor(c), c.Param("id")); err != nil {
+		return err
+	}
+
+	// send success response
+	return c.JSON(http.StatusNoContent, nil)
+}
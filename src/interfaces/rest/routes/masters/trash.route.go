@@ -0,0 +1,186 @@
+package masterroute
+
+import (
+	masterdi "kiraform/src/applications/dependencies/masters"
+	"kiraform/src/applications/helpers"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"kiraform/src/utils"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type TrashHandler struct {
+	DB           *gorm.DB
+	Validator    *validator.Validate
+	Dependencies masterdi.TrashDependencies
+}
+
+func NewTrashHandler(DB *gorm.DB, validator *validator.Validate, dependencies masterdi.TrashDependencies) *TrashHandler {
+	return &TrashHandler{
+		DB:           DB,
+		Validator:    validator,
+		Dependencies: dependencies,
+	}
+}
+
+func NewTrashHTTP(g *echo.Group, DB *gorm.DB) {
+	validator := utils.NewValidator()
+	h := NewTrashHandler(DB, validator, *masterdi.NewTrashDependencies(DB))
+
+	// define endpoints
+	t := g.Group("/trash/workspaces")
+	t.GET("", h.FindDeletedWorkspaces)
+	t.PUT("/:workspace_id", h.RestoreWorkspace)
+	t.GET("/:workspace_id", h.FindWorkspaceTrash)
+	t.PUT("/:workspace_id/:entity_type/:id", h.RestoreWorkspaceItem)
+}
+
+// @Security BearerAuth
+// @Summary      Deleted Workspaces
+// @Description  Get the list of deleted workspaces you own
+// @Tags         Master - Trash
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 page query int true "Page of list data"
+// @Param 		 limit query int true "Limitting data you want to get"
+// @Param 		 search query string false "Find your data with keywords"
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(deleted_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
+// @Param 		 pagination query string false "Pagination mode, offset or cursor" default(offset)
+// @Param 		 cursor query string false "Cursor token from next_cursor or prev_cursor"
+// @Param 		 count query bool false "Set false to skip total_page count, default false on cursor pagination"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/trash/workspaces [get]
+func (h *TrashHandler) FindDeletedWorkspaces(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	// perform to get data
+	params, err := utils.QParams(c, masterschema.TrashQuerySpec)
+	if err != nil {
+		return err
+	}
+	list, err := h.Dependencies.UC.FindDeletedWorkspaces(c.Request().Context(), userID, params)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    list,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Restore Workspace
+// @Description  Restore deleted workspace, only owner is allowed
+// @Tags         Master - Trash
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 workspace_id path string true "Workspace ID"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      404  {object} commonschema.ResponseHTTP "Workspace not found in trash"
+// @Router       /api/trash/workspaces/{workspace_id} [put]
+func (h *TrashHandler) RestoreWorkspace(c echo.Context) error {
+	workspaceID := c.Param("workspace_id")
+
+	// perform to restore data
+	if err := h.Dependencies.UC.RestoreWorkspace(c.Request().Context(), helpers.Actor(c), workspaceID); err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Data restored",
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Workspace Trash
+// @Description  Get deleted campaigns and campaign seo in workspace
+// @Tags         Master - Trash
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 workspace_id path string true "Workspace ID"
+// @Param 		 page query int true "Page of list data"
+// @Param 		 limit query int true "Limitting data you want to get"
+// @Param 		 search query string false "Find your data with keywords"
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(deleted_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
+// @Param 		 pagination query string false "Pagination mode, offset or cursor" default(offset)
+// @Param 		 cursor query string false "Cursor token from next_cursor or prev_cursor"
+// @Param 		 count query bool false "Set false to skip total_page count, default false on cursor pagination"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/trash/workspaces/{workspace_id} [get]
+func (h *TrashHandler) FindWorkspaceTrash(c echo.Context) error {
+	workspaceID := c.Param("workspace_id")
+
+	// check allowed access
+	if err := helpers.CheckAllowedWorkspace(c, workspaceID, h.Dependencies.DB); err != nil {
+		return err
+	}
+
+	// perform to get data
+	params, err := utils.QParams(c, masterschema.TrashQuerySpec)
+	if err != nil {
+		return err
+	}
+	list, err := h.Dependencies.UC.FindWorkspaceTrash(c.Request().Context(), workspaceID, params)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    list,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Restore Workspace Item
+// @Description  Restore deleted campaign or campaign seo, campaign is restored together with its forms
+// @Tags         Master - Trash
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 workspace_id path string true "Workspace ID"
+// @Param 		 entity_type path string true "Type of data" Enums(campaign, campaign_seo)
+// @Param 		 id path string true "ID of your data"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Failure      404  {object} commonschema.ResponseHTTP "Data not found in trash"
+// @Router       /api/trash/workspaces/{workspace_id}/{entity_type}/{id} [put]
+func (h *TrashHandler) RestoreWorkspaceItem(c echo.Context) error {
+	workspaceID := c.Param("workspace_id")
+	entityType := c.Param("entity_type")
+	ID := c.Param("id")
+
+	// check allowed access
+	if err := helpers.CheckAllowedWorkspace(c, workspaceID, h.Dependencies.DB); err != nil {
+		return err
+	}
+
+	// perform to restore data
+	if err := h.Dependencies.UC.RestoreWorkspaceItem(c.Request().Context(), helpers.Actor(c), workspaceID, entityType, ID); err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Data restored",
+	}
+	return c.JSON(response.Code, response)
+}
@@ -0,0 +1,363 @@
+package masterroute
+
+import (
+	masterdi "kiraform/src/applications/dependencies/masters"
+	"kiraform/src/applications/helpers"
+	"kiraform/src/applications/keyring"
+	"kiraform/src/applications/models"
+	"kiraform/src/applications/sessions"
+	"kiraform/src/infras/configs"
+	"kiraform/src/infras/mailer"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"kiraform/src/utils"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type UserHandler struct {
+	DB           *gorm.DB
+	Validator    *validator.Validate
+	Dependencies masterdi.UserDependencies
+}
+
+func NewUserHandler(DB *gorm.DB, validator *validator.Validate, dependencies masterdi.UserDependencies) *UserHandler {
+	return &UserHandler{
+		DB:           DB,
+		Validator:    validator,
+		Dependencies: dependencies,
+	}
+}
+
+func NewUserHTTP(g *echo.Group, DB *gorm.DB, config configs.AuthConfig, keys *keyring.Keyring, mail mailer.Mailer, tracker *sessions.Tracker) {
+	validator := utils.NewValidator()
+	h := NewUserHandler(DB, validator, *masterdi.NewUserDependencies(DB, config, keys, mail, tracker))
+
+	// define endpoints, every one of them needs users:manage permission
+	u := g.Group("/users")
+	u.GET("", h.FindUsers)
+	u.GET("/:id", h.FindUser)
+	u.PUT("/active/:id", h.UpdateUserActive)
+	u.DELETE("/:id", h.DeleteUser)
+	u.PUT("/restore/:id", h.RestoreUser)
+	u.POST("/reset_password/:id", h.ResetUserPassword)
+	u.POST("/roles/:id", h.AssignUserRole)
+	u.DELETE("/roles/:id/:role", h.RevokeUserRole)
+	u.POST("/packages/:id", h.GrantUserPackage)
+}
+
+// @Security BearerAuth
+// @Summary      List Users
+// @Description  Get every user with its roles, active package and total workspace, deleted users are included, needs users:manage permission
+// @Tags         Master - Users
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 page query int true "Page of list data"
+// @Param 		 limit query int true "Limitting data you want to get"
+// @Param 		 search query string false "Find by email, name or user identity"
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(created_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
+// @Param 		 pagination query string false "Pagination mode, offset or cursor" default(offset)
+// @Param 		 cursor query string false "Cursor token from next_cursor or prev_cursor"
+// @Param 		 count query bool false "Set false to skip total_page count, default false on cursor pagination"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Failure      403  {object} commonschema.ResponseHTTP "Permission is required"
+// @Router       /api/users [get]
+func (h *UserHandler) FindUsers(c echo.Context) error {
+	// only user with permission can manage users
+	if err := helpers.CheckPermission(c, models.PermissionUsersManage); err != nil {
+		return err
+	}
+
+	// perform to get data
+	params, err := utils.QParams(c, masterschema.UserQuerySpec)
+	if err != nil {
+		return err
+	}
+	list, err := h.Dependencies.UC.FindUsers(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    list,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Detail User
+// @Description  Get user with its roles, active package and total workspace, needs users:manage permission
+// @Tags         Master - Users
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 id path string true "User ID"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      403  {object} commonschema.ResponseHTTP "Permission is required"
+// @Failure      404  {object} commonschema.ResponseHTTP "User not found"
+// @Router       /api/users/{id} [get]
+func (h *UserHandler) FindUser(c echo.Context) error {
+	// only user with permission can manage users
+	if err := helpers.CheckPermission(c, models.PermissionUsersManage); err != nil {
+		return err
+	}
+
+	// perform to get data
+	data, err := h.Dependencies.UC.FindUser(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    data,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Activate Or Deactivate User
+// @Description  Deactivated user is signed out of every device and its tokens and api keys are refused right away, needs users:manage permission
+// @Tags         Master - Users
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 id path string true "User ID"
+// @Param        userActivePayload  body      masterschema.UserActivePayload   true  "Active status"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Failure      403  {object} commonschema.ResponseHTTP "Permission is required"
+// @Failure      404  {object} commonschema.ResponseHTTP "User not found"
+// @Router       /api/users/active/{id} [put]
+func (h *UserHandler) UpdateUserActive(c echo.Context) error {
+	// only user with permission can manage users
+	if err := helpers.CheckPermission(c, models.PermissionUsersManage); err != nil {
+		return err
+	}
+
+	var body masterschema.UserActivePayload
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid body")
+	}
+
+	if err := h.Validator.Struct(body); err != nil {
+		return err
+	}
+
+	// call usecase for business validation
+	if err := h.Dependencies.UC.UpdateUserActive(c.Request().Context(), helpers.Actor(c), c.Param("id"), body); err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Data is successfully updated",
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Delete User
+// @Description  Soft delete user, it is deactivated and can be restored later, needs users:manage permission
+// @Tags         Master - Users
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 id path string true "User ID"
+// @Success      204  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      403  {object} commonschema.ResponseHTTP "Permission is required"
+// @Failure      404  {object} commonschema.ResponseHTTP "User not found"
+// @Router       /api/users/{id} [delete]
+func (h *UserHandler) DeleteUser(c echo.Context) error {
+	// only user with permission can manage users
+	if err := helpers.CheckPermission(c, models.PermissionUsersManage); err != nil {
+		return err
+	}
+
+	// call usecase for business validation
+	if err := h.Dependencies.UC.DeleteUser(c.Request().Context(), helpers.Actor(c), c.Param("id")); err != nil {
+		return err
+	}
+
+	// send success response
+	return c.JSON(http.StatusNoContent, nil)
+}
+
+// @Security BearerAuth
+// @Summary      Restore User
+// @Description  Restore deleted user, it stays inactive until activated again, needs users:manage permission
+// @Tags         Master - Users
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 id path string true "User ID"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      403  {object} commonschema.ResponseHTTP "Permission is required"
+// @Failure      404  {object} commonschema.ResponseHTTP "User not found"
+// @Router       /api/users/restore/{id} [put]
+func (h *UserHandler) RestoreUser(c echo.Context) error {
+	// only user with permission can manage users
+	if err := helpers.CheckPermission(c, models.PermissionUsersManage); err != nil {
+		return err
+	}
+
+	// perform to restore data
+	if err := h.Dependencies.UC.RestoreUser(c.Request().Context(), helpers.Actor(c), c.Param("id")); err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Data restored",
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Force Password Reset
+// @Description  Replace password of user, sign it out of every device and email it a link to set new password, needs users:manage permission
+// @Tags         Master - Users
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 id path string true "User ID"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      403  {object} commonschema.ResponseHTTP "Permission is required"
+// @Failure      404  {object} commonschema.ResponseHTTP "User not found"
+// @Router       /api/users/reset_password/{id} [post]
+func (h *UserHandler) ResetUserPassword(c echo.Context) error {
+	// only user with permission can manage users
+	if err := helpers.CheckPermission(c, models.PermissionUsersManage); err != nil {
+		return err
+	}
+
+	// call usecase for business validation
+	data, err := h.Dependencies.UC.ResetUserPassword(c.Request().Context(), helpers.Actor(c), c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Password is reset, a reset link has been sent to the user",
+		Data:    data,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Assign User Role
+// @Description  Add role to user, user is signed out so its next login carries the role, needs users:manage permission
+// @Tags         Master - Users
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 id path string true "User ID"
+// @Param        userRolePayload  body      masterschema.UserRolePayload   true  "Role name"
+// @Success      201  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Failure      403  {object} commonschema.ResponseHTTP "Permission is required"
+// @Failure      404  {object} commonschema.ResponseHTTP "User or role not found"
+// @Router       /api/users/roles/{id} [post]
+func (h *UserHandler) AssignUserRole(c echo.Context) error {
+	// only user with permission can manage users
+	if err := helpers.CheckPermission(c, models.PermissionUsersManage); err != nil {
+		return err
+	}
+
+	var body masterschema.UserRolePayload
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid body")
+	}
+
+	if err := h.Validator.Struct(body); err != nil {
+		return err
+	}
+
+	// call usecase for business validation
+	if err := h.Dependencies.UC.AssignUserRole(c.Request().Context(), helpers.Actor(c), c.Param("id"), body); err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusCreated,
+		Message: "Data is successfully created",
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Revoke User Role
+// @Description  Remove role of user, user keeps at least one role and is signed out, needs users:manage permission
+// @Tags         Master - Users
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 id path string true "User ID"
+// @Param 		 role path string true "Role name"
+// @Success      204  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      403  {object} commonschema.ResponseHTTP "Permission is required"
+// @Failure      404  {object} commonschema.ResponseHTTP "User or role not found"
+// @Router       /api/users/roles/{id}/{role} [delete]
+func (h *UserHandler) RevokeUserRole(c echo.Context) error {
+	// only user with permission can manage users
+	if err := helpers.CheckPermission(c, models.PermissionUsersManage); err != nil {
+		return err
+	}
+
+	// call usecase for business validation
+	if err := h.Dependencies.UC.RevokeUserRole(c.Request().Context(), helpers.Actor(c), c.Param("id"), c.Param("role")); err != nil {
+		return err
+	}
+
+	// send success response
+	return c.JSON(http.StatusNoContent, nil)
+}
+
+// @Security BearerAuth
+// @Summary      Grant User Package
+// @Description  Replace active package of user with package of code for some days, needs users:manage permission
+// @Tags         Master - Users
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 id path string true "User ID"
+// @Param        userPackagePayload  body      masterschema.UserPackagePayload   true  "Package code and days"
+// @Success      201  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Failure      403  {object} commonschema.ResponseHTTP "Permission is required"
+// @Failure      404  {object} commonschema.ResponseHTTP "User not found"
+// @Router       /api/users/packages/{id} [post]
+func (h *UserHandler) GrantUserPackage(c echo.Context) error {
+	// only user with permission can manage users
+	if err := helpers.CheckPermission(c, models.PermissionUsersManage); err != nil {
+		return err
+	}
+
+	var body masterschema.UserPackagePayload
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid body")
+	}
+
+	if err := h.Validator.Struct(body); err != nil {
+		return err
+	}
+
+	// call usecase for business validation
+	if err := h.Dependencies.UC.GrantUserPackage(c.Request().Context(), helpers.Actor(c), c.Param("id"), body); err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusCreated,
+		Message: "Data is successfully created",
+	}
+	return c.JSON(response.Code, response)
+}
@@ -2,13 +2,15 @@ package masterroute
 
 import (
 	"errors"
+	"kiraform/src/applications/apperrors"
 	masterdi "kiraform/src/applications/dependencies/masters"
 	"kiraform/src/applications/helpers"
+	"kiraform/src/applications/models"
+	"kiraform/src/interfaces/rest/middlewares"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
 	masterschema "kiraform/src/interfaces/rest/schemas/masters"
 	"kiraform/src/utils"
 	"net/http"
-	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
@@ -29,8 +31,8 @@ func NewWorkspaceHandler(DB *gorm.DB, validator *validator.Validate, dependencie
 	}
 }
 
-func NewWorkspaceHTTP(g *echo.Group, DB *gorm.DB) {
-	validator := validator.New()
+func NewWorkspaceHTTP(g *echo.Group, DB *gorm.DB, scopes middlewares.APIKeyScopes) {
+	validator := utils.NewValidator()
 	h := NewWorkspaceHandler(DB, validator, *masterdi.NewWorkspaceDependencies(DB))
 
 	// workspace endpoints
@@ -38,17 +40,19 @@ func NewWorkspaceHTTP(g *echo.Group, DB *gorm.DB) {
 	w.GET("", h.FindWorkspaces)
 	w.GET("/campaigns", h.FindAllCampaigns)
 	w.GET("/detail/:id", h.FindWorkspace)
+	w.GET("/analytics/:workspace_id", h.WorkspaceAnalytics)
 	w.POST("", h.CreateWorkspace)
 	w.PUT("/:id", h.UpdateWorkspace)
+	w.PUT("/two_factor/:workspace_id", h.UpdateWorkspaceTwoFactor)
 	w.DELETE("/:id", h.DeleteWokspace)
 
 	// workspace user endpoints
 	wu := w.Group("/users")
-	wu.GET("/:workspace_id", h.FindWorkspaceUsers)
-	wu.GET("/:workspace_id/:id", h.FindWorkspaceUser)
-	wu.POST("/:workspace_id", h.CreateWorkspaceUser)
-	wu.PUT("/:workspace_id/:id", h.UpdateWorkspaceUser)
-	wu.DELETE("/:workspace_id/:id", h.DeleteWokspaceUser)
+	scopes.Allow(wu.GET("/:workspace_id", h.FindWorkspaceUsers), models.ScopeMembersManage)
+	scopes.Allow(wu.GET("/:workspace_id/:id", h.FindWorkspaceUser), models.ScopeMembersManage)
+	scopes.Allow(wu.POST("/:workspace_id", h.CreateWorkspaceUser), models.ScopeMembersManage)
+	scopes.Allow(wu.PUT("/:workspace_id/:id", h.UpdateWorkspaceUser), models.ScopeMembersManage)
+	scopes.Allow(wu.DELETE("/:workspace_id/:id", h.DeleteWokspaceUser), models.ScopeMembersManage)
 }
 
 // @Security BearerAuth
@@ -60,7 +64,8 @@ func NewWorkspaceHTTP(g *echo.Group, DB *gorm.DB) {
 // @Param 		 page query int true "Page of list data"
 // @Param 		 limit query int true "Limitting data you want to get"
 // @Param 		 search query string false "Find your data with keywords"
-// @Param 		 orderBy query string false "Ordering data" example(created_at:desc)
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(created_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
 // @Success      200  {object} commonschema.ResponseHTTP "Request success"
 // @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
 // @Router       /api/workspaces [get]
@@ -70,8 +75,7 @@ func (h *WorkspaceHandler) FindWorkspaces(c echo.Context) error {
 
 	// get logged data
 	var userID *string
-	roleName := c.Get("role_name").(string)
-	if strings.ToLower(roleName) != "admin" {
+	if !helpers.HasPermission(c, models.PermissionWorkspacesManage) {
 		val, ok := c.Get("user_id").(string)
 		if !ok {
 			return c.JSON(response.Code, "missing user id")
@@ -80,11 +84,13 @@ func (h *WorkspaceHandler) FindWorkspaces(c echo.Context) error {
 	}
 
 	// perform to get data
-	params := utils.QParams(c)
-	list, err := h.Dependencies.UC.FindWorkspaces(userID, params)
+	params, err := utils.QParams(c, masterschema.WorkspaceQuerySpec)
 	if err != nil {
-		response.Message = err.Error()
-		return c.JSON(response.Code, response)
+		return err
+	}
+	list, err := h.Dependencies.UC.FindWorkspaces(c.Request().Context(), userID, params)
+	if err != nil {
+		return err
 	}
 
 	// send response
@@ -112,9 +118,9 @@ func (h *WorkspaceHandler) FindAllCampaigns(c echo.Context) error {
 	}
 
 	// get all campaigns for this user
-	data, err := h.Dependencies.UC.FindAllCampaignsByUser(userID)
+	data, err := h.Dependencies.UC.FindAllCampaignsByUser(c.Request().Context(), userID)
 	if err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// send response
@@ -142,11 +148,11 @@ func (h *WorkspaceHandler) FindWorkspace(c echo.Context) error {
 	// check allowed access
 	err := helpers.CheckAllowedWorkspace(c, ID, h.Dependencies.DB)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// get data by id
-	data, err := h.Dependencies.UC.FindWorkspaceByID(ID)
+	data, err := h.Dependencies.UC.FindWorkspaceByID(c.Request().Context(), ID)
 	if err != nil {
 		response.Message = err.Error()
 		return c.JSON(http.StatusNotFound, response)
@@ -172,8 +178,7 @@ func (h *WorkspaceHandler) FindWorkspace(c echo.Context) error {
 func (h *WorkspaceHandler) CreateWorkspace(c echo.Context) error {
 	// define data
 	var body masterschema.WorkspacePayload
-	userID, ok := c.Get("user_id").(string)
-	if !ok {
+	if _, ok := c.Get("user_id").(string); !ok {
 		return echo.NewHTTPError(http.StatusBadRequest, "Missing user id")
 	}
 
@@ -182,13 +187,13 @@ func (h *WorkspaceHandler) CreateWorkspace(c echo.Context) error {
 	}
 
 	if err := h.Validator.Struct(body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// call usecase for busines validation
-	err := h.Dependencies.UC.CreateWorkspace(userID, body)
+	err := h.Dependencies.UC.CreateWorkspace(c.Request().Context(), helpers.Actor(c), body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send success response
@@ -199,6 +204,51 @@ func (h *WorkspaceHandler) CreateWorkspace(c echo.Context) error {
 	return c.JSON(response.Code, response)
 }
 
+// @Security BearerAuth
+// @Summary      Require Two-Factor
+// @Description  Owner requires every member to sign in with two-factor code before accessing this workspace
+// @Tags         Master - Workspaces
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 workspace_id path string true "Workspace ID"
+// @Param        workspaceTwoFactorPayload  body      masterschema.WorkspaceTwoFactorPayload   true  "Two-factor requirement"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/workspaces/two_factor/{workspace_id} [put]
+func (h *WorkspaceHandler) UpdateWorkspaceTwoFactor(c echo.Context) error {
+	// define data
+	var body masterschema.WorkspaceTwoFactorPayload
+	workspaceID := c.Param("workspace_id")
+
+	// only owner decides it
+	err := helpers.CheckWorkspaceOwner(c, workspaceID, h.Dependencies.DB)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid body")
+	}
+
+	// owner signed in by password only would be locked out right away
+	if body.RequireTwoFactor && !helpers.TwoFactorSigned(c) {
+		return apperrors.Forbidden("enable two-factor authentication and sign in with it before requiring it for members")
+	}
+
+	// call usecase for business validation
+	err = h.Dependencies.UC.UpdateWorkspaceTwoFactor(c.Request().Context(), helpers.Actor(c), workspaceID, body)
+	if err != nil {
+		return err
+	}
+
+	// send success response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Data is successfully updated",
+	}
+	return c.JSON(response.Code, response)
+}
+
 // @Security BearerAuth
 // @Summary      Update Workspace
 // @Description  Update existing workspace data
@@ -219,13 +269,13 @@ func (h *WorkspaceHandler) UpdateWorkspace(c echo.Context) error {
 	}
 
 	if err := h.Validator.Struct(body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// call usecase for business validation
-	err := h.Dependencies.UC.UpdateWorkspace(ID, body)
+	err := h.Dependencies.UC.UpdateWorkspace(c.Request().Context(), helpers.Actor(c), ID, body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send success response
@@ -246,9 +296,9 @@ func (h *WorkspaceHandler) DeleteWokspace(c echo.Context) error {
 	ID := c.Param("id")
 
 	// call usecase for business validation
-	err := h.Dependencies.UC.DeleteWorkspace(ID)
+	err := h.Dependencies.UC.DeleteWorkspace(c.Request().Context(), helpers.Actor(c), ID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send success response
@@ -265,7 +315,8 @@ func (h *WorkspaceHandler) DeleteWokspace(c echo.Context) error {
 // @Param 		 page query int true "Page of list data"
 // @Param 		 limit query int true "Limitting data you want to get"
 // @Param 		 search query string false "Find your data with keywords"
-// @Param 		 orderBy query string false "Ordering data" example(created_at:desc)
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(created_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
 // @Success      200  {object} commonschema.ResponseHTTP "Request success"
 // @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
 // @Router       /api/workspaces/users/{workspace_id} [get]
@@ -282,11 +333,13 @@ func (h *WorkspaceHandler) FindWorkspaceUsers(c echo.Context) error {
 	}
 
 	// perform to get data
-	params := utils.QParams(c)
-	list, err := h.Dependencies.UC.FindWorkspaceUsers(workspaceID, params)
+	params, err := utils.QParams(c, masterschema.WorkspaceUserQuerySpec)
 	if err != nil {
-		response.Message = err.Error()
-		return c.JSON(response.Code, response)
+		return err
+	}
+	list, err := h.Dependencies.UC.FindWorkspaceUsers(c.Request().Context(), workspaceID, params)
+	if err != nil {
+		return err
 	}
 
 	// send response
@@ -321,7 +374,7 @@ func (h *WorkspaceHandler) FindWorkspaceUser(c echo.Context) error {
 	}
 
 	// get data by id
-	data, err := h.Dependencies.UC.FindWorkspaceUserByID(workspaceID, ID)
+	data, err := h.Dependencies.UC.FindWorkspaceUserByID(c.Request().Context(), workspaceID, ID)
 	if err != nil {
 		response.Message = err.Error()
 		return c.JSON(http.StatusNotFound, response)
@@ -353,7 +406,7 @@ func (h *WorkspaceHandler) CreateWorkspaceUser(c echo.Context) error {
 	// check allowed access
 	err := helpers.CheckAllowedWorkspace(c, workspaceID, h.Dependencies.DB)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	if err := c.Bind(&body); err != nil {
@@ -361,13 +414,13 @@ func (h *WorkspaceHandler) CreateWorkspaceUser(c echo.Context) error {
 	}
 
 	if err := h.Validator.Struct(body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// call usecase for busines validation
-	err = h.Dependencies.UC.CreateWorkspaceUser(workspaceID, body)
+	err = h.Dependencies.UC.CreateWorkspaceUser(c.Request().Context(), helpers.Actor(c), workspaceID, body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send success response
@@ -398,7 +451,7 @@ func (h *WorkspaceHandler) UpdateWorkspaceUser(c echo.Context) error {
 	// check allowed access
 	err := helpers.CheckAllowedWorkspace(c, workspaceID, h.Dependencies.DB)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	if err := c.Bind(&body); err != nil {
@@ -406,13 +459,13 @@ func (h *WorkspaceHandler) UpdateWorkspaceUser(c echo.Context) error {
 	}
 
 	if err := h.Validator.Struct(body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// call usecase for business validation
-	err = h.Dependencies.UC.UpdateWorkspaceUser(workspaceID, ID, body)
+	err = h.Dependencies.UC.UpdateWorkspaceUser(c.Request().Context(), helpers.Actor(c), workspaceID, ID, body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send success response
@@ -437,15 +490,57 @@ func (h *WorkspaceHandler) DeleteWokspaceUser(c echo.Context) error {
 	// check allowed access
 	err := helpers.CheckAllowedWorkspace(c, workspaceID, h.Dependencies.DB)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// call usecase for business validation
-	err = h.Dependencies.UC.DeleteWorkspaceUser(workspaceID, ID)
+	err = h.Dependencies.UC.DeleteWorkspaceUser(c.Request().Context(), helpers.Actor(c), workspaceID, ID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send success response
 	return c.JSON(http.StatusNoContent, nil)
 }
+
+// @Security BearerAuth
+// @Summary      Workspace Analytics
+// @Description  Get submission, review and conversion analytics across all campaigns in workspace, result may lag behind for up to 5 minutes
+// @Tags         Master - Workspaces
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 workspace_id path string true "Workspace ID"
+// @Param 		 start_date query string false "Start date (YYYY-MM-DD), default 60 days ago"
+// @Param 		 end_date query string false "End date (YYYY-MM-DD), default today"
+// @Param 		 group_by query string false "Group submissions by day, week or month" default(day)
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/workspaces/analytics/{workspace_id} [get]
+func (h *WorkspaceHandler) WorkspaceAnalytics(c echo.Context) error {
+	// get parameters
+	workspaceID := c.Param("workspace_id")
+	params, err := utils.AParams(c)
+	if err != nil {
+		return err
+	}
+
+	// check allowed access
+	err = helpers.CheckAllowedWorkspace(c, workspaceID, h.Dependencies.DB)
+	if err != nil {
+		return err
+	}
+
+	// send to usecase to get data
+	data, err := h.Dependencies.UC.WorkspaceAnalytics(c.Request().Context(), workspaceID, params)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    data,
+	}
+	return c.JSON(response.Code, response)
+}
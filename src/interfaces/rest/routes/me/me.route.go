@@ -2,8 +2,10 @@ package meroute
 
 import (
 	medi "kiraform/src/applications/dependencies/me"
+	"kiraform/src/applications/twofactor"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
 	meschema "kiraform/src/interfaces/rest/schemas/me"
+	"kiraform/src/utils"
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
@@ -25,14 +27,21 @@ func NewMeHandler(DB *gorm.DB, validator *validator.Validate, dependencies medi.
 	}
 }
 
-func NewMeHTTP(g *echo.Group, DB *gorm.DB) {
-	h := NewMeHandler(DB, validator.New(), *medi.NewMeDependencies(DB))
+func NewMeHTTP(g *echo.Group, DB *gorm.DB, twoFactor *twofactor.Manager) {
+	h := NewMeHandler(DB, utils.NewValidator(), *medi.NewMeDependencies(DB, twoFactor))
 
 	// regist route
 	m := g.Group("/me")
 	m.GET("", h.Me)
 	m.PUT("/user_profile", h.UpdateUserProfile)
 	m.PUT("/change_password", h.ChangePassword)
+
+	// two-factor authentication
+	tf := m.Group("/2fa")
+	tf.GET("", h.GetTwoFactor)
+	tf.POST("/enroll", h.EnrollTwoFactor)
+	tf.POST("/confirm", h.ConfirmTwoFactor)
+	tf.POST("/disable", h.DisableTwoFactor)
 }
 
 // @Security BearerAuth
@@ -50,15 +59,13 @@ func (h *MeHandler) Me(c echo.Context) error {
 	// get user id from logged token
 	userID, ok := c.Get("user_id").(string)
 	if !ok {
-		response.Message = "your token is invalid"
-		return echo.NewHTTPError(response.Code, response)
+		return echo.NewHTTPError(http.StatusUnauthorized, "your token is invalid")
 	}
 
 	// get data user account
-	data, err := h.Dependencies.UC.GetProfile(userID)
+	data, err := h.Dependencies.UC.GetProfile(c.Request().Context(), userID)
 	if err != nil {
-		response.Message = err.Error()
-		return echo.NewHTTPError(response.Code, response)
+		return err
 	}
 
 	// send success response
@@ -80,29 +87,24 @@ func (h *MeHandler) Me(c echo.Context) error {
 // @Failure      400  {object} commonschema.ResponseHTTP "Failure to update"
 // @Router       /api/me/user_profile [put]
 func (h *MeHandler) UpdateUserProfile(c echo.Context) error {
-	response := commonschema.ResponseHTTP{Code: http.StatusBadRequest}
 	var body meschema.UserProfilePayload
 	userID, ok := c.Get("user_id").(string)
 	if !ok {
-		response.Message = "your token is invalid"
-		return c.JSON(response.Code, response)
+		return echo.NewHTTPError(http.StatusUnauthorized, "your token is invalid")
 	}
 
 	if err := c.Bind(&body); err != nil {
-		response.Message = err.Error()
-		return c.JSON(response.Code, response)
+		return err
 	}
 
 	if err := h.Validator.Struct(body); err != nil {
-		response.Message = err.Error()
-		return c.JSON(response.Code, response)
+		return err
 	}
 
 	// send into uscease for updating process
-	err := h.Dependencies.UC.UpdateProfile(userID, body)
+	err := h.Dependencies.UC.UpdateProfile(c.Request().Context(), userID, body)
 	if err != nil {
-		response.Message = err.Error()
-		return c.JSON(response.Code, response)
+		return err
 	}
 	return c.JSON(http.StatusNoContent, nil)
 }
@@ -118,29 +120,153 @@ func (h *MeHandler) UpdateUserProfile(c echo.Context) error {
 // @Failure      400  {object} commonschema.ResponseHTTP "Failure to change password"
 // @Router       /api/me/change_password [put]
 func (h *MeHandler) ChangePassword(c echo.Context) error {
-	response := commonschema.ResponseHTTP{Code: http.StatusBadRequest}
 	var body meschema.ChangePasswordPayload
 	userID, ok := c.Get("user_id").(string)
 	if !ok {
-		response.Message = "your token is invalid"
-		return c.JSON(response.Code, response)
+		return echo.NewHTTPError(http.StatusUnauthorized, "your token is invalid")
 	}
 
 	if err := c.Bind(&body); err != nil {
-		response.Message = err.Error()
-		return c.JSON(response.Code, response)
+		return err
 	}
 
 	if err := h.Validator.Struct(body); err != nil {
-		response.Message = err.Error()
-		return c.JSON(response.Code, response)
+		return err
 	}
 
 	// send into uscease for updating process
-	err := h.Dependencies.UC.ChangePassword(userID, body)
+	err := h.Dependencies.UC.ChangePassword(c.Request().Context(), userID, body)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusNoContent, nil)
+}
+
+// @Security BearerAuth
+// @Summary      Two-Factor Status
+// @Description  Get status of your two-factor authentication
+// @Tags         Me
+// @Accept       json
+// @Produce      json
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/me/2fa [get]
+func (h *MeHandler) GetTwoFactor(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "your token is invalid")
+	}
+
+	data, err := h.Dependencies.UC.GetTwoFactor(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    data,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Enroll Two-Factor
+// @Description  Start two-factor enrolment, add the secret or otpauth uri to your authenticator app then confirm it with its first code
+// @Tags         Me
+// @Accept       json
+// @Produce      json
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/me/2fa/enroll [post]
+func (h *MeHandler) EnrollTwoFactor(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "your token is invalid")
+	}
+
+	data, err := h.Dependencies.UC.EnrollTwoFactor(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    data,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Confirm Two-Factor
+// @Description  Enable two-factor authentication with first code of your authenticator app, recovery codes are shown only once
+// @Tags         Me
+// @Accept       json
+// @Produce      json
+// @Param        twoFactorCodePayload  body      meschema.TwoFactorCodePayload   true  "Code of authenticator app"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/me/2fa/confirm [post]
+func (h *MeHandler) ConfirmTwoFactor(c echo.Context) error {
+	var body meschema.TwoFactorCodePayload
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "your token is invalid")
+	}
+
+	if err := c.Bind(&body); err != nil {
+		return err
+	}
+
+	if err := h.Validator.Struct(body); err != nil {
+		return err
+	}
+
+	data, err := h.Dependencies.UC.ConfirmTwoFactor(c.Request().Context(), userID, body)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Two-factor authentication is enabled, store recovery codes now since they are not shown again",
+		Data:    data,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Disable Two-Factor
+// @Description  Disable two-factor authentication with your password and a code of authenticator app or a recovery code
+// @Tags         Me
+// @Accept       json
+// @Produce      json
+// @Param        twoFactorDisablePayload  body      meschema.TwoFactorDisablePayload   true  "Password and code"
+// @Success 	 204  "Two-factor disabled"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/me/2fa/disable [post]
+func (h *MeHandler) DisableTwoFactor(c echo.Context) error {
+	var body meschema.TwoFactorDisablePayload
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "your token is invalid")
+	}
+
+	if err := c.Bind(&body); err != nil {
+		return err
+	}
+
+	if err := h.Validator.Struct(body); err != nil {
+		return err
+	}
+
+	err := h.Dependencies.UC.DisableTwoFactor(c.Request().Context(), userID, body)
 	if err != nil {
-		response.Message = err.Error()
-		return c.JSON(response.Code, response)
+		return err
 	}
 	return c.JSON(http.StatusNoContent, nil)
 }
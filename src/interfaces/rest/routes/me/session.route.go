@@ -0,0 +1,168 @@
+package meroute
+
+import (
+	medi "kiraform/src/applications/dependencies/me"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	meschema "kiraform/src/interfaces/rest/schemas/me"
+	"kiraform/src/utils"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type SessionHandler struct {
+	DB           *gorm.DB
+	Validator    *validator.Validate
+	Dependencies medi.SessionDependencies
+}
+
+func NewSessionHandler(DB *gorm.DB, validator *validator.Validate, dependencies medi.SessionDependencies) *SessionHandler {
+	return &SessionHandler{
+		DB:           DB,
+		Validator:    validator,
+		Dependencies: dependencies,
+	}
+}
+
+func NewSessionHTTP(g *echo.Group, DB *gorm.DB) {
+	h := NewSessionHandler(DB, utils.NewValidator(), *medi.NewSessionDependencies(DB))
+
+	// regist route
+	m := g.Group("/me")
+	m.GET("/sessions", h.FindSessions)
+	m.DELETE("/sessions", h.RevokeOtherSessions)
+	m.DELETE("/sessions/:id", h.RevokeSession)
+	m.GET("/login-history", h.FindLoginHistories)
+}
+
+// @Security BearerAuth
+// @Summary      List Sessions
+// @Description  Get devices where you are logged in, current marks session of this request
+// @Tags         Me
+// @Accept  	 json
+// @Produce  	 json
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/me/sessions [get]
+func (h *SessionHandler) FindSessions(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "your token is invalid")
+	}
+	sessionID, _ := c.Get("sid").(string)
+
+	// perform to get data
+	data, err := h.Dependencies.UC.FindSessions(c.Request().Context(), userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    data,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Revoke Session
+// @Description  Log out a device, its access token stops working immediately
+// @Tags         Me
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 id path string true "Session ID"
+// @Success 	 204  "Session revoked"
+// @Failure      404  {object} commonschema.ResponseHTTP "Session not found"
+// @Router       /api/me/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "your token is invalid")
+	}
+
+	if _, err := utils.ParseUUID(c.Param("id"), "id"); err != nil {
+		return err
+	}
+
+	err := h.Dependencies.UC.RevokeSession(c.Request().Context(), userID, c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusNoContent, nil)
+}
+
+// @Security BearerAuth
+// @Summary      Revoke Other Sessions
+// @Description  Log out every other device, session of this request is kept
+// @Tags         Me
+// @Accept  	 json
+// @Produce  	 json
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/me/sessions [delete]
+func (h *SessionHandler) RevokeOtherSessions(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "your token is invalid")
+	}
+	sessionID, _ := c.Get("sid").(string)
+
+	revoked, err := h.Dependencies.UC.RevokeOtherSessions(c.Request().Context(), userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Other sessions are revoked",
+		Data:    map[string]any{"revoked": revoked},
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Login History
+// @Description  Get your successful and failed logins
+// @Tags         Me
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 page query int true "Page of list data"
+// @Param 		 limit query int true "Limitting data you want to get"
+// @Param 		 search query string false "Find by ip or user agent"
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(created_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data by method, success, ip or created_at"
+// @Param 		 pagination query string false "Pagination mode, offset or cursor" default(offset)
+// @Param 		 cursor query string false "Cursor token from next_cursor or prev_cursor"
+// @Param 		 count query bool false "Set false to skip total_page count, default false on cursor pagination"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/me/login-history [get]
+func (h *SessionHandler) FindLoginHistories(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "your token is invalid")
+	}
+
+	// perform to get data
+	params, err := utils.QParams(c, meschema.LoginHistoryQuerySpec)
+	if err != nil {
+		return err
+	}
+	list, err := h.Dependencies.UC.FindLoginHistories(c.Request().Context(), userID, params)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    list,
+	}
+	return c.JSON(response.Code, response)
+}
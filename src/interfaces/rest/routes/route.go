@@ -1,36 +1,79 @@
 package routes
 
 import (
+	masterdi "kiraform/src/applications/dependencies/masters"
+	"kiraform/src/applications/keyring"
+	masterrepo "kiraform/src/applications/repos/masters"
+	"kiraform/src/applications/sessions"
+	"kiraform/src/applications/sso"
+	"kiraform/src/applications/twofactor"
+	"kiraform/src/infras/configs"
+	"kiraform/src/infras/health"
+	"kiraform/src/infras/mailer"
+	"kiraform/src/infras/ratelimit"
 	"kiraform/src/interfaces/rest/middlewares"
 	authroute "kiraform/src/interfaces/rest/routes/auths"
+	healthroute "kiraform/src/interfaces/rest/routes/healths"
 	masterroute "kiraform/src/interfaces/rest/routes/masters"
 	meroute "kiraform/src/interfaces/rest/routes/me"
 	storeroute "kiraform/src/interfaces/rest/routes/stores"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
 
-func Routes(e *echo.Echo, DB *gorm.DB) {
+func Routes(e *echo.Echo, DB *gorm.DB, config configs.Config, limiter ratelimit.Store, checker *health.Checker, keys *keyring.Keyring, twoFactor *twofactor.Manager, registry *sso.Registry, mail mailer.Mailer) {
+	// liveness and readiness probes
+	healthroute.NewHealthHTTP(e, checker)
+
+	// prometheus metrics
+	healthroute.NewMetricsHTTP(e, config.HTTP.MetricsToken)
+
+	// public keys of access tokens
+	authroute.NewJWKSHTTP(e, keys)
+
+	// sessions of access tokens and login history
+	tracker := sessions.NewTracker(masterrepo.NewSessionRepository(DB), masterrepo.NewLoginHistoryRepository(DB), masterrepo.NewUserRepository(DB), mail, config.Auth.Issuer)
+
 	// unauthorized endpoint
+	// each public group defines its own rate limit
 	publicApi := e.Group("/api")
-	authroute.NewAuthHTTP(publicApi, DB)
-	masterroute.NewFormEntryHTTP(publicApi, DB)
-	storeroute.NewStorePublicHTTP(publicApi, DB)
+	authroute.NewAuthHTTP(publicApi, DB, limiter, config.Auth, keys, twoFactor, mail, tracker)
+	authroute.NewOIDCHTTP(publicApi, DB, limiter, config.Auth, keys, twoFactor, mail, tracker, registry)
+	masterroute.NewFormEntryHTTP(publicApi, DB, limiter, config, keys, tracker)
+	storeroute.NewStorePublicHTTP(publicApi, DB, limiter, config.Storage)
 
 	// re-define /api for authorized endpoint
-	// then regist middleware
+	// then regist middleware, limit is counted per account
+	// workspace api keys are accepted only by routes opened for their scopes
 	privateApi := e.Group("/api")
-	privateApi.Use(middlewares.VerifyToken)
+	apiKeys := &middlewares.APIKeyAuth{
+		Usecase: masterdi.NewAPIKeyDependencies(DB).UC,
+		Scopes:  middlewares.APIKeyScopes{},
+	}
+	privateApi.Use(middlewares.VerifyToken(keys, apiKeys, tracker))
+	privateApi.Use(middlewares.RateLimit(limiter, middlewares.RateLimitConfig{
+		Name:    "private",
+		Limit:   300,
+		Window:  time.Minute,
+		KeyFunc: middlewares.ByUser,
+	}))
 
 	// profile routes
-	meroute.NewMeHTTP(privateApi, DB)
+	meroute.NewMeHTTP(privateApi, DB, twoFactor)
+	meroute.NewSessionHTTP(privateApi, DB)
 
 	// master routes
 	masterroute.NewFormHTTP(privateApi, DB)
-	masterroute.NewWorkspaceHTTP(privateApi, DB)
-	masterroute.NewCampaignHTTP(privateApi, DB)
+	masterroute.NewWorkspaceHTTP(privateApi, DB, apiKeys.Scopes)
+	masterroute.NewAPIKeyHTTP(privateApi, DB)
+	masterroute.NewCampaignHTTP(privateApi, DB, apiKeys.Scopes)
+	masterroute.NewAuditHTTP(privateApi, DB)
+	masterroute.NewTrashHTTP(privateApi, DB)
+	masterroute.NewUserHTTP(privateApi, DB, config.Auth, keys, mail, tracker)
+	masterroute.NewRoleHTTP(privateApi, DB, tracker)
 
 	// store routes
-	storeroute.NewStoreHTTP(privateApi, DB)
+	storeroute.NewStoreHTTP(privateApi, DB, config.Storage)
 }
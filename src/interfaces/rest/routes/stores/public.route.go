@@ -2,9 +2,14 @@ package storeroute
 
 import (
 	storedi "kiraform/src/applications/dependencies/stores"
+	"kiraform/src/infras/configs"
+	"kiraform/src/infras/ratelimit"
+	"kiraform/src/interfaces/rest/middlewares"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	storeschema "kiraform/src/interfaces/rest/schemas/stores"
 	"kiraform/src/utils"
 	"net/http"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
@@ -25,11 +30,15 @@ func NewStorePublicHandler(DB *gorm.DB, validator *validator.Validate, dependenc
 	}
 }
 
-func NewStorePublicHTTP(g *echo.Group, DB *gorm.DB) {
-	validator := validator.New()
-	h := NewStorePublicHandler(DB, validator, *storedi.NewStoreDependencies(DB))
+func NewStorePublicHTTP(g *echo.Group, DB *gorm.DB, limiter ratelimit.Store, storage configs.StorageConfig) {
+	validator := utils.NewValidator()
+	h := NewStorePublicHandler(DB, validator, *storedi.NewStoreDependencies(DB, storage))
 
-	s := g.Group("/storepub")
+	s := g.Group("/storepub", middlewares.RateLimit(limiter, middlewares.RateLimitConfig{
+		Name:   "storepub",
+		Limit:  120,
+		Window: time.Minute,
+	}))
 	s.GET("/:key", h.FindStore)
 	s.GET("/categories/:key", h.FindStoreCategories)
 	s.GET("/products/:key", h.FindStoreProducts)
@@ -51,9 +60,9 @@ func (h *StorePublicHandler) FindStore(c echo.Context) error {
 	key := c.Param("key")
 
 	// get data from usecase
-	data, err := h.Dependencies.UC.FindStoreByKey(c, key)
+	data, err := h.Dependencies.UC.FindStoreByKey(c.Request().Context(), c, key)
 	if err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// send response
@@ -79,9 +88,9 @@ func (h *StorePublicHandler) FindStoreCategories(c echo.Context) error {
 	key := c.Param("key")
 
 	// get data from usecase
-	data, err := h.Dependencies.UC.FindStoreCategoriesByKey(key)
+	data, err := h.Dependencies.UC.FindStoreCategoriesByKey(c.Request().Context(), key)
 	if err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// send response
@@ -101,21 +110,29 @@ func (h *StorePublicHandler) FindStoreCategories(c echo.Context) error {
 // @Param 		 page query int true "Page of list data"
 // @Param 		 limit query int true "Limitting data you want to get"
 // @Param 		 search query string false "Find your data with keywords"
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(created_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
 // @Param 		 category_id query string false "category of product you want to get"
+// @Param 		 pagination query string false "Pagination mode, offset or cursor" default(offset)
+// @Param 		 cursor query string false "Cursor token from next_cursor or prev_cursor"
+// @Param 		 count query bool false "Set false to skip total_page count, default false on cursor pagination"
 // @Success      200  {object} commonschema.ResponseHTTP "Request success"
 // @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
 // @Router       /api/storepub/products/{key} [get]
 func (h *StorePublicHandler) FindStoreProducts(c echo.Context) error {
 	// get parameters
-	params := utils.QParams(c)
+	params, err := utils.QParams(c, storeschema.ProductQuerySpec)
+	if err != nil {
+		return err
+	}
 	key := c.Param("key")
 	category_id := c.QueryParam("category_id")
 	response := commonschema.ResponseHTTP{Code: http.StatusBadRequest}
 
 	// get list of product by store key
-	list, err := h.Dependencies.UC.FindStoreProductsByStoreKey(c, key, params, &category_id)
+	list, err := h.Dependencies.UC.FindStoreProductsByStoreKey(c.Request().Context(), c, key, params, &category_id)
 	if err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// send response
@@ -3,6 +3,8 @@ package storeroute
 import (
 	"errors"
 	storedi "kiraform/src/applications/dependencies/stores"
+	"kiraform/src/applications/helpers"
+	"kiraform/src/infras/configs"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
 	storeschema "kiraform/src/interfaces/rest/schemas/stores"
 	"kiraform/src/utils"
@@ -27,9 +29,9 @@ func NewStoreHandler(DB *gorm.DB, validator *validator.Validate, dependencies st
 	}
 }
 
-func NewStoreHTTP(g *echo.Group, DB *gorm.DB) {
-	validator := validator.New()
-	h := NewStoreHandler(DB, validator, *storedi.NewStoreDependencies(DB))
+func NewStoreHTTP(g *echo.Group, DB *gorm.DB, storage configs.StorageConfig) {
+	validator := utils.NewValidator()
+	h := NewStoreHandler(DB, validator, *storedi.NewStoreDependencies(DB, storage))
 
 	// define store routes
 	s := g.Group("/store")
@@ -52,6 +54,11 @@ func NewStoreHTTP(g *echo.Group, DB *gorm.DB) {
 	sp.POST("", h.CreateStoreProduct)
 	sp.PUT("/:id", h.UpdateStoreProduct)
 	sp.DELETE("/:id", h.DeleteStoreProduct)
+
+	// define store trash routes
+	st := s.Group("/trash")
+	st.GET("", h.FindStoreTrash)
+	st.PUT("/:entity_type/:id", h.RestoreStoreItem)
 }
 
 // @Security BearerAuth
@@ -72,9 +79,9 @@ func (h *StoreHandler) FindStore(c echo.Context) error {
 	}
 
 	// get data from usecase
-	data, err := h.Dependencies.UC.FindStore(c, userID)
+	data, err := h.Dependencies.UC.FindStore(c.Request().Context(), c, userID)
 	if err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// send response
@@ -105,17 +112,17 @@ func (h *StoreHandler) UpdateStore(c echo.Context) error {
 
 	// validate body
 	if err := c.Bind(&body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	if err := h.Validator.Struct(body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	// send to usecase for store-update process
-	err := h.Dependencies.UC.UpdateStore(userID, body)
+	err := h.Dependencies.UC.UpdateStore(c.Request().Context(), helpers.Actor(c), body)
 	if err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// send response
@@ -133,12 +140,17 @@ func (h *StoreHandler) UpdateStore(c echo.Context) error {
 // @Param 		 page query int true "Page of list data"
 // @Param 		 limit query int true "Limitting data you want to get"
 // @Param 		 search query string false "Find your data with keywords"
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(created_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
 // @Success      200  {object} commonschema.ResponseHTTP "Request success"
 // @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
 // @Router       /api/store/product_categories [get]
 func (h *StoreHandler) FindStoreProductCategories(c echo.Context) error {
 	// get parameters
-	params := utils.QParams(c)
+	params, err := utils.QParams(c, storeschema.ProductCategoryQuerySpec)
+	if err != nil {
+		return err
+	}
 	userID, _ := c.Get("user_id").(string)
 	if userID == "" {
 		return echo.NewHTTPError(http.StatusUnauthorized, errors.New("your token is not valid"))
@@ -146,9 +158,9 @@ func (h *StoreHandler) FindStoreProductCategories(c echo.Context) error {
 	response := commonschema.ResponseHTTP{Code: http.StatusBadRequest}
 
 	// get list of product categories
-	list, err := h.Dependencies.UC.FindStoreProductCategories(userID, params)
+	list, err := h.Dependencies.UC.FindStoreProductCategories(c.Request().Context(), userID, params)
 	if err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// send response
@@ -178,12 +190,9 @@ func (h *StoreHandler) FindStoreProductCategory(c echo.Context) error {
 	response := commonschema.ResponseHTTP{Code: http.StatusBadRequest}
 
 	// get detail data
-	data, err := h.Dependencies.UC.FindStoreProductCategory(userID, ID)
+	data, err := h.Dependencies.UC.FindStoreProductCategory(c.Request().Context(), userID, ID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			response.Code = http.StatusNotFound
-		}
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// send response
@@ -214,17 +223,17 @@ func (h *StoreHandler) CreateStoreProductCategory(c echo.Context) error {
 
 	// validate body
 	if err := c.Bind(&body); err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	if err := h.Validator.Struct(body); err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// perform to create data
-	err := h.Dependencies.UC.CreateStoreProductCategory(userID, body)
+	err := h.Dependencies.UC.CreateStoreProductCategory(c.Request().Context(), helpers.Actor(c), body)
 	if err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// send response
@@ -257,17 +266,17 @@ func (h *StoreHandler) UpdateStoreProductCategory(c echo.Context) error {
 
 	// validate body
 	if err := c.Bind(&body); err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	if err := h.Validator.Struct(body); err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// perform to create data
-	err := h.Dependencies.UC.UpdateStoreProductCategory(userID, ID, body)
+	err := h.Dependencies.UC.UpdateStoreProductCategory(c.Request().Context(), helpers.Actor(c), ID, body)
 	if err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// send response
@@ -296,9 +305,9 @@ func (h *StoreHandler) DeleteStoreProductCategory(c echo.Context) error {
 	response := commonschema.ResponseHTTP{Code: http.StatusBadRequest}
 
 	// perform to create data
-	err := h.Dependencies.UC.DeleteStoreProductCategory(userID, ID)
+	err := h.Dependencies.UC.DeleteStoreProductCategory(c.Request().Context(), helpers.Actor(c), ID)
 	if err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// send response
@@ -316,6 +325,11 @@ func (h *StoreHandler) DeleteStoreProductCategory(c echo.Context) error {
 // @Param 		 page query int true "Page of list data"
 // @Param 		 limit query int true "Limitting data you want to get"
 // @Param 		 search query string false "Find your data with keywords"
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(created_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
+// @Param 		 pagination query string false "Pagination mode, offset or cursor" default(offset)
+// @Param 		 cursor query string false "Cursor token from next_cursor or prev_cursor"
+// @Param 		 count query bool false "Set false to skip total_page count, default false on cursor pagination"
 // @Success      200  {object} commonschema.ResponseHTTP "Request success"
 // @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
 // @Router       /api/store/products [get]
@@ -326,12 +340,15 @@ func (h *StoreHandler) FindStoreProducts(c echo.Context) error {
 	if userID == "" {
 		return echo.NewHTTPError(http.StatusUnauthorized, errors.New("your token is not valid"))
 	}
-	params := utils.QParams(c)
+	params, err := utils.QParams(c, storeschema.ProductQuerySpec)
+	if err != nil {
+		return err
+	}
 
 	// get data from usecase
-	data, err := h.Dependencies.UC.FindStoreProducts(c, userID, params)
+	data, err := h.Dependencies.UC.FindStoreProducts(c.Request().Context(), c, userID, params)
 	if err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// send response
@@ -350,6 +367,8 @@ func (h *StoreHandler) FindStoreProducts(c echo.Context) error {
 // @Param 		 page query int true "Page of list data"
 // @Param 		 limit query int true "Limitting data you want to get"
 // @Param 		 search query string false "Find your data with keywords"
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(created_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
 // @Param 		 id path string true "ID of your data"
 // @Success      200  {object} commonschema.ResponseHTTP "Request success"
 // @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
@@ -362,12 +381,15 @@ func (h *StoreHandler) FindStoreProductFormEntries(c echo.Context) error {
 	if userID == "" {
 		return echo.NewHTTPError(http.StatusUnauthorized, errors.New("your token is not valid"))
 	}
-	params := utils.QParams(c)
+	params, err := utils.QParams(c, storeschema.ProductFormEntryQuerySpec)
+	if err != nil {
+		return err
+	}
 
 	// get data from usecase
-	data, err := h.Dependencies.UC.FindStoreProductFormEntries(userID, ID, params)
+	data, err := h.Dependencies.UC.FindStoreProductFormEntries(c.Request().Context(), userID, ID, params)
 	if err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// send response
@@ -397,9 +419,9 @@ func (h *StoreHandler) FindStoreProduct(c echo.Context) error {
 	}
 
 	// get data from usecase
-	data, err := h.Dependencies.UC.FindStoreProduct(c, userID, ID)
+	data, err := h.Dependencies.UC.FindStoreProduct(c.Request().Context(), c, userID, ID)
 	if err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// send response
@@ -430,17 +452,17 @@ func (h *StoreHandler) CreateStoreProduct(c echo.Context) error {
 
 	// validate body
 	if err := c.Bind(&body); err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	if err := h.Validator.Struct(body); err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// perform to create data
-	err := h.Dependencies.UC.CreateStoreProduct(userID, body)
+	err := h.Dependencies.UC.CreateStoreProduct(c.Request().Context(), helpers.Actor(c), body)
 	if err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// send response
@@ -473,17 +495,17 @@ func (h *StoreHandler) UpdateStoreProduct(c echo.Context) error {
 
 	// validate body
 	if err := c.Bind(&body); err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	if err := h.Validator.Struct(body); err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// perform to create data
-	err := h.Dependencies.UC.UpdateStoreProduct(userID, ID, body)
+	err := h.Dependencies.UC.UpdateStoreProduct(c.Request().Context(), helpers.Actor(c), ID, body)
 	if err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// send response
@@ -512,9 +534,9 @@ func (h *StoreHandler) DeleteStoreProduct(c echo.Context) error {
 	response := commonschema.ResponseHTTP{Code: http.StatusBadRequest}
 
 	// perform to create data
-	err := h.Dependencies.UC.DeleteStoreProduct(userID, ID)
+	err := h.Dependencies.UC.DeleteStoreProduct(c.Request().Context(), helpers.Actor(c), ID)
 	if err != nil {
-		return echo.NewHTTPError(response.Code, err.Error())
+		return err
 	}
 
 	// send response
@@ -522,3 +544,80 @@ func (h *StoreHandler) DeleteStoreProduct(c echo.Context) error {
 	response.Message = "Data deleted"
 	return c.JSON(response.Code, response)
 }
+
+// @Security BearerAuth
+// @Summary      Store Trash
+// @Description  Get deleted product categories and products of logged user store
+// @Tags         Store - Trash
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 page query int true "Page of list data"
+// @Param 		 limit query int true "Limitting data you want to get"
+// @Param 		 search query string false "Find your data with keywords"
+// @Param 		 orderBy query string false "Ordering data, multiple keys separated by comma" example(deleted_at:desc)
+// @Param 		 filter[field][operator] query string false "Filter data, operator is one of eq, ne, gt, gte, lt, lte, contains, in"
+// @Param 		 pagination query string false "Pagination mode, offset or cursor" default(offset)
+// @Param 		 cursor query string false "Cursor token from next_cursor or prev_cursor"
+// @Param 		 count query bool false "Set false to skip total_page count, default false on cursor pagination"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Router       /api/store/trash [get]
+func (h *StoreHandler) FindStoreTrash(c echo.Context) error {
+	// prepare usable data
+	userID, _ := c.Get("user_id").(string)
+	if userID == "" {
+		return echo.NewHTTPError(http.StatusUnauthorized, errors.New("your token is not valid"))
+	}
+	params, err := utils.QParams(c, storeschema.TrashQuerySpec)
+	if err != nil {
+		return err
+	}
+
+	// get data from usecase
+	data, err := h.Dependencies.UC.FindStoreTrash(c.Request().Context(), userID, params)
+	if err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Request success",
+		Data:    data,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// @Security BearerAuth
+// @Summary      Restore Store Item
+// @Description  Restore deleted product category or product, product is restored together with its images
+// @Tags         Store - Trash
+// @Accept  	 json
+// @Produce  	 json
+// @Param 		 entity_type path string true "Type of data" Enums(product_category, product)
+// @Param 		 id path string true "ID of your data"
+// @Success      200  {object} commonschema.ResponseHTTP "Request success"
+// @Failure      400  {object} commonschema.ResponseHTTP "Request failure"
+// @Failure      409  {object} commonschema.ResponseHTTP "Category of product is still deleted"
+// @Router       /api/store/trash/{entity_type}/{id} [put]
+func (h *StoreHandler) RestoreStoreItem(c echo.Context) error {
+	// get parameters
+	entityType := c.Param("entity_type")
+	ID := c.Param("id")
+	userID, _ := c.Get("user_id").(string)
+	if userID == "" {
+		return echo.NewHTTPError(http.StatusUnauthorized, errors.New("your token is not valid"))
+	}
+
+	// perform to restore data
+	if err := h.Dependencies.UC.RestoreStoreItem(c.Request().Context(), helpers.Actor(c), entityType, ID); err != nil {
+		return err
+	}
+
+	// send response
+	response := commonschema.ResponseHTTP{
+		Code:    http.StatusOK,
+		Message: "Data restored",
+	}
+	return c.JSON(response.Code, response)
+}
@@ -4,3 +4,14 @@ type LoginPayload struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
 }
+
+type LoginTwoFactorPayload struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required,max=32"`
+}
+
+// LoginResult carries access token, or challenge token when two-factor code is required
+type LoginResult struct {
+	AccessToken    string
+	ChallengeToken string
+}
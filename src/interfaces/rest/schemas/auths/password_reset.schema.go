@@ -0,0 +1,7 @@
+package authschema
+
+type ResetPasswordPayload struct {
+	Token           string `json:"token" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+	ConfirmPassword string `json:"confirm_password" validate:"required"`
+}
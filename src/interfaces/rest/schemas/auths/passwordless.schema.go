@@ -0,0 +1,14 @@
+package authschema
+
+type PasswordlessPayload struct {
+	Email string `json:"email" validate:"required,email,max=100"`
+}
+
+type PasswordlessCodePayload struct {
+	Email string `json:"email" validate:"required,email,max=100"`
+	Code  string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type MagicLinkPayload struct {
+	Token string `json:"token" validate:"required"`
+}
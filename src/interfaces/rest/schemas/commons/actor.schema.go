@@ -0,0 +1,9 @@
+package commonschema
+
+// Actor is who sends the request, recorded in audit logs
+type Actor struct {
+	UserID    string `json:"user_id"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	RequestID string `json:"request_id"`
+}
@@ -0,0 +1,16 @@
+package commonschema
+
+// CountByID is one row of grouped count, used to load totals of many rows in one query
+type CountByID struct {
+	ID    string `json:"id"`
+	Total int64  `json:"total"`
+}
+
+// CountMap indexes grouped counts by id, missing id means zero
+func CountMap(rows []CountByID) map[string]int64 {
+	counts := make(map[string]int64, len(rows))
+	for _, v := range rows {
+		counts[v.ID] = v.Total
+	}
+	return counts
+}
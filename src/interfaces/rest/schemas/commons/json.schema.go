@@ -0,0 +1,29 @@
+package commonschema
+
+import (
+	"errors"
+)
+
+// JSON keeps json column as is, so it is sent as object instead of escaped string
+type JSON []byte
+
+func (j *JSON) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append((*j)[0:0], v...)
+	case string:
+		*j = JSON(v)
+	default:
+		return errors.New("unsupported type for json column")
+	}
+	return nil
+}
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
@@ -1,8 +1,56 @@
 package commonschema
 
+type SortParam struct {
+	Field  string `json:"field"`
+	Column string `json:"-"`
+	Desc   bool   `json:"desc"`
+}
+
+type FilterParam struct {
+	Field    string `json:"field"`
+	Column   string `json:"-"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
+
+// CursorKey is position of the last seen row, encoded as opaque cursor token
+type CursorKey struct {
+	CreatedAt string `json:"t"`
+	ID        string `json:"id"`
+	Prev      bool   `json:"p,omitempty"`
+}
+
 type QueryParams struct {
-	Page    int    `json:"page"`
-	Limit   int    `json:"limit"`
-	Search  string `json:"search"`
-	OrderBy string `json:"order_by"`
+	Page       int           `json:"page"`
+	Limit      int           `json:"limit"`
+	Search     string        `json:"search"`
+	OrderBy    string        `json:"order_by"`
+	Sorts      []SortParam   `json:"sorts"`
+	Filters    []FilterParam `json:"filters"`
+	Pagination string        `json:"pagination"`
+	Cursor     string        `json:"cursor"`
+	SkipCount  bool          `json:"skip_count"`
+	CursorKey  *CursorKey    `json:"-"`
+}
+
+// filter types, filter value is checked against type of its column before querying
+const (
+	FilterText   = "text"
+	FilterBool   = "bool"
+	FilterNumber = "number"
+	FilterTime   = "time"
+	FilterUUID   = "uuid"
+)
+
+// FilterField is qualified column of a filter field and type of its value
+type FilterField struct {
+	Column string
+	Type   string
+}
+
+// QuerySpec declares fields of a list endpoint that can be sorted or filtered,
+// each field is mapped to its qualified column
+type QuerySpec struct {
+	Sorts   map[string]string
+	Filters map[string]FilterField
 }
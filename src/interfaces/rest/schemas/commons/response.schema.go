@@ -11,4 +11,16 @@ type ResponseList struct {
 	Parameters QueryParams `json:"parameters"`
 	TotalPage  int         `json:"total_page"`
 	Rows       any         `json:"rows"`
+	NextCursor *string     `json:"next_cursor"`
+	PrevCursor *string     `json:"prev_cursor"`
+}
+
+type ErrorField struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ErrorDetail struct {
+	Code   string       `json:"code"`
+	Fields []ErrorField `json:"fields,omitempty"`
 }
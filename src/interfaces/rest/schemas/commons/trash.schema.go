@@ -0,0 +1,14 @@
+package commonschema
+
+import "time"
+
+// TrashSchema is soft-deleted item listed in trash bin, parent_id is set for child item like campaign seo
+type TrashSchema struct {
+	ID            string    `json:"id"`
+	EntityType    string    `json:"entity_type"`
+	Title         string    `json:"title"`
+	ParentID      *string   `json:"parent_id"`
+	DeletedAt     time.Time `json:"deleted_at"`
+	DeletedBy     *string   `json:"deleted_by"`
+	DeletedByName *string   `json:"deleted_by_name"`
+}
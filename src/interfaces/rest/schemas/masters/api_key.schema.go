@@ -0,0 +1,31 @@
+package masterschema
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyPayload struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,oneof=entries:read campaigns:write members:manage"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"required,min=1,max=365" default:"90"`
+}
+
+type APIKeyResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  uuid.UUID  `json:"created_by"`
+	MFA        bool       `json:"created_with_mfa"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyCreatedResponse carries plain key, it is shown only once and can not be read again
+type APIKeyCreatedResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
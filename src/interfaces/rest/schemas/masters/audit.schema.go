@@ -0,0 +1,25 @@
+package masterschema
+
+import (
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"time"
+)
+
+type AuditLogSchema struct {
+	ID          string            `json:"id"`
+	WorkspaceID *string           `json:"workspace_id"`
+	StoreID     *string           `json:"store_id"`
+	ActorID     *string           `json:"actor_id"`
+	ActorName   *string           `json:"actor_name"`
+	ActorEmail  *string           `json:"actor_email"`
+	Action      string            `json:"action"`
+	EntityType  string            `json:"entity_type"`
+	EntityID    string            `json:"entity_id"`
+	Before      commonschema.JSON `json:"before" swaggertype:"object"`
+	After       commonschema.JSON `json:"after" swaggertype:"object"`
+	Changes     commonschema.JSON `json:"changes" swaggertype:"object"`
+	IP          string            `json:"ip"`
+	UserAgent   string            `json:"user_agent"`
+	RequestID   string            `json:"request_id"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
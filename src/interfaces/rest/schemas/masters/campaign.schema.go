@@ -47,6 +47,7 @@ type DetailCampaignSchema struct {
 	IsPublish   bool                       `json:"is_publish"`
 	CreatedAt   *time.Time                 `json:"created_at"`
 	Forms       []DetailCampaignFormSchema `json:"forms"`
+	Guard       *FormEntryGuardSchema      `json:"guard,omitempty"`
 }
 
 type CampaignDashboard struct {
@@ -66,10 +67,12 @@ type FormEntryList struct {
 	WorkspaceID   string `json:"workspace_id"`
 	Status        string `json:"status"`
 	Remark        string `json:"remark"`
+	SpamReason    string `json:"spam_reason"`
 	CampaignTitle string `json:"campaign_title"`
 	CampaignKey   string `json:"campaign_key"`
 	CampaignSlug  string `json:"campaign_slug"`
 	UserName      string `json:"user_name"`
 	UserEmail     string `json:"user_email"`
 	CreatedAt     string `json:"created_at"`
+	Snippet       string `json:"snippet"`
 }
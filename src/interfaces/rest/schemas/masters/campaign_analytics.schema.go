@@ -0,0 +1,72 @@
+package masterschema
+
+import "github.com/google/uuid"
+
+type AnalyticsParams struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	GroupBy   string `json:"group_by"`
+	Timezone  string `json:"timezone"`
+}
+
+type CampaignEntryAnalytics struct {
+	Parameters AnalyticsParams          `json:"parameters"`
+	Rows       []CampaignFormEntryChart `json:"rows"`
+}
+
+type AnalyticsOption struct {
+	CampaignFormAttributeID uuid.UUID `json:"campaign_form_attribute_id"`
+	Label                   string    `json:"label"`
+	Value                   string    `json:"value"`
+	Total                   int64     `json:"total"`
+	Percentage              float64   `json:"percentage"`
+}
+
+type AnalyticsNumeric struct {
+	Total  int64   `json:"total"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Avg    float64 `json:"avg"`
+	Median float64 `json:"median"`
+}
+
+type CampaignFieldAnalytics struct {
+	CampaignFormID uuid.UUID         `json:"campaign_form_id"`
+	Title          string            `json:"title"`
+	FormCode       string            `json:"form_code"`
+	FormName       string            `json:"form_name"`
+	IsRequired     bool              `json:"is_required"`
+	TotalAnswered  int64             `json:"total_answered"`
+	CompletionRate float64           `json:"completion_rate"`
+	Options        []AnalyticsOption `json:"options"`
+	Numeric        *AnalyticsNumeric `json:"numeric"`
+}
+
+type CampaignFieldAnalyticsResponse struct {
+	Parameters   AnalyticsParams          `json:"parameters"`
+	TotalEntries int64                    `json:"total_entries"`
+	Fields       []CampaignFieldAnalytics `json:"fields"`
+}
+
+// raw rows scanned from aggregate queries
+type FieldAnswerCount struct {
+	CampaignFormID uuid.UUID `json:"campaign_form_id"`
+	Total          int64     `json:"total"`
+}
+
+type AttributeAnswerCount struct {
+	CampaignFormID          uuid.UUID `json:"campaign_form_id"`
+	CampaignFormAttributeID uuid.UUID `json:"campaign_form_attribute_id"`
+	Label                   string    `json:"label"`
+	Value                   string    `json:"value"`
+	Total                   int64     `json:"total"`
+}
+
+type FieldNumericStat struct {
+	CampaignFormID uuid.UUID `json:"campaign_form_id"`
+	Total          int64     `json:"total"`
+	Min            float64   `json:"min"`
+	Max            float64   `json:"max"`
+	Avg            float64   `json:"avg"`
+	Median         float64   `json:"median"`
+}
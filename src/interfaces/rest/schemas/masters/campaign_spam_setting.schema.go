@@ -0,0 +1,34 @@
+package masterschema
+
+import "time"
+
+type CampaignSpamSettingPayload struct {
+	Honeypot         bool `json:"honeypot"`
+	MinSubmitSeconds int  `json:"min_submit_seconds" validate:"min=0,max=3600"`
+	Captcha          bool `json:"captcha"`
+	DuplicateCheck   bool `json:"duplicate_check"`
+}
+
+type CampaignSpamSettingSchema struct {
+	CampaignID       string     `json:"campaign_id"`
+	Honeypot         bool       `json:"honeypot"`
+	MinSubmitSeconds int        `json:"min_submit_seconds"`
+	Captcha          bool       `json:"captcha"`
+	DuplicateCheck   bool       `json:"duplicate_check"`
+	UpdatedAt        *time.Time `json:"updated_at"`
+}
+
+// FormEntryGuardSchema tells public form which anti-spam inputs must be sent back
+type FormEntryGuardSchema struct {
+	FormToken string `json:"form_token"`
+	Honeypot  bool   `json:"honeypot"`
+	Captcha   bool   `json:"captcha"`
+}
+
+// FormEntryGuardPayload is taken from request headers of public submission
+type FormEntryGuardPayload struct {
+	Honeypot     string
+	FormToken    string
+	CaptchaToken string
+	RemoteIP     string
+}
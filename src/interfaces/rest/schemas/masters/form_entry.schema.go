@@ -10,6 +10,25 @@ type FormEntryPayload struct {
 	Value                   string  `json:"value"`
 }
 
+type FormEntryReviewPayload struct {
+	Status string `json:"status" validate:"required,oneof=S1 S2 S3 S4"`
+	Remark string `json:"remark"`
+}
+
+type FormEntryFieldFilter struct {
+	CampaignFormID string `json:"campaign_form_id"`
+	Operator       string `json:"operator"`
+	Value          string `json:"value"`
+}
+
+type FormEntryFilter struct {
+	Status    string                 `json:"status"`
+	StartDate string                 `json:"start_date"`
+	EndDate   string                 `json:"end_date"`
+	Timezone  string                 `json:"timezone"`
+	Fields    []FormEntryFieldFilter `json:"fields"`
+}
+
 type FormDetailEntrySchema struct {
 	ID                      string    `json:"id"`
 	CampaignFormID          string    `json:"campaign_form_id"`
@@ -34,6 +53,7 @@ type FormEntrySchema struct {
 	Remark              string    `json:"remark"`
 	CreatedAt           time.Time `json:"created_at"`
 	ProductID           *string   `json:"product_id"`
+	Snippet             string    `json:"snippet"`
 }
 
 type ProductResponse struct {
@@ -0,0 +1,156 @@
+package masterschema
+
+import commonschema "kiraform/src/interfaces/rest/schemas/commons"
+
+var WorkspaceQuerySpec = commonschema.QuerySpec{
+	Sorts: map[string]string{
+		"title":      "workspaces.title",
+		"created_at": "workspaces.created_at",
+		"updated_at": "workspaces.updated_at",
+	},
+	Filters: map[string]commonschema.FilterField{
+		"title":      {Column: "workspaces.title", Type: commonschema.FilterText},
+		"timezone":   {Column: "workspaces.timezone", Type: commonschema.FilterText},
+		"created_at": {Column: "workspaces.created_at", Type: commonschema.FilterTime},
+	},
+}
+
+var WorkspaceUserQuerySpec = commonschema.QuerySpec{
+	Sorts: map[string]string{
+		"user_name":  "users.fullname",
+		"user_email": "users.email",
+		"status":     "workspace_users.status",
+		"created_at": "workspace_users.created_at",
+	},
+	Filters: map[string]commonschema.FilterField{
+		"user_email": {Column: "users.email", Type: commonschema.FilterText},
+		"status":     {Column: "workspace_users.status", Type: commonschema.FilterText},
+		"created_at": {Column: "workspace_users.created_at", Type: commonschema.FilterTime},
+	},
+}
+
+var CampaignQuerySpec = commonschema.QuerySpec{
+	Sorts: map[string]string{
+		"title":      "campaigns.title",
+		"key":        "campaigns.key",
+		"is_publish": "campaigns.is_publish",
+		"created_at": "campaigns.created_at",
+	},
+	Filters: map[string]commonschema.FilterField{
+		"title":      {Column: "campaigns.title", Type: commonschema.FilterText},
+		"is_publish": {Column: "campaigns.is_publish", Type: commonschema.FilterBool},
+		"created_at": {Column: "campaigns.created_at", Type: commonschema.FilterTime},
+	},
+}
+
+var CampaignSeoQuerySpec = commonschema.QuerySpec{
+	Sorts: map[string]string{
+		"platform":   "campaign_seos.platform",
+		"event":      "campaign_seos.event",
+		"created_at": "campaign_seos.created_at",
+	},
+	Filters: map[string]commonschema.FilterField{
+		"platform":   {Column: "campaign_seos.platform", Type: commonschema.FilterText},
+		"event":      {Column: "campaign_seos.event", Type: commonschema.FilterText},
+		"created_at": {Column: "campaign_seos.created_at", Type: commonschema.FilterTime},
+	},
+}
+
+var FormQuerySpec = commonschema.QuerySpec{
+	Sorts: map[string]string{
+		"name":       "forms.name",
+		"code":       "forms.code",
+		"created_at": "forms.created_at",
+	},
+	Filters: map[string]commonschema.FilterField{
+		"name": {Column: "forms.name", Type: commonschema.FilterText},
+		"code": {Column: "forms.code", Type: commonschema.FilterText},
+	},
+}
+
+// FormEntryQuerySpec is used by entry list of campaign
+var FormEntryQuerySpec = commonschema.QuerySpec{
+	Sorts: map[string]string{
+		"created_at": "form_entries.created_at",
+		"status":     "form_entries.status",
+		"user_name":  "users.fullname",
+		"user_email": "users.email",
+	},
+	Filters: map[string]commonschema.FilterField{
+		"status":     {Column: "form_entries.status", Type: commonschema.FilterText},
+		"user_email": {Column: "users.email", Type: commonschema.FilterText},
+		"created_at": {Column: "form_entries.created_at", Type: commonschema.FilterTime},
+	},
+}
+
+// HistoryQuerySpec is used by entry history of logged user
+var HistoryQuerySpec = commonschema.QuerySpec{
+	Sorts: map[string]string{
+		"created_at":     "form_entries.created_at",
+		"status":         "form_entries.status",
+		"campaign_title": "campaigns.title",
+	},
+	Filters: map[string]commonschema.FilterField{
+		"status":      {Column: "form_entries.status", Type: commonschema.FilterText},
+		"campaign_id": {Column: "form_entries.campaign_id", Type: commonschema.FilterUUID},
+		"created_at":  {Column: "form_entries.created_at", Type: commonschema.FilterTime},
+	},
+}
+
+var AuditLogQuerySpec = commonschema.QuerySpec{
+	Sorts: map[string]string{
+		"action":      "audit_logs.action",
+		"entity_type": "audit_logs.entity_type",
+		"created_at":  "audit_logs.created_at",
+	},
+	Filters: map[string]commonschema.FilterField{
+		"action":       {Column: "audit_logs.action", Type: commonschema.FilterText},
+		"entity_type":  {Column: "audit_logs.entity_type", Type: commonschema.FilterText},
+		"entity_id":    {Column: "audit_logs.entity_id", Type: commonschema.FilterText},
+		"actor_id":     {Column: "audit_logs.actor_id", Type: commonschema.FilterUUID},
+		"workspace_id": {Column: "audit_logs.workspace_id", Type: commonschema.FilterUUID},
+		"store_id":     {Column: "audit_logs.store_id", Type: commonschema.FilterUUID},
+		"ip":           {Column: "audit_logs.ip", Type: commonschema.FilterText},
+		"created_at":   {Column: "audit_logs.created_at", Type: commonschema.FilterTime},
+	},
+}
+
+var TrashQuerySpec = commonschema.QuerySpec{
+	Sorts: map[string]string{
+		"title":       "trash.title",
+		"entity_type": "trash.entity_type",
+		"deleted_at":  "trash.deleted_at",
+	},
+	Filters: map[string]commonschema.FilterField{
+		"entity_type": {Column: "trash.entity_type", Type: commonschema.FilterText},
+		"parent_id":   {Column: "trash.parent_id", Type: commonschema.FilterUUID},
+		"deleted_by":  {Column: "trash.deleted_by", Type: commonschema.FilterUUID},
+		"deleted_at":  {Column: "trash.deleted_at", Type: commonschema.FilterTime},
+	},
+}
+
+var UserQuerySpec = commonschema.QuerySpec{
+	Sorts: map[string]string{
+		"email":      "users.email",
+		"fullname":   "users.fullname",
+		"created_at": "users.created_at",
+	},
+	Filters: map[string]commonschema.FilterField{
+		"email":      {Column: "users.email", Type: commonschema.FilterText},
+		"is_active":  {Column: "users.is_active", Type: commonschema.FilterBool},
+		"is_pending": {Column: "users.is_pending", Type: commonschema.FilterBool},
+		"deleted":    {Column: "users.deleted", Type: commonschema.FilterBool},
+		"created_at": {Column: "users.created_at", Type: commonschema.FilterTime},
+	},
+}
+
+var RoleQuerySpec = commonschema.QuerySpec{
+	Sorts: map[string]string{
+		"name":       "roles.name",
+		"created_at": "roles.created_at",
+	},
+	Filters: map[string]commonschema.FilterField{
+		"name":       {Column: "roles.name", Type: commonschema.FilterText},
+		"created_at": {Column: "roles.created_at", Type: commonschema.FilterTime},
+	},
+}
@@ -0,0 +1,20 @@
+package masterschema
+
+import "time"
+
+type RolePayload struct {
+	Name        string   `json:"name" validate:"required,max=50"`
+	Description string   `json:"description" validate:"max=100"`
+	Permissions []string `json:"permissions" validate:"dive,oneof=users:manage roles:manage workspaces:manage audit:read"`
+}
+
+type RoleList struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Permissions []string   `json:"permissions"`
+	BuiltIn     bool       `json:"built_in"`
+	TotalUser   int64      `json:"total_user"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+}
@@ -0,0 +1,52 @@
+package masterschema
+
+import "time"
+
+type UserActivePayload struct {
+	IsActive *bool `json:"is_active" validate:"required"`
+}
+
+type UserRolePayload struct {
+	Role string `json:"role" validate:"required,max=50"`
+}
+
+type UserPackagePayload struct {
+	Code string `json:"code" validate:"required,max=50"`
+	Days int    `json:"days" validate:"required,min=1,max=3650"`
+}
+
+type UserRoleSchema struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserPackageSchema struct {
+	ID         string    `json:"id"`
+	Code       string    `json:"code"`
+	Name       string    `json:"name"`
+	ActiveDate time.Time `json:"active_date"`
+	ExpireDate time.Time `json:"expire_date"`
+}
+
+// UserList is account seen by admin, Package is nil when user has no active package
+type UserList struct {
+	ID             string             `json:"id"`
+	UserIdentity   string             `json:"user_identity"`
+	Email          string             `json:"email"`
+	Fullname       string             `json:"fullname"`
+	IsActive       bool               `json:"is_active"`
+	IsPending      bool               `json:"is_pending"`
+	Deleted        bool               `json:"deleted"`
+	Roles          []UserRoleSchema   `json:"roles"`
+	Package        *UserPackageSchema `json:"package"`
+	TotalWorkspace int64              `json:"total_workspace"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      *time.Time         `json:"updated_at"`
+}
+
+// UserPasswordResetResponse tells where reset link was sent, token itself is never returned
+type UserPasswordResetResponse struct {
+	Email     string    `json:"email"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
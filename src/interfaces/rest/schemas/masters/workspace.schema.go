@@ -7,6 +7,11 @@ type WorkspacePayload struct {
 	Description string `json:"description"`
 	IsPublish   bool   `json:"is_publish" default:"false"`
 	Thumbnail   string `json:"thumbnail"`
+	Timezone    string `json:"timezone"`
+}
+
+type WorkspaceTwoFactorPayload struct {
+	RequireTwoFactor bool `json:"require_two_factor"`
 }
 
 type WorkspaceList struct {
@@ -17,6 +22,7 @@ type WorkspaceList struct {
 	Description string    `json:"description"`
 	IsPublish   bool      `json:"is_publish"`
 	Thumbnail   string    `json:"thumbnail"`
+	Timezone    string    `json:"timezone"`
 	TotalForm   int64     `json:"total_form"`
 	TotalSubmit int64     `json:"total_submit"`
 	CreatedAt   time.Time `json:"created_at"`
@@ -0,0 +1,49 @@
+package masterschema
+
+type WorkspaceAnalyticsSummary struct {
+	TotalSubmit    int64   `json:"total_submit"`
+	TotalVisit     int64   `json:"total_visit"`
+	TotalPending   int64   `json:"total_pending"`
+	TotalApproved  int64   `json:"total_approved"`
+	TotalRejected  int64   `json:"total_rejected"`
+	ApprovalRate   float64 `json:"approval_rate"`
+	RejectionRate  float64 `json:"rejection_rate"`
+	ConversionRate float64 `json:"conversion_rate"`
+}
+
+type WorkspaceAnalyticsGrowth struct {
+	TotalSubmit    float64 `json:"total_submit"`
+	TotalVisit     float64 `json:"total_visit"`
+	ApprovalRate   float64 `json:"approval_rate"`
+	ConversionRate float64 `json:"conversion_rate"`
+}
+
+type WorkspaceTopCampaign struct {
+	CampaignID     string  `json:"campaign_id"`
+	Title          string  `json:"title"`
+	Key            string  `json:"key"`
+	TotalSubmit    int64   `json:"total_submit"`
+	TotalVisit     int64   `json:"total_visit"`
+	ConversionRate float64 `json:"conversion_rate"`
+}
+
+type WorkspaceTopReviewer struct {
+	UserID        string `json:"user_id"`
+	UserName      string `json:"user_name"`
+	UserEmail     string `json:"user_email"`
+	TotalReviewed int64  `json:"total_reviewed"`
+	TotalApproved int64  `json:"total_approved"`
+	TotalRejected int64  `json:"total_rejected"`
+}
+
+type WorkspaceAnalyticsResponse struct {
+	Parameters      AnalyticsParams           `json:"parameters"`
+	PreviousPeriod  AnalyticsParams           `json:"previous_period"`
+	Summary         WorkspaceAnalyticsSummary `json:"summary"`
+	PreviousSummary WorkspaceAnalyticsSummary `json:"previous_summary"`
+	Growth          WorkspaceAnalyticsGrowth  `json:"growth"`
+	Submissions     []CampaignFormEntryChart  `json:"submissions"`
+	TopSubmissions  []WorkspaceTopCampaign    `json:"top_submissions"`
+	TopConversions  []WorkspaceTopCampaign    `json:"top_conversions"`
+	TopReviewers    []WorkspaceTopReviewer    `json:"top_reviewers"`
+}
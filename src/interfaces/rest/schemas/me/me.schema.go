@@ -26,9 +26,10 @@ type UserProfile struct {
 }
 
 type UserRole struct {
-	RoleID    string    `json:"role_id"`
-	RoleName  string    `json:"role_name"`
-	CreatedAt time.Time `json:"created_at"`
+	RoleID      string    `json:"role_id"`
+	RoleName    string    `json:"role_name"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type UserSummary struct {
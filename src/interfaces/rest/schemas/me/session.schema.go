@@ -0,0 +1,41 @@
+package meschema
+
+import (
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"time"
+)
+
+type SessionSchema struct {
+	ID         string    `json:"id"`
+	Method     string    `json:"method"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+type LoginHistorySchema struct {
+	ID        string    `json:"id"`
+	Method    string    `json:"method"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	NewDevice bool      `json:"new_device"`
+	SessionID *string   `json:"session_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var LoginHistoryQuerySpec = commonschema.QuerySpec{
+	Sorts: map[string]string{
+		"created_at": "login_histories.created_at",
+	},
+	Filters: map[string]commonschema.FilterField{
+		"method":     {Column: "login_histories.method", Type: commonschema.FilterText},
+		"success":    {Column: "login_histories.success", Type: commonschema.FilterBool},
+		"ip":         {Column: "login_histories.ip", Type: commonschema.FilterText},
+		"created_at": {Column: "login_histories.created_at", Type: commonschema.FilterTime},
+	},
+}
@@ -0,0 +1,28 @@
+package meschema
+
+import "time"
+
+type TwoFactorCodePayload struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+type TwoFactorDisablePayload struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
+
+type TwoFactorStatus struct {
+	Enabled           bool       `json:"enabled"`
+	ConfirmedAt       *time.Time `json:"confirmed_at"`
+	RecoveryCodesLeft int64      `json:"recovery_codes_left"`
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+// TwoFactorRecoveryCodes is shown only once, every code signs in once when authenticator is lost
+type TwoFactorRecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,54 @@
+package storeschema
+
+import commonschema "kiraform/src/interfaces/rest/schemas/commons"
+
+var ProductCategoryQuerySpec = commonschema.QuerySpec{
+	Sorts: map[string]string{
+		"name":       "store_product_categories.name",
+		"created_at": "store_product_categories.created_at",
+	},
+	Filters: map[string]commonschema.FilterField{
+		"name":       {Column: "store_product_categories.name", Type: commonschema.FilterText},
+		"created_at": {Column: "store_product_categories.created_at", Type: commonschema.FilterTime},
+	},
+}
+
+var ProductQuerySpec = commonschema.QuerySpec{
+	Sorts: map[string]string{
+		"name":       "store_products.name",
+		"price":      "store_products.price",
+		"created_at": "store_products.created_at",
+	},
+	Filters: map[string]commonschema.FilterField{
+		"name":       {Column: "store_products.name", Type: commonschema.FilterText},
+		"price":      {Column: "store_products.price", Type: commonschema.FilterNumber},
+		"status":     {Column: "store_products.status", Type: commonschema.FilterText},
+		"created_at": {Column: "store_products.created_at", Type: commonschema.FilterTime},
+	},
+}
+
+var ProductFormEntryQuerySpec = commonschema.QuerySpec{
+	Sorts: map[string]string{
+		"created_at":     "form_entries.created_at",
+		"status":         "form_entries.status",
+		"campaign_title": "campaigns.title",
+	},
+	Filters: map[string]commonschema.FilterField{
+		"status":     {Column: "form_entries.status", Type: commonschema.FilterText},
+		"created_at": {Column: "form_entries.created_at", Type: commonschema.FilterTime},
+	},
+}
+
+var TrashQuerySpec = commonschema.QuerySpec{
+	Sorts: map[string]string{
+		"title":       "trash.title",
+		"entity_type": "trash.entity_type",
+		"deleted_at":  "trash.deleted_at",
+	},
+	Filters: map[string]commonschema.FilterField{
+		"entity_type": {Column: "trash.entity_type", Type: commonschema.FilterText},
+		"parent_id":   {Column: "trash.parent_id", Type: commonschema.FilterUUID},
+		"deleted_by":  {Column: "trash.deleted_by", Type: commonschema.FilterUUID},
+		"deleted_at":  {Column: "trash.deleted_at", Type: commonschema.FilterTime},
+	},
+}
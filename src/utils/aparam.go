@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"kiraform/src/applications/apperrors"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const dateLayout = "2006-01-02"
+
+// AParams reads analytics parameters, omitted dates are filled by AnalyticsRange
+// once timezone of workspace is known
+func AParams(c echo.Context) (*masterschema.AnalyticsParams, error) {
+	a := masterschema.AnalyticsParams{
+		StartDate: c.QueryParam("start_date"),
+		EndDate:   c.QueryParam("end_date"),
+		GroupBy:   "day",
+	}
+
+	if c.QueryParam("group_by") != "" {
+		a.GroupBy = strings.ToLower(c.QueryParam("group_by"))
+	}
+
+	// validate parameters
+	if a.StartDate != "" {
+		if _, err := time.Parse(dateLayout, a.StartDate); err != nil {
+			return nil, apperrors.Field("start_date", "must be in YYYY-MM-DD format")
+		}
+	}
+
+	if a.EndDate != "" {
+		if _, err := time.Parse(dateLayout, a.EndDate); err != nil {
+			return nil, apperrors.Field("end_date", "must be in YYYY-MM-DD format")
+		}
+	}
+
+	if a.GroupBy != "day" && a.GroupBy != "week" && a.GroupBy != "month" {
+		return nil, apperrors.Field("group_by", "must be one of day, week or month")
+	}
+
+	return &a, nil
+}
+
+// AnalyticsRange fills omitted dates with default range of last 60 days, today
+// follows params.Timezone so the window does not shift a day around midnight
+func AnalyticsRange(params *masterschema.AnalyticsParams) error {
+	return analyticsRange(params, time.Now())
+}
+
+func analyticsRange(params *masterschema.AnalyticsParams, now time.Time) error {
+	loc, err := time.LoadLocation(params.Timezone)
+	if err != nil {
+		return err
+	}
+
+	today := now.In(loc)
+	if params.StartDate == "" {
+		params.StartDate = today.AddDate(0, 0, -60).Format(dateLayout)
+	}
+	if params.EndDate == "" {
+		params.EndDate = today.Format(dateLayout)
+	}
+
+	// both dates are checked by AParams or set above
+	start, _ := time.Parse(dateLayout, params.StartDate)
+	end, _ := time.Parse(dateLayout, params.EndDate)
+	if end.Before(start) {
+		return apperrors.Field("end_date", "must be after start_date")
+	}
+	return nil
+}
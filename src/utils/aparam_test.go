@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"errors"
+	"kiraform/src/applications/apperrors"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"testing"
+	"time"
+	_ "time/tzdata" // zones are known even without system tzdata
+)
+
+func TestAnalyticsRangeFollowsTimezone(t *testing.T) {
+	cases := []struct {
+		name     string
+		timezone string
+		now      string
+		start    string
+		end      string
+	}{
+		{"utc", "UTC", "2026-03-01T17:30:00Z", "2025-12-31", "2026-03-01"},
+		{"ahead of utc past midnight", "Asia/Jakarta", "2026-03-01T17:30:00Z", "2026-01-01", "2026-03-02"},
+		{"behind utc before midnight", "America/Los_Angeles", "2026-03-01T07:30:00Z", "2025-12-30", "2026-02-28"},
+		{"day after spring forward", "America/New_York", "2026-03-09T04:30:00Z", "2026-01-08", "2026-03-09"},
+		{"day of fall back", "Europe/Berlin", "2026-10-25T22:30:00Z", "2026-08-26", "2026-10-25"},
+		{"fourteen hours ahead", "Pacific/Kiritimati", "2026-06-30T12:00:00Z", "2026-05-02", "2026-07-01"},
+		{"eleven hours behind", "Pacific/Pago_Pago", "2026-06-30T12:00:00Z", "2026-05-01", "2026-06-30"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			now, err := time.Parse(time.RFC3339, tc.now)
+			if err != nil {
+				t.Fatalf("parse now: %v", err)
+			}
+			params := masterschema.AnalyticsParams{Timezone: tc.timezone}
+			if err := analyticsRange(&params, now); err != nil {
+				t.Fatalf("analytics range: %v", err)
+			}
+			if params.StartDate != tc.start || params.EndDate != tc.end {
+				t.Fatalf("got %s to %s, want %s to %s", params.StartDate, params.EndDate, tc.start, tc.end)
+			}
+		})
+	}
+}
+
+func TestAnalyticsRangeKeepsGivenDates(t *testing.T) {
+	now := time.Date(2026, 3, 1, 17, 30, 0, 0, time.UTC)
+	params := masterschema.AnalyticsParams{StartDate: "2026-02-01", EndDate: "2026-02-28", Timezone: "Asia/Jakarta"}
+	if err := analyticsRange(&params, now); err != nil {
+		t.Fatalf("analytics range: %v", err)
+	}
+	if params.StartDate != "2026-02-01" || params.EndDate != "2026-02-28" {
+		t.Fatalf("got %s to %s, want given dates", params.StartDate, params.EndDate)
+	}
+
+	// only start is given, end is today of the timezone
+	params = masterschema.AnalyticsParams{StartDate: "2026-03-02", Timezone: "Asia/Jakarta"}
+	if err := analyticsRange(&params, now); err != nil {
+		t.Fatalf("analytics range: %v", err)
+	}
+	if params.EndDate != "2026-03-02" {
+		t.Fatalf("got end %s, want today of Jakarta", params.EndDate)
+	}
+
+	// the same start is after today of UTC
+	params = masterschema.AnalyticsParams{StartDate: "2026-03-02", Timezone: "UTC"}
+	var appErr *apperrors.Error
+	if err := analyticsRange(&params, now); !errors.As(err, &appErr) || appErr.Code != apperrors.CodeValidation {
+		t.Fatalf("got error %v, want end date validation", err)
+	}
+
+	params = masterschema.AnalyticsParams{Timezone: "Mars/Olympus"}
+	if err := analyticsRange(&params, now); err == nil {
+		t.Fatal("unknown timezone is accepted")
+	}
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+type cacheItem[T any] struct {
+	value     T
+	expiredAt time.Time
+}
+
+// Cache is a small in-memory cache with time-to-live for each key
+type Cache[T any] struct {
+	mu    sync.RWMutex
+	ttl   time.Duration
+	items map[string]cacheItem[T]
+}
+
+func NewCache[T any](ttl time.Duration) *Cache[T] {
+	return &Cache[T]{
+		ttl:   ttl,
+		items: map[string]cacheItem[T]{},
+	}
+}
+
+func (c *Cache[T]) Get(key string) (T, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, ok := c.items[key]
+	if !ok || time.Now().After(item.expiredAt) {
+		var empty T
+		return empty, false
+	}
+	return item.value, true
+}
+
+func (c *Cache[T]) Set(key string, value T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// clean up expired items to keep memory small
+	now := time.Now()
+	for k, v := range c.items {
+		if now.After(v.expiredAt) {
+			delete(c.items, k)
+		}
+	}
+
+	c.items[key] = cacheItem[T]{
+		value:     value,
+		expiredAt: now.Add(c.ttl),
+	}
+}
+
+func (c *Cache[T]) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+}
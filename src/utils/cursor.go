@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"kiraform/src/applications/apperrors"
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"slices"
+	"time"
+)
+
+// cursorTimeLayout follows postgres timestamp text output
+const cursorTimeLayout = "2006-01-02 15:04:05.999999"
+
+func EncodeCursor(key commonschema.CursorKey) string {
+	b, _ := json.Marshal(key)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCursor(token string) (*commonschema.CursorKey, error) {
+	invalid := apperrors.Field("cursor", "is not valid")
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalid
+	}
+
+	var key commonschema.CursorKey
+	if err := json.Unmarshal(b, &key); err != nil || key.CreatedAt == "" || key.ID == "" {
+		return nil, invalid
+	}
+
+	if _, err := time.Parse(cursorTimeLayout, key.CreatedAt); err != nil {
+		return nil, invalid
+	}
+	return &key, nil
+}
+
+// CursorTime formats time as used in cursor token
+func CursorTime(t time.Time) string {
+	return t.Format(cursorTimeLayout)
+}
+
+// CursorClause returns keyset condition and order for cursor pagination,
+// order is empty when request uses offset pagination
+func CursorClause(params *commonschema.QueryParams, createdColumn string, idColumn string) (string, []any, string) {
+	if params == nil || params.Pagination != "cursor" {
+		return "", nil, ""
+	}
+
+	// previous page is read in reverse order, then flipped back by CursorPage
+	order := createdColumn + " DESC, " + idColumn + " DESC"
+	operator := "<"
+	if params.CursorKey != nil && params.CursorKey.Prev {
+		order = createdColumn + " ASC, " + idColumn + " ASC"
+		operator = ">"
+	}
+
+	if params.CursorKey == nil {
+		return "", nil, order
+	}
+
+	condition := "(" + createdColumn + ", " + idColumn + ") " + operator + " (?::TIMESTAMP, ?::UUID)"
+	return condition, []any{params.CursorKey.CreatedAt, params.CursorKey.ID}, order
+}
+
+// CursorPage trims rows fetched with limit + 1 and builds next and previous cursor,
+// key returns created_at and id of each row
+func CursorPage[T any](rows []T, params *commonschema.QueryParams, key func(T) (string, string)) ([]T, *string, *string) {
+	if params == nil || params.Pagination != "cursor" {
+		return rows, nil, nil
+	}
+
+	hasMore := len(rows) > params.Limit
+	if hasMore {
+		rows = rows[:params.Limit]
+	}
+
+	isPrev := params.CursorKey != nil && params.CursorKey.Prev
+	if isPrev {
+		slices.Reverse(rows)
+	}
+
+	if len(rows) == 0 {
+		return rows, nil, nil
+	}
+
+	var next, prev *string
+	if hasMore || isPrev {
+		createdAt, ID := key(rows[len(rows)-1])
+		token := EncodeCursor(commonschema.CursorKey{CreatedAt: createdAt, ID: ID})
+		next = &token
+	}
+
+	if (params.CursorKey != nil && !isPrev) || (isPrev && hasMore) {
+		createdAt, ID := key(rows[0])
+		token := EncodeCursor(commonschema.CursorKey{CreatedAt: createdAt, ID: ID, Prev: true})
+		prev = &token
+	}
+
+	return rows, next, prev
+}
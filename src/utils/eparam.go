@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"fmt"
+	"kiraform/src/applications/apperrors"
+	masterschema "kiraform/src/interfaces/rest/schemas/masters"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+var fieldOperators = []string{"eq", "contains", "gt", "gte", "lt", "lte"}
+
+// EParams reads form entry filters from query string.
+// field filter format is field[<campaign_form_id>]=<operator>:<value>, eg. field[uuid]=contains:1234
+func EParams(c echo.Context) (*masterschema.FormEntryFilter, error) {
+	f := masterschema.FormEntryFilter{
+		Status:    strings.ToUpper(c.QueryParam("status")),
+		StartDate: c.QueryParam("start_date"),
+		EndDate:   c.QueryParam("end_date"),
+		Timezone:  "UTC",
+	}
+
+	// validate status and dates
+	if f.Status != "" && f.Status != "S1" && f.Status != "S2" && f.Status != "S3" && f.Status != "S4" {
+		return nil, apperrors.Field("status", "must be one of S1, S2, S3 or S4")
+	}
+
+	if f.StartDate != "" {
+		if _, err := time.Parse(dateLayout, f.StartDate); err != nil {
+			return nil, apperrors.Field("start_date", "must be in YYYY-MM-DD format")
+		}
+	}
+
+	if f.EndDate != "" {
+		if _, err := time.Parse(dateLayout, f.EndDate); err != nil {
+			return nil, apperrors.Field("end_date", "must be in YYYY-MM-DD format")
+		}
+	}
+
+	// collect field filters
+	for key, values := range c.QueryParams() {
+		if !strings.HasPrefix(key, "field[") || !strings.HasSuffix(key, "]") {
+			continue
+		}
+
+		campaignFormID := strings.TrimSuffix(strings.TrimPrefix(key, "field["), "]")
+		if _, err := uuid.Parse(campaignFormID); err != nil {
+			return nil, apperrors.Field(key, "must use valid campaign form id")
+		}
+
+		for _, v := range values {
+			// value without operator is treated as equal
+			operator, value := "eq", v
+			if o, val, ok := strings.Cut(v, ":"); ok && slices.Contains(fieldOperators, strings.ToLower(o)) {
+				operator, value = strings.ToLower(o), val
+			}
+
+			if operator != "eq" && operator != "contains" {
+				if _, err := strconv.ParseFloat(value, 64); err != nil {
+					return nil, apperrors.Field(key, fmt.Sprintf("must be a number for operator %s", operator))
+				}
+			}
+
+			f.Fields = append(f.Fields, masterschema.FormEntryFieldFilter{
+				CampaignFormID: campaignFormID,
+				Operator:       operator,
+				Value:          value,
+			})
+		}
+	}
+
+	return &f, nil
+}
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UnusablePassword hashes random password of user who signs in without password or whose password
+// is reset by admin, it can not be guessed and is replaced by password reset
+func UnusablePassword() (string, error) {
+	password := make([]byte, 32)
+	if _, err := rand.Read(password); err != nil {
+		return "", err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(password)), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedPassword), nil
+}
@@ -2,14 +2,41 @@ package utils
 
 import (
 	"fmt"
+	"kiraform/src/applications/apperrors"
 	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
-func QParams(c echo.Context) *commonschema.QueryParams {
+// filter key format is filter[field] or filter[field][operator]
+var filterKey = regexp.MustCompile(`^filter\[([a-z_]+)\](?:\[([a-z]+)\])?$`)
+
+// filterTimeLayouts are accepted values of time filter
+var filterTimeLayouts = []string{time.DateOnly, "2006-01-02 15:04:05", "2006-01-02T15:04:05", time.RFC3339Nano}
+
+var filterOperators = map[string]string{
+	"eq":       "=",
+	"ne":       "<>",
+	"gt":       ">",
+	"gte":      ">=",
+	"lt":       "<",
+	"lte":      "<=",
+	"contains": "ILIKE",
+	"in":       "IN",
+}
+
+// likeEscaper makes wildcards and escape character in contains filter match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func QParams(c echo.Context, spec commonschema.QuerySpec) (*commonschema.QueryParams, error) {
 	q := commonschema.QueryParams{
 		Page:    1,  // default
 		Limit:   10, // default
@@ -35,12 +62,192 @@ func QParams(c echo.Context) *commonschema.QueryParams {
 		q.Search = c.QueryParam("search")
 	}
 
+	// orderBy can have multiple keys, eg. orderBy=title:asc,created_at:desc
 	if c.QueryParam("orderBy") != "" {
-		o := strings.Split(c.QueryParam("orderBy"), ":")
-		if len(o) > 1 {
-			q.OrderBy = fmt.Sprintf("%s %s", o[0], o[1])
+		q.OrderBy = c.QueryParam("orderBy")
+		for _, v := range strings.Split(q.OrderBy, ",") {
+			field, direction, _ := strings.Cut(strings.TrimSpace(v), ":")
+			column, ok := spec.Sorts[field]
+			if !ok {
+				return nil, apperrors.Field("orderBy", fmt.Sprintf("field %s is not allowed, allowed values: %s", field, allowed(spec.Sorts)))
+			}
+
+			direction = strings.ToLower(direction)
+			if direction != "" && direction != "asc" && direction != "desc" {
+				return nil, apperrors.Field("orderBy", fmt.Sprintf("direction %s is not allowed, allowed values: asc, desc", direction))
+			}
+
+			q.Sorts = append(q.Sorts, commonschema.SortParam{
+				Field:  field,
+				Column: column,
+				Desc:   direction == "desc",
+			})
+		}
+	}
+
+	// cursor pagination is opt-in, passing cursor token implies it
+	q.Pagination = strings.ToLower(c.QueryParam("pagination"))
+	if q.Pagination == "" {
+		q.Pagination = "offset"
+	}
+	if c.QueryParam("cursor") != "" {
+		key, err := DecodeCursor(c.QueryParam("cursor"))
+		if err != nil {
+			return nil, err
+		}
+		q.Pagination = "cursor"
+		q.Cursor = c.QueryParam("cursor")
+		q.CursorKey = key
+	}
+
+	if q.Pagination != "offset" && q.Pagination != "cursor" {
+		return nil, apperrors.Field("pagination", "must be one of cursor, offset")
+	}
+
+	if q.Pagination == "cursor" && len(q.Sorts) > 0 {
+		return nil, apperrors.Field("orderBy", "is not supported with cursor pagination")
+	}
+
+	// count query is skipped by default on cursor pagination
+	q.SkipCount = q.Pagination == "cursor"
+	if c.QueryParam("count") != "" {
+		count, err := strconv.ParseBool(c.QueryParam("count"))
+		if err != nil {
+			return nil, apperrors.Field("count", "must be true or false")
+		}
+		q.SkipCount = !count
+	}
+
+	// collect filters, sorted by key so generated query is stable
+	queryParams := c.QueryParams()
+	for _, key := range slices.Sorted(maps.Keys(queryParams)) {
+		if !strings.HasPrefix(key, "filter[") {
+			continue
+		}
+
+		match := filterKey.FindStringSubmatch(key)
+		if match == nil {
+			return nil, apperrors.Field(key, "format must be filter[field][operator]=value")
+		}
+
+		field, operator := match[1], match[2]
+		filter, ok := spec.Filters[field]
+		if !ok {
+			return nil, apperrors.Field(key, fmt.Sprintf("field %s is not allowed, allowed values: %s", field, allowed(spec.Filters)))
+		}
+
+		if operator == "" {
+			operator = "eq"
+		}
+		if _, ok := filterOperators[operator]; !ok {
+			return nil, apperrors.Field(key, fmt.Sprintf("operator %s is not allowed, allowed values: %s", operator, allowed(filterOperators)))
+		}
+
+		for _, value := range queryParams[key] {
+			// contains compares column as text, so any value is usable
+			if operator != "contains" {
+				values := []string{value}
+				if operator == "in" {
+					values = strings.Split(value, ",")
+				}
+				for i, v := range values {
+					parsed, err := filterValue(filter.Type, v)
+					if err != nil {
+						return nil, apperrors.Field(key, err.Error())
+					}
+					values[i] = parsed
+				}
+				value = strings.Join(values, ",")
+			}
+
+			q.Filters = append(q.Filters, commonschema.FilterParam{
+				Field:    field,
+				Column:   filter.Column,
+				Operator: operator,
+				Value:    value,
+			})
 		}
 	}
 
-	return &q
+	return &q, nil
+}
+
+// OrderClause builds order statement from validated sorts, def is used when no sort requested,
+// idColumn is appended last so rows with equal sort values keep the same place between pages
+func OrderClause(params *commonschema.QueryParams, def string, idColumn string) string {
+	var orders []string
+	if params != nil && len(params.Sorts) > 0 {
+		for _, v := range params.Sorts {
+			if v.Desc {
+				orders = append(orders, v.Column+" DESC")
+			} else {
+				orders = append(orders, v.Column+" ASC")
+			}
+		}
+	} else if def != "" {
+		orders = append(orders, def)
+	}
+	return strings.Join(append(orders, idColumn+" ASC"), ", ")
+}
+
+// FilterClause builds where condition from validated filters
+func FilterClause(params *commonschema.QueryParams) (string, []any) {
+	if params == nil || len(params.Filters) == 0 {
+		return "", nil
+	}
+
+	var conditions []string
+	var args []any
+	for _, v := range params.Filters {
+		switch v.Operator {
+		case "contains":
+			conditions = append(conditions, v.Column+`::TEXT ILIKE ? ESCAPE '\'`)
+			args = append(args, "%"+likeEscaper.Replace(v.Value)+"%")
+		case "in":
+			conditions = append(conditions, v.Column+" IN ?")
+			args = append(args, strings.Split(v.Value, ","))
+		default:
+			conditions = append(conditions, v.Column+" "+filterOperators[v.Operator]+" ?")
+			args = append(args, v.Value)
+		}
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// filterValue checks value against type of filter column, so bad input is rejected
+// instead of failing in database, the returned value is normalized
+func filterValue(filterType string, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch filterType {
+	case commonschema.FilterBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("value %q must be true or false", value)
+		}
+		return strconv.FormatBool(b), nil
+	case commonschema.FilterNumber:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return "", fmt.Errorf("value %q must be a number", value)
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case commonschema.FilterTime:
+		for _, layout := range filterTimeLayouts {
+			if _, err := time.Parse(layout, value); err == nil {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("value %q must be a date like 2006-01-02 or time like 2006-01-02T15:04:05Z", value)
+	case commonschema.FilterUUID:
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return "", fmt.Errorf("value %q must be a uuid", value)
+		}
+		return id.String(), nil
+	}
+	return value, nil
+}
+
+func allowed[T any](m map[string]T) string {
+	return strings.Join(slices.Sorted(maps.Keys(m)), ", ")
 }
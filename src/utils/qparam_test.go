@@ -0,0 +1,54 @@
+package utils
+
+import (
+	commonschema "kiraform/src/interfaces/rest/schemas/commons"
+	"testing"
+)
+
+func TestFilterClauseEscapesContains(t *testing.T) {
+	cases := []struct {
+		value string
+		want  string
+	}{
+		{"shoe", `%shoe%`},
+		{"50%", `%50\%%`},
+		{"first_name", `%first\_name%`},
+		{`C:\temp`, `%C:\\temp%`},
+		{`\%_`, `%\\\%\_%`},
+	}
+	for _, tc := range cases {
+		params := &commonschema.QueryParams{Filters: []commonschema.FilterParam{
+			{Field: "title", Column: "products.title", Operator: "contains", Value: tc.value},
+		}}
+		condition, args := FilterClause(params)
+		if condition != `products.title::TEXT ILIKE ? ESCAPE '\'` {
+			t.Fatalf("condition = %q", condition)
+		}
+		if len(args) != 1 || args[0] != tc.want {
+			t.Fatalf("value %q: args = %v, want %q", tc.value, args, tc.want)
+		}
+	}
+}
+
+func TestOrderClauseEndsWithID(t *testing.T) {
+	cases := []struct {
+		name   string
+		params *commonschema.QueryParams
+		def    string
+		want   string
+	}{
+		{"no params", nil, "users.created_at DESC", "users.created_at DESC, users.id ASC"},
+		{"no default", &commonschema.QueryParams{}, "", "users.id ASC"},
+		{"requested sorts", &commonschema.QueryParams{Sorts: []commonschema.SortParam{
+			{Field: "status", Column: "users.status"},
+			{Field: "created_at", Column: "users.created_at", Desc: true},
+		}}, "users.created_at DESC", "users.status ASC, users.created_at DESC, users.id ASC"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := OrderClause(tc.params, tc.def, "users.id"); got != tc.want {
+				t.Fatalf("order = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+// SecretBox encrypts values stored in database with AES-GCM keyed by secret key,
+// so database dump alone does not reveal them
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+func NewSecretBox(secret string) (*SecretBox, error) {
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+// Seal returns base64 of nonce followed by encrypted value
+func (b *SecretBox) Seal(plain []byte) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b.aead.Seal(nonce, nonce, plain, nil)), nil
+}
+
+// Open decrypts value made by Seal, it fails when secret key is changed
+func (b *SecretBox) Open(sealed string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, err
+	}
+	size := b.aead.NonceSize()
+	if len(raw) < size {
+		return nil, errors.New("sealed value is too short")
+	}
+	return b.aead.Open(nil, raw[:size], raw[size:], nil)
+}
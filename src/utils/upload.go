@@ -28,9 +28,9 @@ func RemoveImage(filePath string) error {
 	return nil
 }
 
-func UploadImage(base664String string, dir string, uniqueID string) (*string, error) {
+// UploadImage saves base64 image under storage root, returned path is also its url path
+func UploadImage(rootFolder string, maxSize int, base664String string, dir string, uniqueID string) (*string, error) {
 	// define parameters
-	rootFolder := "cdn"
 	targetFolder := fmt.Sprintf("%s/%s", rootFolder, dir)
 	allowedExtensions := map[string]any{
 		"image/png":  ".png",
@@ -38,7 +38,6 @@ func UploadImage(base664String string, dir string, uniqueID string) (*string, er
 		"image/jpg":  ".jpg",
 		"image/webp": ".webp",
 	}
-	maxSize := 2 * 1024 * 1024 // 2MB
 
 	// validating data
 	mimeType, data, err := parseBase64(base664String)
@@ -57,7 +56,7 @@ func UploadImage(base664String string, dir string, uniqueID string) (*string, er
 	}
 
 	if len(decoded) > maxSize {
-		return nil, errors.New("image too large (max 2MB)")
+		return nil, fmt.Errorf("image too large (max %dKB)", maxSize/1024)
 	}
 
 	// ensure folder exists
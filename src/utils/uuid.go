@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"kiraform/src/applications/apperrors"
+
+	"github.com/google/uuid"
+)
+
+// ParseUUID parses uuid from client input, field is used in validation error
+func ParseUUID(value string, field string) (uuid.UUID, error) {
+	ID, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, apperrors.Field(field, "must be a valid uuid")
+	}
+	return ID, nil
+}
+
+// NullableUUID parses trusted uuid string, invalid or empty value becomes nil
+func NullableUUID(value string) *uuid.UUID {
+	ID, err := uuid.Parse(value)
+	if err != nil {
+		return nil
+	}
+	return &ID
+}